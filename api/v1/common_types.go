@@ -1,10 +1,26 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// BuildOnlyAnnotation can be set to "true" to build the image of an object
+// without setting it in .spec.image (i.e. with "sub build"). The workload of
+// the object keeps using its current image and the result of the build is
+// reported in .status.container.
+const BuildOnlyAnnotation = "substratus.ai/build-only"
+
+// AllowedNamespacesAnnotation can be set on a Dataset or Model to a comma
+// separated list of the namespaces (or "*" for all namespaces) whose Models
+// may reference (and mount) it. Objects can always be referenced from their
+// own namespace.
+const AllowedNamespacesAnnotation = "substratus.ai/allowed-namespaces"
+
 // +structType=atomic
+
+// Build describes how to build the container image of an object. Images are
+// built in the cluster with kaniko (gcr.io/kaniko-project/executor).
 type Build struct {
 	// Git is a reference to a git repository that will be built within the cluster.
 	// Built image will be set in the .spec.image field.
@@ -12,6 +28,17 @@ type Build struct {
 	// Upload can be set to request to start an upload flow where the client is
 	// responsible for uploading a local directory that is to be built in the cluster.
 	Upload *BuildUpload `json:"upload,omitempty"`
+
+	// Args are passed to the build as build arguments (ARG instructions in
+	// the Dockerfile).
+	// +kubebuilder:validation:XValidation:rule="self.all(k, k.matches('^[A-Za-z_][A-Za-z0-9_]*$'))",message="args keys must be valid build argument names"
+	Args map[string]string `json:"args,omitempty"`
+	// Target is the stage of a multi-stage Dockerfile to build.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][A-Za-z0-9_.-]*$`
+	Target string `json:"target,omitempty"`
+	// BuilderImage overrides the kaniko executor image (i.e. to use a mirror
+	// or a pinned version). It must accept the flags of the kaniko executor.
+	BuilderImage string `json:"builderImage,omitempty"`
 }
 
 // +structType=atomic
@@ -44,6 +71,33 @@ type BuildGit struct {
 	// This branch will be pulled only at build time and not monitored
 	// for changes.
 	Branch string `json:"branch,omitempty"`
+
+	// SecretRef is a reference to a Secret in the same namespace that
+	// contains credentials for cloning a private repository over HTTPS.
+	// The Secret should contain a "username" and a "password" key
+	// (the password can be a personal access token).
+	SecretRef *ObjectRef `json:"secretRef,omitempty"`
+}
+
+// ContainerStatus describes the container image that was built for an
+// object. It is populated by the builder and is independent of the Ready
+// condition.
+type ContainerStatus struct {
+	// Image is the image that was built and pushed.
+	Image string `json:"image,omitempty"`
+
+	// Digest is the digest of the pushed image (i.e. "sha256:...").
+	Digest string `json:"digest,omitempty"`
+
+	// BuildJob is the name of the latest image-builder Job.
+	BuildJob string `json:"buildJob,omitempty"`
+
+	// Reason is the state of the latest build: JobNotComplete, JobComplete
+	// or JobFailed.
+	Reason string `json:"reason,omitempty"`
+
+	// Message describes why the latest build failed.
+	Message string `json:"message,omitempty"`
 }
 
 type UploadStatus struct {
@@ -66,31 +120,90 @@ type UploadStatus struct {
 	StoredMD5Checksum string `json:"storedMD5Checksum,omitempty"`
 }
 
+// HuggingFaceSource is a repository on the HuggingFace Hub that is
+// downloaded by the built-in loader.
+type HuggingFaceSource struct {
+	// Repo is the ID of the repository (i.e. "tiiuae/falcon-7b").
+	Repo string `json:"repo"`
+
+	// Revision is a branch, tag or commit of the repository
+	// (defaults to the default branch).
+	Revision string `json:"revision,omitempty"`
+
+	// TokenSecretRef references a Secret key that holds a HuggingFace access
+	// token. Required for gated and private repositories.
+	TokenSecretRef *corev1.SecretKeySelector `json:"tokenSecretRef,omitempty"`
+}
+
 type ObjectRef struct {
 	// Name of Kubernetes object.
 	Name string `json:"name"`
 
-	// FUTURE: Possibly allow for cross-namespace references.
+	// Namespace of Kubernetes object, defaults to the namespace of the
+	// referencing object. Currently only honored for the base Model and the
+	// Datasets of a Model: the referenced artifacts are mounted read-only
+	// and owner references (garbage collection) never cross namespaces.
+	// The referenced object has to allow the namespace of the Model with the
+	// substratus.ai/allowed-namespaces annotation.
+	// The Model and Dataset references of Notebooks and Servers reject it.
+	Namespace string `json:"namespace,omitempty"`
+
 	// FUTURE: Possibly allow for cross-cluster references.
 }
 
 type Resources struct {
-	//+kubebuilder:default:=2
-	// CPU resources.
+	// CPU resources. Defaults to the cluster-wide defaults for the GPU
+	// type (if configured), otherwise 2.
 	CPU int64 `json:"cpu,omitempty"`
 
-	//+kubebuilder:default:=10
-	// Disk size in Gigabytes.
+	// Disk size in Gigabytes, defaulted like CPU (otherwise 10). This is
+	// requested as ephemeral storage and should cover anything written to
+	// local (non-/content) directories such as caches and scratch space.
+	// Artifacts, datasets and models that are mounted from buckets (e.g. via
+	// gcsfuse on GCP) do not consume this local disk.
 	Disk int64 `json:"disk,omitempty"`
 
-	//+kubebuilder:default:=10
-	// Memory is the amount of RAM in Gigabytes.
+	// Memory is the amount of RAM in Gigabytes, defaulted like CPU
+	// (otherwise 10). When GPUs are requested, at least the total memory of
+	// the GPUs is requested (capped by Limits.Memory if set).
 	Memory int64 `json:"memory,omitempty"`
 
 	// GPU resources.
 	GPU *GPUResources `json:"gpu,omitempty"`
+
+	//+kubebuilder:validation:Minimum=1
+	// Nodes is the number of Pods that a Model's training is distributed
+	// across. Each Pod receives the resources above. Only used by Models.
+	Nodes int32 `json:"nodes,omitempty"`
+
+	//+kubebuilder:validation:MaxLength=63
+	//+kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// NodePool pins the Pods to the node pool with the given name (the
+	// "cloud.google.com/gke-nodepool" node label on GCP), in addition to the
+	// node selectors of the requested GPU type. Ignored on kind.
+	NodePool string `json:"nodePool,omitempty"`
+
+	// Limits optionally caps CPU and Memory above the amounts requested
+	// above. When not set, no CPU or Memory limits are applied.
+	// GPU limits always equal the requested GPU count.
+	Limits *ResourceLimits `json:"limits,omitempty"`
+}
+
+type ResourceLimits struct {
+	// CPU limit. Must not be less than the requested CPU.
+	CPU int64 `json:"cpu,omitempty"`
+
+	// Memory limit in Gigabytes. Must not be less than the requested Memory.
+	Memory int64 `json:"memory,omitempty"`
+
+	// Disk limit in Gigabytes. Must not be less than the requested Disk.
+	// Pods that write more than this amount to local disk are evicted.
+	Disk int64 `json:"disk,omitempty"`
 }
 
+//+kubebuilder:validation:Enum=nvidia-a100;nvidia-t4;nvidia-l4
+
+// GPUType is the type of GPU. Not every type is available on every cloud.
 type GPUType string
 
 const (
@@ -99,13 +212,78 @@ const (
 	GPUTypeNvidiaL4   = GPUType("nvidia-l4")
 )
 
+// GPUTypes lists all known GPU types. Keep in sync with the enum validation
+// of GPUType.
+var GPUTypes = []GPUType{
+	GPUTypeNvidiaA100,
+	GPUTypeNvidiaT4,
+	GPUTypeNvidiaL4,
+}
+
 type GPUResources struct {
 	// Type of GPU.
 	Type GPUType `json:"type,omitempty"`
-	// Count is the number of GPUs.
+	// Count is the number of GPUs (the number of MIG partitions when shared
+	// with the MIG strategy).
 	Count int64 `json:"count,omitempty"`
+	// Sharing requests a share of a GPU instead of whole GPUs, i.e. for
+	// lightweight Notebooks.
+	Sharing *GPUSharing `json:"sharing,omitempty"`
+}
+
+//+kubebuilder:validation:Enum=TimeSharing;MIG
+
+// GPUSharingStrategy is the way that a GPU is shared between containers.
+type GPUSharingStrategy string
+
+const (
+	// GPUSharingTimeSharing shares a whole GPU between containers that take
+	// turns using it (no memory or fault isolation).
+	GPUSharingTimeSharing GPUSharingStrategy = "TimeSharing"
+	// GPUSharingMIG partitions a GPU into isolated Multi-Instance GPU
+	// partitions. Only supported by some GPU types (i.e. nvidia-a100).
+	GPUSharingMIG GPUSharingStrategy = "MIG"
+)
+
+//+kubebuilder:validation:XValidation:rule="self.strategy == 'MIG' ? has(self.profile) : !has(self.profile)",message="profile is required for (and only supported by) the MIG strategy"
+
+// GPUSharing configures how a GPU is shared.
+type GPUSharing struct {
+	// Strategy used to share the GPU.
+	Strategy GPUSharingStrategy `json:"strategy"`
+
+	// MaxSharedClients is the maximum number of containers that share a GPU
+	// with the TimeSharing strategy (defaults to 2 on GKE).
+	//+kubebuilder:validation:Minimum=2
+	MaxSharedClients int32 `json:"maxSharedClients,omitempty"`
+
+	// Profile is the MIG partition size (i.e. "1g.5gb"), the memory of the
+	// partition is used in place of the memory of the GPU.
+	Profile string `json:"profile,omitempty"`
 }
 
 type ArtifactsStatus struct {
+	// URL of the bucket prefix that contains the artifacts. Artifacts can
+	// consist of any number of files and directories under this prefix.
 	URL string `json:"url,omitempty"`
+
+	// Checksum is a combined checksum of all artifact objects, it changes
+	// when any artifact is added, removed or modified.
+	Checksum string `json:"checksum,omitempty"`
+
+	// ObjectCount is the number of artifact objects when the Job that wrote
+	// them completed.
+	ObjectCount int64 `json:"objectCount,omitempty"`
+
+	// SizeBytes is the total size of the artifact objects when the Job that
+	// wrote them completed.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// Cloud is the name of the cloud that the artifacts are stored in
+	// (i.e. "gcp").
+	Cloud string `json:"cloud,omitempty"`
+
+	// Region is the region (or multi-region) that the artifact bucket is
+	// located in, empty when unknown (i.e. the cloud does not have one).
+	Region string `json:"region,omitempty"`
 }
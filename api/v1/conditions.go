@@ -1,25 +1,58 @@
 package v1
 
 const (
-	ConditionUploaded = "Uploaded"
-	ConditionBuilt    = "Built"
-	ConditionComplete = "Complete"
-	ConditionServing  = "Serving"
+	ConditionUploaded  = "Uploaded"
+	ConditionBuilt     = "Built"
+	ConditionComplete  = "Complete"
+	ConditionServing   = "Serving"
+	ConditionPublished = "Published"
+
+	// ConditionResourcePressure is informational, it is set when the usage of
+	// the Pod(s) backing an object has been close to its limits for a
+	// sustained period of time.
+	ConditionResourcePressure = "ResourcePressure"
+
+	// ConditionUnschedulable is informational, it is set when the Pod(s)
+	// backing an object can not be scheduled (i.e. no nodes with the
+	// requested GPUs are available).
+	ConditionUnschedulable = "Unschedulable"
+
+	// ConditionDataDrift is informational, it is set when the artifacts of a
+	// loaded Dataset changed in the bucket after they were recorded (i.e. by
+	// an out-of-band write). The Dataset is not re-loaded automatically.
+	ConditionDataDrift = "DataDrift"
+
+	// ConditionBucketLocationMismatch is informational, it is set when the
+	// artifact bucket that an object writes to is not located where the cloud
+	// configuration expects it to be (access is slower and might incur
+	// egress costs).
+	ConditionBucketLocationMismatch = "BucketLocationMismatch"
 )
 
 const (
 	ReasonModelNotFound = "ModelNotFound"
 	ReasonModelNotReady = "ModelNotReady"
+	// ReasonModelRevisionNotFound is set when the Model revision that a
+	// Server pins does not exist.
+	ReasonModelRevisionNotFound = "ModelRevisionNotFound"
+	// ReasonModelArtifactsEmpty is set when the Model revision that a Server
+	// would serve has no artifacts.
+	ReasonModelArtifactsEmpty = "ModelArtifactsEmpty"
 
 	ReasonBaseModelNotFound = "BaseModelNotFound"
 	ReasonBaseModelNotReady = "BaseModelNotReady"
 
 	ReasonDatasetNotFound = "DatasetNotFound"
 	ReasonDatasetNotReady = "ReasonDatasetNotReady"
+	// ReasonReferenceNotAllowed is set when a referenced object in another
+	// namespace does not allow references from the namespace of the Model.
+	ReasonReferenceNotAllowed = "ReferenceNotAllowed"
 
 	ReasonJobNotComplete     = "JobNotComplete"
 	ReasonJobComplete        = "JobComplete"
 	ReasonJobFailed          = "JobFailed"
+	ReasonTimedOut           = "TimedOut"
+	ReasonContainerCrashing  = "ContainerCrashing"
 	ReasonDeploymentReady    = "DeploymentReady"
 	ReasonDeploymentNotReady = "DeploymentNotReady"
 	ReasonPodReady           = "PodReady"
@@ -27,6 +60,27 @@ const (
 
 	ReasonSuspended = "Suspended"
 
+	// ReasonFailed is set when reconciling an object failed in a way that
+	// retrying will not resolve (i.e. the spec can not be turned into a Pod).
+	ReasonFailed = "Failed"
+
 	ReasonAwaitingUpload = "AwaitingUpload"
 	ReasonUploadFound    = "UploadFound"
+
+	ReasonResourceUsageHigh   = "ResourceUsageHigh"
+	ReasonResourceUsageNormal = "ResourceUsageNormal"
+
+	ReasonConfigError = "ConfigError"
+
+	ReasonKMSKeyInaccessible = "KMSKeyInaccessible"
+
+	ReasonBucketNotColocated = "BucketNotColocated"
+
+	ReasonMountDriverNotInstalled = "MountDriverNotInstalled"
+
+	ReasonPodUnschedulable = "PodUnschedulable"
+	ReasonPodsScheduled    = "PodsScheduled"
+
+	ReasonChecksumChanged = "ChecksumChanged"
+	ReasonChecksumMatches = "ChecksumMatches"
 )
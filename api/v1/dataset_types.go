@@ -1,16 +1,24 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
+//+kubebuilder:validation:XValidation:rule="!has(self.source) || (!has(self.image) && !has(self.build))",message="source can not be combined with image or build"
+//+kubebuilder:validation:XValidation:rule="!has(self.source) || !has(self.sample)",message="sample is not supported by the built-in loaders of source"
+
 // DatasetSpec defines the desired state of Dataset.
 type DatasetSpec struct {
 	// Command to run in the container.
 	Command []string `json:"command,omitempty"`
 
+	// Args to pass to the Command, or to the entrypoint of the image when
+	// Command is not set. Ignored when Source is set.
+	Args []string `json:"args,omitempty"`
+
 	// Environment variables in the container
 	Env map[string]string `json:"env,omitempty"`
 
@@ -20,14 +28,89 @@ type DatasetSpec struct {
 	// Build specifies how to build an image.
 	Build *Build `json:"build,omitempty"`
 
-	// Resources are the compute resources required by the container.
+	// Format of the loaded data. It is passed to the loader and to the
+	// containers of Models that are trained on this Dataset in the
+	// DATASET_FORMAT environment variable.
+	Format DatasetFormat `json:"format,omitempty"`
+
+	// Source loads the Dataset with a built-in loader instead of a loader
+	// image (Image and Build must not be set, Command is ignored).
+	Source *DatasetSource `json:"source,omitempty"`
+
+	// Resources are the compute resources required by the data-loader
+	// container. Memory and disk limits default to the requested amounts
+	// unless set in Limits.
 	Resources *Resources `json:"resources,omitempty"`
 
-	// Params will be passed into the loading process as environment variables.
-	Params map[string]intstr.IntOrString `json:"params,omitempty"`
+	// PriorityClassName is the PriorityClass of the data-loader Pod.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// InitContainers run in order before the loader container (i.e. to
+	// download a tokenizer or decrypt a secret). They get the volume mounts
+	// of the loader container to stage files in /content/artifacts. On GKE
+	// the bucket is only mounted in init containers when the gcsfuse
+	// sidecar runs as a native sidecar (GKE 1.29+).
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
+	// Params will be passed into the loading process as environment variables
+	// and as a JSON file. Values can be any JSON value, including nested
+	// objects and lists.
+	Params map[string]apiextensionsv1.JSON `json:"params,omitempty"`
+
+	// MountOptions are additional gcsfuse mount options that are used
+	// wherever this Dataset is mounted (ignored on clouds that do not use gcsfuse).
+	// An empty value sets a flag without a value.
+	// Example: {"stat-cache-ttl": "1h", "max-conns-per-host": "100"}
+	MountOptions map[string]string `json:"mountOptions,omitempty"`
+
+	// Timeout is the maximum duration that the data-loader Job may run for
+	// (i.e. "2h"), measured from when the Job started. Once exceeded, the
+	// Job is stopped and the Complete condition reports TimedOut.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Sample loads only a subset of the data, to iterate on a trainer
+	// without loading the full Dataset. The loader receives it in the
+	// SAMPLE_COUNT or SAMPLE_FRACTION environment variable and is
+	// responsible for the sampling. Changing or removing the sample of a
+	// loaded Dataset loads it again.
+	Sample *DatasetSample `json:"sample,omitempty"`
 }
 
-func (d *Dataset) GetParams() map[string]intstr.IntOrString {
+//+kubebuilder:validation:XValidation:rule="has(self.count) != has(self.fraction)",message="exactly one of count or fraction must be set"
+
+// DatasetSample is a subset of the data of a Dataset.
+type DatasetSample struct {
+	// Count is the number of records to load (i.e. lines of a jsonl file or
+	// rows of a table, as interpreted by the loader).
+	//+kubebuilder:validation:Minimum=1
+	Count int64 `json:"count,omitempty"`
+
+	// Fraction of the records to load, a decimal between 0 and 1
+	// (i.e. "0.01").
+	//+kubebuilder:validation:Pattern=`^0?\.[0-9]*[1-9][0-9]*$`
+	Fraction string `json:"fraction,omitempty"`
+}
+
+//+kubebuilder:validation:Enum=jsonl;json;csv;parquet;text;images
+
+// DatasetFormat is the format of the files of a Dataset.
+type DatasetFormat string
+
+//+kubebuilder:validation:XValidation:rule="has(self.http) != has(self.huggingFace)",message="exactly one of http or huggingFace must be set"
+
+// DatasetSource is a location that the built-in loader copies into the
+// Dataset artifacts.
+type DatasetSource struct {
+	// HTTP is an http(s) URL of a file that is downloaded as-is into the
+	// Dataset artifacts (i.e. "https://example.com/data/train.jsonl").
+	//+kubebuilder:validation:Pattern=`^https?://`
+	HTTP string `json:"http,omitempty"`
+
+	// HuggingFace downloads a dataset repository from the HuggingFace Hub.
+	HuggingFace *HuggingFaceSource `json:"huggingFace,omitempty"`
+}
+
+func (d *Dataset) GetParams() map[string]apiextensionsv1.JSON {
 	return d.Spec.Params
 }
 
@@ -72,6 +155,14 @@ func (d *Dataset) GetStatusUpload() UploadStatus {
 	return d.Status.BuildUpload
 }
 
+func (d *Dataset) SetStatusContainer(c ContainerStatus) {
+	d.Status.Container = c
+}
+
+func (d *Dataset) GetStatusContainer() ContainerStatus {
+	return d.Status.Container
+}
+
 // DatasetStatus defines the observed state of Dataset.
 type DatasetStatus struct {
 	// Ready indicates that the Dataset is ready to use. See Conditions for more details.
@@ -86,12 +177,35 @@ type DatasetStatus struct {
 
 	// BuildUpload contains the status of the build context upload.
 	BuildUpload UploadStatus `json:"buildUpload,omitempty"`
+
+	// Container contains the status of the container image build.
+	Container ContainerStatus `json:"container,omitempty"`
+
+	// JobName is the name of the Job that loads the Dataset
+	// (i.e. for "kubectl logs job/<jobName>").
+	JobName string `json:"jobName,omitempty"`
+
+	// Format of the loaded data (from Spec.Format), set once the Dataset
+	// is loaded.
+	Format DatasetFormat `json:"format,omitempty"`
+
+	// Sample is set when the loaded data is a sample (from Spec.Sample).
+	// The artifacts checksum then describes the sampled data.
+	Sample *DatasetSample `json:"sample,omitempty"`
 }
 
 //+kubebuilder:resource:categories=ai,shortName=data
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+//+kubebuilder:printcolumn:name="Format",type="string",JSONPath=".status.format"
+//+kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.artifacts.url"
+//+kubebuilder:printcolumn:name="Size",type="integer",JSONPath=".status.artifacts.sizeBytes"
+//+kubebuilder:printcolumn:name="Objects",type="integer",JSONPath=".status.artifacts.objectCount",priority=1
+//+kubebuilder:printcolumn:name="Job",type="string",JSONPath=".status.jobName",priority=1
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".status.artifacts.cloud"
+//+kubebuilder:printcolumn:name="Region",type="string",JSONPath=".status.artifacts.region"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // The Dataset API is used to describe data that can be referenced for training Models.
 //
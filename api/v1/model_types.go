@@ -1,16 +1,24 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
+//+kubebuilder:validation:XValidation:rule="!has(self.dataset) || !has(self.datasets)",message="dataset and datasets are mutually exclusive"
+//+kubebuilder:validation:XValidation:rule="!has(self.source) || (!has(self.image) && !has(self.build) && !has(self.model))",message="source can not be combined with image, build or model"
+
 // ModelSpec defines the desired state of Model
 type ModelSpec struct {
 	// Command to run in the container.
 	Command []string `json:"command,omitempty"`
 
+	// Args to pass to the Command, or to the entrypoint of the image when
+	// Command is not set. Ignored when Source is set.
+	Args []string `json:"args,omitempty"`
+
 	// Environment variables in the container
 	Env map[string]string `json:"env,omitempty"`
 
@@ -20,22 +28,91 @@ type ModelSpec struct {
 	// Build specifies how to build an image.
 	Build *Build `json:"build,omitempty"`
 
+	// Source imports the Model with a built-in loader instead of a loader
+	// image (Image, Build and Model must not be set, Command is ignored).
+	// Other Models can then use this Model as their base Model.
+	Source *ModelSource `json:"source,omitempty"`
+
 	// Resources are the compute resources required by the container.
 	Resources *Resources `json:"resources,omitempty"`
 
+	// PriorityClassName is the PriorityClass of the modeller Pods. A higher
+	// priority allows training to preempt lower priority Pods to free up
+	// GPUs on a shared cluster.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// InitContainers run in order before the modeller container (i.e. to
+	// download a tokenizer or warm a cache). They get the volume mounts of
+	// the modeller container to stage files that it consumes. On GKE the
+	// buckets are only mounted in init containers when the gcsfuse sidecar
+	// runs as a native sidecar (GKE 1.29+).
+	InitContainers []corev1.Container `json:"initContainers,omitempty"`
+
 	// Model should be set in order to mount another model to be
-	// used for transfer learning.
+	// used for transfer learning. The base Model can be in another
+	// namespace (i.e. a central namespace for shared base Models).
 	Model *ObjectRef `json:"model,omitempty"`
 
-	// Dataset to mount for training.
+	// Dataset to mount for training. The Dataset can be in another
+	// namespace.
 	Dataset *ObjectRef `json:"dataset,omitempty"`
 
+	// Datasets to mount for training when combining multiple Datasets.
+	// Each Dataset is mounted at /content/data/<name> and can be in another
+	// namespace, the names must be unique. Mutually exclusive with Dataset.
+	//+kubebuilder:validation:XValidation:rule="self.all(x, self.exists_one(y, y.name == x.name))",message="datasets must have unique names"
+	Datasets []ObjectRef `json:"datasets,omitempty"`
+
 	// Parameters are passing into the model training/loading container as environment variables.
 	// Environment variable name will be `"PARAM_" + uppercase(key)`.
-	Params map[string]intstr.IntOrString `json:"params,omitempty"`
+	// All parameters (including nested objects and lists) are also available
+	// in /content/params.json.
+	Params map[string]apiextensionsv1.JSON `json:"params,omitempty"`
+
+	// Resume enables checkpointing: a "checkpoints" directory that is persisted
+	// in the Model bucket is mounted into the container and restarts of the
+	// modeller Pod (e.g. after preemption) do not count towards Job retries.
+	// The container is expected to resume from the latest checkpoint found
+	// in $CHECKPOINTS_DIR.
+	Resume bool `json:"resume,omitempty"`
+
+	// MountOptions are additional gcsfuse mount options that are used
+	// wherever this Model is mounted (ignored on clouds that do not use gcsfuse).
+	// An empty value sets a flag without a value.
+	// Example: {"stat-cache-ttl": "1h", "max-conns-per-host": "100"}
+	MountOptions map[string]string `json:"mountOptions,omitempty"`
+
+	// Publish pushes the trained Model artifacts to an OCI registry as an
+	// artifact after the modeller Job completes.
+	Publish *ModelPublish `json:"publish,omitempty"`
+
+	// Timeout is the maximum duration that the modeller Job may run for
+	// (i.e. "12h"), measured from when the Job started. Once exceeded, the
+	// Job is stopped and the Complete condition reports TimedOut.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// ModelSource is a location that the built-in loader imports the Model from.
+type ModelSource struct {
+	// HuggingFace downloads a model repository from the HuggingFace Hub.
+	HuggingFace *HuggingFaceSource `json:"huggingFace"`
 }
 
-func (m *Model) GetParams() map[string]intstr.IntOrString {
+// ModelPublish configures pushing Model artifacts to an OCI registry.
+type ModelPublish struct {
+	// Reference is the OCI reference to push to.
+	// Example: "us-central1-docker.pkg.dev/my-project/models/falcon-7b:v1"
+	Reference string `json:"reference"`
+
+	// SecretName is the name of a Secret of type kubernetes.io/dockerconfigjson
+	// in the Model's namespace that contains credentials for the registry.
+	SecretName string `json:"secretName,omitempty"`
+
+	// Image that contains the oras CLI, used to push the artifact.
+	Image *string `json:"image,omitempty"`
+}
+
+func (m *Model) GetParams() map[string]apiextensionsv1.JSON {
 	return m.Spec.Params
 }
 
@@ -74,6 +151,26 @@ func (m *Model) GetStatusArtifacts() ArtifactsStatus {
 	return m.Status.Artifacts
 }
 
+// LatestRevision returns the latest revision of the Model artifacts, nil
+// before the first modeller Job completes.
+func (m *Model) LatestRevision() *ModelRevision {
+	if len(m.Status.Revisions) == 0 {
+		return nil
+	}
+	return &m.Status.Revisions[len(m.Status.Revisions)-1]
+}
+
+// Revision returns the given revision of the Model artifacts, nil if it
+// does not exist.
+func (m *Model) Revision(revision int64) *ModelRevision {
+	for i := range m.Status.Revisions {
+		if m.Status.Revisions[i].Revision == revision {
+			return &m.Status.Revisions[i]
+		}
+	}
+	return nil
+}
+
 func (m *Model) SetStatusUpload(b UploadStatus) {
 	m.Status.BuildUpload = b
 }
@@ -82,6 +179,14 @@ func (m *Model) GetStatusUpload() UploadStatus {
 	return m.Status.BuildUpload
 }
 
+func (m *Model) SetStatusContainer(c ContainerStatus) {
+	m.Status.Container = c
+}
+
+func (m *Model) GetStatusContainer() ContainerStatus {
+	return m.Status.Container
+}
+
 // ModelStatus defines the observed state of Model
 type ModelStatus struct {
 	// Ready indicates that the Model is ready to use. See Conditions for more details.
@@ -96,12 +201,90 @@ type ModelStatus struct {
 
 	// BuildUpload contains the status of the build context upload.
 	BuildUpload UploadStatus `json:"buildUpload,omitempty"`
+
+	// Container contains the status of the container image build.
+	Container ContainerStatus `json:"container,omitempty"`
+
+	// JobName is the name of the Job that trains or imports the Model
+	// (i.e. for "kubectl logs job/<jobName>").
+	JobName string `json:"jobName,omitempty"`
+
+	// Checkpoints status, only set when Resume is enabled.
+	Checkpoints *CheckpointsStatus `json:"checkpoints,omitempty"`
+
+	// Publish status, only set when Publish is configured.
+	Publish *PublishStatus `json:"publish,omitempty"`
+
+	// Revisions are the immutable versions of the Model artifacts, oldest
+	// first. Every modeller Job writes a new revision instead of
+	// overwriting the previous one, Artifacts.URL points at the latest.
+	Revisions []ModelRevision `json:"revisions,omitempty"`
+}
+
+// ModelRevision is a version of the Model artifacts written by a completed
+// modeller Job.
+type ModelRevision struct {
+	// Revision is the number of the revision, starting at 1.
+	Revision int64 `json:"revision"`
+
+	// URL of the bucket prefix of the revision, the artifacts are stored in
+	// its "artifacts" directory.
+	URL string `json:"url"`
+
+	// Checksum is a combined checksum of all artifact objects of the revision.
+	Checksum string `json:"checksum,omitempty"`
+
+	// ObjectCount is the number of artifact objects of the revision.
+	ObjectCount int64 `json:"objectCount,omitempty"`
+
+	// SizeBytes is the total size of the artifact objects of the revision.
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+
+	// Image that the revision was trained (or loaded) with.
+	Image string `json:"image,omitempty"`
+
+	// CompletionTime is when the modeller Job that wrote the revision
+	// completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PublishStatus records the OCI artifact that the Model was pushed as.
+type PublishStatus struct {
+	// Reference that was pushed to.
+	Reference string `json:"reference,omitempty"`
+
+	// Digest of the pushed artifact manifest.
+	Digest string `json:"digest,omitempty"`
+}
+
+// CheckpointsStatus tracks the checkpoints written by the modeller.
+type CheckpointsStatus struct {
+	// URL of the checkpoints directory in the Model bucket.
+	URL string `json:"url,omitempty"`
+
+	// Checksum is a combined checksum of all checkpoint objects.
+	Checksum string `json:"checksum,omitempty"`
+
+	// ObjectCount is the number of checkpoint objects.
+	ObjectCount int64 `json:"objectCount,omitempty"`
+
+	// LastCheckpointTime is when a change to the checkpoints was last observed.
+	LastCheckpointTime *metav1.Time `json:"lastCheckpointTime,omitempty"`
 }
 
 //+kubebuilder:resource:categories=ai
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+//+kubebuilder:printcolumn:name="GPU",type="string",JSONPath=".spec.resources.gpu.type"
+//+kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.artifacts.url"
+//+kubebuilder:printcolumn:name="Size",type="integer",JSONPath=".status.artifacts.sizeBytes"
+//+kubebuilder:printcolumn:name="Objects",type="integer",JSONPath=".status.artifacts.objectCount",priority=1
+//+kubebuilder:printcolumn:name="Job",type="string",JSONPath=".status.jobName",priority=1
+//+kubebuilder:printcolumn:name="Cloud",type="string",JSONPath=".status.artifacts.cloud"
+//+kubebuilder:printcolumn:name="Region",type="string",JSONPath=".status.artifacts.region"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // The Model API is used to build and train machine learning models.
 //
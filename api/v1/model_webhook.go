@@ -0,0 +1,16 @@
+package v1
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Hub marks v1 as the version that other Model versions are converted to
+// and from.
+func (*Model) Hub() {}
+
+// SetupWebhookWithManager registers the conversion webhook for Models.
+func (m *Model) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(m).
+		Complete()
+}
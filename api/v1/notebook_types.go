@@ -1,11 +1,13 @@
 package v1
 
 import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
+//+kubebuilder:validation:XValidation:rule="!has(self.modelRevision) || has(self.model)",message="modelRevision requires model"
+
 // NotebookSpec defines the desired state of Notebook
 type NotebookSpec struct {
 	// Command to run in the container.
@@ -27,17 +29,32 @@ type NotebookSpec struct {
 	// Resources are the compute resources required by the container.
 	Resources *Resources `json:"resources,omitempty"`
 
-	// Model to load into the notebook container.
+	// PriorityClassName is the PriorityClass of the Notebook Pod.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Model to load into the notebook container. The Model is mounted
+	// read-only at /content/model and must be in the same namespace as
+	// the Notebook.
+	//+kubebuilder:validation:XValidation:rule="!has(self.__namespace__)",message="model must be in the same namespace as the Notebook"
 	Model *ObjectRef `json:"model,omitempty"`
 
-	// Dataset to load into the notebook container.
+	// ModelRevision pins the revision of the Model artifacts that are
+	// mounted (see the revisions in the Model status). When no revision is
+	// pinned, the latest revision is mounted once the Model is ready.
+	//+kubebuilder:validation:Minimum=1
+	ModelRevision int64 `json:"modelRevision,omitempty"`
+
+	// Dataset to load into the notebook container. The Dataset is mounted
+	// read-only at /content/data and must be in the same namespace as
+	// the Notebook.
+	//+kubebuilder:validation:XValidation:rule="!has(self.__namespace__)",message="dataset must be in the same namespace as the Notebook"
 	Dataset *ObjectRef `json:"dataset,omitempty"`
 
 	// Params will be passed into the notebook container as environment variables.
-	Params map[string]intstr.IntOrString `json:"params,omitempty"`
+	Params map[string]apiextensionsv1.JSON `json:"params,omitempty"`
 }
 
-func (n *Notebook) GetParams() map[string]intstr.IntOrString {
+func (n *Notebook) GetParams() map[string]apiextensionsv1.JSON {
 	return n.Spec.Params
 }
 
@@ -80,6 +97,14 @@ func (n *Notebook) GetStatusUpload() UploadStatus {
 	return n.Status.BuildUpload
 }
 
+func (n *Notebook) SetStatusContainer(c ContainerStatus) {
+	n.Status.Container = c
+}
+
+func (n *Notebook) GetStatusContainer() ContainerStatus {
+	return n.Status.Container
+}
+
 func (n *Notebook) GetStatusArtifacts() ArtifactsStatus {
 	return n.Status.Artifacts
 }
@@ -102,12 +127,16 @@ type NotebookStatus struct {
 
 	// BuildUpload contains the status of the build context upload.
 	BuildUpload UploadStatus `json:"buildUpload,omitempty"`
+
+	// Container contains the status of the container image build.
+	Container ContainerStatus `json:"container,omitempty"`
 }
 
 //+kubebuilder:resource:categories=ai,shortName=nb
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // The Notebook API can be used to quickly spin up a development environment backed by high performance compute.
 //
@@ -1,16 +1,26 @@
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 )
 
+// DefaultServerPort is the port that Servers listen on when ServerSpec.Port
+// is not set.
+const DefaultServerPort = 8080
+
 // ServerSpec defines the desired state of Server
 type ServerSpec struct {
 	// Command to run in the container.
 	Command []string `json:"command,omitempty"`
 
+	// Args to pass to the Command, or to the entrypoint of the image when
+	// Command is not set.
+	Args []string `json:"args,omitempty"`
+
 	// Environment variables in the container
 	Env map[string]string `json:"env,omitempty"`
 
@@ -23,11 +33,204 @@ type ServerSpec struct {
 	// Resources are the compute resources required by the container.
 	Resources *Resources `json:"resources,omitempty"`
 
+	// PriorityClassName is the PriorityClass of the Server Pods (i.e. to
+	// keep inference from being preempted by batch workloads).
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Sidecars are additional containers in the Server Pods (i.e. a proxy
+	// or a cache warmer). They get the volume mounts of the server
+	// container.
+	Sidecars []corev1.Container `json:"sidecars,omitempty"`
+
 	// Model references the Model object to be served.
-	Model ObjectRef `json:"model,omitempty"`
+	Model ServerModelRef `json:"model,omitempty"`
 
 	// Params will be passed into the loading process as environment variables.
-	Params map[string]intstr.IntOrString `json:"params,omitempty"`
+	Params map[string]apiextensionsv1.JSON `json:"params,omitempty"`
+
+	// Replicas is the number of Server Pods to run. Ignored when
+	// Autoscaling is set.
+	//+kubebuilder:default:=1
+	//+kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Port that the server container listens on for HTTP traffic. The
+	// Service exposes the same port. It is also passed to the container
+	// in the PORT environment variable.
+	//+kubebuilder:default:=8080
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// Expose configures how the Server is exposed, defaults to a
+	// cluster-internal (ClusterIP) Service.
+	Expose *ServerExpose `json:"expose,omitempty"`
+
+	// Strategy configures how Server Pods are replaced when the Server is
+	// updated (i.e. a new image or Model).
+	Strategy *ServerStrategy `json:"strategy,omitempty"`
+
+	// Autoscaling configures a HorizontalPodAutoscaler for the Server. When
+	// set, Replicas is ignored.
+	Autoscaling *ServerAutoscaling `json:"autoscaling,omitempty"`
+
+	// ReadinessProbe overrides the default readiness probe of the server
+	// container. Servers should only respond successfully on the probed
+	// path (defaults to "/") once the model is loaded, otherwise requests
+	// are routed to Pods that can not serve them yet.
+	ReadinessProbe *ServerProbe `json:"readinessProbe,omitempty"`
+
+	// StartupProbe overrides the default startup probe of the server container.
+	// The default allows up to 30 minutes for the model to load.
+	StartupProbe *ServerProbe `json:"startupProbe,omitempty"`
+}
+
+//+kubebuilder:validation:XValidation:rule="!has(self.revision) || !has(self.checksum)",message="revision and checksum are mutually exclusive"
+//+kubebuilder:validation:XValidation:rule="!has(self.__namespace__)",message="model must be in the same namespace as the Server"
+
+// ServerModelRef references the Model that a Server serves and optionally
+// pins a revision of its artifacts.
+type ServerModelRef struct {
+	ObjectRef `json:",inline"`
+
+	// Revision pins the revision of the Model artifacts to serve (see the
+	// revisions in the Model status). When no revision is pinned, the
+	// latest revision is served and re-trainings are rolled out once they
+	// complete.
+	//+kubebuilder:validation:Minimum=1
+	Revision int64 `json:"revision,omitempty"`
+
+	// Checksum pins the (latest) revision whose artifacts have the
+	// checksum, i.e. to keep serving the same artifacts across
+	// re-trainings that produce identical results.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// Pinned returns true when a revision of the Model is pinned.
+func (r ServerModelRef) Pinned() bool {
+	return r.Revision != 0 || r.Checksum != ""
+}
+
+// ServerStrategy configures the rolling update of Server Pods.
+type ServerStrategy struct {
+	// MaxSurge is the maximum number of Pods (or percentage of replicas) that
+	// can be created above the desired number of replicas during an update.
+	// Defaults to 1 as GPU capacity is usually scarce.
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is the maximum number of Pods (or percentage of replicas)
+	// that can be unavailable during an update. Defaults to 0 so that
+	// capacity is never reduced during a rollout.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MinReadySeconds is the number of seconds that a new Pod must be ready
+	// before it is considered available, i.e. to allow a model to warm up
+	// on the GPU before old Pods are removed.
+	//+kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+}
+
+// ServerProbe is a HTTP GET probe against the server container, or a check
+// that a file exists when File is set.
+type ServerProbe struct {
+	// Path to request. Defaults to "/".
+	Path string `json:"path,omitempty"`
+
+	// File that must exist for the probe to succeed, relative to the
+	// mounted model ("/content/model") unless absolute (i.e. "config.json"
+	// to wait for the model to be mounted). Replaces the HTTP request, Path
+	// and Port must not be set. Requires the "test" command in the image.
+	File string `json:"file,omitempty"`
+
+	// Port to request. Defaults to the serving port (see ServerSpec.Port).
+	Port *int32 `json:"port,omitempty"`
+
+	// InitialDelaySeconds is the number of seconds after the container has started before the probe is initiated.
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+
+	// TimeoutSeconds is the number of seconds after which the probe times out.
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// PeriodSeconds is how often (in seconds) to perform the probe.
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures before the probe is considered failed.
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+//+kubebuilder:validation:Enum=ClusterIP;LoadBalancer;Ingress
+
+// ServerExposeType is the way that a Server is exposed.
+type ServerExposeType string
+
+const (
+	// ServerExposeClusterIP only exposes the Server within the cluster.
+	ServerExposeClusterIP ServerExposeType = "ClusterIP"
+	// ServerExposeLoadBalancer exposes the Server through a LoadBalancer
+	// Service (provisioned by the cloud).
+	ServerExposeLoadBalancer ServerExposeType = "LoadBalancer"
+	// ServerExposeIngress exposes the Server through an Ingress in front of
+	// a ClusterIP Service.
+	ServerExposeIngress ServerExposeType = "Ingress"
+)
+
+//+kubebuilder:validation:XValidation:rule="self.type == 'Ingress' || (!has(self.host) && !has(self.path) && !has(self.tlsSecretName) && !has(self.ingressClassName))",message="host, path, tlsSecretName and ingressClassName are only supported for the Ingress type"
+
+// ServerExpose configures how a Server is exposed.
+type ServerExpose struct {
+	// Type of exposure.
+	//+kubebuilder:default:=ClusterIP
+	Type ServerExposeType `json:"type,omitempty"`
+
+	// Host that the Ingress routes to the Server (i.e. "falcon.example.com").
+	// All hosts are matched when empty.
+	Host string `json:"host,omitempty"`
+
+	// Path prefix that the Ingress routes to the Server, defaults to "/".
+	Path string `json:"path,omitempty"`
+
+	// TLSSecretName is the name of a Secret (of type kubernetes.io/tls) that
+	// terminates TLS for Host on the Ingress.
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// IngressClassName selects the Ingress controller, defaults to the
+	// default IngressClass of the cluster.
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Annotations are added to the Ingress (Ingress type) or the Service
+	// (LoadBalancer type), i.e. to configure an internal load balancer.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ServerAutoscaling specifies how the number of Server replicas is scaled.
+// At least one target should be set.
+type ServerAutoscaling struct {
+	// MinReplicas is the lower limit for the number of replicas.
+	//+kubebuilder:default:=1
+	//+kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper limit for the number of replicas.
+	//+kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetConcurrency is the average number of in-flight requests per replica.
+	// Requires the "substratus_server_concurrent_requests" metric to be exposed
+	// through the custom metrics API.
+	//+kubebuilder:validation:Minimum=1
+	TargetConcurrency *int32 `json:"targetConcurrency,omitempty"`
+
+	// TargetGPUUtilization is the average GPU utilization percentage per replica.
+	// Requires the DCGM exporter "DCGM_FI_DEV_GPU_UTIL" metric to be exposed
+	// through the custom metrics API.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=100
+	TargetGPUUtilization *int32 `json:"targetGPUUtilization,omitempty"`
+
+	// TargetCPUUtilization is the average CPU utilization percentage (of requested CPU) per replica.
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:validation:Maximum=100
+	TargetCPUUtilization *int32 `json:"targetCPUUtilization,omitempty"`
 }
 
 // ServerStatus defines the observed state of Server
@@ -41,12 +244,32 @@ type ServerStatus struct {
 
 	// Upload contains the status of the build context upload.
 	Upload UploadStatus `json:"buildUpload,omitempty"`
+
+	// Container contains the status of the container image build.
+	Container ContainerStatus `json:"container,omitempty"`
+
+	// URL is the cluster-internal address of the Server
+	// (i.e. "http://falcon-7b-server.default.svc.cluster.local:8080").
+	URL string `json:"url,omitempty"`
+
+	// ExternalURL is the address of the Server outside of the cluster, set
+	// once the load balancer of the Service or Ingress is provisioned (or
+	// from the Ingress host).
+	ExternalURL string `json:"externalURL,omitempty"`
+
+	// ModelRevision is the revision of the Model artifacts that the Server
+	// serves, unset for Models that were trained before revisions.
+	ModelRevision int64 `json:"modelRevision,omitempty"`
 }
 
 //+kubebuilder:resource:categories=ai
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+//+kubebuilder:printcolumn:name="URL",type="string",JSONPath=".status.url"
+//+kubebuilder:printcolumn:name="External URL",type="string",JSONPath=".status.externalURL",priority=1
+//+kubebuilder:printcolumn:name="Model Revision",type="integer",JSONPath=".status.modelRevision",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // The Server API is used to deploy a server that exposes the capabilities of a Model
 // via a HTTP interface.
@@ -60,10 +283,18 @@ type Server struct {
 	Status ServerStatus `json:"status,omitempty"`
 }
 
-func (s *Server) GetParams() map[string]intstr.IntOrString {
+func (s *Server) GetParams() map[string]apiextensionsv1.JSON {
 	return s.Spec.Params
 }
 
+// GetPort returns the port that the server container listens on.
+func (s *Server) GetPort() int32 {
+	if s.Spec.Port == nil {
+		return DefaultServerPort
+	}
+	return *s.Spec.Port
+}
+
 func (s *Server) GetBuild() *Build {
 	return s.Spec.Build
 }
@@ -82,6 +313,14 @@ func (s *Server) SetImage(image string) {
 	s.Spec.Image = ptr.To(image)
 }
 
+// GetExposeType returns the Expose type, defaulting to ClusterIP.
+func (s *Server) GetExposeType() ServerExposeType {
+	if s.Spec.Expose == nil || s.Spec.Expose.Type == "" {
+		return ServerExposeClusterIP
+	}
+	return s.Spec.Expose.Type
+}
+
 func (s *Server) GetConditions() *[]metav1.Condition {
 	return &s.Status.Conditions
 }
@@ -102,6 +341,14 @@ func (s *Server) GetStatusUpload() UploadStatus {
 	return s.Status.Upload
 }
 
+func (s *Server) SetStatusContainer(c ContainerStatus) {
+	s.Status.Container = c
+}
+
+func (s *Server) GetStatusContainer() ContainerStatus {
+	return s.Status.Container
+}
+
 //+kubebuilder:object:root=true
 
 // ServerList contains a list of Server
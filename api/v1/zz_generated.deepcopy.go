@@ -10,6 +10,8 @@ Copyright 2023.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -36,13 +38,20 @@ func (in *Build) DeepCopyInto(out *Build) {
 	if in.Git != nil {
 		in, out := &in.Git, &out.Git
 		*out = new(BuildGit)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Upload != nil {
 		in, out := &in.Upload, &out.Upload
 		*out = new(BuildUpload)
 		**out = **in
 	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Build.
@@ -58,6 +67,11 @@ func (in *Build) DeepCopy() *Build {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuildGit) DeepCopyInto(out *BuildGit) {
 	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(ObjectRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BuildGit.
@@ -85,6 +99,40 @@ func (in *BuildUpload) DeepCopy() *BuildUpload {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckpointsStatus) DeepCopyInto(out *CheckpointsStatus) {
+	*out = *in
+	if in.LastCheckpointTime != nil {
+		in, out := &in.LastCheckpointTime, &out.LastCheckpointTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckpointsStatus.
+func (in *CheckpointsStatus) DeepCopy() *CheckpointsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckpointsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerStatus) DeepCopyInto(out *ContainerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerStatus.
+func (in *ContainerStatus) DeepCopy() *ContainerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Dataset) DeepCopyInto(out *Dataset) {
 	*out = *in
@@ -144,6 +192,41 @@ func (in *DatasetList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatasetSample) DeepCopyInto(out *DatasetSample) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasetSample.
+func (in *DatasetSample) DeepCopy() *DatasetSample {
+	if in == nil {
+		return nil
+	}
+	out := new(DatasetSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatasetSource) DeepCopyInto(out *DatasetSource) {
+	*out = *in
+	if in.HuggingFace != nil {
+		in, out := &in.HuggingFace, &out.HuggingFace
+		*out = new(HuggingFaceSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasetSource.
+func (in *DatasetSource) DeepCopy() *DatasetSource {
+	if in == nil {
+		return nil
+	}
+	out := new(DatasetSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatasetSpec) DeepCopyInto(out *DatasetSpec) {
 	*out = *in
@@ -152,6 +235,11 @@ func (in *DatasetSpec) DeepCopyInto(out *DatasetSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make(map[string]string, len(*in))
@@ -169,18 +257,47 @@ func (in *DatasetSpec) DeepCopyInto(out *DatasetSpec) {
 		*out = new(Build)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(DatasetSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(Resources)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Params != nil {
 		in, out := &in.Params, &out.Params
-		*out = make(map[string]intstr.IntOrString, len(*in))
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Sample != nil {
+		in, out := &in.Sample, &out.Sample
+		*out = new(DatasetSample)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasetSpec.
@@ -205,6 +322,12 @@ func (in *DatasetStatus) DeepCopyInto(out *DatasetStatus) {
 	}
 	out.Artifacts = in.Artifacts
 	in.BuildUpload.DeepCopyInto(&out.BuildUpload)
+	out.Container = in.Container
+	if in.Sample != nil {
+		in, out := &in.Sample, &out.Sample
+		*out = new(DatasetSample)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatasetStatus.
@@ -220,6 +343,11 @@ func (in *DatasetStatus) DeepCopy() *DatasetStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GPUResources) DeepCopyInto(out *GPUResources) {
 	*out = *in
+	if in.Sharing != nil {
+		in, out := &in.Sharing, &out.Sharing
+		*out = new(GPUSharing)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUResources.
@@ -232,6 +360,41 @@ func (in *GPUResources) DeepCopy() *GPUResources {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPUSharing) DeepCopyInto(out *GPUSharing) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPUSharing.
+func (in *GPUSharing) DeepCopy() *GPUSharing {
+	if in == nil {
+		return nil
+	}
+	out := new(GPUSharing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HuggingFaceSource) DeepCopyInto(out *HuggingFaceSource) {
+	*out = *in
+	if in.TokenSecretRef != nil {
+		in, out := &in.TokenSecretRef, &out.TokenSecretRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HuggingFaceSource.
+func (in *HuggingFaceSource) DeepCopy() *HuggingFaceSource {
+	if in == nil {
+		return nil
+	}
+	out := new(HuggingFaceSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Model) DeepCopyInto(out *Model) {
 	*out = *in
@@ -291,6 +454,65 @@ func (in *ModelList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelPublish) DeepCopyInto(out *ModelPublish) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelPublish.
+func (in *ModelPublish) DeepCopy() *ModelPublish {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelPublish)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelRevision) DeepCopyInto(out *ModelRevision) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelRevision.
+func (in *ModelRevision) DeepCopy() *ModelRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ModelSource) DeepCopyInto(out *ModelSource) {
+	*out = *in
+	if in.HuggingFace != nil {
+		in, out := &in.HuggingFace, &out.HuggingFace
+		*out = new(HuggingFaceSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSource.
+func (in *ModelSource) DeepCopy() *ModelSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ModelSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
 	*out = *in
@@ -299,6 +521,11 @@ func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make(map[string]string, len(*in))
@@ -316,11 +543,23 @@ func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
 		*out = new(Build)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Source != nil {
+		in, out := &in.Source, &out.Source
+		*out = new(ModelSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = new(Resources)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Model != nil {
 		in, out := &in.Model, &out.Model
 		*out = new(ObjectRef)
@@ -331,13 +570,35 @@ func (in *ModelSpec) DeepCopyInto(out *ModelSpec) {
 		*out = new(ObjectRef)
 		**out = **in
 	}
+	if in.Datasets != nil {
+		in, out := &in.Datasets, &out.Datasets
+		*out = make([]ObjectRef, len(*in))
+		copy(*out, *in)
+	}
 	if in.Params != nil {
 		in, out := &in.Params, &out.Params
-		*out = make(map[string]intstr.IntOrString, len(*in))
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.MountOptions != nil {
+		in, out := &in.MountOptions, &out.MountOptions
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = new(ModelPublish)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelSpec.
@@ -362,6 +623,24 @@ func (in *ModelStatus) DeepCopyInto(out *ModelStatus) {
 	}
 	out.Artifacts = in.Artifacts
 	in.BuildUpload.DeepCopyInto(&out.BuildUpload)
+	out.Container = in.Container
+	if in.Checkpoints != nil {
+		in, out := &in.Checkpoints, &out.Checkpoints
+		*out = new(CheckpointsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Publish != nil {
+		in, out := &in.Publish, &out.Publish
+		*out = new(PublishStatus)
+		**out = **in
+	}
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]ModelRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ModelStatus.
@@ -480,9 +759,9 @@ func (in *NotebookSpec) DeepCopyInto(out *NotebookSpec) {
 	}
 	if in.Params != nil {
 		in, out := &in.Params, &out.Params
-		*out = make(map[string]intstr.IntOrString, len(*in))
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
 }
@@ -509,6 +788,7 @@ func (in *NotebookStatus) DeepCopyInto(out *NotebookStatus) {
 	}
 	out.Artifacts = in.Artifacts
 	in.BuildUpload.DeepCopyInto(&out.BuildUpload)
+	out.Container = in.Container
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotebookStatus.
@@ -536,12 +816,47 @@ func (in *ObjectRef) DeepCopy() *ObjectRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishStatus) DeepCopyInto(out *PublishStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishStatus.
+func (in *PublishStatus) DeepCopy() *PublishStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceLimits) DeepCopyInto(out *ResourceLimits) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceLimits.
+func (in *ResourceLimits) DeepCopy() *ResourceLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Resources) DeepCopyInto(out *Resources) {
 	*out = *in
 	if in.GPU != nil {
 		in, out := &in.GPU, &out.GPU
 		*out = new(GPUResources)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(ResourceLimits)
 		**out = **in
 	}
 }
@@ -583,6 +898,63 @@ func (in *Server) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerAutoscaling) DeepCopyInto(out *ServerAutoscaling) {
+	*out = *in
+	if in.TargetConcurrency != nil {
+		in, out := &in.TargetConcurrency, &out.TargetConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetGPUUtilization != nil {
+		in, out := &in.TargetGPUUtilization, &out.TargetGPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TargetCPUUtilization != nil {
+		in, out := &in.TargetCPUUtilization, &out.TargetCPUUtilization
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerAutoscaling.
+func (in *ServerAutoscaling) DeepCopy() *ServerAutoscaling {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerAutoscaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerExpose) DeepCopyInto(out *ServerExpose) {
+	*out = *in
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerExpose.
+func (in *ServerExpose) DeepCopy() *ServerExpose {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerExpose)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerList) DeepCopyInto(out *ServerList) {
 	*out = *in
@@ -615,6 +987,42 @@ func (in *ServerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerModelRef) DeepCopyInto(out *ServerModelRef) {
+	*out = *in
+	out.ObjectRef = in.ObjectRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerModelRef.
+func (in *ServerModelRef) DeepCopy() *ServerModelRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerModelRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerProbe) DeepCopyInto(out *ServerProbe) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerProbe.
+func (in *ServerProbe) DeepCopy() *ServerProbe {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerProbe)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerSpec) DeepCopyInto(out *ServerSpec) {
 	*out = *in
@@ -623,6 +1031,11 @@ func (in *ServerSpec) DeepCopyInto(out *ServerSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Env != nil {
 		in, out := &in.Env, &out.Env
 		*out = make(map[string]string, len(*in))
@@ -645,14 +1058,56 @@ func (in *ServerSpec) DeepCopyInto(out *ServerSpec) {
 		*out = new(Resources)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	out.Model = in.Model
 	if in.Params != nil {
 		in, out := &in.Params, &out.Params
-		*out = make(map[string]intstr.IntOrString, len(*in))
+		*out = make(map[string]apiextensionsv1.JSON, len(*in))
 		for key, val := range *in {
-			(*out)[key] = val
+			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Expose != nil {
+		in, out := &in.Expose, &out.Expose
+		*out = new(ServerExpose)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(ServerStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(ServerAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessProbe != nil {
+		in, out := &in.ReadinessProbe, &out.ReadinessProbe
+		*out = new(ServerProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartupProbe != nil {
+		in, out := &in.StartupProbe, &out.StartupProbe
+		*out = new(ServerProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerSpec.
@@ -676,6 +1131,7 @@ func (in *ServerStatus) DeepCopyInto(out *ServerStatus) {
 		}
 	}
 	in.Upload.DeepCopyInto(&out.Upload)
+	out.Container = in.Container
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStatus.
@@ -688,6 +1144,31 @@ func (in *ServerStatus) DeepCopy() *ServerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerStrategy) DeepCopyInto(out *ServerStrategy) {
+	*out = *in
+	if in.MaxSurge != nil {
+		in, out := &in.MaxSurge, &out.MaxSurge
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerStrategy.
+func (in *ServerStrategy) DeepCopy() *ServerStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UploadStatus) DeepCopyInto(out *UploadStatus) {
 	*out = *in
@@ -0,0 +1,22 @@
+// Package v1alpha1 contains the previous version of the Substratus API.
+// Objects are converted to and from the v1 (hub) version by the conversion
+// webhook.
+// +kubebuilder:object:generate=true
+// +groupName=substratus.ai
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "substratus.ai", Version: "v1alpha1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
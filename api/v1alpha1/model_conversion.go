@@ -0,0 +1,80 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// specAnnotation preserves the v1 spec when a Model is converted to
+// v1alpha1 so that fields that do not exist in v1alpha1 survive a
+// round trip.
+const specAnnotation = "substratus.ai/v1-spec"
+
+var _ conversion.Convertible = &Model{}
+
+// ConvertTo converts this Model to the Hub version (v1).
+func (src *Model) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*apiv1.Model)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	if raw, ok := dst.Annotations[specAnnotation]; ok {
+		if err := json.Unmarshal([]byte(raw), &dst.Spec); err != nil {
+			return fmt.Errorf("unmarshalling %s annotation: %w", specAnnotation, err)
+		}
+		delete(dst.Annotations, specAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.Command = src.Spec.Command
+	dst.Spec.Env = src.Spec.Env
+	dst.Spec.Image = src.Spec.Image
+	dst.Spec.Build = src.Spec.Build
+	dst.Spec.Resources = src.Spec.Resources
+	dst.Spec.Model = src.Spec.BaseModel
+	dst.Spec.Dataset = src.Spec.TrainingDataset
+	dst.Spec.Params = src.Spec.Params
+
+	dst.Status.Ready = src.Status.Ready
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.Artifacts = src.Status.Artifacts
+	dst.Status.BuildUpload = src.Status.BuildUpload
+
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1) to this version.
+func (dst *Model) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*apiv1.Model)
+
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	raw, err := json.Marshal(src.Spec)
+	if err != nil {
+		return fmt.Errorf("marshalling spec: %w", err)
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[specAnnotation] = string(raw)
+
+	dst.Spec.Command = src.Spec.Command
+	dst.Spec.Env = src.Spec.Env
+	dst.Spec.Image = src.Spec.Image
+	dst.Spec.Build = src.Spec.Build
+	dst.Spec.Resources = src.Spec.Resources
+	dst.Spec.BaseModel = src.Spec.Model
+	dst.Spec.TrainingDataset = src.Spec.Dataset
+	dst.Spec.Params = src.Spec.Params
+
+	dst.Status.Ready = src.Status.Ready
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.Artifacts = src.Status.Artifacts
+	dst.Status.BuildUpload = src.Status.BuildUpload
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/api/v1alpha1"
+)
+
+func TestModelConversion(t *testing.T) {
+	alpha := &v1alpha1.Model{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "my-ns"},
+		Spec: v1alpha1.ModelSpec{
+			Image:           ptr.To("some-image"),
+			BaseModel:       &apiv1.ObjectRef{Name: "base"},
+			TrainingDataset: &apiv1.ObjectRef{Name: "data"},
+			Params:          map[string]apiextensionsv1.JSON{"epochs": {Raw: []byte(`1`)}},
+		},
+		Status: v1alpha1.ModelStatus{Ready: true},
+	}
+
+	var hub apiv1.Model
+	require.NoError(t, alpha.ConvertTo(&hub))
+	require.Equal(t, "my-model", hub.Name)
+	require.Equal(t, "base", hub.Spec.Model.Name)
+	require.Equal(t, "data", hub.Spec.Dataset.Name)
+	require.Equal(t, "some-image", *hub.Spec.Image)
+	require.Equal(t, alpha.Spec.Params, hub.Spec.Params)
+	require.True(t, hub.Status.Ready)
+
+	// Fields that only exist in v1 survive a round trip through v1alpha1.
+	hub.Spec.Resume = true
+	hub.Spec.MountOptions = map[string]string{"stat-cache-ttl": "1h"}
+
+	var back v1alpha1.Model
+	require.NoError(t, back.ConvertFrom(&hub))
+	require.Equal(t, alpha.Spec, back.Spec)
+
+	var roundTripped apiv1.Model
+	require.NoError(t, back.ConvertTo(&roundTripped))
+	require.Equal(t, hub.Spec, roundTripped.Spec)
+	require.Nil(t, roundTripped.Annotations)
+}
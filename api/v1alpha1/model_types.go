@@ -0,0 +1,84 @@
+package v1alpha1
+
+import (
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// ModelSpec defines the desired state of Model
+type ModelSpec struct {
+	// Command to run in the container.
+	Command []string `json:"command,omitempty"`
+
+	// Environment variables in the container
+	Env map[string]string `json:"env,omitempty"`
+
+	// Image that contains model code and dependencies.
+	Image *string `json:"image,omitempty"`
+
+	// Build specifies how to build an image.
+	Build *apiv1.Build `json:"build,omitempty"`
+
+	// Resources are the compute resources required by the container.
+	Resources *apiv1.Resources `json:"resources,omitempty"`
+
+	// BaseModel should be set in order to mount another model to be
+	// used for transfer learning. Renamed to "model" in v1.
+	BaseModel *apiv1.ObjectRef `json:"baseModel,omitempty"`
+
+	// TrainingDataset to mount for training. Renamed to "dataset" in v1.
+	TrainingDataset *apiv1.ObjectRef `json:"trainingDataset,omitempty"`
+
+	// Parameters are passing into the model training/loading container as environment variables.
+	Params map[string]apiextensionsv1.JSON `json:"params,omitempty"`
+}
+
+// ModelStatus defines the observed state of Model
+type ModelStatus struct {
+	// Ready indicates that the Model is ready to use. See Conditions for more details.
+	//+kubebuilder:default:=false
+	Ready bool `json:"ready"`
+
+	// Conditions is the list of conditions that describe the current state of the Model.
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Artifacts status.
+	Artifacts apiv1.ArtifactsStatus `json:"artifacts,omitempty"`
+
+	// BuildUpload contains the status of the build context upload.
+	BuildUpload apiv1.UploadStatus `json:"buildUpload,omitempty"`
+}
+
+//+kubebuilder:resource:categories=ai
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:unservedversion
+//+kubebuilder:printcolumn:name="Ready",type="boolean",JSONPath=".status.ready"
+
+// Model is the v1alpha1 version of the Model API. It is not served until
+// the conversion webhook is enabled (see config/crd/patches/webhook_in_models.yaml),
+// otherwise the API server would convert objects without renaming fields.
+type Model struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the desired state of the Model.
+	Spec ModelSpec `json:"spec,omitempty"`
+	// Status is the observed state of the Model.
+	Status ModelStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// ModelList contains a list of Model
+type ModelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Model `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Model{}, &ModelList{})
+}
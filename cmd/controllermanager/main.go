@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
@@ -18,11 +22,16 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
+	apiv1alpha1 "github.com/substratusai/substratus/api/v1alpha1"
 	"github.com/substratusai/substratus/internal/cloud"
 	"github.com/substratusai/substratus/internal/controller"
+	"github.com/substratusai/substratus/internal/metricstore"
+	"github.com/substratusai/substratus/internal/resources"
 	"github.com/substratusai/substratus/internal/sci"
+	"github.com/substratusai/substratus/internal/tracing"
 )
 
 var (
@@ -34,6 +43,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(apiv1.AddToScheme(scheme))
+	utilruntime.Must(apiv1alpha1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -43,11 +53,53 @@ func main() {
 	var probeAddr string
 	var configDumpPath string
 	var sciAddr string
+	var resourcePressureWindow time.Duration
+	var resourcePressureInterval time.Duration
+	var resourcePressureThreshold float64
+	var metricsHistoryBackend string
+	var metricsHistoryRetention time.Duration
+	var metricsHistoryInterval time.Duration
+	var prometheusAddr string
+	var dcgmExporterNamespace string
+	var dcgmExporterSelector string
+	var dcgmExporterPort int
+	var enableWebhooks bool
+	var maxConcurrentReconciles int
+	var createBuckets bool
+	var bucketCheckInterval time.Duration
+	var defaultResourcesConfigMap string
+	var requeueInterval time.Duration
+	var datasetDriftCheckInterval time.Duration
+	var sciQPS float64
+	var sciBurst int
+	var otlpEndpoint string
+	var otlpInsecure bool
 	flag.StringVar(&configDumpPath, "config-dump-path", "", "The filepath to dump the running config to.")
 	// TODO: Change SCI Service name to be cloud-agnostic.
 	flag.StringVar(&sciAddr, "sci-address", "sci.substratus.svc.cluster.local:10080", "The address of the Substratus Cloud Interface server.")
+	flag.Float64Var(&sciQPS, "sci-qps", 20, "The maximum rate of requests to the SCI server (shared by all controllers) to stay within cloud API quotas. Not limited when 0.")
+	flag.IntVar(&sciBurst, "sci-burst", 50, "The maximum burst of requests to the SCI server above the --sci-qps rate.")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.DurationVar(&resourcePressureWindow, "resource-pressure-window", 0, "How long Notebook/Server usage must stay above the threshold to report a ResourcePressure condition. Disabled when 0.")
+	flag.DurationVar(&resourcePressureInterval, "resource-pressure-interval", time.Minute, "How often Notebook/Server usage is sampled when ResourcePressure reporting is enabled.")
+	flag.Float64Var(&resourcePressureThreshold, "resource-pressure-threshold", 0.9, "Fraction of the CPU/memory limits (and of the GPU memory) above which usage is considered under pressure.")
+	flag.StringVar(&dcgmExporterNamespace, "dcgm-exporter-namespace", "", "The namespace of the NVIDIA DCGM exporter that GPU usage is read from. GPU usage is not read when empty.")
+	flag.StringVar(&dcgmExporterSelector, "dcgm-exporter-selector", "app=nvidia-dcgm-exporter", "The label selector of the NVIDIA DCGM exporter Pods.")
+	flag.IntVar(&dcgmExporterPort, "dcgm-exporter-port", 9400, "The port that the NVIDIA DCGM exporter serves metrics on.")
+	flag.StringVar(&metricsHistoryBackend, "metrics-history-backend", "", `Backend for Notebook/Server usage history served at /history on the metrics endpoint (behind the same kube-rbac-proxy authorization as /metrics): "local", "prometheus" or "" (disabled).`)
+	flag.DurationVar(&metricsHistoryRetention, "metrics-history-retention", 72*time.Hour, "How long usage history is kept for by the local backend.")
+	flag.DurationVar(&metricsHistoryInterval, "metrics-history-interval", time.Minute, "How often usage is recorded by the local backend.")
+	flag.StringVar(&prometheusAddr, "prometheus-address", "", "The address of the Prometheus server used by the prometheus history backend.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1, "The maximum number of objects each controller reconciles concurrently.")
+	flag.DurationVar(&requeueInterval, "requeue-interval", controller.RequeueInterval, "The base interval that objects waiting on unschedulable or crashing Pods are re-checked at (up to 50% jitter is added).")
+	flag.DurationVar(&datasetDriftCheckInterval, "dataset-drift-check-interval", 0, "How often the artifacts of loaded Datasets are re-checksummed to report a DataDrift condition. Disabled when 0.")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv("OTLP_ENDPOINT"), "The host:port of an OTLP/gRPC collector to export traces of reconciles and SCI requests to. Disabled when empty.")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "Connect to the OTLP collector without TLS.")
+	flag.BoolVar(&createBuckets, "create-buckets", true, "Create artifact buckets that do not exist (in the configured BUCKET_LOCATION) on startup. Disable it when the buckets are provisioned outside of Substratus (a misconfigured bucket URL would otherwise create a new bucket).")
+	flag.DurationVar(&bucketCheckInterval, "bucket-check-interval", 10*time.Minute, "How often the artifact buckets are checked again (i.e. to retry creating them with the configured BUCKET_KMS_KEY). Only checked on startup when 0.")
+	flag.StringVar(&defaultResourcesConfigMap, "default-resources-configmap", "", `The "<namespace>/<name>" of a ConfigMap with default resources, keyed by "default" or GPU type. Read at startup.`)
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false, "Serve the Model conversion webhook (requires a serving certificate).")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -59,6 +111,23 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "substratus-controller-manager", otlpEndpoint, otlpInsecure)
+	if err != nil {
+		setupLog.Error(err, "unable to setup tracing")
+		os.Exit(1)
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			setupLog.Error(err, "unable to flush traces")
+		}
+	}()
+
+	if requeueInterval <= 0 {
+		setupLog.Error(fmt.Errorf("must be positive, got %v", requeueInterval), "invalid requeue interval")
+		os.Exit(1)
+	}
+	controller.RequeueInterval = requeueInterval
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
@@ -101,9 +170,20 @@ func main() {
 	}
 
 	// TODO(any): setup TLS
+	sciLimiter := sci.NewRateLimiter(sciQPS, sciBurst)
 	conn, err := grpc.Dial(
 		sciAddr,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(
+			sci.TracingUnaryClientInterceptor(),
+			// Waiting on the limiter is not part of the request latency.
+			sci.RateLimitUnaryClientInterceptor(sciLimiter),
+			sci.MetricsUnaryClientInterceptor(metrics.Registry),
+		),
+		grpc.WithChainStreamInterceptor(
+			sci.TracingStreamClientInterceptor(),
+			sci.RateLimitStreamClientInterceptor(sciLimiter),
+		),
 	)
 	if err != nil {
 		setupLog.Error(err, "unable to create an SCI gRPC client")
@@ -113,12 +193,23 @@ func main() {
 	// Create a client using the connection
 	sciClient := sci.NewControllerClient(conn)
 
-	// this environment is only set within a container running on K8s
-	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
-		kubernetesClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	kubernetesClient, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "error creating K8s client-go client")
+		os.Exit(1)
+	}
+
+	var defaultResources *resources.Defaults
+	if defaultResourcesConfigMap != "" {
+		defaultResources, err = loadDefaultResources(context.Background(), kubernetesClient, cld.Name(), defaultResourcesConfigMap)
 		if err != nil {
-			setupLog.Error(err, "error creating K8s client-go client")
+			setupLog.Error(err, "unable to load default resources")
+			os.Exit(1)
 		}
+	}
+
+	// this environment is only set within a container running on K8s
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
 		err = controller.AssociatePrincipalSCIServiceAccount(context.Background(), kubernetesClient, cld)
 		if err != nil {
 			setupLog.Error(err, "error associating principal to SCI K8s ServiceAccount")
@@ -126,6 +217,69 @@ func main() {
 		}
 	}
 
+	buckets := &controller.BucketChecker{
+		Cloud:         cld,
+		SCI:           sciClient,
+		CreateMissing: createBuckets,
+		Interval:      bucketCheckInterval,
+	}
+	if err := mgr.Add(buckets); err != nil {
+		setupLog.Error(err, "unable to add bucket checker")
+		os.Exit(1)
+	}
+
+	var usageReader controller.PodMetricsReader = &controller.KubeletSummaryReader{Client: kubernetesClient}
+	if dcgmExporterNamespace != "" {
+		usageReader = &controller.DCGMExporterReader{
+			Reader:        usageReader,
+			Client:        kubernetesClient,
+			Namespace:     dcgmExporterNamespace,
+			LabelSelector: dcgmExporterSelector,
+			Port:          dcgmExporterPort,
+		}
+	}
+
+	var resourcePressure *controller.ResourcePressureMonitor
+	if resourcePressureWindow > 0 {
+		resourcePressure = &controller.ResourcePressureMonitor{
+			Reader:    usageReader,
+			Threshold: resourcePressureThreshold,
+			Window:    resourcePressureWindow,
+			Interval:  resourcePressureInterval,
+		}
+	}
+
+	var metricsHistory metricstore.Store
+	switch metricsHistoryBackend {
+	case "":
+	case "local":
+		metricsHistory = metricstore.NewMemoryStore(metricsHistoryRetention)
+		if err := mgr.Add(&controller.MetricsCollector{
+			Client:   mgr.GetClient(),
+			Reader:   usageReader,
+			Store:    metricsHistory,
+			Interval: metricsHistoryInterval,
+		}); err != nil {
+			setupLog.Error(err, "unable to add metrics collector")
+			os.Exit(1)
+		}
+	case "prometheus":
+		metricsHistory, err = metricstore.NewPrometheusStore(prometheusAddr)
+		if err != nil {
+			setupLog.Error(err, "unable to configure prometheus metrics history")
+			os.Exit(1)
+		}
+	default:
+		setupLog.Error(fmt.Errorf("unknown backend: %q", metricsHistoryBackend), "invalid metrics history backend")
+		os.Exit(1)
+	}
+	if metricsHistory != nil {
+		if err := mgr.AddMetricsExtraHandler("/history", metricstore.NewHandler(metricsHistory)); err != nil {
+			setupLog.Error(err, "unable to add metrics history handler")
+			os.Exit(1)
+		}
+	}
+
 	if err = (&controller.ModelReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -135,17 +289,21 @@ func main() {
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
 		},
+		DefaultResources:        defaultResources,
+		Buckets:                 buckets,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Model")
 		os.Exit(1)
 	}
 	if err = (&controller.BuildReconciler{
-		Scheme:    mgr.GetScheme(),
-		Client:    mgr.GetClient(),
-		Cloud:     cld,
-		SCI:       sciClient,
-		NewObject: func() controller.BuildableObject { return &apiv1.Model{} },
-		Kind:      "Model",
+		Scheme:                  mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Cloud:                   cld,
+		SCI:                     sciClient,
+		NewObject:               func() controller.BuildableObject { return &apiv1.Model{} },
+		Kind:                    "Model",
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ModelBuilder")
 		os.Exit(1)
@@ -159,17 +317,21 @@ func main() {
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
 		},
+		ResourcePressure:        resourcePressure,
+		DefaultResources:        defaultResources,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Server")
 		os.Exit(1)
 	}
 	if err = (&controller.BuildReconciler{
-		Scheme:    mgr.GetScheme(),
-		Client:    mgr.GetClient(),
-		Cloud:     cld,
-		SCI:       sciClient,
-		NewObject: func() controller.BuildableObject { return &apiv1.Server{} },
-		Kind:      "Server",
+		Scheme:                  mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Cloud:                   cld,
+		SCI:                     sciClient,
+		NewObject:               func() controller.BuildableObject { return &apiv1.Server{} },
+		Kind:                    "Server",
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ServerBuilder")
 		os.Exit(1)
@@ -183,17 +345,21 @@ func main() {
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
 		},
+		ResourcePressure:        resourcePressure,
+		DefaultResources:        defaultResources,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Notebook")
 		os.Exit(1)
 	}
 	if err = (&controller.BuildReconciler{
-		Scheme:    mgr.GetScheme(),
-		Client:    mgr.GetClient(),
-		Cloud:     cld,
-		SCI:       sciClient,
-		NewObject: func() controller.BuildableObject { return &apiv1.Notebook{} },
-		Kind:      "Notebook",
+		Scheme:                  mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Cloud:                   cld,
+		SCI:                     sciClient,
+		NewObject:               func() controller.BuildableObject { return &apiv1.Notebook{} },
+		Kind:                    "Notebook",
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "NotebookBuilder")
 		os.Exit(1)
@@ -207,21 +373,32 @@ func main() {
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
 		},
+		DefaultResources:        defaultResources,
+		DriftCheckInterval:      datasetDriftCheckInterval,
+		Buckets:                 buckets,
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Dataset")
 		os.Exit(1)
 	}
 	if err = (&controller.BuildReconciler{
-		Scheme:    mgr.GetScheme(),
-		Client:    mgr.GetClient(),
-		Cloud:     cld,
-		SCI:       sciClient,
-		NewObject: func() controller.BuildableObject { return &apiv1.Dataset{} },
-		Kind:      "Dataset",
+		Scheme:                  mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Cloud:                   cld,
+		SCI:                     sciClient,
+		NewObject:               func() controller.BuildableObject { return &apiv1.Dataset{} },
+		Kind:                    "Dataset",
+		MaxConcurrentReconciles: maxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "DatasetBuilder")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&apiv1.Model{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Model")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -240,6 +417,20 @@ func main() {
 	}
 }
 
+// loadDefaultResources reads the default resources from the ConfigMap
+// referenced as "<namespace>/<name>".
+func loadDefaultResources(ctx context.Context, clientset kubernetes.Interface, cloudName, ref string) (*resources.Defaults, error) {
+	namespace, name, ok := strings.Cut(ref, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid configmap reference %q, expected <namespace>/<name>", ref)
+	}
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting configmap: %w", err)
+	}
+	return resources.ParseDefaults(cloudName, cm.Data)
+}
+
 func dumpConfigToFile(path string, config interface{}) error {
 	var buf bytes.Buffer
 	if err := yaml.NewEncoder(&buf).Encode(config); err != nil {
@@ -1,56 +1,113 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"log"
 	"net"
+	"os"
 	"strconv"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/iam"
-	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/sethvargo/go-envconfig"
 	"github.com/substratusai/substratus/internal/sci"
 	awssci "github.com/substratusai/substratus/internal/sci/aws"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	hv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"github.com/substratusai/substratus/internal/tracing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
+var setupLog = ctrl.Log.WithName("setup")
+
 func main() {
-	// serve by default on port 10081
+	// serve by default on port 10081 (all interfaces)
 	var port int
 	flag.IntVar(&port, "port", 10081, "port number to listen on")
+	var addr string
+	flag.StringVar(&addr, "addr", os.Getenv("LISTEN_ADDRESS"), "host or IP address to listen on, i.e. localhost (defaults to all interfaces)")
+	var enableReflection bool
+	flag.BoolVar(&enableReflection, "enable-reflection", false, "register the gRPC reflection service (for debugging with tools like grpcurl and sub sci)")
+	var caBundle string
+	flag.StringVar(&caBundle, "ca-bundle", os.Getenv("CA_BUNDLE"), "path to a PEM file with additional CA certificates to trust (i.e. for a TLS intercepting proxy)")
+	var credentialsFile, credentialsProfile string
+	flag.StringVar(&credentialsFile, "credentials-file", os.Getenv("CREDENTIALS_FILE"), "path to a shared credentials file with access keys (i.e. a mounted Secret) to use instead of the ambient identity (IRSA)")
+	flag.StringVar(&credentialsProfile, "credentials-profile", os.Getenv("CREDENTIALS_PROFILE"), `profile to use from the credentials file (defaults to "default")`)
+
+	var shutdownTimeout time.Duration
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 25*time.Second, "how long to wait for in-flight requests to complete on shutdown (keep below the terminationGracePeriodSeconds of the Pod)")
+
+	var otlpEndpoint string
+	var otlpInsecure bool
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv("OTLP_ENDPOINT"), "host:port of an OTLP/gRPC collector to export traces to (tracing is disabled when empty)")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "connect to the OTLP collector without TLS")
+
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	// Create new AWS Server
-	s, err := NewServer()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "substratus-sci-aws", otlpEndpoint, otlpInsecure)
 	if err != nil {
-		log.Fatalf("failed to create AWS server: %v", err)
+		setupLog.Error(err, "failed to setup tracing")
+		os.Exit(1)
 	}
 
-	gs := grpc.NewServer()
-	sci.RegisterControllerServer(gs, s)
+	// Proxy (HTTPS_PROXY, NO_PROXY) and CA settings apply to all AWS clients.
+	if err := sci.ConfigureDefaultTransport(caBundle); err != nil {
+		setupLog.Error(err, "failed to configure http transport")
+		os.Exit(1)
+	}
 
-	// Setup Health Check
-	hs := health.NewServer()
-	hs.SetServingStatus("", hv1.HealthCheckResponse_SERVING)
-	hv1.RegisterHealthServer(gs, hs)
+	// Create new AWS Server
+	s, err := NewServer(credentialsFile, credentialsProfile)
+	if err != nil {
+		setupLog.Error(err, "failed to create AWS server")
+		os.Exit(1)
+	}
 
-	fmt.Printf("awssci server listening on port %v...", port)
-	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	gs := sci.NewGRPCServer(s, ctrl.Log.WithName("sci"), enableReflection)
+
+	bindAddr := net.JoinHostPort(addr, strconv.Itoa(port))
+	lis, err := net.Listen("tcp", bindAddr)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		setupLog.Error(err, "failed to listen", "address", bindAddr)
+		os.Exit(1)
+	}
+	// The resolved address includes the port that was picked for --port=0.
+	setupLog.Info("awssci server listening", "address", lis.Addr().String())
+
+	if err := sci.Serve(ctrl.SetupSignalHandler(), gs, lis, shutdownTimeout); err != nil {
+		setupLog.Error(err, "failed to serve", "address", lis.Addr().String())
+		os.Exit(1)
 	}
+	setupLog.Info("server stopped")
 
-	if err := gs.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "failed to flush traces")
 	}
 }
 
-func NewServer() (*awssci.Server, error) {
-	sess, err := session.NewSession()
+// NewServer creates a Server. When credentialsFile is set, the access keys
+// of the given profile in that file are used instead of the default
+// credential chain.
+func NewServer(credentialsFile, credentialsProfile string) (*awssci.Server, error) {
+	var cfg aws.Config
+	if credentialsFile != "" {
+		creds := credentials.NewSharedCredentials(credentialsFile, credentialsProfile)
+		if _, err := creds.Get(); err != nil {
+			return nil, fmt.Errorf("failed to load credentials from %s: %w", credentialsFile, err)
+		}
+		cfg.Credentials = creds
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{Config: cfg})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS session: %w", err)
 	}
@@ -73,8 +130,13 @@ func NewServer() (*awssci.Server, error) {
 
 	oidcProviderARN := fmt.Sprintf("arn:aws:iam::%s:oidc-provider/%s", accountId, oidcProviderURL)
 
+	var s3Cfg awssci.S3Config
+	if err := envconfig.Process(context.Background(), &s3Cfg); err != nil {
+		return nil, fmt.Errorf("s3 config from environment: %w", err)
+	}
+
 	c := &awssci.Clients{
-		S3Client:  s3.New(sess),
+		S3Client:  awssci.NewS3Client(sess, s3Cfg),
 		IAMClient: iam.New(sess),
 	}
 
@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"flag"
-	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 
@@ -16,19 +16,35 @@ import (
 	"cloud.google.com/go/storage"
 	"github.com/substratusai/substratus/internal/sci"
 	"github.com/substratusai/substratus/internal/sci/gcp"
+	"github.com/substratusai/substratus/internal/tracing"
 	"google.golang.org/api/iam/v1"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	hv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/api/option"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
 var setupLog = ctrl.Log.WithName("setup")
 
 func main() {
-	// serve by default on port 10080
+	// serve by default on port 10080 (all interfaces)
 	var port int
 	flag.IntVar(&port, "port", 10080, "port number to listen on")
+	var addr string
+	flag.StringVar(&addr, "addr", os.Getenv("LISTEN_ADDRESS"), "host or IP address to listen on, i.e. localhost (defaults to all interfaces)")
+	var enableReflection bool
+	flag.BoolVar(&enableReflection, "enable-reflection", false, "register the gRPC reflection service (for debugging with tools like grpcurl and sub sci)")
+	var caBundle, storageEndpoint, iamEndpoint, iamCredentialsEndpoint string
+	flag.StringVar(&caBundle, "ca-bundle", os.Getenv("CA_BUNDLE"), "path to a PEM file with additional CA certificates to trust (i.e. for a TLS intercepting proxy)")
+	flag.StringVar(&storageEndpoint, "storage-endpoint", os.Getenv("STORAGE_ENDPOINT"), "override the GCS endpoint (i.e. a Private Service Connect endpoint)")
+	flag.StringVar(&iamEndpoint, "iam-endpoint", os.Getenv("IAM_ENDPOINT"), "override the IAM endpoint")
+	flag.StringVar(&iamCredentialsEndpoint, "iam-credentials-endpoint", os.Getenv("IAM_CREDENTIALS_ENDPOINT"), "override the IAM Credentials endpoint")
+
+	var shutdownTimeout time.Duration
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 25*time.Second, "how long to wait for in-flight requests to complete on shutdown (keep below the terminationGracePeriodSeconds of the Pod)")
+
+	var otlpEndpoint string
+	var otlpInsecure bool
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv("OTLP_ENDPOINT"), "host:port of an OTLP/gRPC collector to export traces to (tracing is disabled when empty)")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "connect to the OTLP collector without TLS")
 
 	opts := zap.Options{
 		Development: true,
@@ -38,20 +54,50 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	shutdownTracing, err := tracing.Setup(context.Background(), "substratus-sci-gcp", otlpEndpoint, otlpInsecure)
+	if err != nil {
+		setupLog.Error(err, "failed to setup tracing")
+		os.Exit(1)
+	}
+
+	// Proxy (HTTPS_PROXY, NO_PROXY) and CA settings apply to all clients
+	// below as they are all HTTP based.
+	if err := sci.ConfigureDefaultTransport(caBundle); err != nil {
+		setupLog.Error(err, "failed to configure http transport")
+		os.Exit(1)
+	}
+	s, err := gcp.NewServer()
+	if err != nil {
+		setupLog.Error(err, "failed to create server")
+		os.Exit(1)
+	}
+
+	endpoint := func(e string) []option.ClientOption {
+		var opts []option.ClientOption
+		if s.CredentialsFile != "" {
+			// Authenticate with the mounted key instead of the ambient identity.
+			opts = append(opts, option.WithCredentialsFile(s.CredentialsFile))
+		}
+		if e != "" {
+			opts = append(opts, option.WithEndpoint(e))
+		}
+		return opts
+	}
+
 	ctx := context.Background()
-	iamCredClient, err := credentials.NewIamCredentialsClient(ctx)
+	iamCredClient, err := credentials.NewIamCredentialsRESTClient(ctx, endpoint(iamCredentialsEndpoint)...)
 	if err != nil {
 		setupLog.Error(err, "failed to create iam credentials client")
 		os.Exit(1)
 	}
 
-	iamService, err := iam.NewService(ctx)
+	iamService, err := iam.NewService(ctx, endpoint(iamEndpoint)...)
 	if err != nil {
 		setupLog.Error(err, "failed to create iam client")
 		os.Exit(1)
 	}
 
-	storageClient, err := storage.NewClient(context.Background())
+	storageClient, err := storage.NewClient(ctx, endpoint(storageEndpoint)...)
 	if err != nil {
 		setupLog.Error(err, "failed to create storage client")
 		os.Exit(1)
@@ -60,11 +106,6 @@ func main() {
 	hc := &http.Client{}
 	mc := metadata.NewClient(hc)
 
-	s, err := gcp.NewServer()
-	if err != nil {
-		setupLog.Error(err, "failed to create server")
-		os.Exit(1)
-	}
 	s.Clients = gcp.Clients{
 		IAMCredentialsClient: iamCredClient,
 		IAM:                  iamService,
@@ -81,23 +122,28 @@ func main() {
 		setupLog.Error(err, "failed to validate server")
 		os.Exit(1)
 	}
-	gs := grpc.NewServer()
-	sci.RegisterControllerServer(gs, s)
-
-	// Setup Health Check
-	hs := health.NewServer()
-	hs.SetServingStatus("", hv1.HealthCheckResponse_SERVING)
-	hv1.RegisterHealthServer(gs, hs)
+	gs := sci.NewGRPCServer(s, ctrl.Log.WithName("sci"), enableReflection)
 
-	fmt.Printf("sci.gcp server listening on port %v...", port)
-	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	bindAddr := net.JoinHostPort(addr, strconv.Itoa(port))
+	lis, err := net.Listen("tcp", bindAddr)
 	if err != nil {
-		setupLog.Error(err, "failed to listen", "port", port)
+		setupLog.Error(err, "failed to listen", "address", bindAddr)
 		os.Exit(1)
 	}
+	// The resolved address includes the port that was picked for --port=0.
+	setupLog.Info("sci.gcp server listening", "address", lis.Addr().String())
 
-	if err := gs.Serve(lis); err != nil {
-		setupLog.Error(err, "failed to serve", "port", port)
+	if err := sci.Serve(ctrl.SetupSignalHandler(), gs, lis, shutdownTimeout); err != nil {
+		setupLog.Error(err, "failed to serve", "address", lis.Addr().String())
 		os.Exit(1)
 	}
+	setupLog.Info("server stopped")
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "failed to flush traces")
+	}
+
+	if err := storageClient.Close(); err != nil {
+		setupLog.Error(err, "failed to close storage client")
+	}
 }
@@ -1,59 +1,96 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/substratusai/substratus/internal/sci"
 	scikind "github.com/substratusai/substratus/internal/sci/kind"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/health"
-	hv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"github.com/substratusai/substratus/internal/tracing"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
 
+var setupLog = ctrl.Log.WithName("setup")
+
 func main() {
 	var cfg struct {
+		addr                 string
 		port                 int
 		signedURLPort        int
 		hostSignedURLAddress string
+		bucketDir            string
+		enableReflection     bool
 	}
 	flag.IntVar(&cfg.port, "port", 10080, "port number to listen on")
+	flag.StringVar(&cfg.addr, "addr", os.Getenv("LISTEN_ADDRESS"), "host or IP address to listen on, i.e. localhost (defaults to all interfaces)")
 	flag.IntVar(&cfg.signedURLPort, "signed-url-port", 8080, "port to listen for signed url traffic")
 	flag.StringVar(&cfg.hostSignedURLAddress, "host-signed-url-address", "http://localhost:30080",
 		"host address that port forwards to the signed url port within the cluster. this should be set in kind config.yaml.")
+	flag.StringVar(&cfg.bucketDir, "bucket-dir", scikind.DefaultBucketDir, "directory that the bucket host path is mounted at, objects outside of it are not served")
+	flag.BoolVar(&cfg.enableReflection, "enable-reflection", false, "register the gRPC reflection service (for debugging with tools like grpcurl and sub sci)")
+
+	var shutdownTimeout time.Duration
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 25*time.Second, "how long to wait for in-flight requests to complete on shutdown (keep below the terminationGracePeriodSeconds of the Pod)")
+
+	var otlpEndpoint string
+	var otlpInsecure bool
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", os.Getenv("OTLP_ENDPOINT"), "host:port of an OTLP/gRPC collector to export traces to (tracing is disabled when empty)")
+	flag.BoolVar(&otlpInsecure, "otlp-insecure", false, "connect to the OTLP collector without TLS")
+
+	opts := zap.Options{
+		Development: true,
+	}
+	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), "substratus-sci-kind", otlpEndpoint, otlpInsecure)
+	if err != nil {
+		setupLog.Error(err, "failed to setup tracing")
+		os.Exit(1)
+	}
+
 	s := &scikind.Server{
 		SignedURLAddress: cfg.hostSignedURLAddress,
+		BucketDir:        cfg.bucketDir,
 	}
 	signedURLServer := &http.Server{
 		Addr:    fmt.Sprintf(":%v", cfg.signedURLPort),
 		Handler: s,
 	}
 	go func() {
-		log.Printf("Listening for signed URL traffic on address: %v", cfg.signedURLPort)
-		log.Fatal(signedURLServer.ListenAndServe())
+		setupLog.Info("Listening for signed URL traffic", "port", cfg.signedURLPort)
+		if err := signedURLServer.ListenAndServe(); err != nil {
+			setupLog.Error(err, "failed to serve signed URL traffic")
+			os.Exit(1)
+		}
 	}()
 
-	gs := grpc.NewServer()
-	sci.RegisterControllerServer(gs, s)
+	gs := sci.NewGRPCServer(s, ctrl.Log.WithName("sci"), cfg.enableReflection)
 
-	// Setup Health Check
-	hs := health.NewServer()
-	hs.SetServingStatus("", hv1.HealthCheckResponse_SERVING)
-	hv1.RegisterHealthServer(gs, hs)
-
-	addr := fmt.Sprintf(":%v", cfg.port)
-	log.Printf("Listening for gRPC traffic on address: %v", addr)
+	addr := net.JoinHostPort(cfg.addr, strconv.Itoa(cfg.port))
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		log.Fatalf("failed to listen: %v", err)
+		setupLog.Error(err, "failed to listen", "address", addr)
+		os.Exit(1)
+	}
+	setupLog.Info("Listening for gRPC traffic", "address", lis.Addr().String())
+
+	if err := sci.Serve(ctrl.SetupSignalHandler(), gs, lis, shutdownTimeout); err != nil {
+		setupLog.Error(err, "failed to serve", "address", addr)
+		os.Exit(1)
 	}
+	setupLog.Info("server stopped")
 
-	if err := gs.Serve(lis); err != nil {
-		log.Fatalf("failed to serve: %v", err)
+	if err := shutdownTracing(context.Background()); err != nil {
+		setupLog.Error(err, "failed to flush traces")
 	}
 }
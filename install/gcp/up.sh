@@ -107,5 +107,5 @@ data:
   REGISTRY_URL: ${REGISTRY_URL}
   PRINCIPAL: ${SERVICE_ACCOUNT}
 EOF
-kubectl apply -f https://raw.githubusercontent.com/substratusai/substratus/main/install/gcp/manifests.yaml
+kubectl apply --server-side -f https://raw.githubusercontent.com/substratusai/substratus/main/install/gcp/manifests.yaml
 fi
@@ -74,5 +74,5 @@ helm upgrade \
 if [ "${install_operator}" == "yes" ]; then
   kubectl apply -f ${kubernetes_dir}/namespace.yaml
   kubectl apply -f ${kubernetes_dir}/config.yaml
-  kubectl apply -f ${kubernetes_dir}/system.yaml
+  kubectl apply --server-side -f ${kubernetes_dir}/system.yaml
 fi
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
@@ -18,6 +21,10 @@ func applyCommand() *cobra.Command {
 		namespace  string
 		filename   string
 		kubeconfig string
+		context    string
+		wait       bool
+		timeout    time.Duration
+		force      bool
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
@@ -27,7 +34,7 @@ func applyCommand() *cobra.Command {
 			return fmt.Errorf("Flag -f (--filename) required")
 		}
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -42,28 +49,56 @@ func applyCommand() *cobra.Command {
 			return fmt.Errorf("client: %w", err)
 		}
 
+		ctx := cmd.Context()
+		if flags.timeout > 0 {
+			flags.wait = true
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, flags.timeout)
+			defer cancel()
+		}
+
+		// Fall back to a non-interactive run (i.e. in CI) when there is no
+		// terminal to render to.
+		interactive := term.IsTerminal(int(os.Stdout.Fd()))
+		var opts []tea.ProgramOption
+		if !interactive {
+			opts = append(opts, tea.WithoutRenderer(), tea.WithInput(nil))
+		}
+
 		// Initialize our program
 		tui.P = tea.NewProgram((&tui.ApplyModel{
-			Ctx:      cmd.Context(),
+			Ctx:      ctx,
 			Filename: flags.filename,
 			Namespace: tui.Namespace{
 				Contextual: kubeconfigNamespace,
 				Specified:  flags.namespace,
 			},
-			Client: client,
-			K8s:    clientset,
-		}).New())
-		if _, err := tui.P.Run(); err != nil {
+			Wait:           flags.wait,
+			ForceConflicts: flags.force,
+			Client:         client,
+			K8s:            clientset,
+		}).New(), opts...)
+		final, err := tui.P.Run()
+		if err != nil {
 			return err
 		}
 
-		return nil
+		m := final.(tui.ApplyModel)
+		if !interactive {
+			fmt.Print(m.View())
+		}
+
+		return m.Err()
 	}
 
 	cmd := &cobra.Command{
 		Use:     "apply",
 		Aliases: []string{"ap"},
 		Short:   "Apply Substratus (or any Kubernetes) objects",
+		Long: `Apply Substratus (or any Kubernetes) objects server-side. Repeated applies
+merge the fields that are set in the manifests, fields that are set by others
+(i.e. the controller) are left alone. Changing a field that is owned by
+another manager fails with a conflict unless --force-conflicts is given.`,
 		Example: `  # Scan *.yaml files looking for manifests to apply.
   sub apply ./dir/
 
@@ -71,7 +106,10 @@ func applyCommand() *cobra.Command {
   sub apply -f manifests.yaml
 
   # Apply a remote manifest.
-  sub apply -f https://some/manifest.yaml`,
+  sub apply -f https://some/manifest.yaml
+
+  # Wait up to 30 minutes for the applied objects to be ready (i.e. in CI).
+  sub apply -f manifests.yaml --wait --timeout 30m`,
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := run(cmd, args); err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -86,8 +124,12 @@ func applyCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace of Notebook")
 	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "Manifest file")
+	cmd.Flags().BoolVar(&flags.wait, "wait", false, "Wait for applied objects to be ready, exiting non-zero if they fail")
+	cmd.Flags().DurationVar(&flags.timeout, "timeout", 0, "Maximum time to wait for objects to be ready (implies --wait, 0 means no timeout)")
+	cmd.Flags().BoolVar(&flags.force, "force-conflicts", false, "Take ownership of fields that conflict with other field managers")
 
 	return cmd
 }
@@ -0,0 +1,326 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cli/utils"
+	"github.com/substratusai/substratus/internal/client"
+	"github.com/substratusai/substratus/internal/tui"
+)
+
+type buildableObject interface {
+	client.Object
+	GetBuild() *apiv1.Build
+	GetConditions() *[]metav1.Condition
+	GetStatusContainer() apiv1.ContainerStatus
+}
+
+func buildCommand() *cobra.Command {
+	var flags struct {
+		namespace  string
+		filename   string
+		kubeconfig string
+		context    string
+		upload     uploadFlags
+		force      bool
+	}
+
+	run := func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+
+		tarballOpts, err := flags.upload.tarballOptions()
+		if err != nil {
+			return err
+		}
+
+		obj, err := findBuildableManifest(path, flags.filename)
+		if err != nil {
+			return err
+		}
+
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
+		if err != nil {
+			return fmt.Errorf("rest config: %w", err)
+		}
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("clientset: %w", err)
+		}
+		c, err := NewClient(clientset, restConfig)
+		if err != nil {
+			return fmt.Errorf("client: %w", err)
+		}
+
+		if flags.namespace != "" {
+			obj.SetNamespace(flags.namespace)
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace(kubeconfigNamespace)
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace("default")
+			}
+		}
+
+		res, err := c.Resource(obj)
+		if err != nil {
+			return fmt.Errorf("resource client: %w", err)
+		}
+
+		out := cmd.ErrOrStderr()
+		ref := obj.GetObjectKind().GroupVersionKind().Kind + "/" + obj.GetName()
+
+		fmt.Fprintf(out, "Tarring %s...\n", path)
+		tarball, err := client.PrepareImageTarball(ctx, path, tarballOpts, func(string) {})
+		if err != nil {
+			var tooLarge *client.TarballTooLargeError
+			if errors.As(err, &tooLarge) {
+				return err
+			}
+			return fmt.Errorf("preparing tarball: %w", err)
+		}
+		defer os.RemoveAll(tarball.TempDir)
+		if tarballOpts.WarnSize > 0 && tarball.Size > tarballOpts.WarnSize {
+			fmt.Fprintf(out, "Warning: the tarball is %s, exclude files that are not needed to build the image in .substratusignore\n", tui.FormatBytes(tarball.Size))
+		}
+
+		// The build-only annotation keeps the controller from setting the
+		// built image in .spec.image, so the workload of the object keeps
+		// running its current image.
+		annotations := obj.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[apiv1.BuildOnlyAnnotation] = "true"
+		obj.SetAnnotations(annotations)
+		if err := client.SetUploadContainerSpec(obj, tarball, utils.NewUUID()); err != nil {
+			return fmt.Errorf("setting upload in spec: %w", err)
+		}
+		if err := res.Apply(obj, flags.force); err != nil {
+			if apierrors.IsConflict(err) {
+				return fmt.Errorf("applying: %w (rerun with --force-conflicts to take ownership of the fields)", err)
+			}
+			return fmt.Errorf("applying: %w", err)
+		}
+		applied, err := res.Get(obj.GetNamespace(), obj.GetName())
+		if err != nil {
+			return fmt.Errorf("getting %s: %w", ref, err)
+		}
+		generation := applied.(client.Object).GetGeneration()
+
+		fmt.Fprintf(out, "Uploading to %s...\n", ref)
+		if err := res.Upload(ctx, obj, tarball, func(uploaded, total int64) {}); err != nil {
+			return err
+		}
+
+		fmt.Fprintln(out, "Waiting for the build to start...")
+		stopLogs := func() {}
+		built, err := waitForBuild(ctx, res, obj, generation, func(jobName string) {
+			stopLogs = startBuildLogs(ctx, clientset, obj.GetNamespace(), jobName, cmd.OutOrStdout(), out)
+		})
+		stopLogs()
+		if err != nil {
+			return err
+		}
+
+		status := built.GetStatusContainer()
+		if status.Reason == apiv1.ReasonJobFailed {
+			msg := fmt.Sprintf("build of %s failed (job %s)", ref, status.BuildJob)
+			if status.Message != "" {
+				msg += ": " + status.Message
+			}
+			return errors.New(msg)
+		}
+		fmt.Fprintf(out, "Image: %s\n", status.Image)
+		if status.Digest != "" {
+			fmt.Fprintf(out, "Digest: %s\n", status.Digest)
+		}
+
+		return nil
+	}
+
+	cmd := &cobra.Command{
+		Use:   "build [dir]",
+		Short: "Build the container image of a local directory without running it. Supported kinds: Dataset, Model, Notebook, Server.",
+		Long: `Upload a local directory and build its container image, streaming the logs
+of the build. The object is not updated to use the new image, so loading,
+training or serving is not restarted. A later "sub run" or "sub apply" of the
+same directory reuses the image.`,
+		Example: `  # Build the image of the single manifest in the current directory.
+  sub build
+
+  # Build the image of a Model.
+  sub build -f model.yaml .`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := run(cmd, args); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	defaultKubeconfig := os.Getenv("KUBECONFIG")
+	if defaultKubeconfig == "" {
+		defaultKubeconfig = clientcmd.RecommendedHomeFile
+	}
+	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "path to kubernetes kubeconfig file")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "manifest file (defaults to the single Substratus manifest in *.yaml files of the dir)")
+	cmd.Flags().BoolVar(&flags.force, "force-conflicts", false, "take ownership of fields that conflict with other field managers")
+	flags.upload.register(cmd)
+
+	return cmd
+}
+
+// findBuildableManifest decodes the object to build from the file, or from
+// the *.yaml files of the dir if no file is given. Exactly one object must
+// be found.
+func findBuildableManifest(dir, filename string) (buildableObject, error) {
+	path := dir
+	if filename != "" {
+		path = filename
+	}
+	manifests, err := tui.ReadManifests(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []buildableObject
+	for _, m := range manifests {
+		for _, doc := range tui.SplitManifest(m.Data) {
+			obj, err := client.Decode(doc)
+			if err != nil {
+				if filename == "" {
+					// Unrelated YAML files are expected when scanning a dir.
+					continue
+				}
+				return nil, fmt.Errorf("decoding %s: %w", m.Path, err)
+			}
+			if b, ok := obj.(buildableObject); ok {
+				found = append(found, b)
+			}
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no Dataset, Model, Notebook or Server manifest found")
+	case 1:
+		return found[0], nil
+	default:
+		return nil, fmt.Errorf("found %d manifests, specify one with -f (--filename)", len(found))
+	}
+}
+
+// waitForBuild polls the object until the builder has reported on the given
+// generation and the build has finished. The onJob func is called once with
+// the name of the builder Job when the build is in progress.
+func waitForBuild(ctx context.Context, res *client.Resource, obj client.Object, generation int64, onJob func(jobName string)) (buildableObject, error) {
+	var built buildableObject
+	var jobStarted bool
+	if err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		fetched, err := res.Get(obj.GetNamespace(), obj.GetName())
+		if err != nil {
+			return false, err
+		}
+		b, ok := fetched.(buildableObject)
+		if !ok {
+			return false, fmt.Errorf("object is not buildable: %T", fetched)
+		}
+		cond := meta.FindStatusCondition(*b.GetConditions(), apiv1.ConditionBuilt)
+		if cond == nil || cond.ObservedGeneration < generation {
+			return false, nil
+		}
+		status := b.GetStatusContainer()
+		switch status.Reason {
+		case apiv1.ReasonJobComplete, apiv1.ReasonJobFailed:
+			built = b
+			return true, nil
+		case apiv1.ReasonJobNotComplete:
+			if !jobStarted && status.BuildJob != "" {
+				jobStarted = true
+				onJob(status.BuildJob)
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return nil, fmt.Errorf("waiting for build: %w", err)
+	}
+	return built, nil
+}
+
+// buildLogsDrainTimeout is how long the logs of a finished build are still
+// streamed for (the stream ends by itself once the builder container exits).
+var buildLogsDrainTimeout = 5 * time.Second
+
+// startBuildLogs streams the logs of the builder Job in the background, so
+// that the build status keeps being polled while the logs are followed (i.e.
+// a Job that fails before its Pod starts is still noticed). The returned func
+// stops the stream once the build is done.
+func startBuildLogs(ctx context.Context, clientset kubernetes.Interface, namespace, jobName string, w, errW io.Writer) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := streamBuildLogs(ctx, clientset, namespace, jobName, w); err != nil && ctx.Err() == nil {
+			fmt.Fprintf(errW, "Failed to stream build logs: %v\n", err)
+		}
+	}()
+	return func() {
+		select {
+		case <-done:
+		case <-time.After(buildLogsDrainTimeout):
+		}
+		cancel()
+		<-done
+	}
+}
+
+// streamBuildLogs follows the logs of the builder container until it exits.
+func streamBuildLogs(ctx context.Context, clientset kubernetes.Interface, namespace, jobName string, w io.Writer) error {
+	pods := clientset.CoreV1().Pods(namespace)
+	var podName string
+	if err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+		if err != nil {
+			return false, err
+		}
+		for _, p := range list.Items {
+			if p.Status.Phase != corev1.PodPending {
+				podName = p.Name
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("waiting for pod to start: %w", err)
+	}
+
+	logs, err := pods.GetLogs(podName, &corev1.PodLogOptions{Container: "builder", Follow: true}).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+	_, err = io.Copy(w, logs)
+	return err
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/client"
+)
+
+func TestFindBuildableManifest(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+		return p
+	}
+
+	model := write("model.yaml", `apiVersion: substratus.ai/v1
+kind: Model
+metadata:
+  name: falcon-7b
+`)
+	write("kustomization.yaml", `resources: [model.yaml]
+`)
+
+	obj, err := findBuildableManifest(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, "falcon-7b", obj.GetName())
+
+	_, err = findBuildableManifest(dir, write("bad.txt", `kind: [`))
+	require.ErrorContains(t, err, "decoding")
+
+	write("server.yaml", `apiVersion: substratus.ai/v1
+kind: Server
+metadata:
+  name: falcon-7b
+`)
+	_, err = findBuildableManifest(dir, "")
+	require.EqualError(t, err, "found 2 manifests, specify one with -f (--filename)")
+
+	obj, err = findBuildableManifest(dir, model)
+	require.NoError(t, err)
+	require.IsType(t, &apiv1.Model{}, obj)
+
+	_, err = findBuildableManifest(t.TempDir(), "")
+	require.EqualError(t, err, "no Dataset, Model, Notebook or Server manifest found")
+}
+
+func TestWaitForBuild(t *testing.T) {
+	built := func(generation int64, status apiv1.ContainerStatus) *apiv1.Model {
+		m := &apiv1.Model{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "substratus.ai/v1", Kind: "Model"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "falcon-7b", Generation: generation},
+		}
+		m.Status.Container = status
+		m.Status.Conditions = []metav1.Condition{{
+			Type:               apiv1.ConditionBuilt,
+			Status:             metav1.ConditionFalse,
+			Reason:             status.Reason,
+			ObservedGeneration: generation,
+		}}
+		return m
+	}
+	states := []*apiv1.Model{
+		// Result of the previous build.
+		built(1, apiv1.ContainerStatus{Reason: apiv1.ReasonJobComplete, BuildJob: "old"}),
+		built(2, apiv1.ContainerStatus{Reason: apiv1.ReasonJobNotComplete, BuildJob: "new"}),
+		built(2, apiv1.ContainerStatus{Reason: apiv1.ReasonJobComplete, BuildJob: "new", Image: "img"}),
+	}
+
+	var mtx sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/substratus.ai/v1/namespaces/default/models/falcon-7b", r.URL.Path)
+		mtx.Lock()
+		state := states[0]
+		if len(states) > 1 {
+			states = states[1:]
+		}
+		mtx.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(state))
+	}))
+	defer srv.Close()
+
+	obj := built(2, apiv1.ContainerStatus{})
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(obj.GroupVersionKind(), meta.RESTScopeNamespace)
+	c := &client.Client{Config: &rest.Config{Host: srv.URL}, RESTMapper: mapper}
+	res, err := c.Resource(obj)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var jobs []string
+	result, err := waitForBuild(ctx, res, obj, 2, func(jobName string) {
+		jobs = append(jobs, jobName)
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"new"}, jobs)
+	require.Equal(t, "img", result.GetStatusContainer().Image)
+}
+
+func TestStartBuildLogs(t *testing.T) {
+	defer func(d time.Duration) { buildLogsDrainTimeout = d }(buildLogsDrainTimeout)
+	buildLogsDrainTimeout = 100 * time.Millisecond
+
+	pod := func(phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "builder", Labels: map[string]string{"job-name": "falcon-7b-container-builder"}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// The Pod of a failed Job never starts, stopping must not wait on it.
+	var logs, errs bytes.Buffer
+	stop := startBuildLogs(ctx, fake.NewSimpleClientset(pod(corev1.PodPending)), "default", "falcon-7b-container-builder", &logs, &errs)
+	stop()
+	require.Empty(t, logs.String())
+	require.Empty(t, errs.String(), "canceling the stream is not an error")
+
+	stop = startBuildLogs(ctx, fake.NewSimpleClientset(pod(corev1.PodRunning)), "default", "falcon-7b-container-builder", &logs, &errs)
+	stop()
+	require.Equal(t, "fake logs", logs.String())
+	require.Empty(t, errs.String())
+}
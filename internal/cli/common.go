@@ -1,6 +1,11 @@
 package cli
 
 import (
+	"compress/gzip"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/client-go/kubernetes/scheme"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
@@ -13,3 +18,36 @@ func init() {
 
 // NewClient is a dirty hack to allow the client to be mocked out in tests.
 var NewClient = client.NewClient
+
+// uploadFlags configure the tarball that a local directory is uploaded as.
+type uploadFlags struct {
+	compressionLevel int
+	maxSize          string
+	warnSize         string
+}
+
+func (f *uploadFlags) register(cmd *cobra.Command) {
+	defaults := client.DefaultTarballOptions
+	cmd.Flags().IntVar(&f.compressionLevel, "compression-level", defaults.CompressionLevel, "gzip compression level of the uploaded tarball (0 for none to 9 for best, -1 for the default, -2 for Huffman-only)")
+	cmd.Flags().StringVar(&f.maxSize, "max-upload-size", resource.NewQuantity(defaults.MaxSize, resource.BinarySI).String(), "maximum size of the uploaded tarball (0 for no limit), exclude files with .substratusignore to stay under it")
+	cmd.Flags().StringVar(&f.warnSize, "upload-warn-size", resource.NewQuantity(defaults.WarnSize, resource.BinarySI).String(), "warn before uploading a tarball larger than this size (0 to disable)")
+}
+
+func (f *uploadFlags) tarballOptions() (client.TarballOptions, error) {
+	if f.compressionLevel < gzip.HuffmanOnly || f.compressionLevel > gzip.BestCompression {
+		return client.TarballOptions{}, fmt.Errorf("flags: --compression-level: must be between %d and %d", gzip.HuffmanOnly, gzip.BestCompression)
+	}
+	maxSize, err := resource.ParseQuantity(f.maxSize)
+	if err != nil {
+		return client.TarballOptions{}, fmt.Errorf("flags: --max-upload-size: %w", err)
+	}
+	warnSize, err := resource.ParseQuantity(f.warnSize)
+	if err != nil {
+		return client.TarballOptions{}, fmt.Errorf("flags: --upload-warn-size: %w", err)
+	}
+	return client.TarballOptions{
+		CompressionLevel: f.compressionLevel,
+		MaxSize:          maxSize.Value(),
+		WarnSize:         warnSize.Value(),
+	}, nil
+}
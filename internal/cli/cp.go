@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cli/utils"
+	"github.com/substratusai/substratus/internal/client"
+	"github.com/substratusai/substratus/internal/sci"
+	"github.com/substratusai/substratus/internal/tui"
+)
+
+const sciPort = 10080
+
+// artifactsRef is a "dataset/<name>[:path]" argument of cp.
+type artifactsRef struct {
+	object client.Object
+	name   string
+	path   string
+}
+
+func cpCommand() *cobra.Command {
+	var flags struct {
+		namespace    string
+		kubeconfig   string
+		context      string
+		sciNamespace string
+		sciAddr      string
+	}
+
+	run := func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		src, srcRemote, err := parseArtifactsRef(args[0])
+		if err != nil {
+			return err
+		}
+		dst, dstRemote, err := parseArtifactsRef(args[1])
+		if err != nil {
+			return err
+		}
+		if srcRemote == dstRemote {
+			return fmt.Errorf("exactly one of the arguments must be a dataset/<name> or model/<name> reference")
+		}
+		remote := dst
+		if srcRemote {
+			remote = src
+		}
+
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
+		if err != nil {
+			return fmt.Errorf("rest config: %w", err)
+		}
+		namespace := flags.namespace
+		if namespace == "" {
+			namespace = kubeconfigNamespace
+		}
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("clientset: %w", err)
+		}
+		c, err := NewClient(clientset, restConfig)
+		if err != nil {
+			return fmt.Errorf("client: %w", err)
+		}
+
+		res, err := c.Resource(remote.object)
+		if err != nil {
+			return fmt.Errorf("resource client: %w", err)
+		}
+		ref := remote.object.GetObjectKind().GroupVersionKind().Kind + "/" + remote.name
+		fetched, err := res.Get(namespace, remote.name)
+		if err != nil {
+			return fmt.Errorf("getting %s: %w", ref, err)
+		}
+		artifacts := fetched.(interface{ GetStatusArtifacts() apiv1.ArtifactsStatus }).GetStatusArtifacts()
+		if artifacts.URL == "" {
+			return fmt.Errorf("%s has no artifacts bucket yet", ref)
+		}
+		loc, err := client.NewArtifactsLocation(artifacts.URL, remote.path)
+		if err != nil {
+			return fmt.Errorf("artifacts url: %w", err)
+		}
+
+		addr := flags.sciAddr
+		if addr == "" {
+			stop, forwarded, err := portForwardSCI(ctx, c, clientset, flags.sciNamespace)
+			if err != nil {
+				return err
+			}
+			defer stop()
+			addr = forwarded
+		}
+		conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return fmt.Errorf("connecting to sci: %w", err)
+		}
+		defer conn.Close()
+		sciClient := sci.NewControllerClient(conn)
+
+		out := cmd.ErrOrStderr()
+		lastFile := 0
+		progress := func(name string, file, total int, copied, size int64) {
+			if file != lastFile && lastFile != 0 {
+				fmt.Fprintln(out)
+			}
+			lastFile = file
+			fmt.Fprintf(out, "\r[%d/%d] %s: %s/%s", file, total, name, tui.FormatBytes(copied), tui.FormatBytes(size))
+		}
+
+		if srcRemote {
+			err = client.DownloadArtifacts(ctx, sciClient, loc, dst.path, progress)
+		} else {
+			err = client.UploadArtifacts(ctx, sciClient, src.path, loc, progress)
+		}
+		if lastFile != 0 {
+			fmt.Fprintln(out)
+		}
+		return err
+	}
+
+	cmd := &cobra.Command{
+		Use:   "cp <src> <dst>",
+		Short: "Copy files to or from the artifacts of a Dataset or Model",
+		Long: `Copy local files or directories (recursively) to or from the artifacts of a
+Dataset or Model. Remote paths are relative to the artifacts directory
+("/content/artifacts" in the container) and are given as
+dataset/<name>[:path] or model/<name>[:path]. Prefix local paths that look
+like a reference with "./".
+
+Files are transferred directly with the bucket using signed URLs that are
+requested from the Substratus Cloud Interface (SCI). The SCI is port-forwarded
+to unless --addr is given.`,
+		Example: `  # Upload a file into the artifacts of a Dataset.
+  sub cp ./train.jsonl dataset/squad
+
+  # Upload a directory to artifacts/splits/.
+  sub cp ./splits dataset/squad:splits
+
+  # Download all artifacts of a Dataset into a local directory.
+  sub cp dataset/squad ./squad
+
+  # Download a single file of a Model.
+  sub cp model/falcon-7b:config.json .`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := run(cmd, args); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	defaultKubeconfig := os.Getenv("KUBECONFIG")
+	if defaultKubeconfig == "" {
+		defaultKubeconfig = clientcmd.RecommendedHomeFile
+	}
+	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "path to kubernetes kubeconfig file")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVar(&flags.sciNamespace, "sci-namespace", "substratus", "namespace of the SCI server")
+	cmd.Flags().StringVar(&flags.sciAddr, "addr", "", "address of the SCI server (port-forwarded to if not set)")
+
+	return cmd
+}
+
+// parseArtifactsRef parses a dataset/<name>[:path] or model/<name>[:path]
+// reference. Other arguments are returned as local paths.
+func parseArtifactsRef(arg string) (artifactsRef, bool, error) {
+	kind, rest, ok := strings.Cut(arg, "/")
+	if !ok {
+		return artifactsRef{path: arg}, false, nil
+	}
+	var obj client.Object
+	switch strings.ToLower(kind) {
+	case "dataset", "datasets", "data":
+		obj = &apiv1.Dataset{TypeMeta: metav1.TypeMeta{APIVersion: "substratus.ai/v1", Kind: "Dataset"}}
+	case "model", "models", "mdl":
+		obj = &apiv1.Model{TypeMeta: metav1.TypeMeta{APIVersion: "substratus.ai/v1", Kind: "Model"}}
+	default:
+		return artifactsRef{path: arg}, false, nil
+	}
+	name, p, _ := strings.Cut(rest, ":")
+	if name == "" {
+		return artifactsRef{}, false, fmt.Errorf("invalid reference %q, expected %s/<name>[:path]", arg, kind)
+	}
+	return artifactsRef{object: obj, name: name, path: p}, true, nil
+}
+
+// portForwardSCI forwards a free local port to a ready Pod of the SCI server
+// and returns its address once the forward is ready.
+func portForwardSCI(ctx context.Context, c client.Interface, clientset kubernetes.Interface, namespace string) (func(), string, error) {
+	list, err := clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: "app=sci"})
+	if err != nil {
+		return nil, "", fmt.Errorf("listing sci pods: %w", err)
+	}
+	pod := firstReadyPod(list.Items)
+	if pod == nil {
+		return nil, "", fmt.Errorf("no ready sci pod found in namespace %s", namespace)
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return nil, "", fmt.Errorf("finding a free port: %w", err)
+	}
+	port := l.Addr().(*net.TCPAddr).Port
+	l.Close()
+
+	fwdCtx, cancel := context.WithCancel(ctx)
+	ready := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- c.PortForward(fwdCtx, nil, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, client.ForwardedPorts{Local: port, Pod: sciPort}, ready)
+	}()
+
+	select {
+	case <-ready:
+		return cancel, net.JoinHostPort("localhost", strconv.Itoa(port)), nil
+	case err := <-errs:
+		cancel()
+		return nil, "", fmt.Errorf("port-forwarding to sci: %w", err)
+	case <-time.After(30 * time.Second):
+		cancel()
+		return nil, "", fmt.Errorf("timed out port-forwarding to sci pod %s", pod.Name)
+	}
+}
@@ -18,12 +18,13 @@ func deleteCommand() *cobra.Command {
 		namespace  string
 		filename   string
 		kubeconfig string
+		context    string
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
 		defer tui.LogFile.Close()
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -73,6 +74,7 @@ func deleteCommand() *cobra.Command {
 		defaultKubeconfig = clientcmd.RecommendedHomeFile
 	}
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace of Notebook")
 	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "Manifest file")
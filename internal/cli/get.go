@@ -6,6 +6,8 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 
@@ -15,14 +17,30 @@ import (
 
 func getCommand() *cobra.Command {
 	var flags struct {
-		namespace  string
-		kubeconfig string
+		namespace     string
+		kubeconfig    string
+		context       string
+		selector      string
+		fieldSelector string
+		ready         bool
+		notReady      bool
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
 		defer tui.LogFile.Close()
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		if _, err := labels.Parse(flags.selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+		if _, err := fields.ParseSelector(flags.fieldSelector); err != nil {
+			return fmt.Errorf("invalid field selector: %w", err)
+		}
+		var ready *bool
+		if flags.ready || flags.notReady {
+			ready = &flags.ready
+		}
+
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -55,6 +73,10 @@ func getCommand() *cobra.Command {
 			Scope:     scope,
 			Namespace: namespace,
 
+			LabelSelector: flags.selector,
+			FieldSelector: flags.fieldSelector,
+			Ready:         ready,
+
 			Client: client,
 		}).New() /*, tea.WithAltScreen()*/)
 		if _, err := tui.P.Run(); err != nil {
@@ -67,7 +89,12 @@ func getCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get Substratus Datasets, Models, Notebooks, and Servers",
-		Args:  cobra.MaximumNArgs(1),
+		Example: `  # Get all Substratus objects.
+  sub get
+
+  # Get Models with a given label that are not ready yet.
+  sub get models -l team=nlp --not-ready`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := run(cmd, args); err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -81,8 +108,14 @@ func getCommand() *cobra.Command {
 		defaultKubeconfig = clientcmd.RecommendedHomeFile
 	}
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace of Notebook")
+	cmd.Flags().StringVarP(&flags.selector, "selector", "l", "", "Label selector to filter on (i.e. -l key1=value1,key2=value2)")
+	cmd.Flags().StringVar(&flags.fieldSelector, "field-selector", "", "Field selector to filter on (i.e. --field-selector metadata.name=falcon-7b)")
+	cmd.Flags().BoolVar(&flags.ready, "ready", false, "Only list objects that are ready")
+	cmd.Flags().BoolVar(&flags.notReady, "not-ready", false, "Only list objects that are not ready")
+	cmd.MarkFlagsMutuallyExclusive("ready", "not-ready")
 
 	return cmd
 }
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/resources"
+)
+
+func gpusCommand() *cobra.Command {
+	var flags struct {
+		cloud string
+	}
+
+	cmd := &cobra.Command{
+		Use:   "gpus",
+		Short: "List the GPU types that can be requested (.spec.resources.gpu.type)",
+		Example: `  # List GPUs available on GCP.
+  sub gpus --cloud gcp`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gpus := resources.SupportedGPUs(flags.cloud)
+			if len(gpus) == 0 {
+				return fmt.Errorf("no GPUs are supported on cloud %q", flags.cloud)
+			}
+			for _, gpu := range gpus {
+				fmt.Fprintln(cmd.OutOrStdout(), gpu)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.cloud, "cloud", cloud.GCPName, fmt.Sprintf("Cloud to list GPUs for (%s or %s)", cloud.GCPName, cloud.KindName))
+
+	return cmd
+}
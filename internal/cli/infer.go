@@ -17,12 +17,13 @@ func inferCommand() *cobra.Command {
 	var flags struct {
 		namespace  string
 		kubeconfig string
+		context    string
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
 		defer tui.LogFile.Close()
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -78,6 +79,7 @@ func inferCommand() *cobra.Command {
 		defaultKubeconfig = clientcmd.RecommendedHomeFile
 	}
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace of Notebook")
 
@@ -19,7 +19,13 @@ func notebookCommand() *cobra.Command {
 		namespace  string
 		filename   string
 		kubeconfig string
+		context    string
 		fullscreen bool
+		port       int
+		noBrowser  bool
+		noSync     bool
+		upload     uploadFlags
+		force      bool
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
@@ -34,7 +40,7 @@ func notebookCommand() *cobra.Command {
 		//	}
 		//}
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -44,6 +50,11 @@ func notebookCommand() *cobra.Command {
 			return fmt.Errorf("clientset: %w", err)
 		}
 
+		tarball, err := flags.upload.tarballOptions()
+		if err != nil {
+			return err
+		}
+
 		client, err := NewClient(clientset, restConfig)
 		if err != nil {
 			return fmt.Errorf("client: %w", err)
@@ -68,8 +79,13 @@ func notebookCommand() *cobra.Command {
 				Contextual: kubeconfigNamespace,
 				Specified:  flags.namespace,
 			},
-			Client: client,
-			K8s:    clientset,
+			NoOpenBrowser:  flags.noBrowser,
+			LocalPort:      flags.port,
+			NoSync:         flags.noSync,
+			Tarball:        tarball,
+			ForceConflicts: flags.force,
+			Client:         client,
+			K8s:            clientset,
 		}).New(), pOpts...)
 		if _, err := tui.P.Run(); err != nil {
 			return err
@@ -96,12 +112,19 @@ func notebookCommand() *cobra.Command {
 		defaultKubeconfig = clientcmd.RecommendedHomeFile
 	}
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace of Notebook")
 	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "Manifest file")
 	cmd.Flags().StringVarP(&flags.resume, "resume", "r", "", "Name of notebook to resume")
 
+	cmd.Flags().IntVarP(&flags.port, "port", "p", 8888, "Local port to forward the notebook to")
+	cmd.Flags().BoolVar(&flags.noBrowser, "no-open-browser", false, "Do not open the notebook in a browser")
+	cmd.Flags().BoolVar(&flags.noSync, "no-sync", false, "Do not sync files between the notebook and the local directory")
+
 	cmd.Flags().BoolVar(&flags.fullscreen, "fullscreen", false, "Fullscreen mode")
+	cmd.Flags().BoolVar(&flags.force, "force-conflicts", false, "Take ownership of fields that conflict with other field managers")
+	flags.upload.register(cmd)
 
 	return cmd
 }
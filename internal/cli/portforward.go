@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cli/utils"
+	"github.com/substratusai/substratus/internal/client"
+)
+
+func portForwardCommand() *cobra.Command {
+	var flags struct {
+		namespace  string
+		kubeconfig string
+		context    string
+		address    string
+	}
+
+	run := func(cmd *cobra.Command, args []string) error {
+		kind, name, ok := strings.Cut(args[0], "/")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid reference %q, expected server/<name>", args[0])
+		}
+		switch strings.ToLower(kind) {
+		case "server", "servers", "srv":
+		default:
+			return fmt.Errorf("unsupported kind %q, only servers can be port-forwarded", kind)
+		}
+
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
+		if err != nil {
+			return fmt.Errorf("rest config: %w", err)
+		}
+		namespace := flags.namespace
+		if namespace == "" {
+			namespace = kubeconfigNamespace
+		}
+
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return fmt.Errorf("clientset: %w", err)
+		}
+
+		c, err := NewClient(clientset, restConfig)
+		if err != nil {
+			return fmt.Errorf("client: %w", err)
+		}
+
+		res, err := c.Resource(&apiv1.Server{TypeMeta: metav1.TypeMeta{APIVersion: "substratus.ai/v1", Kind: "Server"}})
+		if err != nil {
+			return fmt.Errorf("resource client: %w", err)
+		}
+		fetched, err := res.Get(namespace, name)
+		if err != nil {
+			return fmt.Errorf("getting server: %w", err)
+		}
+		server := fetched.(*apiv1.Server)
+
+		var portsArg string
+		if len(args) > 1 {
+			portsArg = args[1]
+		}
+		ports, err := client.ParseForwardedPorts(portsArg, int(server.GetPort()))
+		if err != nil {
+			return err
+		}
+		ports.Address = flags.address
+
+		return portForwardServer(cmd.Context(), c, clientset, server, ports)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "port-forward server/<name> [[localPort:]remotePort]",
+		Short: "Forward a local port to a ready Pod of a Server",
+		Example: `  # Forward localhost:8080 to the port of the Server.
+  sub port-forward server/falcon-7b
+
+  # Forward localhost:9000 to port 8080 of the Server.
+  sub port-forward server/falcon-7b 9000:8080
+
+  # Listen on all interfaces.
+  sub port-forward server/falcon-7b --address 0.0.0.0`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := run(cmd, args); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	defaultKubeconfig := os.Getenv("KUBECONFIG")
+	if defaultKubeconfig == "" {
+		defaultKubeconfig = clientcmd.RecommendedHomeFile
+	}
+	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "path to kubernetes kubeconfig file")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
+	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "kubernetes namespace")
+	cmd.Flags().StringVar(&flags.address, "address", "localhost", "addresses to listen on (comma separated)")
+
+	return cmd
+}
+
+// portForwardServer forwards the given ports to a ready Pod of the Server
+// until the context is cancelled. The forward is re-established on another
+// ready Pod when the current Pod goes away (i.e. after a restart or rollout).
+func portForwardServer(ctx context.Context, c client.Interface, clientset kubernetes.Interface, server *apiv1.Server, ports client.ForwardedPorts) error {
+	const reconnectInterval = 2 * time.Second
+
+	pods := clientset.CoreV1().Pods(server.Namespace)
+	var waiting bool
+	// Set from the goroutines that wait for each (re-)connect.
+	var announced atomic.Bool
+	for {
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: "role=run,server=" + server.Name})
+		if err != nil {
+			return fmt.Errorf("listing pods: %w", err)
+		}
+		pod := firstReadyPod(list.Items)
+		if pod == nil {
+			if !waiting {
+				fmt.Fprintf(os.Stderr, "Waiting for a ready pod of server %s\n", server.Name)
+				waiting = true
+			}
+		} else {
+			waiting = false
+			fwdCtx, cancel := context.WithCancel(ctx)
+			runtime.ErrorHandlers = []func(err error){
+				func(err error) {
+					// Restart broken port-forwards (i.e. the Pod was deleted).
+					fmt.Fprintf(os.Stderr, "Port-forward error: %v\n", err)
+					cancel()
+				},
+			}
+
+			ready := make(chan struct{})
+			go func() {
+				select {
+				case <-ready:
+					if announced.CompareAndSwap(false, true) {
+						host := strings.Split(ports.Address, ",")[0]
+						if host == "" || host == "0.0.0.0" {
+							host = "localhost"
+						}
+						fmt.Printf("Forwarding http://%s -> %s/%s:%d\n", net.JoinHostPort(host, strconv.Itoa(ports.Local)), pod.Namespace, pod.Name, ports.Pod)
+					} else {
+						fmt.Fprintf(os.Stderr, "Reconnected to pod %s\n", pod.Name)
+					}
+				case <-fwdCtx.Done():
+				}
+			}()
+
+			err := c.PortForward(fwdCtx, nil, types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, ports, ready)
+			cancel()
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Port-forward to pod %s stopped: %v\n", pod.Name, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectInterval):
+		}
+	}
+}
+
+func firstReadyPod(pods []corev1.Pod) *corev1.Pod {
+	for i := range pods {
+		if pods[i].DeletionTimestamp != nil {
+			continue
+		}
+		for _, c := range pods[i].Status.Conditions {
+			if c.Type == corev1.PodReady && c.Status == corev1.ConditionTrue {
+				return &pods[i]
+			}
+		}
+	}
+	return nil
+}
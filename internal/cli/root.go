@@ -15,10 +15,16 @@ func Command() *cobra.Command {
 	cmd.AddCommand(applyCommand())
 	cmd.AddCommand(notebookCommand())
 	cmd.AddCommand(runCommand())
+	cmd.AddCommand(buildCommand())
+	cmd.AddCommand(cpCommand())
 	cmd.AddCommand(getCommand())
 	// cmd.AddCommand(inferCommand())
 	cmd.AddCommand(deleteCommand())
 	cmd.AddCommand(serveCommand())
+	cmd.AddCommand(portForwardCommand())
+	cmd.AddCommand(sciCommand())
+	cmd.AddCommand(gpusCommand())
+	cmd.AddCommand(validateCommand())
 
 	return cmd
 }
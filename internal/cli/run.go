@@ -1,15 +1,24 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/utils/ptr"
 
 	"github.com/substratusai/substratus/internal/cli/utils"
+	"github.com/substratusai/substratus/internal/client"
 	"github.com/substratusai/substratus/internal/tui"
 )
 
@@ -18,8 +27,11 @@ func runCommand() *cobra.Command {
 		namespace  string
 		filename   string
 		kubeconfig string
+		context    string
 		increment  bool
 		replace    bool
+		gpus       int
+		upload     uploadFlags
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
@@ -29,7 +41,7 @@ func runCommand() *cobra.Command {
 			return fmt.Errorf("flags: --increment (-i) and --replace (-r): not compatible")
 		}
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -39,6 +51,22 @@ func runCommand() *cobra.Command {
 			return fmt.Errorf("clientset: %w", err)
 		}
 
+		if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+			if dash != 1 {
+				return fmt.Errorf("expected a single <kind>/<name> argument before --")
+			}
+			namespace := flags.namespace
+			if namespace == "" {
+				namespace = kubeconfigNamespace
+			}
+			return runInJob(cmd.Context(), clientset, namespace, args[0], args[dash:], flags.gpus)
+		}
+
+		tarball, err := flags.upload.tarballOptions()
+		if err != nil {
+			return err
+		}
+
 		client, err := NewClient(clientset, restConfig)
 		if err != nil {
 			return fmt.Errorf("client: %w", err)
@@ -59,6 +87,7 @@ func runCommand() *cobra.Command {
 			},
 			Increment: flags.increment,
 			Replace:   flags.replace,
+			Tarball:   tarball,
 			Client:    client,
 			K8s:       clientset,
 		}).New())
@@ -80,8 +109,19 @@ func runCommand() *cobra.Command {
   sub run -f model.yaml .
 
   # Upoad dataset importing code and create a Dataset.
-  sub run -f dataset.yaml .`,
-		Args: cobra.MaximumNArgs(1),
+  sub run -f dataset.yaml .
+
+  # Run a one-off command in the container of an existing Model.
+  sub run model/falcon-7b -- python eval.py`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				if len(args) == dash {
+					return fmt.Errorf("expected a command after --")
+				}
+				return nil
+			}
+			return cobra.MaximumNArgs(1)(cmd, args)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			if err := run(cmd, args); err != nil {
 				fmt.Fprintln(os.Stderr, err)
@@ -95,10 +135,102 @@ func runCommand() *cobra.Command {
 		defaultKubeconfig = clientcmd.RecommendedHomeFile
 	}
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "path to kubernetes kubeconfig file")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "kubernetes namespace")
 	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "manifest file")
 	cmd.Flags().BoolVarP(&flags.increment, "increment", "i", false, "increment the name")
 	cmd.Flags().BoolVarP(&flags.replace, "replace", "r", false, "replace if already exists")
+	cmd.Flags().IntVar(&flags.gpus, "gpu", 0, "number of GPUs to attach when running a command in an existing object (<kind>/<name> -- <command>)")
+	flags.upload.register(cmd)
 
 	return cmd
 }
+
+// runInJob runs a command in a one-off Job that is based on the Job that the
+// controller created for the referenced object, streams the logs and deletes
+// the Job afterwards.
+func runInJob(ctx context.Context, clientset kubernetes.Interface, namespace, ref string, command []string, gpus int) error {
+	baseRef, err := client.ParseObjectRef(namespace, ref)
+	if err != nil {
+		return err
+	}
+
+	jobs := clientset.BatchV1().Jobs(baseRef.Namespace)
+	base, err := jobs.Get(ctx, baseRef.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting job %s for %s (has it been created yet?): %w", baseRef.Name, ref, err)
+	}
+
+	job, err := client.RunJobFromJob(base, command, gpus)
+	if err != nil {
+		return err
+	}
+	job, err = jobs.Create(ctx, job, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("creating job: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Created job %s\n", job.Name)
+	defer func() {
+		// Use a fresh context to clean up after an interrupt.
+		if err := jobs.Delete(context.Background(), job.Name, metav1.DeleteOptions{
+			PropagationPolicy: ptr.To(metav1.DeletePropagationBackground),
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to delete job %s: %v\n", job.Name, err)
+		}
+	}()
+
+	pods := clientset.CoreV1().Pods(baseRef.Namespace)
+	var podName string
+	if err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+		if err != nil {
+			return false, err
+		}
+		for _, p := range list.Items {
+			if p.Status.Phase != corev1.PodPending {
+				podName = p.Name
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("waiting for pod to start: %w", err)
+	}
+
+	logs, err := pods.GetLogs(podName, &corev1.PodLogOptions{Follow: true}).Stream(ctx)
+	if err != nil {
+		return fmt.Errorf("streaming logs: %w", err)
+	}
+	defer logs.Close()
+	if _, err := io.Copy(os.Stdout, logs); err != nil {
+		return fmt.Errorf("streaming logs: %w", err)
+	}
+
+	var failed bool
+	if err := wait.PollUntilContextCancel(ctx, time.Second, true, func(ctx context.Context) (bool, error) {
+		j, err := jobs.Get(ctx, job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, c := range j.Status.Conditions {
+			if c.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch c.Type {
+			case batchv1.JobComplete:
+				return true, nil
+			case batchv1.JobFailed:
+				failed = true
+				return true, nil
+			}
+		}
+		return false, nil
+	}); err != nil {
+		return fmt.Errorf("waiting for job to finish: %w", err)
+	}
+	if failed {
+		return fmt.Errorf("job %s failed", job.Name)
+	}
+
+	return nil
+}
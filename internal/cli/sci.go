@@ -0,0 +1,219 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+// sciCommand calls the Substratus Cloud Interface (SCI) directly, which is
+// useful when troubleshooting cloud permissions.
+func sciCommand() *cobra.Command {
+	var flags struct {
+		addr    string
+		timeout time.Duration
+	}
+
+	// call connects to the SCI server and prints the response of a single RPC.
+	call := func(cmd *cobra.Command, f func(ctx context.Context, c sci.ControllerClient) (proto.Message, error)) {
+		ctx, cancel := context.WithTimeout(cmd.Context(), flags.timeout)
+		defer cancel()
+
+		if err := func() error {
+			conn, err := grpc.DialContext(ctx, flags.addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				return fmt.Errorf("connecting to sci: %w", err)
+			}
+			defer conn.Close()
+
+			resp, err := f(ctx, sci.NewControllerClient(conn))
+			if err != nil {
+				return err
+			}
+
+			out, err := protojson.MarshalOptions{Multiline: true, EmitUnpopulated: true}.Marshal(resp)
+			if err != nil {
+				return fmt.Errorf("marshalling response: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	cmd := &cobra.Command{
+		Use:   "sci",
+		Short: "Call the Substratus Cloud Interface (SCI) directly (for debugging)",
+		Example: `  # Port-forward to the SCI server.
+  kubectl port-forward -n substratus svc/sci 10080:10080
+
+  # Get the MD5 checksum of an object.
+  sub sci md5 --bucket my-bucket --object path/to/object
+
+  # Combined checksum of all objects under a prefix.
+  sub sci checksum --bucket my-bucket --prefix path/to/dir
+
+  # Delete all objects under a prefix, showing the progress.
+  sub sci delete-prefix --bucket my-bucket --prefix path/to/dir --timeout 10m
+
+  # Bind a Kubernetes ServiceAccount to a cloud principal.
+  sub sci bind-identity --principal sa@project.iam.gserviceaccount.com --namespace default --service-account modeller`,
+	}
+	cmd.PersistentFlags().StringVar(&flags.addr, "addr", "localhost:10080", "Address of the SCI server")
+	cmd.PersistentFlags().DurationVar(&flags.timeout, "timeout", 30*time.Second, "Timeout for the call")
+
+	var bucket, object, prefix string
+	md5Cmd := &cobra.Command{
+		Use:   "md5",
+		Short: "Call GetObjectMd5",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				return c.GetObjectMd5(ctx, &sci.GetObjectMd5Request{BucketName: bucket, ObjectName: object})
+			})
+		},
+	}
+	md5Cmd.Flags().StringVar(&bucket, "bucket", "", "Bucket name")
+	md5Cmd.Flags().StringVar(&object, "object", "", "Object name")
+	md5Cmd.MarkFlagRequired("bucket")
+	md5Cmd.MarkFlagRequired("object")
+	cmd.AddCommand(md5Cmd)
+
+	checksumCmd := &cobra.Command{
+		Use:   "checksum",
+		Short: "Call GetPrefixChecksum (lists all objects under a prefix)",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				return c.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{BucketName: bucket, Prefix: prefix})
+			})
+		},
+	}
+	checksumCmd.Flags().StringVar(&bucket, "bucket", "", "Bucket name")
+	checksumCmd.Flags().StringVar(&prefix, "prefix", "", "Object prefix")
+	checksumCmd.MarkFlagRequired("bucket")
+	cmd.AddCommand(checksumCmd)
+
+	locationCmd := &cobra.Command{
+		Use:   "bucket-location",
+		Short: "Call GetBucketLocation",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				return c.GetBucketLocation(ctx, &sci.GetBucketLocationRequest{BucketName: bucket})
+			})
+		},
+	}
+	locationCmd.Flags().StringVar(&bucket, "bucket", "", "Bucket name")
+	locationCmd.MarkFlagRequired("bucket")
+	cmd.AddCommand(locationCmd)
+
+	var location string
+	createBucketCmd := &cobra.Command{
+		Use:   "create-bucket",
+		Short: "Call CreateBucket",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				return c.CreateBucket(ctx, &sci.CreateBucketRequest{BucketName: bucket, Location: location})
+			})
+		},
+	}
+	createBucketCmd.Flags().StringVar(&bucket, "bucket", "", "Bucket name")
+	createBucketCmd.Flags().StringVar(&location, "location", "", "Bucket region or multi-region (defaults to the cloud default)")
+	createBucketCmd.MarkFlagRequired("bucket")
+	cmd.AddCommand(createBucketCmd)
+
+	deletePrefixCmd := &cobra.Command{
+		Use:   "delete-prefix",
+		Short: "Call DeletePrefix (deletes all objects under a prefix)",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				stream, err := c.DeletePrefix(ctx, &sci.DeletePrefixRequest{BucketName: bucket, Prefix: prefix})
+				if err != nil {
+					return nil, err
+				}
+				var last *sci.DeletePrefixProgress
+				for {
+					p, err := stream.Recv()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						return nil, err
+					}
+					last = p
+					fmt.Fprintf(os.Stderr, "\rDeleted %d/%d objects", p.DeletedObjects, p.TotalObjects)
+				}
+				fmt.Fprintln(os.Stderr)
+				if last == nil {
+					return nil, fmt.Errorf("no progress received")
+				}
+				return last, nil
+			})
+		},
+	}
+	deletePrefixCmd.Flags().StringVar(&bucket, "bucket", "", "Bucket name")
+	deletePrefixCmd.Flags().StringVar(&prefix, "prefix", "", "Object prefix")
+	deletePrefixCmd.MarkFlagRequired("bucket")
+	deletePrefixCmd.MarkFlagRequired("prefix")
+	cmd.AddCommand(deletePrefixCmd)
+
+	var principal, namespace, serviceAccount string
+	identityFlags := func(c *cobra.Command) {
+		c.Flags().StringVar(&principal, "principal", "", "Cloud principal (i.e. GCP Service Account email or AWS Role ARN)")
+		c.Flags().StringVar(&namespace, "namespace", "default", "Namespace of the Kubernetes ServiceAccount")
+		c.Flags().StringVar(&serviceAccount, "service-account", "", "Name of the Kubernetes ServiceAccount")
+		c.MarkFlagRequired("principal")
+		c.MarkFlagRequired("service-account")
+	}
+
+	bindCmd := &cobra.Command{
+		Use:   "bind-identity",
+		Short: "Call BindIdentity",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				return c.BindIdentity(ctx, &sci.BindIdentityRequest{
+					Principal:                principal,
+					KubernetesNamespace:      namespace,
+					KubernetesServiceAccount: serviceAccount,
+				})
+			})
+		},
+	}
+	identityFlags(bindCmd)
+	cmd.AddCommand(bindCmd)
+
+	unbindCmd := &cobra.Command{
+		Use:   "unbind-identity",
+		Short: "Call UnbindIdentity",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			call(cmd, func(ctx context.Context, c sci.ControllerClient) (proto.Message, error) {
+				return c.UnbindIdentity(ctx, &sci.UnbindIdentityRequest{
+					Principal:                principal,
+					KubernetesNamespace:      namespace,
+					KubernetesServiceAccount: serviceAccount,
+				})
+			})
+		},
+	}
+	identityFlags(unbindCmd)
+	cmd.AddCommand(unbindCmd)
+
+	return cmd
+}
@@ -18,6 +18,8 @@ func serveCommand() *cobra.Command {
 		namespace  string
 		filename   string
 		kubeconfig string
+		context    string
+		force      bool
 	}
 
 	run := func(cmd *cobra.Command, args []string) error {
@@ -27,7 +29,7 @@ func serveCommand() *cobra.Command {
 		//	return fmt.Errorf("Flag -f (--filename) required")
 		//}
 
-		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig)
+		kubeconfigNamespace, restConfig, err := utils.BuildConfigFromFlags("", flags.kubeconfig, flags.context)
 		if err != nil {
 			return fmt.Errorf("rest config: %w", err)
 		}
@@ -56,8 +58,9 @@ func serveCommand() *cobra.Command {
 				Contextual: kubeconfigNamespace,
 				Specified:  flags.namespace,
 			},
-			Client: client,
-			K8s:    clientset,
+			ForceConflicts: flags.force,
+			Client:         client,
+			K8s:            clientset,
 		}).New())
 		if _, err := tui.P.Run(); err != nil {
 			return err
@@ -89,8 +92,10 @@ func serveCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&flags.kubeconfig, "kubeconfig", "", defaultKubeconfig, "")
+	cmd.Flags().StringVarP(&flags.context, "context", "", "", "kubeconfig context to use (defaults to the current-context)")
 	cmd.Flags().StringVarP(&flags.namespace, "namespace", "n", "", "Namespace of Notebook")
 	cmd.Flags().StringVarP(&flags.filename, "filename", "f", "", "Manifest file")
+	cmd.Flags().BoolVar(&flags.force, "force-conflicts", false, "Take ownership of fields that conflict with other field managers")
 
 	return cmd
 }
@@ -9,9 +9,10 @@ import (
 
 // BuildConfigFromFlags is a modified version of clientcmd.BuildConfigFromFlags
 // that returns the namespace set in the kubeconfig to make sure we play nicely
-// with tools like kubens.
-func BuildConfigFromFlags(masterUrl, kubeconfigPath string) (string, *restclient.Config, error) {
-	if kubeconfigPath == "" && masterUrl == "" {
+// with tools like kubens. If kubeContext is empty, the current-context from the
+// kubeconfig is used.
+func BuildConfigFromFlags(masterUrl, kubeconfigPath, kubeContext string) (string, *restclient.Config, error) {
+	if kubeconfigPath == "" && masterUrl == "" && kubeContext == "" {
 		klog.Warning("Neither --kubeconfig nor --master was specified.  Using the inClusterConfig.  This might not work.")
 		kubeconfig, err := restclient.InClusterConfig()
 		if err == nil {
@@ -19,9 +20,16 @@ func BuildConfigFromFlags(masterUrl, kubeconfigPath string) (string, *restclient
 		}
 		klog.Warning("error creating inClusterConfig, falling back to default config: ", err)
 	}
+	// Use the default loading rules so that $KUBECONFIG and ~/.kube/config
+	// are respected the same way kubectl does.
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfigPath
 	cc := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
-		&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: masterUrl}})
+		rules,
+		&clientcmd.ConfigOverrides{
+			ClusterInfo:    clientcmdapi.Cluster{Server: masterUrl},
+			CurrentContext: kubeContext,
+		})
 
 	ns, _, err := cc.Namespace()
 	if err != nil {
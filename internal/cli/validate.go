@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/client"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/tui"
+	"github.com/substratusai/substratus/internal/validation"
+)
+
+func validateCommand() *cobra.Command {
+	var flags struct {
+		filenames []string
+		cloud     string
+	}
+
+	run := func(cmd *cobra.Command, args []string) error {
+		if len(flags.filenames) == 0 {
+			return fmt.Errorf("Flag -f (--filename) required")
+		}
+		return validateManifests(cmd.OutOrStdout(), flags.filenames, flags.cloud)
+	}
+
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate Substratus manifests without a cluster",
+		Example: `  # Validate a single manifest.
+  sub validate -f model.yaml
+
+  # Validate all manifests in a directory against the GPU types of kind.
+  sub validate -f examples/falcon-7b --cloud kind`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := run(cmd, args); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringArrayVarP(&flags.filenames, "filename", "f", nil, "Manifest file or directory of *.yaml files (can be repeated)")
+	cmd.Flags().StringVar(&flags.cloud, "cloud", cloud.GCPName, fmt.Sprintf("Cloud to validate GPU types for (%s or %s)", cloud.GCPName, cloud.KindName))
+
+	return cmd
+}
+
+// validateManifests validates the documents of all manifests found at the
+// paths and writes the problems to out.
+func validateManifests(out io.Writer, paths []string, cloudName string) error {
+	var manifests []tui.Manifest
+	for _, p := range paths {
+		m, err := tui.ReadManifests(p)
+		if err != nil {
+			return err
+		}
+		manifests = append(manifests, m...)
+	}
+
+	var problems int
+	for _, m := range manifests {
+		for i, doc := range tui.SplitManifest(m.Data) {
+			errs := validateManifest(doc, cloudName)
+			for _, err := range errs {
+				fmt.Fprintf(out, "%s (document %d): %v\n", m.Path, i+1, err)
+			}
+			problems += len(errs)
+		}
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("%d problem(s) found", problems)
+	}
+	fmt.Fprintf(out, "%d file(s) valid\n", len(manifests))
+	return nil
+}
+
+// validateManifest decodes a single YAML document and validates it. Unknown
+// fields are reported for Substratus kinds as they would be dropped when
+// applied. Other kinds are only decoded.
+func validateManifest(doc []byte, cloudName string) []error {
+	obj, err := client.Decode(doc)
+	if err != nil {
+		return []error{fmt.Errorf("decoding: %w", err)}
+	}
+
+	var strict client.Object
+	switch obj.(type) {
+	case *apiv1.Model:
+		strict = &apiv1.Model{}
+	case *apiv1.Dataset:
+		strict = &apiv1.Dataset{}
+	case *apiv1.Server:
+		strict = &apiv1.Server{}
+	case *apiv1.Notebook:
+		strict = &apiv1.Notebook{}
+	default:
+		return nil
+	}
+
+	ref := obj.GetObjectKind().GroupVersionKind().Kind + "/" + obj.GetName()
+	var errs []error
+	if err := yaml.UnmarshalStrict(doc, strict); err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+	}
+	for _, err := range validation.Validate(obj, cloudName) {
+		errs = append(errs, fmt.Errorf("%s: %w", ref, err))
+	}
+	return errs
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+func TestValidateManifest(t *testing.T) {
+	errs := validateManifest([]byte(`apiVersion: substratus.ai/v1
+kind: Model
+metadata:
+  name: falcon-7b
+spec:
+  image: substratusai/model-loader-huggingface
+`), cloud.GCPName)
+	require.Empty(t, errs)
+
+	errs = validateManifest([]byte(`apiVersion: substratus.ai/v1
+kind: Model
+metadata:
+  name: falcon-7b
+spec:
+  image: substratusai/model-loader-huggingface
+  imagee: typo
+  resources:
+    cpu: -1
+`), cloud.GCPName)
+	require.Len(t, errs, 2)
+	require.ErrorContains(t, errs[0], `Model/falcon-7b: error unmarshaling JSON`)
+	require.ErrorContains(t, errs[0], `unknown field "imagee"`)
+	require.ErrorContains(t, errs[1], `Model/falcon-7b: spec.resources.cpu`)
+
+	// Other kinds are only decoded.
+	require.Empty(t, validateManifest([]byte(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+unknown: field
+`), cloud.GCPName))
+
+	require.Len(t, validateManifest([]byte(`kind: [`), cloud.GCPName), 1)
+}
+
+func TestValidateManifests(t *testing.T) {
+	dir := t.TempDir()
+	valid := `apiVersion: substratus.ai/v1
+kind: Dataset
+metadata:
+  name: squad
+spec:
+  image: substratusai/dataset-loader-huggingface
+`
+	invalid := `apiVersion: substratus.ai/v1
+kind: Server
+metadata:
+  name: falcon-7b
+spec:
+  image: substratusai/model-server-basaran
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dataset.yaml"), []byte(valid), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "all.yaml"), []byte(valid+"---\n"+invalid), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(invalid), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, validateManifests(&out, []string{filepath.Join(dir, "dataset.yaml")}, cloud.GCPName))
+	require.Equal(t, "1 file(s) valid\n", out.String())
+
+	out.Reset()
+	require.EqualError(t, validateManifests(&out, []string{dir}, cloud.GCPName), "1 problem(s) found")
+	require.Equal(t, filepath.Join(dir, "all.yaml")+" (document 2): Server/falcon-7b: spec.model.name: Required value\n", out.String())
+
+	require.Error(t, validateManifests(&out, []string{filepath.Join(dir, "missing.yaml")}, cloud.GCPName))
+}
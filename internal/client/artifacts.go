@@ -0,0 +1,237 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+// copySignedURLExpiration is the lifetime of the signed URLs that files are
+// copied with, one URL is requested per file right before it is copied.
+const copySignedURLExpiration = 15 * 60
+
+var plainMD5Pattern = regexp.MustCompile(`^[a-f0-9]{32}$`)
+
+// ArtifactsLocation is a path within the artifacts of a Dataset or Model.
+type ArtifactsLocation struct {
+	Bucket string
+	// Prefix of all objects of the location (without a trailing slash).
+	Prefix string
+	// Dir is set when the path is the artifacts directory or ends with a
+	// "/", local files are copied into it instead of to it.
+	Dir bool
+}
+
+// NewArtifactsLocation resolves a path (relative to the artifacts
+// directory, "/content/artifacts" in the loader) from the artifacts URL of
+// an object's status.
+func NewArtifactsLocation(artifactsURL, subpath string) (ArtifactsLocation, error) {
+	u, err := cloud.ParseBucketURL(artifactsURL)
+	if err != nil {
+		return ArtifactsLocation{}, err
+	}
+	prefix := path.Join(u.Path, "artifacts", path.Clean("/"+subpath))
+	if u.Scheme == "tar" {
+		// Local (kind) buckets are directories on the node.
+		prefix = "/" + prefix
+	}
+	return ArtifactsLocation{
+		Bucket: u.Bucket,
+		Prefix: strings.TrimSuffix(prefix, "/"),
+		Dir:    path.Clean("/"+subpath) == "/" || strings.HasSuffix(subpath, "/"),
+	}, nil
+}
+
+// CopyProgress is called while a file is copied with the bytes copied so
+// far. The file is the index (starting at 1) of the file out of total files.
+type CopyProgress func(name string, file, total int, copied, size int64)
+
+// UploadArtifacts copies a local file or directory (recursively) to the
+// location. A file is uploaded to the location itself unless the location
+// is a directory, the files of a directory are uploaded under the location.
+func UploadArtifacts(ctx context.Context, c sci.ControllerClient, src string, dst ArtifactsLocation, progressF CopyProgress) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	type upload struct{ local, object string }
+	var uploads []upload
+	if info.IsDir() {
+		if err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(src, p)
+			if err != nil {
+				return err
+			}
+			uploads = append(uploads, upload{p, path.Join(dst.Prefix, filepath.ToSlash(rel))})
+			return nil
+		}); err != nil {
+			return fmt.Errorf("walking %s: %w", src, err)
+		}
+	} else {
+		object := dst.Prefix
+		if dst.Dir {
+			object = path.Join(dst.Prefix, filepath.Base(src))
+		}
+		uploads = append(uploads, upload{src, object})
+	}
+
+	for i, u := range uploads {
+		checksum, err := calculateMD5(u.local)
+		if err != nil {
+			return fmt.Errorf("checksum of %s: %w", u.local, err)
+		}
+		resp, err := c.CreateSignedURL(ctx, &sci.CreateSignedURLRequest{
+			BucketName:        dst.Bucket,
+			ObjectName:        u.object,
+			ExpirationSeconds: copySignedURLExpiration,
+			Md5Checksum:       checksum,
+			Method:            http.MethodPut,
+		})
+		if err != nil {
+			return fmt.Errorf("creating signed url: %w", err)
+		}
+		name := strings.TrimPrefix(u.object, dst.Prefix+"/")
+		if err := uploadFileWithRetry(ctx, u.local, checksum, resp.Url, func(uploaded, total int64) {
+			progressF(name, i+1, len(uploads), uploaded, total)
+		}); err != nil {
+			return fmt.Errorf("uploading %s: %w", u.local, err)
+		}
+	}
+	return nil
+}
+
+// DownloadArtifacts copies the objects under the location (recursively) to
+// a local directory, or a single object to dst. The files of a directory are
+// written under dst, single objects are written into dst if it is an
+// existing directory.
+func DownloadArtifacts(ctx context.Context, c sci.ControllerClient, src ArtifactsLocation, dst string, progressF CopyProgress) error {
+	list, err := c.ListObjects(ctx, &sci.ListObjectsRequest{BucketName: src.Bucket, Prefix: src.Prefix})
+	if err != nil {
+		return fmt.Errorf("listing objects: %w", err)
+	}
+
+	type download struct{ object, local, md5 string }
+	var downloads []download
+	if len(list.Objects) > 0 {
+		for _, o := range list.Objects {
+			local, err := localArtifactPath(dst, o.Name)
+			if err != nil {
+				return err
+			}
+			downloads = append(downloads, download{
+				object: path.Join(src.Prefix, o.Name),
+				local:  local,
+				md5:    o.Md5Checksum,
+			})
+		}
+	} else {
+		// Not a directory, try a single object.
+		local := dst
+		if info, err := os.Stat(dst); err == nil && info.IsDir() {
+			local = filepath.Join(dst, path.Base(src.Prefix))
+		}
+		downloads = append(downloads, download{object: src.Prefix, local: local})
+	}
+
+	for i, d := range downloads {
+		resp, err := c.CreateSignedURL(ctx, &sci.CreateSignedURLRequest{
+			BucketName:        src.Bucket,
+			ObjectName:        d.object,
+			ExpirationSeconds: copySignedURLExpiration,
+			Method:            http.MethodGet,
+		})
+		if err != nil {
+			return fmt.Errorf("creating signed url: %w", err)
+		}
+		name := path.Base(d.object)
+		if len(list.Objects) > 0 {
+			name = strings.TrimPrefix(d.object, src.Prefix+"/")
+		}
+		if err := downloadFile(ctx, resp.Url, d.local, d.md5, func(copied, total int64) {
+			progressF(name, i+1, len(downloads), copied, total)
+		}); err != nil {
+			return fmt.Errorf("downloading %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// localArtifactPath returns the path that a listed object (named relative to
+// the prefix) is downloaded to. Objects are written by the containers of the
+// Dataset or Model, names that would be written outside of dst are rejected.
+func localArtifactPath(dst, name string) (string, error) {
+	if name == "" || path.IsAbs(name) || filepath.IsAbs(name) {
+		return "", fmt.Errorf("invalid object name %q", name)
+	}
+	local := filepath.Join(dst, filepath.FromSlash(name))
+	rel, err := filepath.Rel(dst, local)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("object %q would be written outside of %s", name, dst)
+	}
+	return local, nil
+}
+
+// downloadFile GETs a signed URL into the file. The expected md5 checksum
+// (hex) is verified if it is a plain md5 (S3 ETags of multipart uploads are
+// not).
+func downloadFile(ctx context.Context, url, dst, expectedMD5 string, progressF func(copied, total int64)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("not found")
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), &progressReader{
+		total: resp.ContentLength,
+		r:     resp.Body,
+		f:     progressF,
+	}); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if plainMD5Pattern.MatchString(expectedMD5) {
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != expectedMD5 {
+			return fmt.Errorf("md5 checksum mismatch: expected %s, got %s", expectedMD5, actual)
+		}
+	}
+	return nil
+}
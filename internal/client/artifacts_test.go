@@ -0,0 +1,196 @@
+package client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+func TestNewArtifactsLocation(t *testing.T) {
+	cases := []struct {
+		url, subpath string
+		expected     ArtifactsLocation
+	}{
+		{"gs://bkt/abc123", "", ArtifactsLocation{Bucket: "bkt", Prefix: "abc123/artifacts", Dir: true}},
+		{"gs://bkt/abc123", "train.jsonl", ArtifactsLocation{Bucket: "bkt", Prefix: "abc123/artifacts/train.jsonl"}},
+		{"gs://bkt/abc123", "splits/", ArtifactsLocation{Bucket: "bkt", Prefix: "abc123/artifacts/splits", Dir: true}},
+		{"s3://bkt/ns/abc123/", "../../escape", ArtifactsLocation{Bucket: "bkt", Prefix: "ns/abc123/artifacts/escape"}},
+		{"tar:///bucket/abc123", "data", ArtifactsLocation{Prefix: "/bucket/abc123/artifacts/data"}},
+	}
+	for _, c := range cases {
+		loc, err := NewArtifactsLocation(c.url, c.subpath)
+		require.NoError(t, err)
+		require.Equal(t, c.expected, loc, "%s %s", c.url, c.subpath)
+	}
+
+	_, err := NewArtifactsLocation("", "")
+	require.Error(t, err)
+}
+
+// memBucket is an in-memory bucket that serves signed URLs.
+type memBucket struct {
+	sci.FakeSCIControllerClient
+	url string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (b *memBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		sum := md5.Sum(data)
+		if r.Header.Get("Content-MD5") != base64.StdEncoding.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b.objects[name] = data
+	case http.MethodGet:
+		data, ok := b.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	}
+}
+
+func (b *memBucket) CreateSignedURL(ctx context.Context, in *sci.CreateSignedURLRequest, opts ...grpc.CallOption) (*sci.CreateSignedURLResponse, error) {
+	return &sci.CreateSignedURLResponse{Url: b.url + "/" + in.ObjectName}, nil
+}
+
+func (b *memBucket) ListObjects(ctx context.Context, in *sci.ListObjectsRequest, opts ...grpc.CallOption) (*sci.ListObjectsResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	resp := &sci.ListObjectsResponse{}
+	for name, data := range b.objects {
+		if rel, ok := strings.CutPrefix(name, in.Prefix+"/"); ok {
+			sum := md5.Sum(data)
+			resp.Objects = append(resp.Objects, &sci.ObjectInfo{Name: rel, Size: int64(len(data)), Md5Checksum: hex.EncodeToString(sum[:])})
+		}
+	}
+	sort.Slice(resp.Objects, func(i, j int) bool { return resp.Objects[i].Name < resp.Objects[j].Name })
+	return resp, nil
+}
+
+func TestCopyArtifacts(t *testing.T) {
+	bkt := &memBucket{objects: map[string][]byte{}}
+	srv := httptest.NewServer(bkt)
+	defer srv.Close()
+	bkt.url = srv.URL
+
+	ctx := context.Background()
+	noProgress := func(string, int, int, int64, int64) {}
+	root, err := NewArtifactsLocation("gs://bkt/abc", "")
+	require.NoError(t, err)
+
+	src := t.TempDir()
+	for name, content := range map[string]string{
+		"train.jsonl":      "train",
+		"eval/eval.jsonl":  "eval",
+		"eval/extra/x.txt": "x",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(src, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(src, name), []byte(content), 0644))
+	}
+
+	// A single file is copied into the artifacts directory.
+	require.NoError(t, UploadArtifacts(ctx, bkt, filepath.Join(src, "train.jsonl"), root, noProgress))
+	require.Equal(t, "train", string(bkt.objects["abc/artifacts/train.jsonl"]))
+
+	// Directories are copied recursively.
+	splits, err := NewArtifactsLocation("gs://bkt/abc", "splits")
+	require.NoError(t, err)
+	var progress []string
+	require.NoError(t, UploadArtifacts(ctx, bkt, filepath.Join(src, "eval"), splits, func(name string, file, total int, copied, size int64) {
+		if len(progress) < file {
+			progress = append(progress, name)
+		}
+	}))
+	require.Equal(t, "eval", string(bkt.objects["abc/artifacts/splits/eval.jsonl"]))
+	require.Equal(t, "x", string(bkt.objects["abc/artifacts/splits/extra/x.txt"]))
+	require.Equal(t, []string{"eval.jsonl", "extra/x.txt"}, progress)
+
+	// Download everything.
+	dst := t.TempDir()
+	require.NoError(t, DownloadArtifacts(ctx, bkt, root, dst, noProgress))
+	for name, content := range map[string]string{
+		"train.jsonl":        "train",
+		"splits/eval.jsonl":  "eval",
+		"splits/extra/x.txt": "x",
+	} {
+		data, err := os.ReadFile(filepath.Join(dst, name))
+		require.NoError(t, err)
+		require.Equal(t, content, string(data))
+	}
+
+	// Download a single object into a directory.
+	single, err := NewArtifactsLocation("gs://bkt/abc", "splits/eval.jsonl")
+	require.NoError(t, err)
+	dst = t.TempDir()
+	require.NoError(t, DownloadArtifacts(ctx, bkt, single, dst, noProgress))
+	data, err := os.ReadFile(filepath.Join(dst, "eval.jsonl"))
+	require.NoError(t, err)
+	require.Equal(t, "eval", string(data))
+
+	missing, err := NewArtifactsLocation("gs://bkt/abc", "missing.jsonl")
+	require.NoError(t, err)
+	require.ErrorContains(t, DownloadArtifacts(ctx, bkt, missing, t.TempDir(), noProgress), "not found")
+
+	// Objects are named by the containers, names must not escape dst.
+	parent := t.TempDir()
+	dst = filepath.Join(parent, "dst")
+	for _, name := range []string{"../../.bashrc", "../escaped.txt", "/etc/passwd", "a/../../escaped.txt"} {
+		bkt.objects = map[string][]byte{"abc/artifacts/evil/" + name: []byte("evil")}
+		evil, err := NewArtifactsLocation("gs://bkt/abc", "evil")
+		require.NoError(t, err)
+		require.Error(t, DownloadArtifacts(ctx, bkt, evil, dst, noProgress), name)
+	}
+	require.NoFileExists(t, filepath.Join(parent, "escaped.txt"))
+	require.NoDirExists(t, dst, "nothing should be downloaded")
+}
+
+func TestLocalArtifactPath(t *testing.T) {
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "train.jsonl", expected: "/dst/train.jsonl"},
+		{name: "splits/eval.jsonl", expected: "/dst/splits/eval.jsonl"},
+		{name: "a/../b.txt", expected: "/dst/b.txt"},
+		{name: "../../.bashrc"},
+		{name: "a/../../x"},
+		{name: "/etc/x"},
+		{name: ".."},
+		{name: "."},
+		{name: ""},
+	}
+	for _, c := range cases {
+		local, err := localArtifactPath("/dst", c.name)
+		if c.expected == "" {
+			require.Error(t, err, c.name)
+			continue
+		}
+		require.NoError(t, err, c.name)
+		require.Equal(t, c.expected, local, c.name)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -28,7 +29,10 @@ var Version = "development"
 
 type Object = client.Object
 
-var FieldManager = "kubectl"
+// FieldManager is the manager of the fields that the CLI applies
+// (server-side), distinguishing them from the fields set by the controller
+// and other clients.
+var FieldManager = "substratus-cli"
 
 func init() {
 	apiv1.AddToScheme(scheme.Scheme)
@@ -39,7 +43,7 @@ var _ Interface = &Client{}
 type Interface interface {
 	PortForward(ctx context.Context, logger io.Writer, podRef types.NamespacedName, ports ForwardedPorts, ready chan struct{}) error
 	Resource(obj Object) (*Resource, error)
-	SyncFilesFromNotebook(context.Context, *apiv1.Notebook, string,
+	SyncFilesWithNotebook(context.Context, *apiv1.Notebook, string,
 		io.Writer,
 		func(file string, complete bool, err error),
 	) error
@@ -111,6 +115,9 @@ func newRestClient(restConfig *rest.Config, gv schema.GroupVersion, obj Object)
 	return rest.RESTClientFor(restConfig)
 }
 
+// WaitReady polls the object until it is Ready. It returns an error as soon
+// as the object reports that its current generation failed (e.g. the Job
+// failed or timed out) as it would never become Ready.
 func (r *Resource) WaitReady(ctx context.Context, obj Object, progressF func(Object)) error {
 	if err := wait.PollImmediateInfiniteWithContext(ctx, time.Second,
 		func(ctx context.Context) (bool, error) {
@@ -124,8 +131,14 @@ func (r *Resource) WaitReady(ctx context.Context, obj Object, progressF func(Obj
 			if !ok {
 				return false, fmt.Errorf("object is not readyable: %T", fetched)
 			}
+			if readyable.GetStatusReady() {
+				return true, nil
+			}
+			if c := failedCondition(fetched.(Object)); c != nil {
+				return false, fmt.Errorf("%s: %s", c.Reason, c.Message)
+			}
 
-			return readyable.GetStatusReady(), nil
+			return false, nil
 		},
 	); err != nil {
 		return fmt.Errorf("waiting for object to be ready: %w", err)
@@ -134,11 +147,40 @@ func (r *Resource) WaitReady(ctx context.Context, obj Object, progressF func(Obj
 	return nil
 }
 
+// failedReasons are the reasons of a False Complete or Built condition that
+// the controller does not retry until the spec is changed.
+var failedReasons = sets.New(apiv1.ReasonJobFailed, apiv1.ReasonTimedOut, apiv1.ReasonFailed)
+
+// failedCondition returns the condition that reports that the current
+// generation of the object failed (nil if it did not fail).
+func failedCondition(obj Object) *metav1.Condition {
+	conditioned, ok := obj.(interface{ GetConditions() *[]metav1.Condition })
+	if !ok {
+		return nil
+	}
+	for _, condType := range []string{apiv1.ConditionBuilt, apiv1.ConditionComplete} {
+		c := meta.FindStatusCondition(*conditioned.GetConditions(), condType)
+		if c != nil && c.Status == metav1.ConditionFalse && failedReasons.Has(c.Reason) &&
+			c.ObservedGeneration >= obj.GetGeneration() {
+			return c
+		}
+	}
+	return nil
+}
+
 func (r *Resource) Watch(ctx context.Context, namespace string, obj Object, opts *metav1.ListOptions) (watch.Interface, error) {
 	opts.Watch = true
 	if obj != nil && obj.GetName() != "" {
 		opts.ResourceVersion = obj.GetResourceVersion()
-		opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", obj.GetName()).String()
+		nameSelector := fields.OneTermEqualSelector("metadata.name", obj.GetName())
+		if opts.FieldSelector != "" {
+			selector, err := fields.ParseSelector(opts.FieldSelector)
+			if err != nil {
+				return nil, fmt.Errorf("parsing field selector: %w", err)
+			}
+			nameSelector = fields.AndSelectors(selector, nameSelector)
+		}
+		opts.FieldSelector = nameSelector.String()
 	}
 
 	// NOTE: The r.Helper.Watch() method does not support passing a context, calling the code
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func TestWaitReadyFailed(t *testing.T) {
+	model := &apiv1.Model{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Model"},
+		ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default", Generation: 2},
+	}
+	conditions := []metav1.Condition{{
+		// A failure of the previous generation is not final.
+		Type:               apiv1.ConditionComplete,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonJobFailed,
+		ObservedGeneration: 1,
+	}}
+	var gets int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gets++
+		if gets == 2 {
+			conditions[0].ObservedGeneration = 2
+			conditions[0].Message = "Job failed: BackoffLimitExceeded"
+		}
+		fetched := model.DeepCopy()
+		fetched.Status.Conditions = conditions
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(fetched)
+	}))
+	defer srv.Close()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(apiv1.GroupVersion.WithKind("Model"), meta.RESTScopeNamespace)
+	c := &Client{Config: &rest.Config{Host: srv.URL}, RESTMapper: mapper}
+	res, err := c.Resource(model)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	err = res.WaitReady(ctx, model, func(Object) {})
+	require.ErrorContains(t, err, "JobFailed: Job failed: BackoffLimitExceeded")
+	require.Equal(t, 2, gets)
+}
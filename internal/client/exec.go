@@ -0,0 +1,93 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+// ParseObjectRef parses a "<kind>/<name>" reference (i.e. "model/falcon-7b")
+// to the Job that the controller runs for that object.
+func ParseObjectRef(namespace, ref string) (types.NamespacedName, error) {
+	kind, name, ok := strings.Cut(ref, "/")
+	if !ok || name == "" {
+		return types.NamespacedName{}, fmt.Errorf("invalid reference %q, expected <kind>/<name>", ref)
+	}
+
+	var suffix string
+	switch strings.ToLower(kind) {
+	case "model", "models":
+		suffix = "-modeller"
+	case "dataset", "datasets":
+		suffix = "-data-loader"
+	default:
+		return types.NamespacedName{}, fmt.Errorf("unsupported kind %q, expected model or dataset", kind)
+	}
+
+	return types.NamespacedName{Namespace: namespace, Name: name + suffix}, nil
+}
+
+// jobControllerLabels are added to the Pod template by the Job controller.
+var jobControllerLabels = []string{
+	"controller-uid",
+	"job-name",
+	batchv1.ControllerUidLabel,
+	batchv1.JobNameLabel,
+}
+
+// RunJobFromJob returns a one-off Job that runs the given command using the
+// Pod template of a Job that was created by the controller. The template
+// already references the built image, service account and the mounted
+// volumes (Dataset, Model, params).
+func RunJobFromJob(base *batchv1.Job, command []string, gpus int) (*batchv1.Job, error) {
+	tmpl := base.Spec.Template.DeepCopy()
+	if len(tmpl.Spec.Containers) == 0 {
+		return nil, fmt.Errorf("job %s has no containers", base.Name)
+	}
+
+	labels := map[string]string{}
+	for k, v := range tmpl.Labels {
+		labels[k] = v
+	}
+	// The Job controller sets these to select the Pods of the base Job, they
+	// are set again for the new Job.
+	for _, k := range jobControllerLabels {
+		delete(labels, k)
+	}
+	// Avoid being selected as one of the Pods that the controller manages.
+	labels["role"] = "exec"
+	tmpl.Labels = labels
+
+	tmpl.Spec.RestartPolicy = corev1.RestartPolicyNever
+	// Only relevant for distributed (indexed) Jobs.
+	tmpl.Spec.Subdomain = ""
+
+	container := &tmpl.Spec.Containers[0]
+	container.Command = command
+	container.Args = nil
+	if gpus > 0 {
+		if container.Resources.Limits == nil {
+			container.Resources.Limits = corev1.ResourceList{}
+		}
+		container.Resources.Limits[corev1.ResourceName("nvidia.com/gpu")] = *resource.NewQuantity(int64(gpus), resource.DecimalSI)
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:    base.Namespace,
+			GenerateName: base.Name + "-exec-",
+			Labels:       map[string]string{"role": "exec"},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            ptr.To(int32(0)),
+			TTLSecondsAfterFinished: ptr.To(int32(600)),
+			Template:                *tmpl,
+		},
+	}, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestParseObjectRef(t *testing.T) {
+	cases := []struct {
+		ref      string
+		expected types.NamespacedName
+		err      bool
+	}{
+		{ref: "model/falcon-7b", expected: types.NamespacedName{Namespace: "ns", Name: "falcon-7b-modeller"}},
+		{ref: "models/falcon-7b", expected: types.NamespacedName{Namespace: "ns", Name: "falcon-7b-modeller"}},
+		{ref: "Dataset/squad", expected: types.NamespacedName{Namespace: "ns", Name: "squad-data-loader"}},
+		{ref: "datasets/squad", expected: types.NamespacedName{Namespace: "ns", Name: "squad-data-loader"}},
+		{ref: "falcon-7b", err: true},
+		{ref: "model/", err: true},
+		{ref: "server/falcon-7b", err: true},
+	}
+	for _, c := range cases {
+		nn, err := ParseObjectRef("ns", c.ref)
+		if c.err {
+			require.Error(t, err, c.ref)
+			continue
+		}
+		require.NoError(t, err, c.ref)
+		require.Equal(t, c.expected, nn, c.ref)
+	}
+}
+
+func TestRunJobFromJob(t *testing.T) {
+	base := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "falcon-7b-modeller"},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+					"role":                       "run",
+					"model":                      "falcon-7b",
+					"controller-uid":             "abc",
+					"job-name":                   "falcon-7b-modeller",
+					batchv1.ControllerUidLabel:   "abc",
+					batchv1.JobNameLabel:         "falcon-7b-modeller",
+					"substratus.ai/custom-label": "kept",
+				}},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyOnFailure,
+					Subdomain:     "falcon-7b-modeller",
+					Containers: []corev1.Container{{
+						Name:  "model",
+						Image: "trainer",
+						Args:  []string{"train.py"},
+					}},
+				},
+			},
+		},
+	}
+
+	job, err := RunJobFromJob(base, []string{"python", "eval.py"}, 2)
+	require.NoError(t, err)
+
+	require.Equal(t, "ns", job.Namespace)
+	require.Equal(t, "falcon-7b-modeller-exec-", job.GenerateName)
+	require.Nil(t, job.Spec.Selector, "the selector is generated for the new Job")
+	require.Equal(t, map[string]string{
+		"role":                       "exec",
+		"model":                      "falcon-7b",
+		"substratus.ai/custom-label": "kept",
+	}, job.Spec.Template.Labels)
+
+	spec := job.Spec.Template.Spec
+	require.Equal(t, corev1.RestartPolicyNever, spec.RestartPolicy)
+	require.Empty(t, spec.Subdomain)
+	require.Equal(t, []string{"python", "eval.py"}, spec.Containers[0].Command)
+	require.Nil(t, spec.Containers[0].Args)
+	gpus := spec.Containers[0].Resources.Limits["nvidia.com/gpu"]
+	require.Equal(t, int64(2), gpus.Value())
+
+	require.Equal(t, "run", base.Spec.Template.Labels["role"], "the base Job should not be changed")
+	require.Equal(t, []string{"train.py"}, base.Spec.Template.Spec.Containers[0].Args)
+
+	_, err = RunJobFromJob(&batchv1.Job{}, []string{"true"}, 0)
+	require.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// legacyFieldManagers are the field managers that older releases of the CLI
+// applied (and patched) objects as.
+var legacyFieldManagers = sets.New("kubectl")
+
+// upgradeManagedFields moves the fields owned by the legacy field managers,
+// and the fields that the FieldManager set with an Update (i.e. a merge
+// patch), to the Apply entry of the FieldManager (like
+// k8s.io/client-go/util/csaupgrade does for client-side apply). It returns
+// false if there was nothing to upgrade.
+func (r *Resource) upgradeManagedFields(obj Object) (bool, error) {
+	existing, err := r.Get(obj.GetNamespace(), obj.GetName())
+	if err != nil {
+		return false, fmt.Errorf("getting object: %w", err)
+	}
+	accessor, err := meta.Accessor(existing)
+	if err != nil {
+		return false, err
+	}
+
+	managedFields, upgraded, err := upgradedManagedFields(accessor.GetManagedFields())
+	if err != nil {
+		return false, err
+	}
+	if !upgraded {
+		return false, nil
+	}
+
+	patch, err := json.Marshal([]map[string]interface{}{
+		{"op": "replace", "path": "/metadata/managedFields", "value": managedFields},
+		// Fail with a conflict if the object changed in the meantime.
+		{"op": "replace", "path": "/metadata/resourceVersion", "value": accessor.GetResourceVersion()},
+	})
+	if err != nil {
+		return false, err
+	}
+	if _, err := r.Patch(obj.GetNamespace(), obj.GetName(), types.JSONPatchType, patch, &metav1.PatchOptions{}); err != nil {
+		return false, fmt.Errorf("patching managed fields: %w", err)
+	}
+	return true, nil
+}
+
+// upgradedManagedFields returns the managed fields with the entries to
+// upgrade merged into the Apply entry of the FieldManager.
+func upgradedManagedFields(managedFields []metav1.ManagedFieldsEntry) ([]metav1.ManagedFieldsEntry, bool, error) {
+	isApply := func(e metav1.ManagedFieldsEntry) bool {
+		return e.Manager == FieldManager && e.Operation == metav1.ManagedFieldsOperationApply && e.Subresource == ""
+	}
+	toUpgrade := func(e metav1.ManagedFieldsEntry) bool {
+		if e.Subresource != "" {
+			return false
+		}
+		return legacyFieldManagers.Has(e.Manager) ||
+			(e.Manager == FieldManager && e.Operation == metav1.ManagedFieldsOperationUpdate)
+	}
+
+	var (
+		applyEntry *metav1.ManagedFieldsEntry
+		fields     = &fieldpath.Set{}
+		upgraded   bool
+		kept       []metav1.ManagedFieldsEntry
+	)
+	for _, e := range managedFields {
+		switch {
+		case isApply(e):
+			applyEntry = e.DeepCopy()
+		case toUpgrade(e):
+			upgraded = true
+			if applyEntry == nil && e.Operation == metav1.ManagedFieldsOperationApply {
+				// Keep the most recent apply (the entries are sorted by time).
+				e := e.DeepCopy()
+				e.Manager = FieldManager
+				applyEntry = e
+			}
+			if err := unionFields(fields, e); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if !upgraded {
+		return managedFields, false, nil
+	}
+
+	if applyEntry == nil {
+		// Only updates to upgrade, convert the most recent one.
+		for _, e := range managedFields {
+			if toUpgrade(e) {
+				applyEntry = e.DeepCopy()
+				applyEntry.Manager = FieldManager
+				applyEntry.Operation = metav1.ManagedFieldsOperationApply
+				break
+			}
+		}
+	}
+	if err := unionFields(fields, *applyEntry); err != nil {
+		return nil, false, err
+	}
+	raw, err := fields.ToJSON()
+	if err != nil {
+		return nil, false, fmt.Errorf("encoding managed fields: %w", err)
+	}
+	applyEntry.FieldsType = "FieldsV1"
+	applyEntry.FieldsV1 = &metav1.FieldsV1{Raw: raw}
+
+	var result []metav1.ManagedFieldsEntry
+	for _, e := range kept {
+		if isApply(e) {
+			result = append(result, *applyEntry)
+			applyEntry = nil
+			continue
+		}
+		result = append(result, e)
+	}
+	if applyEntry != nil {
+		result = append([]metav1.ManagedFieldsEntry{*applyEntry}, result...)
+	}
+	return result, true, nil
+}
+
+func unionFields(fields *fieldpath.Set, e metav1.ManagedFieldsEntry) error {
+	if e.FieldsV1 == nil {
+		return nil
+	}
+	s := &fieldpath.Set{}
+	if err := s.FromJSON(bytes.NewReader(e.FieldsV1.Raw)); err != nil {
+		return fmt.Errorf("decoding managed fields of %s: %w", e.Manager, err)
+	}
+	*fields = *fields.Union(s)
+	return nil
+}
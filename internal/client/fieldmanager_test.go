@@ -0,0 +1,85 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradedManagedFields(t *testing.T) {
+	entry := func(manager string, op metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager:    manager,
+			Operation:  op,
+			APIVersion: "substratus.ai/v1",
+			FieldsType: "FieldsV1",
+			FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+	controller := entry("manager", metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:image":{}}}`)
+
+	cases := []struct {
+		name     string
+		in       []metav1.ManagedFieldsEntry
+		expected []metav1.ManagedFieldsEntry
+		upgraded bool
+	}{
+		{
+			name:     "nothing to upgrade",
+			in:       []metav1.ManagedFieldsEntry{entry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:command":{}}}`), controller},
+			expected: []metav1.ManagedFieldsEntry{entry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:command":{}}}`), controller},
+		},
+		{
+			name: "suspended with an update",
+			in: []metav1.ManagedFieldsEntry{
+				entry(FieldManager, metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:suspend":{}}}`),
+				entry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:command":{},"f:suspend":{}}}`),
+				controller,
+			},
+			expected: []metav1.ManagedFieldsEntry{
+				entry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:command":{},"f:suspend":{}}}`),
+				controller,
+			},
+			upgraded: true,
+		},
+		{
+			name: "applied by an older release",
+			in: []metav1.ManagedFieldsEntry{
+				entry("kubectl", metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:suspend":{}}}`),
+				entry("kubectl", metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:command":{}}}`),
+				controller,
+			},
+			expected: []metav1.ManagedFieldsEntry{
+				entry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:command":{},"f:suspend":{}}}`),
+				controller,
+			},
+			upgraded: true,
+		},
+		{
+			name: "only updates",
+			in: []metav1.ManagedFieldsEntry{
+				controller,
+				entry(FieldManager, metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:suspend":{}}}`),
+			},
+			expected: []metav1.ManagedFieldsEntry{
+				entry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:suspend":{}}}`),
+				controller,
+			},
+			upgraded: true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out, upgraded, err := upgradedManagedFields(c.in)
+			require.NoError(t, err)
+			require.Equal(t, c.upgraded, upgraded)
+			require.Equal(t, len(c.expected), len(out))
+			for i := range c.expected {
+				require.Equal(t, c.expected[i].Manager, out[i].Manager)
+				require.Equal(t, c.expected[i].Operation, out[i].Operation)
+				require.JSONEq(t, string(c.expected[i].FieldsV1.Raw), string(out[i].FieldsV1.Raw))
+			}
+		})
+	}
+}
@@ -41,17 +41,23 @@ func NotebookForObject(obj Object) (*apiv1.Notebook, error) {
 		}
 
 	case *apiv1.Server:
+		// Mount the revision that the Server serves when it pins one.
+		revision := obj.Spec.Model.Revision
+		if revision == 0 && obj.Spec.Model.Checksum != "" {
+			revision = obj.Status.ModelRevision
+		}
 		nb = &apiv1.Notebook{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      obj.Name + "-server",
 				Namespace: obj.Namespace,
 			},
 			Spec: apiv1.NotebookSpec{
-				Image:     obj.Spec.Image,
-				Env:       obj.Spec.Env,
-				Params:    obj.Spec.Params,
-				Model:     &obj.Spec.Model,
-				Resources: obj.Spec.Resources,
+				Image:         obj.Spec.Image,
+				Env:           obj.Spec.Env,
+				Params:        obj.Spec.Params,
+				Model:         &obj.Spec.Model.ObjectRef,
+				ModelRevision: revision,
+				Resources:     obj.Spec.Resources,
 			},
 		}
 
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func TestNotebookForServer(t *testing.T) {
+	server := func(ref apiv1.ServerModelRef, served int64) *apiv1.Server {
+		s := &apiv1.Server{
+			ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default"},
+			Spec:       apiv1.ServerSpec{Model: ref},
+		}
+		s.Status.ModelRevision = served
+		return s
+	}
+	model := apiv1.ObjectRef{Name: "falcon-7b"}
+
+	cases := map[string]struct {
+		server   *apiv1.Server
+		revision int64
+	}{
+		"latest":          {server(apiv1.ServerModelRef{ObjectRef: model}, 3), 0},
+		"pinned revision": {server(apiv1.ServerModelRef{ObjectRef: model, Revision: 2}, 2), 2},
+		"pinned checksum": {server(apiv1.ServerModelRef{ObjectRef: model, Checksum: "abc"}, 1), 1},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			nb, err := NotebookForObject(c.server)
+			require.NoError(t, err)
+			require.Equal(t, "falcon-7b-server", nb.Name)
+			require.Equal(t, &model, nb.Spec.Model)
+			require.Equal(t, c.revision, nb.Spec.ModelRevision)
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -14,8 +15,10 @@ import (
 )
 
 type ForwardedPorts struct {
-	Local int
-	Pod   int
+	// Address to listen on locally, defaults to localhost.
+	Address string
+	Local   int
+	Pod     int
 }
 
 func (c *Client) PortForward(ctx context.Context, logger io.Writer, podRef types.NamespacedName, ports ForwardedPorts, ready chan struct{}) error {
@@ -36,9 +39,35 @@ func (c *Client) PortForward(ctx context.Context, logger io.Writer, podRef types
 	} else {
 		stdout, stderr = io.Discard, io.Discard
 	}
-	fw, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", ports.Local, ports.Pod)}, ctx.Done(), ready, stdout, stderr)
+	addresses := []string{"localhost"}
+	if ports.Address != "" {
+		addresses = strings.Split(ports.Address, ",")
+	}
+	fw, err := portforward.NewOnAddresses(dialer, addresses, []string{fmt.Sprintf("%d:%d", ports.Local, ports.Pod)}, ctx.Done(), ready, stdout, stderr)
 	if err != nil {
 		return err
 	}
 	return fw.ForwardPorts()
 }
+
+// ParseForwardedPorts parses a "[localPort:]podPort" argument (as accepted by
+// kubectl port-forward). An empty argument forwards the given default port.
+func ParseForwardedPorts(arg string, defaultPort int) (ForwardedPorts, error) {
+	if arg == "" {
+		return ForwardedPorts{Local: defaultPort, Pod: defaultPort}, nil
+	}
+
+	local, pod, found := strings.Cut(arg, ":")
+	if !found {
+		pod = local
+	}
+	podPort, err := strconv.Atoi(pod)
+	if err != nil || podPort <= 0 || podPort > 65535 {
+		return ForwardedPorts{}, fmt.Errorf("invalid port %q", pod)
+	}
+	localPort, err := strconv.Atoi(local)
+	if err != nil || localPort <= 0 || localPort > 65535 {
+		return ForwardedPorts{}, fmt.Errorf("invalid local port %q", local)
+	}
+	return ForwardedPorts{Local: localPort, Pod: podPort}, nil
+}
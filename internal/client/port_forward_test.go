@@ -0,0 +1,32 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseForwardedPorts(t *testing.T) {
+	cases := []struct {
+		arg      string
+		expected ForwardedPorts
+		err      bool
+	}{
+		{arg: "", expected: ForwardedPorts{Local: 8080, Pod: 8080}},
+		{arg: "9000", expected: ForwardedPorts{Local: 9000, Pod: 9000}},
+		{arg: "8000:9000", expected: ForwardedPorts{Local: 8000, Pod: 9000}},
+		{arg: ":9000", err: true},
+		{arg: "8000:", err: true},
+		{arg: "http", err: true},
+		{arg: "70000", err: true},
+	}
+	for _, c := range cases {
+		ports, err := ParseForwardedPorts(c.arg, 8080)
+		if c.err {
+			require.Error(t, err, c.arg)
+			continue
+		}
+		require.NoError(t, err, c.arg)
+		require.Equal(t, c.expected, ports, c.arg)
+	}
+}
@@ -5,16 +5,23 @@ import (
 	"bufio"
 	"compress/gzip"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"sync"
 
+	"github.com/fsnotify/fsnotify"
+	gitignore "github.com/monochromegane/go-gitignore"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -25,12 +32,22 @@ import (
 	"github.com/substratusai/substratus/internal/client/cp"
 )
 
-func (c *Client) SyncFilesFromNotebook(ctx context.Context, nb *apiv1.Notebook, localDir string,
+// notebookContainerName is the container of the notebook Pod that files are
+// synced with.
+const notebookContainerName = "notebook"
+
+// SyncFilesWithNotebook syncs the files in /content of the notebook and the
+// local directory both ways until the context is done. Changes in the
+// notebook are watched by nbwatch and copied to the local directory, local
+// changes are copied to the notebook (paths excluded by .substratusignore are
+// left out). The contents of synced files are tracked so that a change is not
+// copied back to where it came from.
+func (c *Client) SyncFilesWithNotebook(ctx context.Context, nb *apiv1.Notebook, localDir string,
 	logger io.Writer,
 	progressF func(file string, complete bool, err error),
 ) error {
 	podRef := PodForNotebook(nb)
-	const containerName = "notebook"
+	const containerName = notebookContainerName
 
 	cacheDir, err := os.UserCacheDir()
 	if err != nil {
@@ -62,6 +79,13 @@ func (c *Client) SyncFilesFromNotebook(ctx context.Context, nb *apiv1.Notebook,
 		return fmt.Errorf("cp nbwatch to pod: %w", err)
 	}
 
+	synced := &syncedFiles{}
+	go func() {
+		if err := c.syncLocalFilesToNotebook(ctx, localDir, podRef, synced, progressF); err != nil {
+			log.Printf("Local file sync: %v", err)
+		}
+	}()
+
 	r, w := io.Pipe()
 
 	// TODO: Instead of processing events line-by-line, decode them line-by-line
@@ -98,15 +122,19 @@ func (c *Client) SyncFilesFromNotebook(ctx context.Context, nb *apiv1.Notebook,
 			if event.Op == "WRITE" || event.Op == "CREATE" {
 				// NOTE: A long-running port-forward might be more performant here.
 				progressF(event.Path, false, nil)
-				if err := cp.FromPod(ctx, event.Path, localPath, podRef, containerName); err != nil {
+				if err := synced.copyFromPod(ctx, relPath, event.Path, localPath, podRef); err != nil {
 					log.Printf("Sync: failed to copy: %v", err)
 					progressF(event.Path, false, err)
 					continue
 				}
 				progressF(event.Path, true, nil)
 			} else if event.Op == "REMOVE" || event.Op == "RENAME" {
+				if !synced.update(relPath, removedHash) {
+					// Removed locally.
+					continue
+				}
 				progressF(event.Path, false, nil)
-				if err := os.Remove(localPath); err != nil {
+				if err := os.Remove(localPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
 					log.Printf("Sync: failed to remove: %v", err)
 					progressF(event.Path, false, err)
 					continue
@@ -134,6 +162,228 @@ func (c *Client) SyncFilesFromNotebook(ctx context.Context, nb *apiv1.Notebook,
 	return nil
 }
 
+// syncLocalFilesToNotebook copies changes of the local directory (and all of
+// its directories, like nbwatch) to the notebook until the context is done.
+func (c *Client) syncLocalFilesToNotebook(ctx context.Context, localDir string, podRef types.NamespacedName,
+	synced *syncedFiles,
+	progressF func(file string, complete bool, err error),
+) error {
+	ignore, err := loadIgnoreMatcher(localDir)
+	if err != nil {
+		return err
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating watcher: %w", err)
+	}
+	defer w.Close()
+
+	if _, err := watchDirs(w, localDir, localDir, ignore); err != nil {
+		return err
+	}
+
+	copyToPod := func(name, relPath string) {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			log.Printf("Local file sync: reading: %v", err)
+			return
+		}
+		if !synced.update(relPath, contentHash(data)) {
+			// Copied from the notebook or unchanged.
+			return
+		}
+		podPath := path.Join("/content", filepath.ToSlash(relPath))
+		progressF(podPath, false, nil)
+		if err := cp.ToPod(ctx, name, podPath, podRef, notebookContainerName); err != nil {
+			log.Printf("Local file sync: failed to copy: %v", err)
+			progressF(podPath, false, err)
+			return
+		}
+		progressF(podPath, true, nil)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.Errors:
+			if !ok {
+				return nil
+			}
+			log.Printf("Local file sync: watch error: %v", err)
+		case e, ok := <-w.Events:
+			if !ok {
+				return nil
+			}
+			relPath, ok := localSyncPath(localDir, e.Name, ignore)
+			if !ok {
+				continue
+			}
+			podPath := path.Join("/content", filepath.ToSlash(relPath))
+
+			switch {
+			case e.Has(fsnotify.Write) || e.Has(fsnotify.Create):
+				info, err := os.Stat(e.Name)
+				if err != nil {
+					continue
+				}
+				if info.IsDir() {
+					if !e.Has(fsnotify.Create) {
+						continue
+					}
+					// Files can be created in the new directory (i.e. by
+					// mkdir -p or an unpacked archive) before it is watched.
+					files, err := watchDirs(w, localDir, e.Name, ignore)
+					if err != nil {
+						log.Printf("Local file sync: %v", err)
+					}
+					for _, f := range files {
+						if rel, ok := localSyncPath(localDir, f, ignore); ok {
+							copyToPod(f, rel)
+						}
+					}
+					continue
+				}
+				if !info.Mode().IsRegular() {
+					continue
+				}
+				copyToPod(e.Name, relPath)
+			case e.Has(fsnotify.Remove) || e.Has(fsnotify.Rename):
+				if !synced.update(relPath, removedHash) {
+					// Removed in the notebook.
+					continue
+				}
+				progressF(podPath, false, nil)
+				if err := c.exec(ctx, podRef, "rm -rf -- "+shellQuote(podPath), nil, io.Discard, io.Discard); err != nil {
+					log.Printf("Local file sync: failed to remove: %v", err)
+					progressF(podPath, false, err)
+					continue
+				}
+				progressF(podPath, true, nil)
+			}
+		}
+	}
+}
+
+// watchDirs adds watches for dir and all of its directories that are synced,
+// and returns the regular files that are in them.
+func watchDirs(w *fsnotify.Watcher, localDir, dir string, ignore gitignore.IgnoreMatcher) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if p != localDir {
+			if _, ok := localSyncPath(localDir, p, ignore); !ok {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+		if !d.IsDir() {
+			if d.Type().IsRegular() {
+				files = append(files, p)
+			}
+			return nil
+		}
+		if err := w.Add(p); err != nil {
+			return fmt.Errorf("watching %s: %w", p, err)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// localSyncPath returns the path relative to the local directory of a
+// changed file, skipping hidden files (like nbwatch) and ignored paths.
+func localSyncPath(localDir, name string, ignore gitignore.IgnoreMatcher) (string, bool) {
+	relPath, err := filepath.Rel(localDir, name)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if strings.HasPrefix(filepath.Base(name), ".") {
+		return "", false
+	}
+	info, err := os.Stat(name)
+	if ignore.Match(name, err == nil && info.IsDir()) {
+		return "", false
+	}
+	return relPath, true
+}
+
+// removedHash is the content hash of a file that was removed.
+const removedHash = ""
+
+// syncedFiles tracks the content hashes of files (by relative path) that
+// are in sync between the notebook and the local directory.
+type syncedFiles struct {
+	mtx    sync.Mutex
+	hashes map[string]string
+}
+
+// update records the hash of a file and returns false if it was already
+// recorded (the file is in sync).
+func (s *syncedFiles) update(relPath, hash string) bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.hashes == nil {
+		s.hashes = map[string]string{}
+	}
+	if known, ok := s.hashes[relPath]; ok && known == hash {
+		return false
+	}
+	s.hashes[relPath] = hash
+	return true
+}
+
+// copyFromPod copies a file from the notebook to the local path. The local
+// file is only written when its contents change, so that the local watcher
+// does not copy it back.
+func (s *syncedFiles) copyFromPod(ctx context.Context, relPath, src, dst string, podRef types.NamespacedName) error {
+	tmpDir, err := os.MkdirTemp("", "substratus-sync-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tmpPath := filepath.Join(tmpDir, filepath.Base(dst))
+	if err := cp.FromPod(ctx, src, tmpPath, podRef, notebookContainerName); err != nil {
+		return err
+	}
+	info, err := os.Stat(tmpPath)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		// Only the files in the directory are tracked.
+		return cp.FromPod(ctx, src, dst, podRef, notebookContainerName)
+	}
+
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return err
+	}
+	if !s.update(relPath, contentHash(data)) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, info.Mode().Perm())
+}
+
+func contentHash(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// shellQuote quotes a string for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func (c *Client) exec(ctx context.Context, podRef types.NamespacedName,
 	command string, stdin io.Reader, stdout io.Writer, stderr io.Writer,
 ) error {
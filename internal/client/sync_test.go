@@ -0,0 +1,97 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncedFiles(t *testing.T) {
+	var s syncedFiles
+
+	hash := contentHash([]byte("a"))
+	require.True(t, s.update("a.txt", hash), "new file")
+	require.False(t, s.update("a.txt", hash), "copied back unchanged")
+	require.True(t, s.update("a.txt", contentHash([]byte("b"))), "changed file")
+
+	require.True(t, s.update("a.txt", removedHash), "removed file")
+	require.False(t, s.update("a.txt", removedHash), "removed on the other side")
+	require.True(t, s.update("a.txt", hash), "recreated file")
+
+	require.True(t, s.update("b.txt", removedHash), "removed file that was not synced yet")
+}
+
+func TestLocalSyncPath(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".substratusignore"), []byte("data/\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "data"), 0755))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "__pycache__"), 0755))
+	ignore, err := loadIgnoreMatcher(dir)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "train.py", expected: "train.py"},
+		{name: filepath.Join("src", "model.py"), expected: filepath.Join("src", "model.py")},
+		{name: ".hidden"},
+		{name: filepath.Join("src", ".model.py.swp")},
+		{name: "data"},
+		{name: "__pycache__"},
+		{name: "."},
+	}
+	for _, c := range cases {
+		relPath, ok := localSyncPath(dir, filepath.Join(dir, c.name), ignore)
+		require.Equal(t, c.expected != "", ok, c.name)
+		require.Equal(t, c.expected, relPath, c.name)
+	}
+
+	_, ok := localSyncPath(dir, filepath.Join(filepath.Dir(dir), "other"), ignore)
+	require.False(t, ok, "outside of the directory")
+}
+
+func TestWatchDirs(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"src/model/layers", ".git/objects", "__pycache__"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, d), 0755))
+	}
+	for _, f := range []string{"train.py", "src/model/layers/attention.py", ".git/HEAD", "__pycache__/train.pyc"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, f), nil, 0644))
+	}
+	ignore, err := loadIgnoreMatcher(dir)
+	require.NoError(t, err)
+
+	w, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer w.Close()
+
+	files, err := watchDirs(w, dir, dir, ignore)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{
+		filepath.Join(dir, "train.py"),
+		filepath.Join(dir, "src", "model", "layers", "attention.py"),
+	}, files)
+	require.ElementsMatch(t, []string{
+		dir,
+		filepath.Join(dir, "src"),
+		filepath.Join(dir, "src", "model"),
+		filepath.Join(dir, "src", "model", "layers"),
+	}, w.WatchList())
+
+	// A directory tree that is created later.
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "data", "raw"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data", "raw", "train.csv"), nil, 0644))
+	files, err = watchDirs(w, dir, filepath.Join(dir, "data"), ignore)
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "data", "raw", "train.csv")}, files)
+	require.Contains(t, w.WatchList(), filepath.Join(dir, "data", "raw"))
+}
+
+func TestShellQuote(t *testing.T) {
+	require.Equal(t, `'/content/a b.txt'`, shellQuote("/content/a b.txt"))
+	require.Equal(t, `'/content/it'\''s.txt'`, shellQuote("/content/it's.txt"))
+}
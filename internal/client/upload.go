@@ -18,6 +18,9 @@ import (
 	"strings"
 	"time"
 
+	gitignore "github.com/monochromegane/go-gitignore"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
@@ -29,13 +32,64 @@ import (
 
 var httpClient = &http.Client{}
 
+const (
+	uploadAttempts   = 3
+	uploadRetryDelay = 2 * time.Second
+)
+
+// UploadError is returned when the tarball could not be uploaded to storage.
+// It allows callers to distinguish upload failures from apply failures.
+type UploadError struct {
+	Err error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("uploading tarball: %v", e.Err)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
 type Tarball struct {
 	TempDir     string
 	Path        string
 	MD5Checksum string
+	// Size of the compressed tarball in bytes.
+	Size int64
+}
+
+// TarballOptions configure how the build directory is archived.
+type TarballOptions struct {
+	// CompressionLevel is the gzip compression level, from gzip.HuffmanOnly
+	// to gzip.BestCompression (gzip.DefaultCompression is -1).
+	CompressionLevel int
+	// MaxSize of the compressed tarball in bytes, tarring is aborted once it is
+	// exceeded. Zero means no limit.
+	MaxSize int64
+	// WarnSize is the size above which the user is warned before the
+	// tarball is uploaded. Zero disables the warning.
+	WarnSize int64
 }
 
-func PrepareImageTarball(ctx context.Context, buildPath string, progressF func(file string)) (*Tarball, error) {
+// DefaultTarballOptions are used when no options are configured.
+var DefaultTarballOptions = TarballOptions{
+	CompressionLevel: gzip.DefaultCompression,
+	MaxSize:          1 << 30,
+	WarnSize:         100 << 20,
+}
+
+// TarballTooLargeError is returned when the tarball exceeds the max size.
+type TarballTooLargeError struct {
+	MaxSize int64
+}
+
+func (e *TarballTooLargeError) Error() string {
+	return fmt.Sprintf("tarball exceeds the max size of %s, exclude large files (data, checkpoints, virtualenvs) from the upload by listing them in %s",
+		resource.NewQuantity(e.MaxSize, resource.BinarySI), ignoreFilename)
+}
+
+func PrepareImageTarball(ctx context.Context, buildPath string, opts TarballOptions, progressF func(file string)) (*Tarball, error) {
 	exists, err := fileExists(filepath.Join(buildPath, "Dockerfile"))
 	if err != nil {
 		return nil, fmt.Errorf("checking if Dockerfile exists: %w", err)
@@ -50,11 +104,21 @@ func PrepareImageTarball(ctx context.Context, buildPath string, progressF func(f
 	}
 
 	tarPath := filepath.Join(tmpDir, "/archive.tar.gz")
-	err = tarGz(ctx, buildPath, tarPath, progressF)
+	err = tarGz(ctx, buildPath, tarPath, opts, progressF)
 	if err != nil {
+		os.RemoveAll(tmpDir)
+		var tooLarge *TarballTooLargeError
+		if errors.As(err, &tooLarge) {
+			return nil, tooLarge
+		}
 		return nil, fmt.Errorf("failed to create a tar.gz of the directory: %w", err)
 	}
 
+	info, err := os.Stat(tarPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat the tarball: %w", err)
+	}
+
 	checksum, err := calculateMD5(tarPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate the checksum: %w", err)
@@ -64,6 +128,7 @@ func PrepareImageTarball(ctx context.Context, buildPath string, progressF func(f
 		Path:        tarPath,
 		MD5Checksum: checksum,
 		TempDir:     tmpDir,
+		Size:        info.Size(),
 	}, nil
 }
 
@@ -107,7 +172,27 @@ func ClearImage(obj Object) error {
 	return nil
 }
 
+// Apply applies the object server-side as the FieldManager. Fields that are
+// owned by other managers are taken over when force is set, otherwise a
+// conflict error is returned (see apierrors.IsConflict). Fields owned by
+// older releases of the CLI are taken over on a conflict.
 func (r *Resource) Apply(obj Object, force bool) error {
+	err := r.apply(obj, force)
+	if !apierrors.IsConflict(err) {
+		return err
+	}
+	upgraded, upgradeErr := r.upgradeManagedFields(obj)
+	if upgradeErr != nil {
+		log.Printf("Upgrading managed fields: %v", upgradeErr)
+		return err
+	}
+	if !upgraded {
+		return err
+	}
+	return r.apply(obj, force)
+}
+
+func (r *Resource) apply(obj Object, force bool) error {
 	applyManifest, err := json.Marshal(obj)
 	if err != nil {
 		return err
@@ -123,7 +208,10 @@ func (r *Resource) Apply(obj Object, force bool) error {
 	return nil
 }
 
-func (r *Resource) Upload(ctx context.Context, obj Object, tb *Tarball, progressF func(float64)) error {
+// Upload waits for the controller to provide a signed URL and uploads the
+// tarball to it. The progressF func is called with the number of bytes
+// uploaded so far and the total size of the tarball.
+func (r *Resource) Upload(ctx context.Context, obj Object, tb *Tarball, progressF func(uploaded, total int64)) error {
 	// NOTE: The r.Helper.WatchSingle() method does not support passing a context, calling the code
 	// below instead (it was pulled from the Helper implementation).
 	watcher, err := r.RESTClient.Get().
@@ -177,9 +265,11 @@ loop:
 		}
 	}
 
-	if err := uploadTarball(tb, uploadURL, progressF); err != nil {
-		return fmt.Errorf("uploading tarball: %w", err)
+	log.Printf("uploading tarball to: %s", uploadURL)
+	if err := uploadFileWithRetry(ctx, tb.Path, tb.MD5Checksum, uploadURL, progressF); err != nil {
+		return &UploadError{Err: err}
 	}
+	log.Print("successfully uploaded tarball")
 
 	// Trigger the controller to requeue the object.
 	// Nothing special about this annotation.
@@ -206,14 +296,72 @@ func calculateMD5(path string) (string, error) {
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
-func tarGz(ctx context.Context, src, dst string, progressF func(string)) error {
+// ignoreFilename is the name of the file (in the root of the build directory)
+// that lists paths (using .gitignore syntax) to exclude from the tarball.
+const ignoreFilename = ".substratusignore"
+
+// defaultIgnorePatterns are always excluded from the tarball. They can be
+// re-included using a negated pattern (e.g. "!.git/") in the ignore file.
+var defaultIgnorePatterns = []string{
+	".git/",
+	".hg/",
+	".svn/",
+	"__pycache__/",
+	"*.pyc",
+	".ipynb_checkpoints/",
+	"node_modules/",
+	".DS_Store",
+}
+
+func loadIgnoreMatcher(src string) (gitignore.IgnoreMatcher, error) {
+	patterns := strings.Join(defaultIgnorePatterns, "\n") + "\n"
+
+	content, err := os.ReadFile(filepath.Join(src, ignoreFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading %s: %w", ignoreFilename, err)
+	}
+	patterns += string(content)
+
+	return gitignore.NewGitIgnoreFromReader(src, strings.NewReader(patterns)), nil
+}
+
+// limitWriter fails writes that would exceed max bytes (when max is set).
+type limitWriter struct {
+	w       io.Writer
+	written int64
+	max     int64
+}
+
+func (l *limitWriter) Write(p []byte) (int, error) {
+	if l.max > 0 && l.written+int64(len(p)) > l.max {
+		return 0, &TarballTooLargeError{MaxSize: l.max}
+	}
+	n, err := l.w.Write(p)
+	l.written += int64(n)
+	return n, err
+}
+
+func tarGz(ctx context.Context, src, dst string, opts TarballOptions, progressF func(string)) error {
+	src, err := filepath.Abs(src)
+	if err != nil {
+		return fmt.Errorf("resolving absolute path: %w", err)
+	}
+
+	ignore, err := loadIgnoreMatcher(src)
+	if err != nil {
+		return err
+	}
+
 	tarFile, err := os.Create(dst)
 	if err != nil {
 		return fmt.Errorf("failed to create tarFile: %w", err)
 	}
 	defer tarFile.Close()
 
-	gzWriter := gzip.NewWriter(tarFile)
+	gzWriter, err := gzip.NewWriterLevel(&limitWriter{w: tarFile, max: opts.MaxSize}, opts.CompressionLevel)
+	if err != nil {
+		return err
+	}
 	defer gzWriter.Close()
 
 	tarWriter := tar.NewWriter(gzWriter)
@@ -234,6 +382,14 @@ func tarGz(ctx context.Context, src, dst string, progressF func(string)) error {
 			return nil
 		}
 
+		if ignore.Match(path, info.IsDir()) {
+			log.Printf("Ignoring: %v", path)
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
 		header, err := tar.FileInfoHeader(info, info.Name())
 		if err != nil {
 			return fmt.Errorf("failed to read file headers: %w", err)
@@ -277,6 +433,14 @@ func tarGz(ctx context.Context, src, dst string, progressF func(string)) error {
 		return err
 	}
 
+	// Flush explicitly, the last writes can exceed the max size.
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close the tarball: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("failed to close the tarball: %w", err)
+	}
+
 	return nil
 }
 
@@ -295,26 +459,63 @@ type progressReader struct {
 	totalRead int64
 	total     int64
 	r         io.Reader
-	f         func(float64)
+	f         func(uploaded, total int64)
 }
 
 func (r *progressReader) Read(p []byte) (int, error) {
 	n, err := r.r.Read(p)
 	r.totalRead += int64(n)
-	r.f(float64(r.totalRead) / float64(r.total))
+	r.f(r.totalRead, r.total)
 	return n, err
 }
 
-func uploadTarball(tarball *Tarball, url string, progressF func(float64)) error {
-	data, err := hex.DecodeString(tarball.MD5Checksum)
+// retryableError marks an upload failure that is worth re-attempting
+// (dropped connections, server-side errors).
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string { return e.err.Error() }
+func (e retryableError) Unwrap() error { return e.err }
+
+// uploadFileWithRetry re-attempts the PUT to the signed URL when the upload
+// fails with a retryable error. Each attempt re-sends the full file.
+func uploadFileWithRetry(ctx context.Context, path, md5Checksum, url string, progressF func(uploaded, total int64)) error {
+	var err error
+	for attempt := 1; attempt <= uploadAttempts; attempt++ {
+		err = uploadFile(ctx, path, md5Checksum, url, progressF)
+		if err == nil {
+			return nil
+		}
+		var rErr retryableError
+		if !errors.As(err, &rErr) {
+			return err
+		}
+		log.Printf("upload attempt %v/%v failed: %v", attempt, uploadAttempts, err)
+
+		if attempt < uploadAttempts {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * uploadRetryDelay):
+			}
+		}
+	}
+	return fmt.Errorf("giving up after %v attempts: %w", uploadAttempts, err)
+}
+
+// uploadFile PUTs the file to a signed URL. The md5Checksum (hex) of the
+// file is sent in the Content-MD5 header as required by the signature.
+func uploadFile(ctx context.Context, path, md5Checksum, url string, progressF func(uploaded, total int64)) error {
+	data, err := hex.DecodeString(md5Checksum)
 	if err != nil {
 		return fmt.Errorf("failed to decode hex checksum: %w", err)
 	}
 	encodedMd5Checksum := base64.StdEncoding.EncodeToString(data)
 
-	file, err := os.Open(tarball.Path)
+	file, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("tar upload: %w", err)
+		return fmt.Errorf("upload: %w", err)
 	}
 	defer file.Close()
 
@@ -323,29 +524,36 @@ func uploadTarball(tarball *Tarball, url string, progressF func(float64)) error
 		return fmt.Errorf("stat: %w", err)
 	}
 
-	log.Printf("uploading tarball to: %s", url)
-	req, err := http.NewRequest(http.MethodPut, url, &progressReader{
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, &progressReader{
 		total: stat.Size(),
 		r:     file,
 		f:     progressF,
 	})
 	if err != nil {
-		return fmt.Errorf("tar upload: %w", err)
+		return fmt.Errorf("upload: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/octet-stream")
 	req.Header.Set("Content-MD5", encodedMd5Checksum)
 
+	req.ContentLength = stat.Size()
+
 	// Send the request
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("tar upload: %w", err)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return retryableError{fmt.Errorf("upload: %w", err)}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+		err := fmt.Errorf("unexpected response status: %d", resp.StatusCode)
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			return retryableError{err}
+		}
+		return err
 	}
-	log.Print("successfully uploaded tarball")
 	return nil
 }
@@ -0,0 +1,213 @@
+package client
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func TestTarGzIgnore(t *testing.T) {
+	src := t.TempDir()
+	for path, content := range map[string]string{
+		"Dockerfile":                 "FROM scratch",
+		".substratusignore":          "data/\n*.log\n!keep.log\n",
+		"main.py":                    "print('hi')",
+		"train.log":                  "ignored",
+		"keep.log":                   "kept",
+		"data/big.bin":               "ignored",
+		".git/HEAD":                  "ignored",
+		"pkg/__pycache__/main.pyc":   "ignored",
+		"pkg/module.py":              "kept",
+		"node_modules/left-pad/x.js": "ignored",
+	} {
+		full := filepath.Join(src, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+
+	dst := filepath.Join(t.TempDir(), "archive.tar.gz")
+	require.NoError(t, tarGz(context.Background(), src, dst, DefaultTarballOptions, func(string) {}))
+
+	require.Equal(t, []string{
+		".substratusignore",
+		"Dockerfile",
+		"keep.log",
+		"main.py",
+		"pkg",
+		"pkg/module.py",
+	}, tarEntries(t, dst))
+}
+
+func TestPrepareImageTarballMaxSize(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "Dockerfile"), []byte("FROM scratch"), 0644))
+	random := make([]byte, 1<<20)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(src, "weights.bin"), random, 0644))
+
+	opts := DefaultTarballOptions
+	tb, err := PrepareImageTarball(context.Background(), src, opts, func(string) {})
+	require.NoError(t, err)
+	defer os.RemoveAll(tb.TempDir)
+	require.Greater(t, tb.Size, int64(len(random)))
+	info, err := os.Stat(tb.Path)
+	require.NoError(t, err)
+	require.Equal(t, info.Size(), tb.Size)
+
+	opts.MaxSize = 512 << 10
+	_, err = PrepareImageTarball(context.Background(), src, opts, func(string) {})
+	var tooLarge *TarballTooLargeError
+	require.ErrorAs(t, err, &tooLarge)
+	require.ErrorContains(t, err, "512Ki")
+	require.ErrorContains(t, err, ignoreFilename)
+
+	// Excluding the large file brings the tarball under the limit.
+	require.NoError(t, os.WriteFile(filepath.Join(src, ignoreFilename), []byte("*.bin\n"), 0644))
+	tb, err = PrepareImageTarball(context.Background(), src, opts, func(string) {})
+	require.NoError(t, err)
+	defer os.RemoveAll(tb.TempDir)
+	require.Equal(t, []string{ignoreFilename, "Dockerfile"}, tarEntries(t, tb.Path))
+}
+
+func TestTarGzCompressionLevel(t *testing.T) {
+	src := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(src, "data.txt"), bytes.Repeat([]byte("substratus "), 1<<16), 0644))
+
+	sizes := map[int]int64{}
+	for _, level := range []int{gzip.NoCompression, gzip.BestCompression} {
+		dst := filepath.Join(t.TempDir(), "archive.tar.gz")
+		require.NoError(t, tarGz(context.Background(), src, dst, TarballOptions{CompressionLevel: level}, func(string) {}))
+		require.Equal(t, []string{"data.txt"}, tarEntries(t, dst))
+		info, err := os.Stat(dst)
+		require.NoError(t, err)
+		sizes[level] = info.Size()
+	}
+	require.Less(t, sizes[gzip.BestCompression], sizes[gzip.NoCompression])
+
+	err := tarGz(context.Background(), src, filepath.Join(t.TempDir(), "archive.tar.gz"), TarballOptions{CompressionLevel: 42}, func(string) {})
+	require.Error(t, err)
+}
+
+func TestApply(t *testing.T) {
+	var requests []*http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(apiv1.GroupVersion.WithKind("Model"), meta.RESTScopeNamespace)
+	c := &Client{Config: &rest.Config{Host: srv.URL}, RESTMapper: mapper}
+
+	model := &apiv1.Model{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Model"},
+		ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default"},
+	}
+	res, err := c.Resource(model)
+	require.NoError(t, err)
+
+	require.NoError(t, res.Apply(model, false))
+	require.NoError(t, res.Apply(model, true))
+	require.Len(t, requests, 2)
+	for i, force := range []string{"false", "true"} {
+		r := requests[i]
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, "/apis/substratus.ai/v1/namespaces/default/models/falcon-7b", r.URL.Path)
+		require.Equal(t, string(types.ApplyPatchType), r.Header.Get("Content-Type"))
+		require.Equal(t, "substratus-cli", r.URL.Query().Get("fieldManager"))
+		require.Equal(t, force, r.URL.Query().Get("force"))
+	}
+}
+
+func tarEntries(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestApplyUpgradesLegacyManagedFields(t *testing.T) {
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.Header.Get("Content-Type"))
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case r.Method == http.MethodPatch && r.Header.Get("Content-Type") == string(types.ApplyPatchType) && len(requests) == 1:
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(apierrors.NewApplyConflict(nil, "conflict with \"kubectl\"").Status())
+		case r.Method == http.MethodGet:
+			json.NewEncoder(w).Encode(&apiv1.Model{
+				TypeMeta: metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Model"},
+				ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default", ResourceVersion: "7", ManagedFields: []metav1.ManagedFieldsEntry{
+					{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply, APIVersion: "substratus.ai/v1", FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:spec":{"f:image":{}}}`)}},
+				}},
+			})
+		case r.Header.Get("Content-Type") == string(types.JSONPatchType):
+			require.Contains(t, string(body), `"manager":"substratus-cli"`)
+			require.NotContains(t, string(body), `"manager":"kubectl"`)
+			require.Contains(t, string(body), `"value":"7"`)
+			w.Write([]byte(`{"apiVersion":"substratus.ai/v1","kind":"Model"}`))
+		default:
+			w.Write(body)
+		}
+	}))
+	defer srv.Close()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(apiv1.GroupVersion.WithKind("Model"), meta.RESTScopeNamespace)
+	c := &Client{Config: &rest.Config{Host: srv.URL}, RESTMapper: mapper}
+
+	model := &apiv1.Model{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Model"},
+		ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default"},
+	}
+	res, err := c.Resource(model)
+	require.NoError(t, err)
+
+	require.NoError(t, res.Apply(model, false))
+	require.Equal(t, []string{
+		"PATCH " + string(types.ApplyPatchType),
+		"GET ",
+		"PATCH " + string(types.JSONPatchType),
+		"PATCH " + string(types.ApplyPatchType),
+	}, requests)
+}
@@ -2,8 +2,11 @@ package cloud
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"reflect"
+	"strings"
 
 	"cloud.google.com/go/compute/metadata"
 	"github.com/go-playground/validator/v10"
@@ -17,6 +20,10 @@ import (
 
 const CloudEnvVar = "CLOUD"
 
+// validate caches the parsed struct tags of the clouds (it is safe for
+// concurrent use).
+var validate = validator.New()
+
 type Cloud interface {
 	// Name of cloud.
 	Name() string
@@ -40,6 +47,21 @@ type Cloud interface {
 	// was already bound successfully to the service account.
 	GetPrincipal(*corev1.ServiceAccount) (string, bool)
 
+	// ArtifactBuckets returns the buckets that artifacts are stored in.
+	ArtifactBuckets() []BucketURL
+
+	// ExpectedBucketLocation returns the location that artifact buckets
+	// should be in, empty if there is no expectation.
+	ExpectedBucketLocation() string
+
+	// ExpectedBucketKMSKey returns the KMS key that artifact buckets should
+	// be encrypted with by default, empty for cloud managed keys.
+	ExpectedBucketKMSKey() string
+
+	// MountDriver returns the name of the CSIDriver that the volumes of
+	// MountBucket rely on, empty if no driver is needed.
+	MountDriver() string
+
 	// MountBucket mutates the given Pod metadata and Pod spec in order to append
 	// volumes mounts for a bucket.
 	MountBucket(*metav1.ObjectMeta, *corev1.PodSpec, ArtifactObject, MountBucketConfig) error
@@ -77,13 +99,62 @@ func New(ctx context.Context) (Cloud, error) {
 		return nil, fmt.Errorf("autoconfigure: %w", err)
 	}
 
-	if err := validator.New().Struct(c); err != nil {
+	if err := Validate(c); err != nil {
 		return nil, fmt.Errorf("validation: %w", err)
 	}
 
 	return c, nil
 }
 
+// Validate checks that all required configuration of the given cloud is set.
+// Missing fields are reported by the environment variable that sets them.
+func Validate(c Cloud) error {
+	v := reflect.ValueOf(c)
+	if c == nil || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return errors.New("no cloud configured")
+	}
+
+	err := validate.Struct(c)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+	var missing []string
+	for _, fe := range verrs {
+		// The namespace starts with the struct name (i.e. "GCP.Common.ClusterName").
+		path := strings.Split(fe.StructNamespace(), ".")[1:]
+		if name := envName(v.Type(), path); name != "" {
+			missing = append(missing, name)
+		} else {
+			missing = append(missing, fe.StructNamespace())
+		}
+	}
+	return fmt.Errorf("%s: missing required configuration: %s", c.Name(), strings.Join(missing, ", "))
+}
+
+// envName returns the environment variable of the struct field at the given
+// path (i.e. ["Common", "ClusterName"] -> "CLUSTER_NAME").
+func envName(t reflect.Type, path []string) string {
+	for i, name := range path {
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return ""
+		}
+		f, ok := t.FieldByName(name)
+		if !ok {
+			return ""
+		}
+		if i == len(path)-1 {
+			env, _, _ := strings.Cut(f.Tag.Get("env"), ",")
+			return env
+		}
+		t = f.Type
+	}
+	return ""
+}
+
 type BucketMount struct {
 	BucketSubdir  string
 	ContentSubdir string
@@ -94,6 +165,10 @@ type MountBucketConfig struct {
 	Name      string        // Example: model, model-saved, data
 	Mounts    []BucketMount // Example: model, data, logs
 	ReadOnly  bool
+
+	// MountOptions are merged into the default mount options
+	// (only used by clouds that mount buckets via gcsfuse).
+	MountOptions map[string]string
 }
 
 type Object = client.Object
@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
 )
 
 type Common struct {
@@ -13,6 +16,55 @@ type Common struct {
 	ArtifactBucketURL *BucketURL `env:"ARTIFACT_BUCKET_URL,noinit" validate:"required"`
 	RegistryURL       string     `env:"REGISTRY_URL" validate:"required"`
 	Principal         string     `env:"PRINCIPAL" validate:"required"`
+
+	// DatasetBucketURL and ModelBucketURL optionally override
+	// ArtifactBucketURL for the artifacts of Datasets and Models.
+	DatasetBucketURL *BucketURL `env:"DATASET_BUCKET_URL,noinit"`
+	ModelBucketURL   *BucketURL `env:"MODEL_BUCKET_URL,noinit"`
+
+	// NamespacedArtifactPaths prefixes artifact paths with the namespace
+	// of the object (<bucket>/<namespace>/<hash>) to allow for IAM scoping
+	// per namespace. Objects that are already Ready keep using the URL
+	// recorded in their status, so this can be enabled on existing clusters.
+	NamespacedArtifactPaths bool `env:"NAMESPACED_ARTIFACT_PATHS"`
+
+	// BucketLocation is the region (or multi-region) that artifact buckets
+	// are expected to be located in. Buckets elsewhere work, but are slower
+	// to access and incur egress costs.
+	BucketLocation string `env:"BUCKET_LOCATION"`
+
+	// BucketKMSKey is the customer-managed key (GCP CMEK resource name, AWS
+	// KMS key ID or ARN) that missing artifact buckets are created with for
+	// default encryption. Existing buckets are not changed.
+	BucketKMSKey string `env:"BUCKET_KMS_KEY"`
+}
+
+// ArtifactBuckets returns the distinct buckets that artifacts are stored in.
+func (c *Common) ArtifactBuckets() []BucketURL {
+	var buckets []BucketURL
+	seen := map[string]bool{}
+	for _, u := range []*BucketURL{c.ArtifactBucketURL, c.DatasetBucketURL, c.ModelBucketURL} {
+		if u == nil {
+			continue
+		}
+		key := u.Scheme + "://" + u.Bucket
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		buckets = append(buckets, BucketURL{Scheme: u.Scheme, Bucket: u.Bucket})
+	}
+	return buckets
+}
+
+// ExpectedBucketLocation returns the configured BucketLocation.
+func (c *Common) ExpectedBucketLocation() string {
+	return c.BucketLocation
+}
+
+// ExpectedBucketKMSKey returns the configured BucketKMSKey.
+func (c *Common) ExpectedBucketKMSKey() string {
+	return c.BucketKMSKey
 }
 
 func (c *Common) ObjectBuiltImageURL(obj BuildableObject) string {
@@ -35,6 +87,10 @@ func (c *Common) ObjectBuiltImageURL(obj BuildableObject) string {
 	} else if upload := build.Upload; upload != nil {
 		tag = upload.MD5Checksum
 	}
+	if build.Args != nil || build.Target != "" || build.BuilderImage != "" {
+		// Changing the build options should result in a new image.
+		tag += "-" + buildOptionsHash(build)
+	}
 
 	return fmt.Sprintf("%s/%s-%s-%s-%s:%s", c.RegistryURL,
 		c.ClusterName, strings.ToLower(kind), obj.GetNamespace(), obj.GetName(),
@@ -42,12 +98,45 @@ func (c *Common) ObjectBuiltImageURL(obj BuildableObject) string {
 	)
 }
 
+// buildOptionsHash returns a short hash of the options that change the
+// result of a build other than the source.
+func buildOptionsHash(build *apiv1.Build) string {
+	h := md5.New()
+	keys := make([]string, 0, len(build.Args))
+	for k := range build.Args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "arg:%s=%s\n", k, build.Args[k])
+	}
+	fmt.Fprintf(h, "target:%s\nbuilder:%s\n", build.Target, build.BuilderImage)
+	return fmt.Sprintf("%x", h.Sum(nil))[:8]
+}
+
 func (c *Common) ObjectArtifactURL(obj Object) *BucketURL {
-	u := *c.ArtifactBucketURL
+	u := *c.artifactBucketURL(obj)
+	if c.NamespacedArtifactPaths {
+		u.Path = filepath.Join(u.Path, obj.GetNamespace())
+	}
 	u.Path = filepath.Join(u.Path, objectHash(c.ClusterName, obj))
 	return &u
 }
 
+func (c *Common) artifactBucketURL(obj Object) *BucketURL {
+	switch obj.GetObjectKind().GroupVersionKind().Kind {
+	case "Dataset":
+		if c.DatasetBucketURL != nil {
+			return c.DatasetBucketURL
+		}
+	case "Model":
+		if c.ModelBucketURL != nil {
+			return c.ModelBucketURL
+		}
+	}
+	return c.ArtifactBucketURL
+}
+
 func objectHash(cluster string, obj Object) string {
 	h := md5.New()
 	io.WriteString(h, objectHashInput(cluster, obj))
@@ -71,5 +71,31 @@ func TestCommon(t *testing.T) {
 			},
 		},
 	}))
+	withOptions := func(args map[string]string, target string) *apiv1.Model {
+		return &apiv1.Model{
+			TypeMeta:   metav1.TypeMeta{Kind: "Model"},
+			ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "my-ns"},
+			Spec: apiv1.ModelSpec{
+				Build: &apiv1.Build{
+					Git:    &apiv1.BuildGit{Tag: "v1.2.3"},
+					Args:   args,
+					Target: target,
+				},
+			},
+		}
+	}
+	optionsURL := common.ObjectBuiltImageURL(withOptions(map[string]string{"A": "1", "B": "2"}, "runtime"))
+	require.Regexp(t, `^gcr.io/my-project/my-cluster-model-my-ns-my-model:v1\.2\.3-[0-9a-f]{8}$`, optionsURL)
+	require.Equal(t, optionsURL, common.ObjectBuiltImageURL(withOptions(map[string]string{"B": "2", "A": "1"}, "runtime")))
+	require.NotEqual(t, optionsURL, common.ObjectBuiltImageURL(withOptions(map[string]string{"A": "1", "B": "3"}, "runtime")))
+	require.NotEqual(t, optionsURL, common.ObjectBuiltImageURL(withOptions(map[string]string{"A": "1", "B": "2"}, "")))
+
 	require.Equal(t, "gs://my-artifact-bucket/93ea94b18012ca14d84e1468d65e8709", common.ObjectArtifactURL(&apiv1.Model{TypeMeta: metav1.TypeMeta{Kind: "Model"}, ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "my-ns"}}).String())
+
+	common.ModelBucketURL = &cloud.BucketURL{Scheme: "gs", Bucket: "my-model-bucket"}
+	require.Equal(t, "gs://my-model-bucket/93ea94b18012ca14d84e1468d65e8709", common.ObjectArtifactURL(&apiv1.Model{TypeMeta: metav1.TypeMeta{Kind: "Model"}, ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "my-ns"}}).String())
+	common.NamespacedArtifactPaths = true
+	require.Equal(t, "gs://my-model-bucket/my-ns/93ea94b18012ca14d84e1468d65e8709", common.ObjectArtifactURL(&apiv1.Model{TypeMeta: metav1.TypeMeta{Kind: "Model"}, ObjectMeta: metav1.ObjectMeta{Name: "my-model", Namespace: "my-ns"}}).String())
+
+	require.Equal(t, "my-artifact-bucket", common.ObjectArtifactURL(&apiv1.Dataset{TypeMeta: metav1.TypeMeta{Kind: "Dataset"}, ObjectMeta: metav1.ObjectMeta{Name: "my-dataset", Namespace: "my-ns"}}).Bucket)
 }
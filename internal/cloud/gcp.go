@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sort"
 	"strings"
 
 	"cloud.google.com/go/compute/metadata"
@@ -15,12 +16,15 @@ import (
 const (
 	GCPName                  = "gcp"
 	GCPWorkloadIdentityLabel = "iam.gke.io/gcp-service-account"
+	// GCSFuseCSIDriverName is the driver of the Cloud Storage FUSE CSI
+	// driver add-on of GKE.
+	GCSFuseCSIDriverName = "gcsfuse.csi.storage.gke.io"
 )
 
 type GCP struct {
 	Common
-	ProjectID       string `env:"PROJECT_ID" required:"true"`
-	ClusterLocation string `env:"CLUSTER_LOCATION" required:"true"`
+	ProjectID       string `env:"PROJECT_ID" validate:"required"`
+	ClusterLocation string `env:"CLUSTER_LOCATION" validate:"required"`
 }
 
 func (gcp *GCP) Name() string { return GCPName }
@@ -63,6 +67,10 @@ func (gcp *GCP) AutoConfigure(ctx context.Context) error {
 		}
 	}
 
+	if gcp.BucketLocation == "" {
+		gcp.BucketLocation = gcp.region()
+	}
+
 	if gcp.Principal == "" {
 		gcp.Principal = fmt.Sprintf("substratus@%s.iam.gserviceaccount.com", gcp.ProjectID)
 	}
@@ -70,6 +78,8 @@ func (gcp *GCP) AutoConfigure(ctx context.Context) error {
 	return nil
 }
 
+func (gcp *GCP) MountDriver() string { return GCSFuseCSIDriverName }
+
 func (gcp *GCP) MountBucket(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, obj ArtifactObject, req MountBucketConfig) error {
 	if podMetadata.Annotations == nil {
 		podMetadata.Annotations = map[string]string{}
@@ -94,11 +104,11 @@ func (gcp *GCP) MountBucket(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodS
 		Name: req.Name,
 		VolumeSource: corev1.VolumeSource{
 			CSI: &corev1.CSIVolumeSource{
-				Driver:   "gcsfuse.csi.storage.gke.io",
+				Driver:   GCSFuseCSIDriverName,
 				ReadOnly: ptr.To(req.ReadOnly),
 				VolumeAttributes: map[string]string{
 					"bucketName":   bktURL.Bucket,
-					"mountOptions": "implicit-dirs,uid=0,gid=3003",
+					"mountOptions": gcsfuseMountOptions(req.MountOptions),
 				},
 			},
 		},
@@ -123,6 +133,37 @@ func (gcp *GCP) MountBucket(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodS
 	return fmt.Errorf("container not found: %s", req.Container)
 }
 
+// gcsfuseMountOptions merges user supplied options into the default
+// gcsfuse mount options. Keys with empty values are rendered as flags.
+func gcsfuseMountOptions(overrides map[string]string) string {
+	keys := []string{"implicit-dirs", "uid", "gid"}
+	opts := map[string]string{
+		"implicit-dirs": "",
+		"uid":           "0",
+		"gid":           "3003",
+	}
+
+	var extra []string
+	for k, v := range overrides {
+		if _, ok := opts[k]; !ok {
+			extra = append(extra, k)
+		}
+		opts[k] = v
+	}
+	sort.Strings(extra)
+	keys = append(keys, extra...)
+
+	list := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if opts[k] == "" {
+			list = append(list, k)
+		} else {
+			list = append(list, k+"="+opts[k])
+		}
+	}
+	return strings.Join(list, ",")
+}
+
 func (gcp *GCP) GetPrincipal(sa *corev1.ServiceAccount) (string, bool) {
 	principalBound := true
 	if val, exist := sa.Annotations[GCPWorkloadIdentityLabel]; !exist || val != gcp.Principal {
@@ -0,0 +1,18 @@
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_gcsfuseMountOptions(t *testing.T) {
+	require.Equal(t, "implicit-dirs,uid=0,gid=3003", gcsfuseMountOptions(nil))
+	require.Equal(t, "implicit-dirs,uid=1000,gid=3003,max-conns-per-host=100,stat-cache-ttl=1h",
+		gcsfuseMountOptions(map[string]string{
+			"uid":                "1000",
+			"stat-cache-ttl":     "1h",
+			"max-conns-per-host": "100",
+		}),
+	)
+}
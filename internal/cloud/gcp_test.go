@@ -8,6 +8,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/sethvargo/go-envconfig"
 	"github.com/stretchr/testify/require"
+	apiv1 "github.com/substratusai/substratus/api/v1"
 	"github.com/substratusai/substratus/internal/cloud"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -46,3 +47,76 @@ func TestGCP(t *testing.T) {
 	require.Equal(t, actualPrincipal, expectedPrincipal)
 	require.Equal(t, bound, true)
 }
+
+func TestValidate(t *testing.T) {
+	gcp := &cloud.GCP{Common: cloud.Common{
+		ClusterName: "my-cluster",
+		RegistryURL: "gcr.io/my-project",
+		Principal:   "substratus@my-project.iam.gserviceaccount.com",
+	}}
+	gcp.ClusterLocation = "us-central1"
+
+	err := cloud.Validate(gcp)
+	require.EqualError(t, err, "gcp: missing required configuration: ARTIFACT_BUCKET_URL, PROJECT_ID")
+
+	gcp.ProjectID = "my-project"
+	gcp.ArtifactBucketURL = &cloud.BucketURL{Scheme: "gs", Bucket: "my-artifact-bucket"}
+	require.NoError(t, cloud.Validate(gcp))
+
+	kind := &cloud.Kind{Common: gcp.Common}
+	require.EqualError(t, cloud.Validate(kind), "kind: missing required configuration: REGISTRY_PORT_5000_TCP_ADDR")
+	kind.RegistryDiscoveryIP = "10.96.0.10"
+	require.NoError(t, cloud.Validate(kind))
+
+	require.EqualError(t, cloud.Validate((*cloud.GCP)(nil)), "no cloud configured")
+	require.EqualError(t, cloud.Validate(nil), "no cloud configured")
+}
+
+func TestGCPMountBucket(t *testing.T) {
+	gcp := cloud.GCP{Common: cloud.Common{
+		ClusterName:       "my-cluster",
+		ArtifactBucketURL: &cloud.BucketURL{Scheme: "gs", Bucket: "my-artifact-bucket"},
+	}}
+
+	dataset := &apiv1.Dataset{
+		TypeMeta:   metav1.TypeMeta{Kind: "Dataset"},
+		ObjectMeta: metav1.ObjectMeta{Name: "my-dataset", Namespace: "my-ns"},
+		Status: apiv1.DatasetStatus{
+			Artifacts: apiv1.ArtifactsStatus{URL: "gs://my-artifact-bucket/abc123"},
+		},
+	}
+
+	var podMeta metav1.ObjectMeta
+	podSpec := corev1.PodSpec{
+		Volumes:    []corev1.Volume{{Name: "existing"}},
+		Containers: []corev1.Container{{Name: "sidecar"}, {Name: "trainer"}},
+	}
+
+	require.NoError(t, gcp.MountBucket(&podMeta, &podSpec, dataset, cloud.MountBucketConfig{
+		Name: "dataset",
+		Mounts: []cloud.BucketMount{
+			{BucketSubdir: "artifacts", ContentSubdir: "data"},
+		},
+		Container: "trainer",
+		ReadOnly:  true,
+	}))
+
+	require.Equal(t, "true", podMeta.Annotations["gke-gcsfuse/volumes"])
+	require.Len(t, podSpec.Volumes, 2)
+	require.Equal(t, "existing", podSpec.Volumes[0].Name)
+	require.Equal(t, "dataset", podSpec.Volumes[1].Name)
+	require.Equal(t, "my-artifact-bucket", podSpec.Volumes[1].CSI.VolumeAttributes["bucketName"])
+	require.Equal(t, gcp.MountDriver(), podSpec.Volumes[1].CSI.Driver, "the mount driver is checked for by the controllers")
+	require.Empty(t, podSpec.Containers[0].VolumeMounts)
+	require.Equal(t, []corev1.VolumeMount{{
+		Name:      "dataset",
+		MountPath: "/content/data",
+		SubPath:   "abc123/artifacts",
+		ReadOnly:  true,
+	}}, podSpec.Containers[1].VolumeMounts)
+
+	require.Error(t, gcp.MountBucket(&podMeta, &podSpec, dataset, cloud.MountBucketConfig{
+		Name:      "missing",
+		Container: "does-not-exist",
+	}))
+}
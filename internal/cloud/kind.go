@@ -13,7 +13,7 @@ const KindName = "kind"
 type Kind struct {
 	// RegistryDiscoveryIP environment variable comes from the registry Service in the same namespace.
 	// See: https://kubernetes.io/docs/concepts/services-networking/service/#environment-variables
-	RegistryDiscoveryIP string `env:"REGISTRY_PORT_5000_TCP_ADDR" required:"true"`
+	RegistryDiscoveryIP string `env:"REGISTRY_PORT_5000_TCP_ADDR" validate:"required"`
 
 	Common
 }
@@ -47,6 +47,9 @@ func (k *Kind) AutoConfigure(ctx context.Context) error {
 	return nil
 }
 
+// MountDriver returns no driver, buckets are mounted as hostPath volumes.
+func (k *Kind) MountDriver() string { return "" }
+
 func (k *Kind) MountBucket(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, obj ArtifactObject, req MountBucketConfig) error {
 	var bktURL *BucketURL
 	if statusURL := obj.GetStatusArtifacts().URL; statusURL != "" {
@@ -32,17 +32,34 @@ func (b BucketURL) String() string {
 	return fmt.Sprintf("%s://%s/%s", b.Scheme, b.Bucket, b.Path)
 }
 
+// ParseBucketURL parses a bucket URL such as "gs://bucket/some/prefix" or
+// "s3://bucket/some/prefix". The "gcs" scheme is accepted as an alias for "gs".
 func ParseBucketURL(bktURL string) (*BucketURL, error) {
 	u, err := url.Parse(bktURL)
 	if err != nil {
 		return nil, fmt.Errorf("parsing url: %w", err)
 	}
 
-	// NOTE: For local Kind clusters where URL is "tar:///bucket", u.Host will be empty.
+	scheme := u.Scheme
+	switch scheme {
+	case "gs", "gcs":
+		scheme = "gs"
+		fallthrough
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("missing bucket name in url: %q", bktURL)
+		}
+	case "tar":
+		// NOTE: For local Kind clusters where URL is "tar:///bucket", u.Host will be empty.
+	case "":
+		return nil, fmt.Errorf("missing scheme in url: %q", bktURL)
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q in url: %q", u.Scheme, bktURL)
+	}
 
 	return &BucketURL{
-		Scheme: u.Scheme,
+		Scheme: scheme,
 		Bucket: u.Host,
-		Path:   strings.TrimPrefix(u.Path, "/"),
+		Path:   strings.TrimSuffix(strings.TrimPrefix(u.Path, "/"), "/"),
 	}, nil
 }
@@ -0,0 +1,41 @@
+package cloud_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+func TestParseBucketURL(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected *cloud.BucketURL
+		err      bool
+	}{
+		{input: "gs://my-bucket", expected: &cloud.BucketURL{Scheme: "gs", Bucket: "my-bucket"}},
+		{input: "gs://my-bucket/", expected: &cloud.BucketURL{Scheme: "gs", Bucket: "my-bucket"}},
+		{input: "gs://my-bucket/abc", expected: &cloud.BucketURL{Scheme: "gs", Bucket: "my-bucket", Path: "abc"}},
+		{input: "gs://my-bucket/abc/def/", expected: &cloud.BucketURL{Scheme: "gs", Bucket: "my-bucket", Path: "abc/def"}},
+		{input: "gcs://my-bucket/abc", expected: &cloud.BucketURL{Scheme: "gs", Bucket: "my-bucket", Path: "abc"}},
+		{input: "s3://my-bucket/abc/def", expected: &cloud.BucketURL{Scheme: "s3", Bucket: "my-bucket", Path: "abc/def"}},
+		{input: "tar:///bucket/abc", expected: &cloud.BucketURL{Scheme: "tar", Path: "bucket/abc"}},
+		{input: "gs:///abc", err: true},
+		{input: "s3://", err: true},
+		{input: "my-bucket/abc", err: true},
+		{input: "https://example.com/abc", err: true},
+		{input: "gs://my bucket\x7f", err: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.input, func(t *testing.T) {
+			actual, err := cloud.ParseBucketURL(c.input)
+			if c.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+// BucketChecker verifies that the artifact buckets exist and are located
+// where the cloud configuration expects them to be. It is meant to be added
+// to the manager as a Runnable and only reports problems, it never blocks
+// the controllers from starting.
+type BucketChecker struct {
+	Cloud cloud.Cloud
+	SCI   sci.ControllerClient
+
+	// CreateMissing creates buckets that do not exist in the expected
+	// location.
+	CreateMissing bool
+
+	// Interval is how often the buckets are checked again, they are only
+	// checked on start when 0.
+	Interval time.Duration
+
+	mu sync.Mutex
+	// keyErrs are the errors of the buckets (by name) that could not be
+	// created because the configured KMS key is not accessible.
+	keyErrs map[string]error
+	// locationErrs are the errors of the buckets (by name) that are not
+	// located in the expected location.
+	locationErrs map[string]error
+	// locations are the locations of the checked buckets (by name).
+	locations map[string]string
+}
+
+// KMSKeyError returns the error of the bucket if it could not be created
+// because the configured KMS key is not accessible, nil otherwise.
+func (c *BucketChecker) KMSKeyError(bucket string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.keyErrs[bucket]
+}
+
+func (c *BucketChecker) setKMSKeyError(bucket string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.keyErrs, bucket)
+		return
+	}
+	if c.keyErrs == nil {
+		c.keyErrs = map[string]error{}
+	}
+	c.keyErrs[bucket] = err
+}
+
+// LocationError returns the error of the bucket if it is not located in the
+// expected location, nil otherwise.
+func (c *BucketChecker) LocationError(bucket string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.locationErrs[bucket]
+}
+
+func (c *BucketChecker) setLocationError(bucket string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		delete(c.locationErrs, bucket)
+		return
+	}
+	if c.locationErrs == nil {
+		c.locationErrs = map[string]error{}
+	}
+	c.locationErrs[bucket] = err
+}
+
+// Location returns the location of the bucket if it was checked.
+func (c *BucketChecker) Location(bucket string) (string, bool) {
+	if c == nil {
+		return "", false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	location, ok := c.locations[bucket]
+	return location, ok
+}
+
+func (c *BucketChecker) setLocation(bucket, location string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locations == nil {
+		c.locations = map[string]string{}
+	}
+	c.locations[bucket] = location
+}
+
+func (c *BucketChecker) Start(ctx context.Context) error {
+	c.checkAll(ctx)
+	if c.Interval == 0 {
+		return nil
+	}
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.checkAll(ctx)
+		}
+	}
+}
+
+func (c *BucketChecker) checkAll(ctx context.Context) {
+	log := log.FromContext(ctx).WithName("bucket-checker")
+
+	for _, bkt := range c.Cloud.ArtifactBuckets() {
+		if bkt.Scheme == "tar" {
+			// Local (kind) host path.
+			continue
+		}
+		if err := c.check(ctx, bkt); err != nil {
+			log.Error(err, "Artifact bucket check failed", "bucket", bkt.Bucket)
+		}
+	}
+}
+
+func (c *BucketChecker) check(ctx context.Context, bkt cloud.BucketURL) error {
+	log := log.FromContext(ctx).WithName("bucket-checker")
+
+	resp, err := c.SCI.GetBucketLocation(ctx, &sci.GetBucketLocationRequest{BucketName: bkt.Bucket})
+	if err != nil {
+		return fmt.Errorf("getting bucket location: %w", err)
+	}
+	expected := c.Cloud.ExpectedBucketLocation()
+	expectedKey := c.Cloud.ExpectedBucketKMSKey()
+	if !resp.Exists {
+		if !c.CreateMissing {
+			return fmt.Errorf("bucket %q does not exist", bkt.Bucket)
+		}
+		if _, err := c.SCI.CreateBucket(ctx, &sci.CreateBucketRequest{
+			BucketName: bkt.Bucket,
+			Location:   expected,
+			Labels:     map[string]string{"managed-by": "substratus"},
+			KmsKeyName: expectedKey,
+		}); err != nil {
+			if status.Code(err) == codes.FailedPrecondition && expectedKey != "" {
+				c.setKMSKeyError(bkt.Bucket, fmt.Errorf("bucket %q: %s", bkt.Bucket, status.Convert(err).Message()))
+			}
+			return fmt.Errorf("creating bucket: %w", err)
+		}
+		c.setKMSKeyError(bkt.Bucket, nil)
+		c.setLocation(bkt.Bucket, expected)
+		log.Info("Created missing artifact bucket", "bucket", bkt.Bucket, "location", expected)
+		return nil
+	}
+	c.setKMSKeyError(bkt.Bucket, nil)
+	c.setLocation(bkt.Bucket, resp.Location)
+
+	if expected != "" && resp.Location != "" && !bucketLocationMatches(expected, resp.Location) {
+		log.Info("WARNING: Artifact bucket is not located in the expected location, access will be slower and might incur egress costs",
+			"bucket", bkt.Bucket, "location", resp.Location, "expectedLocation", expected)
+		c.setLocationError(bkt.Bucket, fmt.Errorf("bucket %q is located in %q instead of %q", bkt.Bucket, resp.Location, expected))
+	} else {
+		c.setLocationError(bkt.Bucket, nil)
+	}
+
+	if expectedKey != "" {
+		if resp.KmsKeyUnknown {
+			log.Info("WARNING: Unable to verify the default encryption of the artifact bucket, the SCI is not allowed to get it",
+				"bucket", bkt.Bucket, "expectedKmsKey", expectedKey)
+		} else if resp.KmsKeyName != expectedKey {
+			// Existing buckets are not changed, new objects are encrypted
+			// with the default key of the bucket.
+			log.Info("WARNING: Artifact bucket is not encrypted with the configured KMS key by default",
+				"bucket", bkt.Bucket, "kmsKey", resp.KmsKeyName, "expectedKmsKey", expectedKey)
+		}
+	}
+
+	return nil
+}
+
+// reconcileKMSKey guards against starting Jobs that write artifacts to a
+// bucket that could not be created with the configured KMS key, the writes
+// would fail. The given condition type is set to KMSKeyInaccessible until
+// the bucket is checked again successfully.
+func reconcileKMSKey(ctx context.Context, c client.Client, buckets *BucketChecker, bucket string, obj statusObject, conditionType string) (result, error) {
+	keyErr := buckets.KMSKeyError(bucket)
+	if keyErr == nil {
+		return result{success: true}, nil
+	}
+
+	obj.SetStatusReady(false)
+	meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonKMSKeyInaccessible,
+		ObservedGeneration: obj.GetGeneration(),
+		Message:            fmt.Sprintf("The artifact bucket could not be created with the configured KMS key: %v", keyErr),
+	})
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return result{}, fmt.Errorf("updating status: %w", err)
+	}
+	if buckets.Interval == 0 {
+		// No use in retrying until the controller is restarted with access
+		// to the key...
+		return result{}, nil
+	}
+	return result{Result: ctrl.Result{RequeueAfter: requeueAfter(buckets.Interval)}}, nil
+}
+
+// setBucketLocationCondition sets the BucketLocationMismatch condition while
+// the artifact bucket of an object is not located in the expected location
+// and removes it otherwise.
+func setBucketLocationCondition(conditions *[]metav1.Condition, generation int64, buckets *BucketChecker, bucket string) {
+	locationErr := buckets.LocationError(bucket)
+	if locationErr == nil {
+		meta.RemoveStatusCondition(conditions, apiv1.ConditionBucketLocationMismatch)
+		return
+	}
+
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               apiv1.ConditionBucketLocationMismatch,
+		Status:             metav1.ConditionTrue,
+		Reason:             apiv1.ReasonBucketNotColocated,
+		ObservedGeneration: generation,
+		Message:            fmt.Sprintf("Artifact access will be slower and might incur egress costs: %v", locationErr),
+	})
+}
+
+// bucketLocation returns the location of the bucket as reported by the SCI
+// (empty if unknown). Buckets that were checked by the BucketChecker are
+// not looked up again.
+func bucketLocation(ctx context.Context, sciClient sci.ControllerClient, buckets *BucketChecker, bkt *cloud.BucketURL) (string, error) {
+	if bkt.Scheme == "tar" {
+		// Local (kind) host path.
+		return "", nil
+	}
+	if location, ok := buckets.Location(bkt.Bucket); ok {
+		return location, nil
+	}
+	resp, err := sciClient.GetBucketLocation(ctx, &sci.GetBucketLocationRequest{BucketName: bkt.Bucket})
+	if err != nil {
+		return "", fmt.Errorf("calling the sci service to GetBucketLocation: %w", err)
+	}
+	return resp.Location, nil
+}
+
+// bucketLocationMatches reports whether a bucket in the given location is
+// co-located with the expected region. Multi-regions (i.e. "us") match all
+// regions that they contain (i.e. "us-central1").
+func bucketLocationMatches(expected, location string) bool {
+	expected, location = strings.ToLower(expected), strings.ToLower(location)
+	return expected == location || strings.HasPrefix(expected, location+"-")
+}
@@ -0,0 +1,187 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+func TestBucketLocationMatches(t *testing.T) {
+	require.True(t, bucketLocationMatches("us-central1", "us-central1"))
+	require.True(t, bucketLocationMatches("us-central1", "US-CENTRAL1"))
+	require.True(t, bucketLocationMatches("us-central1", "us"))
+	require.False(t, bucketLocationMatches("us-central1", "us-east1"))
+	require.False(t, bucketLocationMatches("europe-west4", "us"))
+}
+
+type missingBucketSCI struct {
+	sci.ControllerClient
+	createErr error
+	created   []*sci.CreateBucketRequest
+}
+
+func (c *missingBucketSCI) GetBucketLocation(ctx context.Context, in *sci.GetBucketLocationRequest, opts ...grpc.CallOption) (*sci.GetBucketLocationResponse, error) {
+	return &sci.GetBucketLocationResponse{Exists: false}, nil
+}
+
+func (c *missingBucketSCI) CreateBucket(ctx context.Context, in *sci.CreateBucketRequest, opts ...grpc.CallOption) (*sci.CreateBucketResponse, error) {
+	c.created = append(c.created, in)
+	if c.createErr != nil {
+		return nil, c.createErr
+	}
+	return &sci.CreateBucketResponse{Created: true}, nil
+}
+
+func TestBucketCheckerKMSKey(t *testing.T) {
+	const key = "projects/p/locations/us-central1/keyRings/r/cryptoKeys/k"
+	cld := &cloud.GCP{Common: cloud.Common{
+		ArtifactBucketURL: &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts"},
+		BucketKMSKey:      key,
+	}}
+
+	var nilChecker *BucketChecker
+	require.NoError(t, nilChecker.KMSKeyError("artifacts"))
+
+	client := &missingBucketSCI{}
+	checker := &BucketChecker{Cloud: cld, SCI: client, CreateMissing: true}
+	require.NoError(t, checker.Start(context.Background()))
+	require.Len(t, client.created, 1)
+	require.Equal(t, key, client.created[0].KmsKeyName)
+	require.NoError(t, checker.KMSKeyError("artifacts"))
+
+	client = &missingBucketSCI{createErr: status.Error(codes.FailedPrecondition, "kms key is not accessible")}
+	checker = &BucketChecker{Cloud: cld, SCI: client, CreateMissing: true}
+	require.NoError(t, checker.Start(context.Background()))
+	require.ErrorContains(t, checker.KMSKeyError("artifacts"), `bucket "artifacts": kms key is not accessible`)
+	require.NoError(t, checker.KMSKeyError("other"), "errors are tracked per bucket")
+
+	// Cleared once the bucket could be created.
+	client.createErr = nil
+	checker.checkAll(context.Background())
+	require.NoError(t, checker.KMSKeyError("artifacts"))
+
+	client = &missingBucketSCI{createErr: status.Error(codes.Unavailable, "connection refused")}
+	checker = &BucketChecker{Cloud: cld, SCI: client, CreateMissing: true}
+	require.NoError(t, checker.Start(context.Background()))
+	require.NoError(t, checker.KMSKeyError("artifacts"), "only key errors are reported")
+
+	// Existing buckets without the key (i.e. created before the key was
+	// configured), or with an encryption that the SCI is not allowed to get,
+	// are only warned about.
+	for _, existing := range []*existingBucketSCI{
+		{kmsKey: ""},
+		{kmsKey: "projects/p/locations/us-central1/keyRings/r/cryptoKeys/other"},
+		{kmsKeyUnknown: true},
+		{kmsKey: key},
+	} {
+		checker = &BucketChecker{Cloud: cld, SCI: existing}
+		require.NoError(t, checker.Start(context.Background()))
+		require.NoError(t, checker.KMSKeyError("artifacts"), existing.kmsKey)
+	}
+}
+
+func TestReconcileKMSKey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	model := &apiv1.Model{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "falcon-7b"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(model).WithStatusSubresource(model).Build()
+
+	checker := &BucketChecker{Interval: time.Minute}
+	checker.setKMSKeyError("artifacts", errors.New("kms key is not accessible"))
+
+	res, err := reconcileKMSKey(context.Background(), c, checker, "other", model, apiv1.ConditionComplete)
+	require.NoError(t, err)
+	require.True(t, res.success, "only objects in the bucket are blocked")
+
+	res, err = reconcileKMSKey(context.Background(), c, checker, "artifacts", model, apiv1.ConditionComplete)
+	require.NoError(t, err)
+	require.False(t, res.success)
+	require.Greater(t, res.RequeueAfter, time.Duration(0), "blocked objects are requeued to be checked again")
+	cond := meta.FindStatusCondition(model.Status.Conditions, apiv1.ConditionComplete)
+	require.NotNil(t, cond)
+	require.Equal(t, apiv1.ReasonKMSKeyInaccessible, cond.Reason)
+}
+
+func TestBucketCheckerLocation(t *testing.T) {
+	cld := &cloud.GCP{Common: cloud.Common{
+		ArtifactBucketURL: &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts"},
+		BucketLocation:    "us-central1",
+	}}
+
+	var conds []metav1.Condition
+	checker := &BucketChecker{Cloud: cld, SCI: &existingBucketSCI{location: "US"}}
+	require.NoError(t, checker.Start(context.Background()))
+	require.NoError(t, checker.LocationError("artifacts"))
+	setBucketLocationCondition(&conds, 1, checker, "artifacts")
+	require.Empty(t, conds)
+
+	sciClient := &existingBucketSCI{location: "EUROPE-WEST4"}
+	checker = &BucketChecker{Cloud: cld, SCI: sciClient}
+	require.NoError(t, checker.Start(context.Background()))
+	require.ErrorContains(t, checker.LocationError("artifacts"), `bucket "artifacts" is located in "EUROPE-WEST4"`)
+	setBucketLocationCondition(&conds, 1, checker, "artifacts")
+	c := meta.FindStatusCondition(conds, apiv1.ConditionBucketLocationMismatch)
+	require.NotNil(t, c)
+	require.Equal(t, metav1.ConditionTrue, c.Status)
+	require.Equal(t, apiv1.ReasonBucketNotColocated, c.Reason)
+
+	// Objects in other buckets are not affected.
+	var otherConds []metav1.Condition
+	setBucketLocationCondition(&otherConds, 1, checker, "other")
+	require.Empty(t, otherConds)
+
+	// Removed once the bucket is checked again in a matching location.
+	sciClient.location = "us-central1"
+	checker.checkAll(context.Background())
+	setBucketLocationCondition(&conds, 1, checker, "artifacts")
+	require.Empty(t, conds)
+}
+
+type existingBucketSCI struct {
+	sci.ControllerClient
+	kmsKey        string
+	kmsKeyUnknown bool
+	location      string
+}
+
+func (c *existingBucketSCI) GetBucketLocation(ctx context.Context, in *sci.GetBucketLocationRequest, opts ...grpc.CallOption) (*sci.GetBucketLocationResponse, error) {
+	return &sci.GetBucketLocationResponse{Exists: true, KmsKeyName: c.kmsKey, KmsKeyUnknown: c.kmsKeyUnknown, Location: c.location}, nil
+}
+
+func TestBucketLocation(t *testing.T) {
+	ctx := context.Background()
+	cld := &cloud.GCP{Common: cloud.Common{
+		ArtifactBucketURL: &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts"},
+		BucketLocation:    "us-central1",
+	}}
+	bkt := &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts"}
+
+	// The actual location is recorded, not the configured one.
+	location, err := bucketLocation(ctx, &existingBucketSCI{location: "eu"}, nil, bkt)
+	require.NoError(t, err)
+	require.Equal(t, "eu", location)
+
+	checker := &BucketChecker{Cloud: cld, SCI: &existingBucketSCI{location: "us"}}
+	require.NoError(t, checker.Start(ctx))
+	location, err = bucketLocation(ctx, nil, checker, bkt)
+	require.NoError(t, err)
+	require.Equal(t, "us", location, "checked buckets are not looked up again")
+
+	location, err = bucketLocation(ctx, nil, nil, &cloud.BucketURL{Scheme: "tar", Path: "/bucket"})
+	require.NoError(t, err)
+	require.Empty(t, location)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -26,7 +28,10 @@ import (
 	"github.com/substratusai/substratus/internal/sci"
 )
 
-const latestUploadPath = "uploads/latest.tar.gz"
+const (
+	latestUploadPath     = "uploads/latest.tar.gz"
+	builderContainerName = "builder"
+)
 
 type BuildableObject interface {
 	client.Object
@@ -39,6 +44,8 @@ type BuildableObject interface {
 	SetStatusReady(bool)
 	GetStatusUpload() apiv1.UploadStatus
 	SetStatusUpload(apiv1.UploadStatus)
+	GetStatusContainer() apiv1.ContainerStatus
+	SetStatusContainer(apiv1.ContainerStatus)
 }
 
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
@@ -54,9 +61,14 @@ type BuildReconciler struct {
 
 	Cloud cloud.Cloud
 	SCI   sci.ControllerClient
+
+	MaxConcurrentReconciles int
 }
 
 func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Build "+r.Kind, r.Kind, req)
+	defer span.End()
+
 	log := log.FromContext(ctx)
 
 	obj := r.NewObject()
@@ -94,17 +106,15 @@ func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		var err error
 		buildJob, err = r.storageBuildJob(ctx, obj)
 		if err != nil {
-			log.Error(err, "unable to construct storage image-builder Job")
-			// No use in retrying...
-			return ctrl.Result{}, nil
+			result, err := reconcileFailed(ctx, r.Client, obj, apiv1.ConditionBuilt, fmt.Errorf("constructing storage image-builder Job: %w", err))
+			return result.Result, err
 		}
 	} else if obj.GetBuild().Git != nil {
 		var err error
 		buildJob, err = r.gitBuildJob(ctx, obj)
 		if err != nil {
-			log.Error(err, "unable to construct git image-builder Job")
-			// No use in retrying...
-			return ctrl.Result{}, nil
+			result, err := reconcileFailed(ctx, r.Client, obj, apiv1.ConditionBuilt, fmt.Errorf("constructing git image-builder Job: %w", err))
+			return result.Result, err
 		}
 	}
 
@@ -117,8 +127,12 @@ func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 	if err := r.Client.Get(ctx, client.ObjectKeyFromObject(buildJob), buildJob); err != nil {
 		if apierrors.IsNotFound(err) {
 			// No Job exists, create one.
-			if err := r.Client.Create(ctx, buildJob); client.IgnoreAlreadyExists(err) != nil {
-				return ctrl.Result{}, fmt.Errorf("creating builder Job: %w", err)
+			if err := r.Client.Create(ctx, buildJob); err != nil {
+				if !apierrors.IsAlreadyExists(err) {
+					return ctrl.Result{}, fmt.Errorf("creating builder Job: %w", err)
+				}
+			} else {
+				jobsCreatedTotal.WithLabelValues(r.Kind, "build").Inc()
 			}
 		} else {
 			return ctrl.Result{}, fmt.Errorf("getting builder Job: %w", err)
@@ -133,11 +147,46 @@ func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		if err := r.Client.Create(ctx, buildJob); err != nil {
 			return ctrl.Result{}, fmt.Errorf("creating builder Job: %w", err)
 		}
+		jobsCreatedTotal.WithLabelValues(r.Kind, "build").Inc()
+	}
+
+	container := obj.GetStatusContainer()
+	container.BuildJob = buildJob.Name
+	container.Message = ""
+
+	if _, failed := jobResult(buildJob); failed {
+		log.Info("The builder Job failed")
+		observeJobFailed(*obj.GetConditions(), apiv1.ConditionBuilt, r.Kind, "build")
+
+		pods, err := jobPods(ctx, r.Client, buildJob)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		container.Reason = apiv1.ReasonJobFailed
+		container.Message, _ = podCrashMessage(pods)
+		obj.SetStatusContainer(container)
+
+		obj.SetStatusReady(false)
+		meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
+			Type:               apiv1.ConditionBuilt,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonJobFailed,
+			ObservedGeneration: obj.GetGeneration(),
+			Message:            fmt.Sprintf("Builder Job failed: %v", buildJob.Name),
+		})
+		if err := r.Client.Status().Update(ctx, obj); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating status: %w", err)
+		}
+
+		return ctrl.Result{}, nil
 	}
 
 	if buildJob.Status.Succeeded < 1 {
 		log.Info("The builder Job has not succeeded yet")
 
+		container.Reason = apiv1.ReasonJobNotComplete
+		obj.SetStatusContainer(container)
+
 		obj.SetStatusReady(false)
 		meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
 			Type:               apiv1.ConditionBuilt,
@@ -154,11 +203,24 @@ func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, nil
 	}
 
-	obj.SetImage(r.Cloud.ObjectBuiltImageURL(obj))
-	if err := r.Client.Update(ctx, obj); err != nil {
-		return ctrl.Result{}, fmt.Errorf("updating container image: %w", err)
+	pods, err := jobPods(ctx, r.Client, buildJob)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
+	image := r.Cloud.ObjectBuiltImageURL(obj)
+	if obj.GetAnnotations()[apiv1.BuildOnlyAnnotation] != "true" {
+		obj.SetImage(image)
+		if err := r.Client.Update(ctx, obj); err != nil {
+			return ctrl.Result{}, fmt.Errorf("updating container image: %w", err)
+		}
+	}
+
+	container.Image = image
+	container.Digest = builtImageDigest(pods)
+	container.Reason = apiv1.ReasonJobComplete
+	obj.SetStatusContainer(container)
+
 	meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
 		Type:               apiv1.ConditionBuilt,
 		Status:             metav1.ConditionTrue,
@@ -175,8 +237,12 @@ func (r *BuildReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 
 func (r *BuildReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
+		// Name the controller explicitly to avoid colliding with the
+		// main controller for the same kind (e.g. in metrics).
+		Named(strings.ToLower(r.Kind) + "-builder").
 		For(r.NewObject()).
 		Owns(&batchv1.Job{}).
+		WithOptions(ctrlcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -285,16 +351,32 @@ func (r *BuildReconciler) gitBuildJob(ctx context.Context, obj BuildableObject)
 		"--compressed-caching=false",
 		"--log-format=color",
 		"--log-timestamp=false",
+		// Report the digest of the pushed image back to the controller.
+		"--digest-file=/dev/termination-log",
 	}
+	buildArgs = append(buildArgs, buildOptionArgs(obj.GetBuild())...)
 
 	var initContainers []corev1.Container
 	var volumeMounts []corev1.VolumeMount
 	var volumes []corev1.Volume
 
-	cloneArgs := []string{
+	var cloneArgs []string
+	var cloneEnv []corev1.EnvVar
+	if git.SecretRef != nil {
+		// Credentials are read from the environment by an inline credential
+		// helper to avoid embedding them in the clone URL or args.
+		cloneArgs = append(cloneArgs,
+			"-c", "credential.helper=!f() { echo username=$GIT_USERNAME; echo password=$GIT_PASSWORD; }; f",
+		)
+		cloneEnv = []corev1.EnvVar{
+			gitSecretEnvVar("GIT_USERNAME", git.SecretRef.Name, "username"),
+			gitSecretEnvVar("GIT_PASSWORD", git.SecretRef.Name, "password"),
+		}
+	}
+	cloneArgs = append(cloneArgs,
 		"clone",
 		git.URL,
-	}
+	)
 	if git.Tag != "" {
 		// NOTE: --branch flag is used for tags too.
 		cloneArgs = append(cloneArgs, "--branch", git.Tag)
@@ -312,6 +394,7 @@ func (r *BuildReconciler) gitBuildJob(ctx context.Context, obj BuildableObject)
 			Name:  "git-clone",
 			Image: "alpine/git",
 			Args:  cloneArgs,
+			Env:   cloneEnv,
 			VolumeMounts: []corev1.VolumeMount{
 				{
 					Name:      "workspace",
@@ -351,7 +434,6 @@ func (r *BuildReconciler) gitBuildJob(ctx context.Context, obj BuildableObject)
 		},
 	}
 
-	const builderContainerName = "builder"
 	annotations["kubectl.kubernetes.io/default-container"] = builderContainerName
 	job = &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -383,10 +465,12 @@ func (r *BuildReconciler) gitBuildJob(ctx context.Context, obj BuildableObject)
 					ServiceAccountName: containerBuilderServiceAccountName,
 					Containers: []corev1.Container{{
 						Name:         builderContainerName,
-						Image:        "gcr.io/kaniko-project/executor:latest",
+						Image:        builderImage(obj.GetBuild()),
 						Args:         buildArgs,
 						VolumeMounts: volumeMounts,
 						Resources:    resources.ContainerBuilderResources(r.Cloud.Name()),
+						// Surface the tail of the build log when the build fails.
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 					}},
 					RestartPolicy: "Never",
 					Volumes:       volumes,
@@ -402,6 +486,18 @@ func (r *BuildReconciler) gitBuildJob(ctx context.Context, obj BuildableObject)
 	return job, nil
 }
 
+func gitSecretEnvVar(name, secretName, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secretName},
+				Key:                  key,
+			},
+		},
+	}
+}
+
 func (r *BuildReconciler) storageBuildJob(ctx context.Context, obj BuildableObject) (*batchv1.Job, error) {
 	var job *batchv1.Job
 
@@ -418,7 +514,10 @@ func (r *BuildReconciler) storageBuildJob(ctx context.Context, obj BuildableObje
 		"--compressed-caching=false",
 		"--log-format=color",
 		"--log-timestamp=false",
+		// Report the digest of the pushed image back to the controller.
+		"--digest-file=/dev/termination-log",
 	}
+	buildArgs = append(buildArgs, buildOptionArgs(obj.GetBuild())...)
 
 	var initContainers []corev1.Container
 	var volumeMounts []corev1.VolumeMount
@@ -482,7 +581,6 @@ func (r *BuildReconciler) storageBuildJob(ctx context.Context, obj BuildableObje
 			})
 	}
 
-	const builderContainerName = "builder"
 	podAnnotations["kubectl.kubernetes.io/default-container"] = builderContainerName
 	job = &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -513,10 +611,12 @@ func (r *BuildReconciler) storageBuildJob(ctx context.Context, obj BuildableObje
 					ServiceAccountName: containerBuilderServiceAccountName,
 					Containers: []corev1.Container{{
 						Name:         builderContainerName,
-						Image:        "gcr.io/kaniko-project/executor:latest",
+						Image:        builderImage(obj.GetBuild()),
 						Args:         buildArgs,
 						VolumeMounts: volumeMounts,
 						Resources:    resources.ContainerBuilderResources(r.Cloud.Name()),
+						// Surface the tail of the build log when the build fails.
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 					}},
 					RestartPolicy: "Never",
 					Volumes:       volumes,
@@ -573,6 +673,47 @@ func (r *BuildReconciler) generateSignedURL(obj BuildableObject) (string, time.T
 	return resp.Url, expirationTime, nil
 }
 
+const defaultBuilderImage = "gcr.io/kaniko-project/executor:latest"
+
+func builderImage(build *apiv1.Build) string {
+	if build.BuilderImage != "" {
+		return build.BuilderImage
+	}
+	return defaultBuilderImage
+}
+
+// buildOptionArgs returns the kaniko flags for the build args and target.
+func buildOptionArgs(build *apiv1.Build) []string {
+	var args []string
+	keys := make([]string, 0, len(build.Args))
+	for k := range build.Args {
+		keys = append(keys, k)
+	}
+	// Sorted for a stable Job spec.
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "--build-arg="+k+"="+build.Args[k])
+	}
+	if build.Target != "" {
+		args = append(args, "--target="+build.Target)
+	}
+	return args
+}
+
+// builtImageDigest returns the digest that the builder wrote to its
+// termination message (see --digest-file) or "" if it is not available
+// (i.e. the Pods were already removed).
+func builtImageDigest(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.Name == builderContainerName && cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0 {
+				return strings.TrimSpace(cs.State.Terminated.Message)
+			}
+		}
+	}
+	return ""
+}
+
 func buildJobName(obj client.Object, kind string) string {
 	// NOTE: Suffix should be under 13 characters (for all Substratus kinds)
 	// to avoid exceeding the name character limit.
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func Test_builtImageDigest(t *testing.T) {
+	terminated := func(name string, exitCode int32, msg string) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: name,
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: exitCode,
+				Message:  msg,
+			}},
+		}}}}
+	}
+
+	require.Equal(t, "", builtImageDigest(nil))
+	require.Equal(t, "", builtImageDigest([]corev1.Pod{terminated("builder", 1, "error building image")}))
+	require.Equal(t, "", builtImageDigest([]corev1.Pod{terminated("sidecar", 0, "sha256:abc")}))
+	require.Equal(t, "sha256:abc", builtImageDigest([]corev1.Pod{
+		terminated("builder", 1, "error building image"),
+		terminated("builder", 0, "sha256:abc\n"),
+	}))
+}
+
+func Test_buildOptionArgs(t *testing.T) {
+	require.Empty(t, buildOptionArgs(&apiv1.Build{}))
+	require.Equal(t, []string{
+		"--build-arg=BASE=python:3.11",
+		"--build-arg=EXTRAS=gpu",
+		"--target=runtime",
+	}, buildOptionArgs(&apiv1.Build{
+		Args:   map[string]string{"EXTRAS": "gpu", "BASE": "python:3.11"},
+		Target: "runtime",
+	}))
+
+	require.Equal(t, defaultBuilderImage, builderImage(&apiv1.Build{}))
+	require.Equal(t, "mirror.example.com/kaniko:v1.19.0", builderImage(&apiv1.Build{BuilderImage: "mirror.example.com/kaniko:v1.19.0"}))
+}
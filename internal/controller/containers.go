@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addSidecars appends user supplied sidecar containers to the Pod. It is
+// called once the primary container is complete (mounts and resources) as
+// the sidecars get copies of its volume mounts, except for paths that a
+// sidecar mounts itself. The kubectl.kubernetes.io/default-container
+// annotation keeps pointing at the primary container.
+func addSidecars(podSpec *corev1.PodSpec, primary string, sidecars []corev1.Container) error {
+	pc, err := listPodContainers(podSpec, primary)
+	if err != nil {
+		return err
+	}
+	for _, c := range sidecars {
+		if _, ok := pc.names[c.Name]; ok {
+			return terminal(fmt.Errorf("sidecar name %q is already used by another container", c.Name))
+		}
+		pc.names[c.Name] = struct{}{}
+		podSpec.Containers = append(podSpec.Containers, withVolumeMounts(c, pc.primaryMounts))
+	}
+	return nil
+}
+
+// addInitContainers appends user supplied init containers to the Pod. They
+// run in order before the primary container and get copies of its volume
+// mounts (like sidecars, see addSidecars) so that they can stage files in
+// the volumes that the primary container consumes.
+func addInitContainers(podSpec *corev1.PodSpec, primary string, initContainers []corev1.Container) error {
+	pc, err := listPodContainers(podSpec, primary)
+	if err != nil {
+		return err
+	}
+	for _, c := range initContainers {
+		if _, ok := pc.names[c.Name]; ok {
+			return terminal(fmt.Errorf("init container name %q is already used by another container", c.Name))
+		}
+		pc.names[c.Name] = struct{}{}
+		podSpec.InitContainers = append(podSpec.InitContainers, withVolumeMounts(c, pc.primaryMounts))
+	}
+	return nil
+}
+
+type podContainers struct {
+	names         map[string]struct{}
+	primaryMounts []corev1.VolumeMount
+}
+
+// listPodContainers returns the names of all containers of the Pod and the
+// volume mounts of the primary container.
+func listPodContainers(podSpec *corev1.PodSpec, primary string) (podContainers, error) {
+	pc := podContainers{names: map[string]struct{}{}}
+	found := false
+	for _, c := range append(podSpec.InitContainers, podSpec.Containers...) {
+		pc.names[c.Name] = struct{}{}
+		if c.Name == primary {
+			found = true
+			pc.primaryMounts = c.VolumeMounts
+		}
+	}
+	if !found {
+		return pc, fmt.Errorf("container not found: %s", primary)
+	}
+	return pc, nil
+}
+
+// withVolumeMounts returns a copy of the container with the mounts added,
+// mounts at paths that the container mounts itself are skipped.
+func withVolumeMounts(c corev1.Container, mounts []corev1.VolumeMount) corev1.Container {
+	out := *c.DeepCopy()
+	own := map[string]struct{}{}
+	for _, m := range out.VolumeMounts {
+		own[m.MountPath] = struct{}{}
+	}
+	for _, m := range mounts {
+		if _, ok := own[m.MountPath]; !ok {
+			out.VolumeMounts = append(out.VolumeMounts, m)
+		}
+	}
+	return out
+}
@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+func Test_addSidecars(t *testing.T) {
+	dataMount := corev1.VolumeMount{Name: "artifacts", MountPath: "/content/artifacts", SubPath: "datasets/squad/artifacts"}
+	podSpec := func() *corev1.PodSpec {
+		return &corev1.PodSpec{Containers: []corev1.Container{{
+			Name:         "load",
+			VolumeMounts: []corev1.VolumeMount{dataMount},
+		}}}
+	}
+
+	spec := podSpec()
+	sidecars := []corev1.Container{
+		{Name: "proxy", Image: "envoy"},
+		{Name: "exporter", Image: "exporter", VolumeMounts: []corev1.VolumeMount{{Name: "cache", MountPath: "/content/artifacts"}}},
+	}
+	require.NoError(t, addSidecars(spec, "load", sidecars))
+	require.Len(t, spec.Containers, 3)
+	require.Equal(t, "load", spec.Containers[0].Name, "the primary container should stay first")
+	require.Equal(t, []corev1.VolumeMount{dataMount}, spec.Containers[1].VolumeMounts)
+	require.Equal(t, sidecars[1].VolumeMounts, spec.Containers[2].VolumeMounts, "own mounts should take precedence")
+	require.Empty(t, sidecars[0].VolumeMounts, "the spec should not be modified")
+
+	err := addSidecars(podSpec(), "load", []corev1.Container{{Name: "load"}})
+	require.ErrorContains(t, err, "already used")
+	require.True(t, isTerminal(err))
+
+	require.ErrorContains(t, addSidecars(podSpec(), "serve", nil), "container not found")
+}
+
+func Test_loadJobInitContainers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	r := &DatasetReconciler{
+		Scheme: scheme,
+		Cloud: &cloud.GCP{Common: cloud.Common{
+			ArtifactBucketURL: &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts"},
+		}},
+	}
+	dataset := &apiv1.Dataset{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Dataset"},
+		ObjectMeta: metav1.ObjectMeta{Name: "squad", Namespace: "default"},
+		Spec: apiv1.DatasetSpec{
+			Image: ptr.To("loader"),
+			InitContainers: []corev1.Container{
+				{Name: "tokenizer", Image: "busybox"},
+				{Name: "decrypt", Image: "sops"},
+			},
+		},
+	}
+
+	job, err := r.loadJob(context.Background(), dataset)
+	require.NoError(t, err)
+	podSpec := job.Spec.Template.Spec
+
+	var initNames []string
+	for _, c := range podSpec.InitContainers {
+		initNames = append(initNames, c.Name)
+	}
+	require.Equal(t, []string{"tokenizer", "decrypt"}, initNames, "init containers should run in the order of the spec")
+	require.Equal(t, "load", podSpec.Containers[0].Name)
+	require.Equal(t, "load", job.Spec.Template.Annotations["kubectl.kubernetes.io/default-container"])
+
+	artifactsMount := func(c corev1.Container) *corev1.VolumeMount {
+		for _, m := range c.VolumeMounts {
+			if m.MountPath == "/content/artifacts" {
+				return &m
+			}
+		}
+		return nil
+	}
+	loadMount := artifactsMount(podSpec.Containers[0])
+	require.NotNil(t, loadMount)
+	for _, c := range podSpec.InitContainers {
+		require.Equal(t, loadMount, artifactsMount(c), "container %s should share the artifacts volume", c.Name)
+	}
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// maxCrashMessageLen bounds the termination message that is copied into a
+// condition. Termination messages can be up to 4096 bytes which is too much
+// to show in a status.
+const maxCrashMessageLen = 512
+
+// podCrashMessage returns a description of the first container that is
+// repeatedly crashing (CrashLoopBackOff) or that exited with an error,
+// including its last exit code and termination message. Returns false if
+// none of the Pods' containers are crashing.
+func podCrashMessage(pods []corev1.Pod) (string, bool) {
+	for _, pod := range pods {
+		for _, cs := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			var reason string
+			var term *corev1.ContainerStateTerminated
+			switch {
+			case cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff":
+				reason = cs.State.Waiting.Reason
+				term = cs.LastTerminationState.Terminated
+			case cs.State.Terminated != nil && cs.State.Terminated.ExitCode != 0:
+				reason = cs.State.Terminated.Reason
+				term = cs.State.Terminated
+			default:
+				continue
+			}
+
+			msg := fmt.Sprintf("Pod %s container %s: %s", pod.Name, cs.Name, reason)
+			if term != nil {
+				msg += fmt.Sprintf(", exit code %d", term.ExitCode)
+				if tm := strings.TrimSpace(term.Message); tm != "" {
+					if len(tm) > maxCrashMessageLen {
+						tm = "..." + tm[len(tm)-maxCrashMessageLen:]
+					}
+					msg += ": " + tm
+				}
+			}
+			return msg, true
+		}
+	}
+	return "", false
+}
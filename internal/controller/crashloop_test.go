@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_podCrashMessage(t *testing.T) {
+	running := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "running"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "loader",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}},
+	}
+	crashLooping := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "crashing"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "loader",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+				Reason: "CrashLoopBackOff",
+			}},
+			LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 1,
+				Reason:   "Error",
+				Message:  "ModuleNotFoundError: No module named 'datasets'\n",
+			}},
+		}}},
+	}
+	failed := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "failed"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name: "model",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 137,
+				Reason:   "OOMKilled",
+				Message:  strings.Repeat("x", 1000),
+			}},
+		}}},
+	}
+
+	_, crashing := podCrashMessage([]corev1.Pod{running})
+	require.False(t, crashing)
+
+	msg, crashing := podCrashMessage([]corev1.Pod{running, crashLooping})
+	require.True(t, crashing)
+	require.Equal(t, "Pod crashing container loader: CrashLoopBackOff, exit code 1: ModuleNotFoundError: No module named 'datasets'", msg)
+
+	msg, crashing = podCrashMessage([]corev1.Pod{failed})
+	require.True(t, crashing)
+	require.True(t, strings.HasPrefix(msg, "Pod failed container model: OOMKilled, exit code 137: ..."))
+	require.Len(t, msg, len("Pod failed container model: OOMKilled, exit code 137: ...")+maxCrashMessageLen)
+}
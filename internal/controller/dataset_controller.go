@@ -3,6 +3,12 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -12,6 +18,7 @@ import (
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -21,6 +28,9 @@ import (
 	"github.com/substratusai/substratus/internal/sci"
 )
 
+// defaultHTTPLoaderImage is used to download Datasets that set Spec.Source.HTTP.
+const defaultHTTPLoaderImage = "curlimages/curl:8.4.0"
+
 // DatasetReconciler reconciles a Dataset object.
 type DatasetReconciler struct {
 	client.Client
@@ -30,9 +40,26 @@ type DatasetReconciler struct {
 
 	Cloud cloud.Cloud
 	SCI   sci.ControllerClient
+
+	// DefaultResources is optional, when set it fills in resources that
+	// are omitted.
+	DefaultResources *resources.Defaults
+
+	// Buckets is optional, when set Datasets are failed instead of started
+	// while artifact buckets can not be created with the configured KMS key.
+	Buckets *BucketChecker
+
+	// DriftCheckInterval is how often the artifacts of loaded Datasets are
+	// re-checksummed to detect drift. Disabled when 0.
+	DriftCheckInterval time.Duration
+
+	MaxConcurrentReconciles int
 }
 
 func (r *DatasetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Reconcile Dataset", "Dataset", req)
+	defer span.End()
+
 	log := log.FromContext(ctx)
 
 	log.Info("Reconciling Dataset")
@@ -43,7 +70,11 @@ func (r *DatasetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if dataset.GetImage() == "" {
+	if result, err := reconcileCloudConfig(ctx, r.Client, r.Cloud, &dataset, apiv1.ConditionComplete); !result.success {
+		return result.Result, err
+	}
+
+	if dataset.Spec.Source == nil && dataset.GetImage() == "" {
 		// Image must be building.
 		return ctrl.Result{}, nil
 	}
@@ -52,11 +83,12 @@ func (r *DatasetReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		return result.Result, err
 	}
 
-	if result, err := r.reconcileData(ctx, &dataset); !result.success {
+	result, err := r.reconcileData(ctx, &dataset)
+	if !result.success {
 		return result.Result, err
 	}
 
-	return ctrl.Result{}, nil
+	return result.Result, nil
 }
 
 //+kubebuilder:rbac:groups=substratus.ai,resources=datasets,verbs=get;list;watch;create;update;patch;delete
@@ -71,17 +103,30 @@ func (r *DatasetReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&apiv1.Dataset{}).
 		Owns(&batchv1.Job{}).
+		WithOptions(ctrlcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
 func (r *DatasetReconciler) reconcileData(ctx context.Context, dataset *apiv1.Dataset) (result, error) {
 	log := log.FromContext(ctx)
 
+	if dataset.Status.Ready && !reflect.DeepEqual(dataset.Spec.Sample, dataset.Status.Sample) {
+		log.Info("Sample changed, loading Dataset again")
+		dataset.Status.Ready = false
+	}
+
 	if dataset.Status.Ready {
-		return result{success: true}, nil
+		return r.reconcileDrift(ctx, dataset)
 	}
 
-	dataset.Status.Artifacts.URL = r.Cloud.ObjectArtifactURL(dataset).String()
+	artifactURL := r.Cloud.ObjectArtifactURL(dataset)
+	region, err := bucketLocation(ctx, r.SCI, r.Buckets, artifactURL)
+	if err != nil {
+		return result{}, fmt.Errorf("getting bucket location: %w", err)
+	}
+	dataset.Status.Artifacts.URL = artifactURL.String()
+	dataset.Status.Artifacts.Cloud = r.Cloud.Name()
+	dataset.Status.Artifacts.Region = region
 
 	// ServiceAccount for the loader job.
 	// Within the context of GCP, this ServiceAccount will need IAM permissions
@@ -95,36 +140,62 @@ func (r *DatasetReconciler) reconcileData(ctx context.Context, dataset *apiv1.Da
 		return result, err
 	}
 
+	bucket := r.Cloud.ObjectArtifactURL(dataset).Bucket
+	if result, err := reconcileKMSKey(ctx, r.Client, r.Buckets, bucket, dataset, apiv1.ConditionComplete); !result.success {
+		return result, err
+	}
+	setBucketLocationCondition(dataset.GetConditions(), dataset.Generation, r.Buckets, bucket)
+
+	if result, err := reconcileMountDriver(ctx, r.Client, r.Cloud, dataset, apiv1.ConditionComplete); !result.success {
+		return result, err
+	}
+
 	// Job that will run the data-loader image that was built by the previous Job.
 	loadJob, err := r.loadJob(ctx, dataset)
 	if err != nil {
-		log.Error(err, "unable to construct data-loader Job")
-		// No use in retrying...
-		return result{}, nil
+		return reconcileFailed(ctx, r.Client, dataset, apiv1.ConditionComplete, fmt.Errorf("constructing data-loader Job: %w", err))
 	}
 
+	dataset.Status.JobName = loadJob.Name
 	if err := r.Status().Update(ctx, dataset); err != nil {
 		return result{}, fmt.Errorf("updating status: %w", err)
 	}
 
-	jobResult, err := reconcileJob(ctx, r.Client, loadJob)
+	jobResult, err := reconcileJob(ctx, r.Client, loadJob, "Dataset")
 	if !jobResult.success {
 		dataset.Status.Ready = false
 		if !jobResult.failure {
-			meta.SetStatusCondition(dataset.GetConditions(), metav1.Condition{
-				Type:               apiv1.ConditionComplete,
-				Status:             metav1.ConditionFalse,
-				Reason:             apiv1.ReasonJobNotComplete,
-				ObservedGeneration: dataset.Generation,
-				Message:            "Waiting for data loader Job to complete",
-			})
+			pods, err := jobPods(ctx, r.Client, loadJob)
+			if err != nil {
+				return result{}, err
+			}
+			if setUnschedulableCondition(dataset.GetConditions(), dataset.Generation, pods) && jobResult.RequeueAfter == 0 {
+				jobResult.RequeueAfter = requeueAfter(RequeueInterval)
+			}
+			if msg, crashing := podCrashMessage(pods); crashing {
+				// Report crashes before the Job exhausts its backoffLimit.
+				meta.SetStatusCondition(dataset.GetConditions(), metav1.Condition{
+					Type:               apiv1.ConditionComplete,
+					Status:             metav1.ConditionFalse,
+					Reason:             apiv1.ReasonContainerCrashing,
+					ObservedGeneration: dataset.Generation,
+					Message:            msg,
+				})
+				if jobResult.RequeueAfter == 0 {
+					jobResult.RequeueAfter = requeueAfter(RequeueInterval)
+				}
+			} else {
+				meta.SetStatusCondition(dataset.GetConditions(), metav1.Condition{
+					Type:               apiv1.ConditionComplete,
+					Status:             metav1.ConditionFalse,
+					Reason:             apiv1.ReasonJobNotComplete,
+					ObservedGeneration: dataset.Generation,
+					Message:            "Waiting for data loader Job to complete",
+				})
+			}
 		} else {
-			meta.SetStatusCondition(dataset.GetConditions(), metav1.Condition{
-				Type:               apiv1.ConditionComplete,
-				Status:             metav1.ConditionFalse,
-				Reason:             apiv1.ReasonJobFailed,
-				ObservedGeneration: dataset.Generation,
-			})
+			observeJobFailed(dataset.Status.Conditions, apiv1.ConditionComplete, "Dataset", "run")
+			meta.SetStatusCondition(dataset.GetConditions(), jobFailedCondition(loadJob, dataset.Generation))
 		}
 		if err := r.Status().Update(ctx, dataset); err != nil {
 			return result{}, fmt.Errorf("updating status: %w", err)
@@ -132,6 +203,16 @@ func (r *DatasetReconciler) reconcileData(ctx context.Context, dataset *apiv1.Da
 		return jobResult, err
 	}
 
+	stats, err := r.artifactsStats(ctx, dataset)
+	if err != nil {
+		return result{}, fmt.Errorf("getting artifacts checksum: %w", err)
+	}
+	dataset.Status.Artifacts.Checksum = stats.Checksum
+	dataset.Status.Artifacts.ObjectCount = stats.ObjectCount
+	dataset.Status.Artifacts.SizeBytes = stats.TotalBytes
+	dataset.Status.Format = dataset.Spec.Format
+	dataset.Status.Sample = dataset.Spec.Sample
+
 	dataset.Status.Ready = true
 	meta.SetStatusCondition(dataset.GetConditions(), metav1.Condition{
 		Type:               apiv1.ConditionComplete,
@@ -146,12 +227,131 @@ func (r *DatasetReconciler) reconcileData(ctx context.Context, dataset *apiv1.Da
 	return result{success: true}, nil
 }
 
+// reconcileDrift periodically compares the checksum of the artifacts of a
+// loaded Dataset to the recorded one and reports changes through the
+// DataDrift condition. Like the Unschedulable condition, it is only set to
+// False once it was set.
+func (r *DatasetReconciler) reconcileDrift(ctx context.Context, dataset *apiv1.Dataset) (result, error) {
+	if r.DriftCheckInterval == 0 || dataset.Status.Artifacts.Checksum == "" {
+		return result{success: true}, nil
+	}
+
+	stats, err := r.artifactsStats(ctx, dataset)
+	if err != nil {
+		return result{}, fmt.Errorf("getting artifacts checksum: %w", err)
+	}
+	checksum := stats.Checksum
+
+	drift := metav1.Condition{
+		Type:               apiv1.ConditionDataDrift,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonChecksumMatches,
+		ObservedGeneration: dataset.Generation,
+	}
+	if checksum != dataset.Status.Artifacts.Checksum {
+		drift.Status = metav1.ConditionTrue
+		drift.Reason = apiv1.ReasonChecksumChanged
+		drift.Message = fmt.Sprintf("Artifacts checksum changed from %s to %s", dataset.Status.Artifacts.Checksum, checksum)
+	}
+	if drift.Status == metav1.ConditionTrue || meta.FindStatusCondition(dataset.Status.Conditions, apiv1.ConditionDataDrift) != nil {
+		meta.SetStatusCondition(dataset.GetConditions(), drift)
+		if err := r.Status().Update(ctx, dataset); err != nil {
+			return result{}, fmt.Errorf("updating status: %w", err)
+		}
+	}
+
+	return result{success: true, Result: ctrl.Result{RequeueAfter: requeueAfter(r.DriftCheckInterval)}}, nil
+}
+
+// artifactsStats returns the checksum, object count and size of the
+// artifacts of the Dataset. The artifacts are read from the recorded URL,
+// which is kept while the Dataset is Ready (even if the bucket configuration
+// changes).
+func (r *DatasetReconciler) artifactsStats(ctx context.Context, dataset *apiv1.Dataset) (*sci.GetPrefixChecksumResponse, error) {
+	u, err := cloud.ParseBucketURL(dataset.Status.Artifacts.URL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing artifacts url: %w", err)
+	}
+	resp, err := r.SCI.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{
+		BucketName: u.Bucket,
+		Prefix:     filepath.Join(u.Path, "artifacts"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("calling the sci service to GetPrefixChecksum: %w", err)
+	}
+	return resp, nil
+}
+
+// httpSourceFilename returns the name that a file downloaded from the given
+// URL is stored as (the last path element, i.e. "train.jsonl").
+func httpSourceFilename(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported url scheme %q, expected http or https", u.Scheme)
+	}
+	name := path.Base(u.Path)
+	if name == "." || name == "/" {
+		name = "data"
+	}
+	return name, nil
+}
+
 func (r *DatasetReconciler) loadJob(ctx context.Context, dataset *apiv1.Dataset) (*batchv1.Job, error) {
 	const containerName = "load"
 	envVars, err := resolveEnv(dataset.Spec.Env)
 	if err != nil {
-		return nil, fmt.Errorf("resolving env: %w", err)
+		return nil, terminal(fmt.Errorf("resolving env: %w", err))
+	}
+	params, err := paramsEnv(dataset.GetParams())
+	if err != nil {
+		return nil, terminal(fmt.Errorf("resolving params env: %w", err))
+	}
+	// Explicitly set env takes precedence over params.
+	envVars = append(params, envVars...)
+	if dataset.Spec.Format != "" {
+		envVars = append([]corev1.EnvVar{{Name: "DATASET_FORMAT", Value: string(dataset.Spec.Format)}}, envVars...)
+	}
+	if sample := dataset.Spec.Sample; sample != nil {
+		if sample.Count != 0 {
+			envVars = append([]corev1.EnvVar{{Name: "SAMPLE_COUNT", Value: strconv.FormatInt(sample.Count, 10)}}, envVars...)
+		} else {
+			envVars = append([]corev1.EnvVar{{Name: "SAMPLE_FRACTION", Value: sample.Fraction}}, envVars...)
+		}
+	}
+
+	image, command, args := dataset.GetImage(), dataset.Spec.Command, dataset.Spec.Args
+	var securityContext *corev1.SecurityContext
+	if src := dataset.Spec.Source; src != nil {
+		var sourceEnv []corev1.EnvVar
+		switch {
+		case src.HuggingFace != nil:
+			image = defaultHuggingFaceLoaderImage
+			command, sourceEnv = huggingFaceLoader(src.HuggingFace, "dataset")
+		default:
+			filename, err := httpSourceFilename(src.HTTP)
+			if err != nil {
+				return nil, terminal(fmt.Errorf("parsing source: %w", err))
+			}
+			image = defaultHTTPLoaderImage
+			// The curl image runs as the curl_user (uid 100), which can not
+			// write to the bucket mounts: the FSGroup is not applied to the
+			// CSI (FUSE) and hostPath volumes.
+			securityContext = &corev1.SecurityContext{RunAsUser: ptr.To(int64(0))}
+			command = []string{"sh", "-c", `curl -fsSL --retry 3 -o "$LOAD_DATA_PATH/$SOURCE_FILENAME" "$SOURCE_URL"`}
+			sourceEnv = []corev1.EnvVar{
+				{Name: "SOURCE_URL", Value: src.HTTP},
+				{Name: "SOURCE_FILENAME", Value: filename},
+			}
+		}
+		args = nil
+		envVars = append(append([]corev1.EnvVar{
+			{Name: "LOAD_DATA_PATH", Value: "/content/artifacts"},
+		}, sourceEnv...), envVars...)
 	}
+
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: dataset.Name + "-data-loader",
@@ -159,7 +359,8 @@ func (r *DatasetReconciler) loadJob(ctx context.Context, dataset *apiv1.Dataset)
 			Namespace: dataset.Namespace,
 		},
 		Spec: batchv1.JobSpec{
-			BackoffLimit: ptr.To(int32(2)), // TotalRetries = BackoffLimit + 1
+			BackoffLimit:          ptr.To(int32(2)), // TotalRetries = BackoffLimit + 1
+			ActiveDeadlineSeconds: activeDeadlineSeconds(dataset.Spec.Timeout),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
@@ -175,12 +376,19 @@ func (r *DatasetReconciler) loadJob(ctx context.Context, dataset *apiv1.Dataset)
 						FSGroup: ptr.To(int64(3003)),
 					},
 					ServiceAccountName: dataLoaderServiceAccountName,
+					PriorityClassName:  dataset.Spec.PriorityClassName,
 					Containers: []corev1.Container{
 						{
-							Name:    containerName,
-							Image:   dataset.GetImage(),
-							Command: dataset.Spec.Command,
-							Env:     envVars,
+							Name:            containerName,
+							Image:           image,
+							Command:         command,
+							Args:            args,
+							Env:             envVars,
+							SecurityContext: securityContext,
+							// Surface the tail of the logs in the Pod status
+							// when the container fails without writing a
+							// termination message.
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 						},
 					},
 					RestartPolicy: "Never",
@@ -198,8 +406,9 @@ func (r *DatasetReconciler) loadJob(ctx context.Context, dataset *apiv1.Dataset)
 		Mounts: []cloud.BucketMount{
 			{BucketSubdir: "artifacts", ContentSubdir: "artifacts"},
 		},
-		Container: containerName,
-		ReadOnly:  false,
+		Container:    containerName,
+		ReadOnly:     false,
+		MountOptions: dataset.Spec.MountOptions,
 	}); err != nil {
 		return nil, fmt.Errorf("mounting bucket: %w", err)
 	}
@@ -209,8 +418,12 @@ func (r *DatasetReconciler) loadJob(ctx context.Context, dataset *apiv1.Dataset)
 	}
 
 	if err := resources.Apply(&job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, containerName,
-		r.Cloud.Name(), dataset.Spec.Resources); err != nil {
-		return nil, fmt.Errorf("applying resources: %w", err)
+		r.Cloud.Name(), resources.LoaderResources(r.Cloud.Name(), r.DefaultResources.Resolve(dataset.Spec.Resources))); err != nil {
+		return nil, terminal(fmt.Errorf("applying resources: %w", err))
+	}
+
+	if err := addInitContainers(&job.Spec.Template.Spec, containerName, dataset.Spec.InitContainers); err != nil {
+		return nil, fmt.Errorf("adding init containers: %w", err)
 	}
 
 	return job, nil
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+// prefixChecksumSCI returns the checksum of the given prefixes (and a
+// different one for all other prefixes).
+type prefixChecksumSCI struct {
+	sci.FakeSCIControllerClient
+	checksums map[string]string
+}
+
+func (c *prefixChecksumSCI) GetPrefixChecksum(ctx context.Context, in *sci.GetPrefixChecksumRequest, opts ...grpc.CallOption) (*sci.GetPrefixChecksumResponse, error) {
+	checksum, ok := c.checksums[in.BucketName+"/"+in.Prefix]
+	if !ok {
+		checksum = "unknown-prefix"
+	}
+	return &sci.GetPrefixChecksumResponse{Checksum: checksum}, nil
+}
+
+func Test_reconcileDriftAfterConfigChange(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	c := &cloud.GCP{}
+	c.ClusterName = "my-cluster"
+	c.ArtifactBucketURL = &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts", Path: "/"}
+
+	dataset := &apiv1.Dataset{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Dataset"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "squad"},
+	}
+	loaded := c.ObjectArtifactURL(dataset)
+	dataset.Status.Ready = true
+	dataset.Status.Artifacts.URL = loaded.String()
+	dataset.Status.Artifacts.Checksum = "loaded"
+
+	// The bucket configuration changes after the Dataset was loaded.
+	c.DatasetBucketURL = &cloud.BucketURL{Scheme: "gs", Bucket: "datasets"}
+	c.NamespacedArtifactPaths = true
+	require.NotEqual(t, loaded.String(), c.ObjectArtifactURL(dataset).String())
+
+	r := &DatasetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(dataset).WithStatusSubresource(dataset).Build(),
+		Cloud:  c,
+		SCI: &prefixChecksumSCI{checksums: map[string]string{
+			loaded.Bucket + "/" + filepath.Join(loaded.Path, "artifacts"): "loaded",
+		}},
+		DriftCheckInterval: time.Hour,
+	}
+
+	res, err := r.reconcileData(context.Background(), dataset)
+	require.NoError(t, err)
+	require.True(t, res.success)
+	require.Nil(t, meta.FindStatusCondition(dataset.Status.Conditions, apiv1.ConditionDataDrift), "the recorded artifacts did not change")
+	require.Equal(t, loaded.String(), dataset.Status.Artifacts.URL)
+}
@@ -8,10 +8,11 @@ import (
 	"github.com/stretchr/testify/require"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
@@ -31,9 +32,9 @@ func TestDataset(t *testing.T) {
 					URL: "https://github.com/substratusai/dataset-some-dataset",
 				},
 			},
-			Params: map[string]intstr.IntOrString{
-				"s": intstr.FromString("something-dataset"),
-				"x": intstr.FromInt(123),
+			Params: map[string]apiextensionsv1.JSON{
+				"s": {Raw: []byte(`"something-dataset"`)},
+				"x": {Raw: []byte(`123`)},
 			},
 		},
 	}
@@ -73,4 +74,142 @@ func testDatasetLoad(t *testing.T, dataset *apiv1.Dataset) {
 		assert.True(t, dataset.Status.Ready)
 	}, timeout, interval, "waiting for the dataset to be ready")
 	require.Contains(t, dataset.Status.Artifacts.URL, "gs://test-artifact-bucket")
+	require.Equal(t, "gcp", dataset.Status.Artifacts.Cloud)
+	require.Equal(t, int64(fakeArtifactsObjectCount), dataset.Status.Artifacts.ObjectCount)
+	require.Equal(t, int64(fakeArtifactsSizeBytes), dataset.Status.Artifacts.SizeBytes)
+	require.Equal(t, loaderJob.Name, dataset.Status.JobName)
+}
+
+func TestDatasetFromHTTPSource(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	dataset := &apiv1.Dataset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-ds",
+			Namespace: "default",
+		},
+		Spec: apiv1.DatasetSpec{
+			Source: &apiv1.DatasetSource{
+				HTTP: "https://example.com/data/train.jsonl?download=true",
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, dataset), "create a dataset")
+	t.Cleanup(debugObject(t, dataset))
+
+	var loaderJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: dataset.Namespace, Name: dataset.Name + "-data-loader"}, &loaderJob)
+		assert.NoError(t, err, "getting the data loader job")
+	}, timeout, interval, "waiting for the data loader job to be created")
+
+	container := loaderJob.Spec.Template.Spec.Containers[0]
+	require.Equal(t, "curlimages/curl:8.4.0", container.Image)
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "LOAD_DATA_PATH", Value: "/content/artifacts"})
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "SOURCE_FILENAME", Value: "train.jsonl"})
+	require.NotNil(t, container.SecurityContext)
+	require.Equal(t, ptr.To(int64(0)), container.SecurityContext.RunAsUser, "the curl image does not run as root by default")
+
+	fakeJobComplete(t, &loaderJob)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dataset), dataset)
+		assert.NoError(t, err, "getting the dataset")
+		assert.True(t, dataset.Status.Ready)
+	}, timeout, interval, "waiting for the dataset to be ready")
+}
+
+func TestDatasetImageChangeRecreatesJob(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	dataset := &apiv1.Dataset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-ds",
+			Namespace: "default",
+		},
+		Spec: apiv1.DatasetSpec{
+			Image: ptr.To("some-image"),
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, dataset), "create a dataset")
+	t.Cleanup(debugObject(t, dataset))
+
+	jobKey := types.NamespacedName{Namespace: dataset.Namespace, Name: dataset.Name + "-data-loader"}
+	var loaderJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, jobKey, &loaderJob)
+		assert.NoError(t, err, "getting the data loader job")
+	}, timeout, interval, "waiting for the data loader job to be created")
+	require.Equal(t, "some-image", loaderJob.Spec.Template.Spec.Containers[0].Image)
+	originalUID := loaderJob.UID
+
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(dataset), dataset))
+	dataset.Spec.Image = ptr.To("other-image")
+	require.NoError(t, k8sClient.Update(ctx, dataset), "update the dataset image")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, jobKey, &loaderJob)
+		if assert.NoError(t, err, "getting the data loader job") {
+			assert.NotEqual(t, originalUID, loaderJob.UID)
+			assert.Equal(t, "other-image", loaderJob.Spec.Template.Spec.Containers[0].Image)
+		}
+	}, timeout, interval, "waiting for the data loader job to be recreated")
+}
+
+func TestDatasetSample(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	dataset := &apiv1.Dataset{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-ds",
+			Namespace: "default",
+		},
+		Spec: apiv1.DatasetSpec{
+			Image:  ptr.To("some-image"),
+			Sample: &apiv1.DatasetSample{Count: 100},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, dataset), "create a dataset")
+	t.Cleanup(debugObject(t, dataset))
+
+	jobKey := types.NamespacedName{Namespace: dataset.Namespace, Name: dataset.Name + "-data-loader"}
+	var loaderJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, jobKey, &loaderJob)
+		assert.NoError(t, err, "getting the data loader job")
+	}, timeout, interval, "waiting for the data loader job to be created")
+	require.Contains(t, loaderJob.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{Name: "SAMPLE_COUNT", Value: "100"})
+	originalUID := loaderJob.UID
+
+	fakeJobComplete(t, &loaderJob)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dataset), dataset)
+		assert.NoError(t, err, "getting the dataset")
+		assert.True(t, dataset.Status.Ready)
+	}, timeout, interval, "waiting for the dataset to be ready")
+	require.Equal(t, &apiv1.DatasetSample{Count: 100}, dataset.Status.Sample)
+
+	// Removing the sample loads the full Dataset.
+	dataset.Spec.Sample = nil
+	require.NoError(t, k8sClient.Update(ctx, dataset), "remove the dataset sample")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, jobKey, &loaderJob)
+		if assert.NoError(t, err, "getting the data loader job") {
+			assert.NotEqual(t, originalUID, loaderJob.UID)
+		}
+	}, timeout, interval, "waiting for the data loader job to be recreated")
+	for _, env := range loaderJob.Spec.Template.Spec.Containers[0].Env {
+		require.NotEqual(t, "SAMPLE_COUNT", env.Name)
+	}
+
+	fakeJobComplete(t, &loaderJob)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(dataset), dataset)
+		assert.NoError(t, err, "getting the dataset")
+		assert.True(t, dataset.Status.Ready)
+		assert.Nil(t, dataset.Status.Sample)
+	}, timeout, interval, "waiting for the full dataset to be ready")
 }
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// defaultHuggingFaceLoaderImage is used to download Datasets and Models that
+// set Spec.Source.HuggingFace.
+const defaultHuggingFaceLoaderImage = "python:3.11-slim"
+
+// huggingFaceLoaderScript downloads a snapshot of a HuggingFace Hub repository
+// into the artifacts directory.
+const huggingFaceLoaderScript = `set -e
+pip install --quiet --no-cache-dir huggingface_hub==0.18.0
+python - <<'PY'
+import os
+from huggingface_hub import snapshot_download
+
+snapshot_download(
+    repo_id=os.environ["HF_REPO"],
+    repo_type=os.environ["HF_REPO_TYPE"],
+    revision=os.environ.get("HF_REVISION") or None,
+    token=os.environ.get("HF_TOKEN") or None,
+    local_dir="/content/artifacts",
+    local_dir_use_symlinks=False,
+)
+PY
+`
+
+// huggingFaceLoader returns the command and environment variables of a
+// container that downloads the given repository. The repoType is either
+// "dataset" or "model".
+func huggingFaceLoader(src *apiv1.HuggingFaceSource, repoType string) ([]string, []corev1.EnvVar) {
+	env := []corev1.EnvVar{
+		{Name: "HF_REPO", Value: src.Repo},
+		{Name: "HF_REPO_TYPE", Value: repoType},
+		{Name: "HF_REVISION", Value: src.Revision},
+	}
+	if src.TokenSecretRef != nil {
+		env = append(env, corev1.EnvVar{
+			Name:      "HF_TOKEN",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: src.TokenSecretRef},
+		})
+	}
+	return []string{"sh", "-c", huggingFaceLoaderScript}, env
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,10 +35,16 @@ import (
 const (
 	timeout  = time.Second * 5
 	interval = time.Second / 10
+
+	// The fake SCI reports these stats for the artifacts of every prefix
+	// (Servers do not serve Models without artifacts).
+	fakeArtifactsObjectCount = 3
+	fakeArtifactsSizeBytes   = 2048
 )
 
 var (
 	k8sClient client.Client
+	fakeSCI   *sci.FakeSCIControllerClient
 	testEnv   *envtest.Environment
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -68,6 +75,11 @@ func TestMain(m *testing.M) {
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
 	requireNoError(err)
 
+	// Buckets are mounted with the gcsfuse CSI driver of the test cloud.
+	requireNoError(k8sClient.Create(ctx, &storagev1.CSIDriver{
+		ObjectMeta: metav1.ObjectMeta{Name: cloud.GCSFuseCSIDriverName},
+	}))
+
 	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 		Scheme:             scheme.Scheme,
 		MetricsBindAddress: "0",
@@ -83,7 +95,9 @@ func TestMain(m *testing.M) {
 	testCloud.RegistryURL = "registry.test"
 	testCloud.Principal = "substratus@test-project-id.iam.gserviceaccount.com"
 
-	sciClient := &sci.FakeSCIControllerClient{}
+	fakeSCI = &sci.FakeSCIControllerClient{}
+	fakeSCI.PrefixObjectCount.Store(fakeArtifactsObjectCount)
+	fakeSCI.PrefixTotalBytes.Store(fakeArtifactsSizeBytes)
 
 	// runtimeMgr, err := controller.NewRuntimeManager(controller.GPUTypeNvidiaL4)
 	// requireNoError(err)
@@ -92,7 +106,7 @@ func TestMain(m *testing.M) {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cloud:  testCloud,
-		SCI:    sciClient,
+		SCI:    fakeSCI,
 		ParamsReconciler: &controller.ParamsReconciler{
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
@@ -103,7 +117,7 @@ func TestMain(m *testing.M) {
 		Scheme:    mgr.GetScheme(),
 		Client:    mgr.GetClient(),
 		Cloud:     testCloud,
-		SCI:       sciClient,
+		SCI:       fakeSCI,
 		NewObject: func() controller.BuildableObject { return &apiv1.Model{} },
 		Kind:      "Model",
 	}).SetupWithManager(mgr)
@@ -112,7 +126,7 @@ func TestMain(m *testing.M) {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cloud:  testCloud,
-		SCI:    sciClient,
+		SCI:    fakeSCI,
 		ParamsReconciler: &controller.ParamsReconciler{
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
@@ -123,7 +137,7 @@ func TestMain(m *testing.M) {
 		Scheme:    mgr.GetScheme(),
 		Client:    mgr.GetClient(),
 		Cloud:     testCloud,
-		SCI:       sciClient,
+		SCI:       fakeSCI,
 		NewObject: func() controller.BuildableObject { return &apiv1.Server{} },
 		Kind:      "Server",
 	}).SetupWithManager(mgr)
@@ -132,7 +146,7 @@ func TestMain(m *testing.M) {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cloud:  testCloud,
-		SCI:    sciClient,
+		SCI:    fakeSCI,
 		ParamsReconciler: &controller.ParamsReconciler{
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
@@ -143,7 +157,7 @@ func TestMain(m *testing.M) {
 		Scheme:    mgr.GetScheme(),
 		Client:    mgr.GetClient(),
 		Cloud:     testCloud,
-		SCI:       sciClient,
+		SCI:       fakeSCI,
 		NewObject: func() controller.BuildableObject { return &apiv1.Notebook{} },
 		Kind:      "Notebook",
 	}).SetupWithManager(mgr)
@@ -152,7 +166,7 @@ func TestMain(m *testing.M) {
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
 		Cloud:  testCloud,
-		SCI:    sciClient,
+		SCI:    fakeSCI,
 		ParamsReconciler: &controller.ParamsReconciler{
 			Scheme: mgr.GetScheme(),
 			Client: mgr.GetClient(),
@@ -163,7 +177,7 @@ func TestMain(m *testing.M) {
 		Scheme:    mgr.GetScheme(),
 		Client:    mgr.GetClient(),
 		Cloud:     testCloud,
-		SCI:       sciClient,
+		SCI:       fakeSCI,
 		NewObject: func() controller.BuildableObject { return &apiv1.Dataset{} },
 		Kind:      "Dataset",
 	}).SetupWithManager(mgr)
@@ -202,6 +216,7 @@ type testObject interface {
 	GetStatusReady() bool
 	SetStatusReady(bool)
 	GetBuild() *apiv1.Build
+	GetStatusContainer() apiv1.ContainerStatus
 }
 
 func testContainerBuild(t *testing.T, obj testObject, kind string) {
@@ -230,6 +245,11 @@ func testContainerBuild(t *testing.T, obj testObject, kind string) {
 			assert.True(t, meta.IsStatusConditionTrue(*obj.GetConditions(), apiv1.ConditionBuilt))
 		}
 	}, timeout, interval, "waiting for the container to be ready")
+	if os.Getenv("CI") != "true" {
+		require.Equal(t, builderJob.Name, obj.GetStatusContainer().BuildJob)
+		require.Equal(t, apiv1.ReasonJobComplete, obj.GetStatusContainer().Reason)
+		require.Equal(t, builderJob.Annotations["image"], obj.GetStatusContainer().Image)
+	}
 }
 
 func testParamsConfigMap(t *testing.T, obj testObject, kind string, content string) {
@@ -264,6 +284,18 @@ func fakePodReady(t *testing.T, pod *corev1.Pod) {
 	require.NoError(t, k8sClient.Status().Patch(ctx, updated, client.MergeFrom(pod)), "patching the pod with ready status")
 }
 
+func fakePodNotReady(t *testing.T, pod *corev1.Pod, message string) {
+	updated := pod.DeepCopy()
+	updated.Status.Phase = corev1.PodRunning
+	updated.Status.Conditions = []corev1.PodCondition{{
+		Type:    corev1.PodReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  "ContainersNotReady",
+		Message: message,
+	}}
+	require.NoError(t, k8sClient.Status().Patch(ctx, updated, client.MergeFrom(pod)), "patching the pod with not ready status")
+}
+
 func debugObject(t *testing.T, obj client.Object) func() {
 	return func() {
 		if !t.Failed() {
@@ -14,6 +14,8 @@ const (
 	notebookModelIndex   = "spec.model.name"
 	notebookDatasetIndex = "spec.dataset.name"
 
+	// Model references can cross namespaces, so these are indexed by
+	// "<namespace>/<name>".
 	modelModelIndex   = "spec.model.name"
 	modelDatasetIndex = "spec.dataset.name"
 
@@ -46,17 +48,18 @@ func SetupIndexes(mgr manager.Manager) error {
 		if model.Spec.Model == nil {
 			return []string{}
 		}
-		return []string{model.Spec.Model.Name}
+		return []string{refKey(model.Namespace, *model.Spec.Model).String()}
 	}); err != nil {
 		return fmt.Errorf("model: %w", err)
 	}
 
 	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &apiv1.Model{}, modelDatasetIndex, func(rawObj client.Object) []string {
 		model := rawObj.(*apiv1.Model)
-		if model.Spec.Dataset == nil {
-			return []string{}
+		names := []string{}
+		for _, ref := range modelDatasetRefs(model) {
+			names = append(names, refKey(model.Namespace, ref).String())
 		}
-		return []string{model.Spec.Dataset.Name}
+		return names
 	}); err != nil {
 		return fmt.Errorf("model: %w", err)
 	}
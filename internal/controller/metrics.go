@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// Reconcile durations are already recorded per controller by controller-runtime
+// (controller_runtime_reconcile_time_seconds), the metrics below cover
+// Substratus-specific events.
+var (
+	jobsCreatedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "substratus_jobs_created_total",
+		Help: "Number of Jobs created by the controller manager.",
+	}, []string{"kind", "role"})
+
+	jobsFailedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "substratus_jobs_failed_total",
+		Help: "Number of Jobs observed to have failed.",
+	}, []string{"kind", "role"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(jobsCreatedTotal, jobsFailedTotal)
+}
+
+// observeJobFailed increments the failed Jobs counter unless the failure
+// was already recorded in the given condition type (to avoid counting the
+// same failure on every reconcile).
+func observeJobFailed(conditions []metav1.Condition, conditionType, kind, role string) {
+	if c := meta.FindStatusCondition(conditions, conditionType); c != nil && (c.Reason == apiv1.ReasonJobFailed || c.Reason == apiv1.ReasonTimedOut) {
+		return
+	}
+	jobsFailedTotal.WithLabelValues(kind, role).Inc()
+}
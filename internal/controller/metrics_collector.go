@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/metricstore"
+)
+
+// MetricsCollector periodically records the resource usage of Notebook and
+// Server Pods into a metricstore.Store. It is meant to be added to the
+// manager as a Runnable.
+type MetricsCollector struct {
+	Client   client.Client
+	Reader   PodMetricsReader
+	Store    metricstore.Store
+	Interval time.Duration
+
+	// recorded are the objects that samples were stored for, used to delete
+	// the history of objects once they are deleted.
+	recorded map[metricstore.Object]struct{}
+}
+
+func (c *MetricsCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.collect(ctx)
+			c.deleteHistory(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection returns false because each replica keeps its own history.
+func (c *MetricsCollector) NeedLeaderElection() bool {
+	return false
+}
+
+func (c *MetricsCollector) collect(ctx context.Context) {
+	log := log.FromContext(ctx).WithName("metrics-collector")
+
+	var pods corev1.PodList
+	if err := c.Client.List(ctx, &pods, client.MatchingLabels{"role": "run"}); err != nil {
+		log.Error(err, "unable to list pods")
+		return
+	}
+
+	if c.recorded == nil {
+		c.recorded = map[metricstore.Object]struct{}{}
+	}
+
+	now := time.Now()
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		obj, ok := podOwnerObject(pod)
+		if !ok || pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+
+		usage, err := c.Reader.PodUsage(ctx, pod)
+		if err != nil {
+			log.Error(err, "unable to read pod usage", "pod", pod.Name, "namespace", pod.Namespace)
+			continue
+		}
+
+		values := map[metricstore.Metric]float64{
+			metricstore.MetricCPU:    float64(usage.CPUNanoCores) / 1e9,
+			metricstore.MetricMemory: float64(usage.MemoryBytes),
+			metricstore.MetricDisk:   float64(usage.EphemeralStorageBytes),
+		}
+		if usage.GPUs > 0 {
+			values[metricstore.MetricGPU] = usage.GPUUtilization
+		}
+		for metric, value := range values {
+			if err := c.Store.Append(ctx, obj, metric, metricstore.Sample{Time: now, Value: value}); err != nil {
+				log.Error(err, "unable to store sample", "pod", pod.Name, "namespace", pod.Namespace)
+			}
+		}
+		c.recorded[obj] = struct{}{}
+	}
+}
+
+// deleteHistory deletes the history of recorded objects that no longer exist.
+func (c *MetricsCollector) deleteHistory(ctx context.Context) {
+	log := log.FromContext(ctx).WithName("metrics-collector")
+
+	for obj := range c.recorded {
+		var o client.Object
+		switch obj.Kind {
+		case "Notebook":
+			o = &apiv1.Notebook{}
+		case "Server":
+			o = &apiv1.Server{}
+		default:
+			continue
+		}
+
+		err := c.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, o)
+		if err == nil || !apierrors.IsNotFound(err) {
+			continue
+		}
+		if err := c.Store.Delete(ctx, obj); err != nil {
+			log.Error(err, "unable to delete history", "kind", obj.Kind, "name", obj.Name, "namespace", obj.Namespace)
+			continue
+		}
+		delete(c.recorded, obj)
+	}
+}
+
+// podOwnerObject maps a Pod to the Notebook or Server that it runs for.
+func podOwnerObject(pod *corev1.Pod) (metricstore.Object, bool) {
+	if name, ok := pod.Labels["notebook"]; ok {
+		return metricstore.Object{Kind: "Notebook", Namespace: pod.Namespace, Name: name}, true
+	}
+	if name, ok := pod.Labels["server"]; ok {
+		return metricstore.Object{Kind: "Server", Namespace: pod.Namespace, Name: name}, true
+	}
+	return metricstore.Object{}, false
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func Test_observeJobFailed(t *testing.T) {
+	failed := jobsFailedTotal.WithLabelValues("Model", "test")
+	before := testutil.ToFloat64(failed)
+
+	observeJobFailed(nil, apiv1.ConditionComplete, "Model", "test")
+	require.Equal(t, before+1, testutil.ToFloat64(failed))
+
+	// Already recorded in the condition.
+	observeJobFailed([]metav1.Condition{{
+		Type:   apiv1.ConditionComplete,
+		Status: metav1.ConditionFalse,
+		Reason: apiv1.ReasonJobFailed,
+	}}, apiv1.ConditionComplete, "Model", "test")
+	require.Equal(t, before+1, testutil.ToFloat64(failed))
+
+	// Only the given condition type is checked.
+	observeJobFailed([]metav1.Condition{{
+		Type:   apiv1.ConditionComplete,
+		Status: metav1.ConditionTrue,
+		Reason: apiv1.ReasonJobComplete,
+	}}, apiv1.ConditionBuilt, "Model", "test")
+	require.Equal(t, before+2, testutil.ToFloat64(failed))
+}
@@ -3,6 +3,9 @@ package controller
 import (
 	"context"
 	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -11,9 +14,11 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -34,6 +39,16 @@ type ModelReconciler struct {
 
 	Cloud cloud.Cloud
 	SCI   sci.ControllerClient
+
+	// DefaultResources is optional, when set it fills in resources that
+	// are omitted.
+	DefaultResources *resources.Defaults
+
+	// Buckets is optional, when set Models are failed instead of started
+	// while artifact buckets can not be created with the configured KMS key.
+	Buckets *BucketChecker
+
+	MaxConcurrentReconciles int
 }
 
 type ModelReconcilerConfig struct {
@@ -41,6 +56,9 @@ type ModelReconcilerConfig struct {
 }
 
 func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Reconcile Model", "Model", req)
+	defer span.End()
+
 	log := log.FromContext(ctx)
 
 	log.Info("Reconciling Model")
@@ -51,7 +69,11 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if model.GetImage() == "" {
+	if result, err := reconcileCloudConfig(ctx, r.Client, r.Cloud, &model, apiv1.ConditionComplete); !result.success {
+		return result.Result, err
+	}
+
+	if model.Spec.Source == nil && model.GetImage() == "" {
 		// Image must be building.
 		return ctrl.Result{}, nil
 	}
@@ -68,13 +90,16 @@ func (r *ModelReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl
 }
 
 func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model) (result, error) {
-	log := log.FromContext(ctx)
-
 	if model.Status.Ready {
 		return result{success: true}, nil
 	}
 
-	model.Status.Artifacts.URL = r.Cloud.ObjectArtifactURL(model).String()
+	region, err := bucketLocation(ctx, r.SCI, r.Buckets, r.Cloud.ObjectArtifactURL(model))
+	if err != nil {
+		return result{}, fmt.Errorf("getting bucket location: %w", err)
+	}
+	model.Status.Artifacts.Cloud = r.Cloud.Name()
+	model.Status.Artifacts.Region = region
 
 	// ServiceAccount for the model Job.
 	// Within the context of GCP, this ServiceAccount will need IAM permissions
@@ -92,7 +117,8 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 	var baseModel *apiv1.Model
 	if model.Spec.Model != nil {
 		baseModel = &apiv1.Model{}
-		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: model.Spec.Model.Name}, baseModel); err != nil {
+		baseModelKey := refKey(model.Namespace, *model.Spec.Model)
+		if err := r.Client.Get(ctx, baseModelKey, baseModel); err != nil {
 			if apierrors.IsNotFound(err) {
 				// Update this Model's status.
 				model.Status.Ready = false
@@ -101,6 +127,7 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 					Status:             metav1.ConditionFalse,
 					Reason:             apiv1.ReasonBaseModelNotFound,
 					ObservedGeneration: model.Generation,
+					Message:            fmt.Sprintf("Base Model %q not found in namespace %q", baseModelKey.Name, baseModelKey.Namespace),
 				})
 				if err := r.Status().Update(ctx, model); err != nil {
 					return result{}, fmt.Errorf("failed to update model status: %w", err)
@@ -112,6 +139,9 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 
 			return result{}, fmt.Errorf("getting source model: %w", err)
 		}
+		if !referenceAllowed(baseModel, model.Namespace) {
+			return r.referenceNotAllowed(ctx, model, "base Model", baseModel)
+		}
 		if !baseModel.Status.Ready {
 			// Update this Model's status.
 			model.Status.Ready = false
@@ -120,6 +150,7 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 				Status:             metav1.ConditionFalse,
 				Reason:             apiv1.ReasonBaseModelNotReady,
 				ObservedGeneration: model.Generation,
+				Message:            fmt.Sprintf("Waiting for base Model %q to be ready", baseModel.Name),
 			})
 			if err := r.Status().Update(ctx, model); err != nil {
 				return result{}, fmt.Errorf("failed to update model status: %w", err)
@@ -130,10 +161,12 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 		}
 	}
 
-	var dataset *apiv1.Dataset
-	if model.Spec.Dataset != nil {
-		dataset = &apiv1.Dataset{}
-		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: model.Spec.Dataset.Name}, dataset); err != nil {
+	var datasets []*apiv1.Dataset
+	var notReady []string
+	for _, ref := range modelDatasetRefs(model) {
+		dataset := &apiv1.Dataset{}
+		datasetKey := refKey(model.Namespace, ref)
+		if err := r.Client.Get(ctx, datasetKey, dataset); err != nil {
 			if apierrors.IsNotFound(err) {
 				// Update this Model's status.
 				model.Status.Ready = false
@@ -142,6 +175,7 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 					Status:             metav1.ConditionFalse,
 					Reason:             apiv1.ReasonDatasetNotFound,
 					ObservedGeneration: model.Generation,
+					Message:            fmt.Sprintf("Dataset %q not found in namespace %q", datasetKey.Name, datasetKey.Namespace),
 				})
 				if err := r.Status().Update(ctx, model); err != nil {
 					return result{}, fmt.Errorf("failed to update model status: %w", err)
@@ -151,65 +185,142 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 				return result{}, nil
 			}
 
-			return result{}, fmt.Errorf("getting source model: %w", err)
+			return result{}, fmt.Errorf("getting dataset: %w", err)
+		}
+		if !referenceAllowed(dataset, model.Namespace) {
+			return r.referenceNotAllowed(ctx, model, "Dataset", dataset)
 		}
 		if !dataset.Status.Ready {
-			// Update this Model's status.
-			model.Status.Ready = false
-			meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
-				Type:               apiv1.ConditionComplete,
-				Status:             metav1.ConditionFalse,
-				Reason:             apiv1.ReasonDatasetNotReady,
-				ObservedGeneration: model.Generation,
-			})
-			if err := r.Status().Update(ctx, model); err != nil {
-				return result{}, fmt.Errorf("failed to update model status: %w", err)
-			}
+			notReady = append(notReady, strconv.Quote(dataset.Name))
+		}
+		datasets = append(datasets, dataset)
+	}
+	if len(notReady) > 0 {
+		// Update this Model's status.
+		model.Status.Ready = false
+		meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+			Type:               apiv1.ConditionComplete,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonDatasetNotReady,
+			ObservedGeneration: model.Generation,
+			Message:            fmt.Sprintf("Waiting for Dataset %s to be ready", strings.Join(notReady, ", ")),
+		})
+		if err := r.Status().Update(ctx, model); err != nil {
+			return result{}, fmt.Errorf("failed to update model status: %w", err)
+		}
 
-			// Allow for watch to requeue.
-			return result{}, nil
+		// Allow for watch to requeue.
+		return result{}, nil
+	}
+
+	bucket := r.Cloud.ObjectArtifactURL(model).Bucket
+	if result, err := reconcileKMSKey(ctx, r.Client, r.Buckets, bucket, model, apiv1.ConditionComplete); !result.success {
+		return result, err
+	}
+	setBucketLocationCondition(model.GetConditions(), model.Generation, r.Buckets, bucket)
+
+	if result, err := reconcileMountDriver(ctx, r.Client, r.Cloud, model, apiv1.ConditionComplete); !result.success {
+		return result, err
+	}
+
+	if nodes := modellerNodes(model); nodes > 1 {
+		svc, err := r.modellerService(model)
+		if err != nil {
+			return reconcileFailed(ctx, r.Client, model, apiv1.ConditionComplete, fmt.Errorf("constructing modeller Service: %w", err))
+		}
+		if err := r.Patch(ctx, svc, client.Apply, client.FieldOwner("model-controller")); err != nil {
+			return result{}, fmt.Errorf("failed to apply modeller service: %w", err)
 		}
 	}
 
-	modellerJob, err := r.modellerJob(ctx, model, baseModel, dataset)
+	revision, modellerJob, err := r.modellerRevision(ctx, model, baseModel, datasets)
 	if err != nil {
-		log.Error(err, "unable to construct modeller Job")
-		// No use in retrying...
-		return result{}, nil
+		return reconcileFailed(ctx, r.Client, model, apiv1.ConditionComplete, fmt.Errorf("constructing modeller Job: %w", err))
+	}
+	if model.Status.Artifacts.URL == "" {
+		// There is no previous revision to keep pointing at.
+		model.Status.Artifacts.URL = modelRevisionURL(r.Cloud, model, revision).String()
+	}
+
+	model.Status.JobName = modellerJob.Name
+	jobResult, err := reconcileJob(ctx, r.Client, modellerJob, "Model")
+	if err != nil {
+		return result{}, err
+	}
+
+	if model.Spec.Resume {
+		if err := r.reconcileCheckpointsStatus(ctx, model, revision); err != nil {
+			return result{}, fmt.Errorf("reconciling checkpoints status: %w", err)
+		}
 	}
 
-	jobResult, err := reconcileJob(ctx, r.Client, modellerJob)
 	if !jobResult.success {
 		model.Status.Ready = false
 		if !jobResult.failure {
-			meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
-				Type:               apiv1.ConditionComplete,
-				Status:             metav1.ConditionFalse,
-				Reason:             apiv1.ReasonJobNotComplete,
-				ObservedGeneration: model.Generation,
-				Message:            "Waiting for modeller Job to complete",
-			})
+			pods, err := jobPods(ctx, r.Client, modellerJob)
+			if err != nil {
+				return result{}, err
+			}
+			if setUnschedulableCondition(model.GetConditions(), model.Generation, pods) && jobResult.RequeueAfter == 0 {
+				jobResult.RequeueAfter = requeueAfter(RequeueInterval)
+			}
+			if msg, crashing := podCrashMessage(pods); crashing {
+				// Report crashes before the Job exhausts its backoffLimit.
+				meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
+					Type:               apiv1.ConditionComplete,
+					Status:             metav1.ConditionFalse,
+					Reason:             apiv1.ReasonContainerCrashing,
+					ObservedGeneration: model.Generation,
+					Message:            msg,
+				})
+				if jobResult.RequeueAfter == 0 {
+					jobResult.RequeueAfter = requeueAfter(RequeueInterval)
+				}
+			} else {
+				meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
+					Type:               apiv1.ConditionComplete,
+					Status:             metav1.ConditionFalse,
+					Reason:             apiv1.ReasonJobNotComplete,
+					ObservedGeneration: model.Generation,
+					Message:            "Waiting for modeller Job to complete",
+				})
+			}
 		} else {
-			meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
-				Type:               apiv1.ConditionComplete,
-				Status:             metav1.ConditionFalse,
-				Reason:             apiv1.ReasonJobFailed,
-				ObservedGeneration: model.Generation,
-			})
+			observeJobFailed(model.Status.Conditions, apiv1.ConditionComplete, "Model", "run")
+			meta.SetStatusCondition(model.GetConditions(), jobFailedCondition(modellerJob, model.Generation))
 		}
 		if err := r.Status().Update(ctx, model); err != nil {
 			return result{}, fmt.Errorf("updating status: %w", err)
 		}
-		return jobResult, err
+		return jobResult, nil
+	}
+
+	if err := r.recordModelRevision(ctx, model, revision, modellerJob); err != nil {
+		return result{}, fmt.Errorf("recording revision: %w", err)
 	}
 
-	model.Status.Ready = true
 	meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
 		Type:               apiv1.ConditionComplete,
 		Status:             metav1.ConditionTrue,
 		Reason:             apiv1.ReasonJobComplete,
 		ObservedGeneration: model.Generation,
 	})
+
+	if model.Spec.Publish != nil {
+		publishResult, err := r.reconcilePublish(ctx, model)
+		if err != nil {
+			return result{}, fmt.Errorf("reconciling publish: %w", err)
+		}
+		if !publishResult.success {
+			model.Status.Ready = false
+			if err := r.Status().Update(ctx, model); err != nil {
+				return result{}, fmt.Errorf("updating status: %w", err)
+			}
+			return publishResult, nil
+		}
+	}
+
+	model.Status.Ready = true
 	if err := r.Status().Update(ctx, model); err != nil {
 		return result{}, fmt.Errorf("updating status: %w", err)
 	}
@@ -221,6 +332,7 @@ func (r *ModelReconciler) reconcileModel(ctx context.Context, model *apiv1.Model
 //+kubebuilder:rbac:groups=substratus.ai,resources=models/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=substratus.ai,resources=models/finalizers,verbs=update
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 
@@ -231,6 +343,8 @@ func (r *ModelReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Watches(&apiv1.Model{}, handler.EnqueueRequestsFromMapFunc(handler.MapFunc(r.findModelsForBaseModel))).
 		Watches(&apiv1.Dataset{}, handler.EnqueueRequestsFromMapFunc(handler.MapFunc(r.findModelsForDataset))).
 		Owns(&batchv1.Job{}).
+		Owns(&corev1.Service{}).
+		WithOptions(ctrlcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -239,8 +353,8 @@ func (r *ModelReconciler) findModelsForBaseModel(ctx context.Context, obj client
 
 	var models apiv1.ModelList
 	if err := r.List(ctx, &models,
-		client.MatchingFields{modelModelIndex: model.Name},
-		client.InNamespace(obj.GetNamespace()),
+		// Referencing Models can be in any namespace.
+		client.MatchingFields{modelModelIndex: client.ObjectKeyFromObject(model).String()},
 	); err != nil {
 		log.Log.Error(err, "unable to list models for base model")
 		return nil
@@ -263,8 +377,8 @@ func (r *ModelReconciler) findModelsForDataset(ctx context.Context, obj client.O
 
 	var models apiv1.ModelList
 	if err := r.List(ctx, &models,
-		client.MatchingFields{modelDatasetIndex: dataset.Name},
-		client.InNamespace(obj.GetNamespace()),
+		// Referencing Models can be in any namespace.
+		client.MatchingFields{modelDatasetIndex: client.ObjectKeyFromObject(dataset).String()},
 	); err != nil {
 		log.Log.Error(err, "unable to list models for dataset")
 		return nil
@@ -282,13 +396,59 @@ func (r *ModelReconciler) findModelsForDataset(ctx context.Context, obj client.O
 	return reqs
 }
 
-// modellerJob returns a Job that will train or load the Model.
-func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *apiv1.Model, dataset *apiv1.Dataset) (*batchv1.Job, error) {
+// modelCheckpointsDir is the directory (relative to the revision path and
+// the container workdir) where checkpoints are stored when Spec.Resume is set.
+const modelCheckpointsDir = "checkpoints"
+
+// reconcileCheckpointsStatus records changes to the checkpoints of the
+// revision being trained in the Model status.
+func (r *ModelReconciler) reconcileCheckpointsStatus(ctx context.Context, model *apiv1.Model, revision int64) error {
+	u := modelRevisionURL(r.Cloud, model, revision)
+	resp, err := r.SCI.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{
+		BucketName: u.Bucket,
+		Prefix:     filepath.Join(u.Path, modelCheckpointsDir),
+	})
+	if err != nil {
+		return fmt.Errorf("calling the sci service to GetPrefixChecksum: %w", err)
+	}
+
+	if model.Status.Checkpoints == nil {
+		model.Status.Checkpoints = &apiv1.CheckpointsStatus{}
+	}
+	cs := model.Status.Checkpoints
+	cs.URL = u.String() + "/" + modelCheckpointsDir
+	if resp.ObjectCount > 0 && resp.Checksum != cs.Checksum {
+		cs.LastCheckpointTime = ptr.To(metav1.Now())
+	}
+	cs.Checksum = resp.Checksum
+	cs.ObjectCount = resp.ObjectCount
+
+	return nil
+}
+
+// modellerJob returns a Job that will train or load the given revision of the
+// Model.
+func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *apiv1.Model, datasets []*apiv1.Dataset, revision int64) (*batchv1.Job, error) {
 	var job *batchv1.Job
 
 	envVars, err := resolveEnv(model.Spec.Env)
 	if err != nil {
-		return nil, fmt.Errorf("resolving env: %w", err)
+		return nil, terminal(fmt.Errorf("resolving env: %w", err))
+	}
+	params, err := paramsEnv(model.GetParams())
+	if err != nil {
+		return nil, terminal(fmt.Errorf("resolving params env: %w", err))
+	}
+	// Explicitly set env takes precedence over params.
+	envVars = append(params, envVars...)
+
+	image, command, args := model.GetImage(), model.Spec.Command, model.Spec.Args
+	if src := model.Spec.Source; src != nil && src.HuggingFace != nil {
+		var sourceEnv []corev1.EnvVar
+		image = defaultHuggingFaceLoaderImage
+		command, sourceEnv = huggingFaceLoader(src.HuggingFace, "model")
+		args = nil
+		envVars = append(sourceEnv, envVars...)
 	}
 
 	// Don't retry expensive Jobs by default.
@@ -305,13 +465,14 @@ func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *api
 	const containerName = "model"
 	job = &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: model.Name + "-modeller",
+			Name: modellerJobName(model),
 			// Cross-Namespace owners not allowed, must be same as model:
 			Namespace: model.Namespace,
 		},
 		Spec: batchv1.JobSpec{
 			// TODO: Allow for configurable retries for Jobs that import models...
-			BackoffLimit: ptr.To(backoffLimit),
+			BackoffLimit:          ptr.To(backoffLimit),
+			ActiveDeadlineSeconds: activeDeadlineSeconds(model.Spec.Timeout),
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: map[string]string{
@@ -327,12 +488,18 @@ func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *api
 						FSGroup: ptr.To(int64(3003)),
 					},
 					ServiceAccountName: modellerServiceAccountName,
+					PriorityClassName:  model.Spec.PriorityClassName,
 					Containers: []corev1.Container{
 						{
 							Name:    containerName,
-							Image:   model.GetImage(),
-							Command: model.Spec.Command,
+							Image:   image,
+							Command: command,
+							Args:    args,
 							Env:     envVars,
+							// Surface the tail of the logs in the Pod status
+							// when the container fails without writing a
+							// termination message.
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 						},
 					},
 					RestartPolicy: "Never",
@@ -341,31 +508,103 @@ func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *api
 		},
 	}
 
+	if nodes := modellerNodes(model); nodes > 1 {
+		// Pods of an Indexed Job get a stable hostname (<job-name>-<index>)
+		// which is resolvable through the headless Service set as subdomain.
+		job.Spec.CompletionMode = ptr.To(batchv1.IndexedCompletion)
+		job.Spec.Completions = ptr.To(nodes)
+		job.Spec.Parallelism = ptr.To(nodes)
+		job.Spec.Template.Spec.Subdomain = modellerJobName(model)
+		job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env,
+			corev1.EnvVar{
+				Name: "RANK",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "metadata.annotations['batch.kubernetes.io/job-completion-index']",
+					},
+				},
+			},
+			corev1.EnvVar{Name: "WORLD_SIZE", Value: strconv.Itoa(int(nodes))},
+			corev1.EnvVar{Name: "MASTER_ADDR", Value: fmt.Sprintf("%s-0.%s", modellerJobName(model), modellerJobName(model))},
+			corev1.EnvVar{Name: "MASTER_PORT", Value: strconv.Itoa(modellerDistributedPort)},
+		)
+		job.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+			{Name: "dist", ContainerPort: modellerDistributedPort, Protocol: corev1.ProtocolTCP},
+		}
+	}
+
+	artifactMounts := []cloud.BucketMount{
+		{BucketSubdir: "artifacts", ContentSubdir: "artifacts"},
+	}
+	if model.Spec.Resume {
+		artifactMounts = append(artifactMounts, cloud.BucketMount{BucketSubdir: modelCheckpointsDir, ContentSubdir: modelCheckpointsDir})
+		job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env,
+			corev1.EnvVar{Name: "CHECKPOINTS_DIR", Value: "/content/" + modelCheckpointsDir},
+		)
+		// Pods that are evicted/preempted are restarted without counting
+		// towards the backoff limit, training resumes from the last checkpoint.
+		job.Spec.PodFailurePolicy = &batchv1.PodFailurePolicy{
+			Rules: []batchv1.PodFailurePolicyRule{
+				{
+					Action: batchv1.PodFailurePolicyActionIgnore,
+					OnPodConditions: []batchv1.PodFailurePolicyOnPodConditionsPattern{
+						{Type: corev1.DisruptionTarget, Status: corev1.ConditionTrue},
+					},
+				},
+			},
+		}
+	}
+
 	if err := mountParamsConfigMap(&job.Spec.Template.Spec, model, containerName); err != nil {
 		return nil, fmt.Errorf("mounting params configmap: %w", err)
 	}
 
-	if err := r.Cloud.MountBucket(&job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, model, cloud.MountBucketConfig{
-		Name: "artifacts",
-		Mounts: []cloud.BucketMount{
-			{BucketSubdir: "artifacts", ContentSubdir: "artifacts"},
-		},
-		Container: containerName,
-		ReadOnly:  false,
+	// The status points at the latest revision (if any), the Job writes to
+	// the new revision.
+	target := model.DeepCopy()
+	target.Status.Artifacts.URL = modelRevisionURL(r.Cloud, model, revision).String()
+	if err := r.Cloud.MountBucket(&job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, target, cloud.MountBucketConfig{
+		Name:         "artifacts",
+		Mounts:       artifactMounts,
+		Container:    containerName,
+		ReadOnly:     false,
+		MountOptions: model.Spec.MountOptions,
 	}); err != nil {
 		return nil, fmt.Errorf("mounting model: %w", err)
 	}
 
-	if dataset != nil {
+	// Names of the Datasets by the suffix of their format env var.
+	datasetNames := map[string]string{}
+	for i, dataset := range datasets {
+		// A single Dataset is mounted at /content/data, multiple Datasets
+		// are mounted at /content/data/<name>.
+		name, contentSubdir := "dataset", "data"
+		if model.Spec.Dataset == nil {
+			name, contentSubdir = fmt.Sprintf("dataset-%d", i), "data/"+dataset.Name
+			suffix := envVarSuffix(dataset.Name)
+			if other, ok := datasetNames[suffix]; ok {
+				return nil, terminal(fmt.Errorf("datasets %q and %q would share the DATASET_FORMAT_%s env var or mount path (dataset names must be unique)", other, dataset.Name, suffix))
+			}
+			datasetNames[suffix] = dataset.Name
+		}
+		if format := dataset.Status.Format; format != "" {
+			envName := "DATASET_FORMAT"
+			if model.Spec.Dataset == nil {
+				envName += "_" + envVarSuffix(dataset.Name)
+			}
+			job.Spec.Template.Spec.Containers[0].Env = append(job.Spec.Template.Spec.Containers[0].Env,
+				corev1.EnvVar{Name: envName, Value: string(format)})
+		}
 		if err := r.Cloud.MountBucket(&job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, dataset, cloud.MountBucketConfig{
-			Name: "dataset",
+			Name: name,
 			Mounts: []cloud.BucketMount{
-				{BucketSubdir: "artifacts", ContentSubdir: "data"},
+				{BucketSubdir: "artifacts", ContentSubdir: contentSubdir},
 			},
-			Container: containerName,
-			ReadOnly:  true,
+			Container:    containerName,
+			ReadOnly:     true,
+			MountOptions: dataset.Spec.MountOptions,
 		}); err != nil {
-			return nil, fmt.Errorf("mounting dataset: %w", err)
+			return nil, fmt.Errorf("mounting dataset %q: %w", dataset.Name, err)
 		}
 	}
 
@@ -375,8 +614,9 @@ func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *api
 			Mounts: []cloud.BucketMount{
 				{BucketSubdir: "artifacts", ContentSubdir: "model"},
 			},
-			Container: containerName,
-			ReadOnly:  true,
+			Container:    containerName,
+			ReadOnly:     true,
+			MountOptions: baseModel.Spec.MountOptions,
 		}); err != nil {
 			return nil, fmt.Errorf("mounting base model: %w", err)
 		}
@@ -387,9 +627,99 @@ func (r *ModelReconciler) modellerJob(ctx context.Context, model, baseModel *api
 	}
 
 	if err := resources.Apply(&job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, containerName,
-		r.Cloud.Name(), model.Spec.Resources); err != nil {
-		return nil, fmt.Errorf("applying resources: %w", err)
+		r.Cloud.Name(), r.DefaultResources.Resolve(model.Spec.Resources)); err != nil {
+		return nil, terminal(fmt.Errorf("applying resources: %w", err))
+	}
+
+	if err := addInitContainers(&job.Spec.Template.Spec, containerName, model.Spec.InitContainers); err != nil {
+		return nil, fmt.Errorf("adding init containers: %w", err)
 	}
 
 	return job, nil
 }
+
+// modellerDistributedPort is the port used by the rank 0 Pod to coordinate
+// distributed training (torch.distributed default).
+const modellerDistributedPort = 29500
+
+// modelDatasetRefs returns the Datasets that a Model is trained on.
+func modelDatasetRefs(model *apiv1.Model) []apiv1.ObjectRef {
+	if model.Spec.Dataset != nil {
+		return []apiv1.ObjectRef{*model.Spec.Dataset}
+	}
+	return model.Spec.Datasets
+}
+
+// envVarSuffix converts a Kubernetes object name to an environment variable
+// suffix (i.e. "my-dataset" -> "MY_DATASET").
+// referenceNotAllowed reports that the Model references an object in another
+// namespace that does not allow it. Updating the annotations of the
+// referenced object triggers a new reconcile.
+func (r *ModelReconciler) referenceNotAllowed(ctx context.Context, model *apiv1.Model, kind string, obj client.Object) (result, error) {
+	model.Status.Ready = false
+	meta.SetStatusCondition(&model.Status.Conditions, metav1.Condition{
+		Type:               apiv1.ConditionComplete,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonReferenceNotAllowed,
+		ObservedGeneration: model.Generation,
+		Message: fmt.Sprintf("%s %q in namespace %q does not allow references from namespace %q (see the %s annotation)",
+			kind, obj.GetName(), obj.GetNamespace(), model.Namespace, apiv1.AllowedNamespacesAnnotation),
+	})
+	if err := r.Status().Update(ctx, model); err != nil {
+		return result{}, fmt.Errorf("failed to update model status: %w", err)
+	}
+	return result{}, nil
+}
+
+func envVarSuffix(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+func modellerJobName(model *apiv1.Model) string {
+	return model.Name + "-modeller"
+}
+
+func modellerNodes(model *apiv1.Model) int32 {
+	if model.Spec.Resources == nil || model.Spec.Resources.Nodes < 1 {
+		return 1
+	}
+	return model.Spec.Resources.Nodes
+}
+
+// modellerService returns a headless Service that provides DNS records for
+// the Pods of a distributed modeller Job.
+func (r *ModelReconciler) modellerService(model *apiv1.Model) (*corev1.Service, error) {
+	s := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      modellerJobName(model),
+			Namespace: model.Namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			// Ranks need to resolve each other before they report ready.
+			PublishNotReadyAddresses: true,
+			Selector: map[string]string{
+				"model": model.Name,
+				"role":  "run",
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "dist",
+					Protocol:   corev1.ProtocolTCP,
+					Port:       modellerDistributedPort,
+					TargetPort: intstr.FromString("dist"),
+				},
+			},
+		},
+	}
+
+	if err := ctrl.SetControllerReference(model, s, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	return s, nil
+}
@@ -8,11 +8,12 @@ import (
 	"github.com/stretchr/testify/require"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
 )
@@ -31,20 +32,313 @@ func TestModelLoaderFromGit(t *testing.T) {
 					URL: "https://test.internal/test/model-loader.git",
 				},
 			},
-			Params: map[string]intstr.IntOrString{
-				"s": intstr.FromString("something-model"),
-				"x": intstr.FromInt(456),
+			Params: map[string]apiextensionsv1.JSON{
+				"s": {Raw: []byte(`"something-model"`)},
+				"x": {Raw: []byte(`456`)},
+				"n": {Raw: []byte(`{"lr": 3e-5, "layers": [1, 2]}`)},
 			},
 		},
 	}
 	require.NoError(t, k8sClient.Create(ctx, model), "create a model that references a git repository")
 
 	testContainerBuild(t, model, "Model")
-	testParamsConfigMap(t, model, "Model", `{ "s": "something-model", "x": 456 }`)
+	testParamsConfigMap(t, model, "Model", `{ "s": "something-model", "x": 456, "n": {"lr": 3e-5, "layers": [1, 2]} }`)
 
 	testModelLoad(t, model)
 }
 
+func TestModelLoaderFromPrivateGit(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Build: &apiv1.Build{
+				Git: &apiv1.BuildGit{
+					URL:       "https://test.internal/test/private-model-loader.git",
+					SecretRef: &apiv1.ObjectRef{Name: "git-credentials"},
+				},
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model that references a private git repository")
+
+	var builderJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-model-bld"}, &builderJob)
+		assert.NoError(t, err, "getting the container builder job")
+	}, timeout, interval, "waiting for the container builder job to be created")
+
+	clone := builderJob.Spec.Template.Spec.InitContainers[0]
+	require.Equal(t, "git-clone", clone.Name)
+	require.Len(t, clone.Env, 2)
+	for _, env := range clone.Env {
+		require.NotNil(t, env.ValueFrom)
+		require.NotNil(t, env.ValueFrom.SecretKeyRef)
+		require.Equal(t, "git-credentials", env.ValueFrom.SecretKeyRef.Name)
+	}
+	require.NotContains(t, strings.Join(clone.Args, " "), "git-credentials")
+}
+
+func TestModelFromHuggingFace(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Source: &apiv1.ModelSource{
+				HuggingFace: &apiv1.HuggingFaceSource{
+					Repo:     "meta-llama/Llama-2-7b-hf",
+					Revision: "main",
+					TokenSecretRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "huggingface"},
+						Key:                  "token",
+					},
+				},
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model that is imported from huggingface")
+
+	var modellerJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: model.Name + "-modeller"}, &modellerJob)
+		assert.NoError(t, err, "getting the modeller job")
+	}, timeout, interval, "waiting for the modeller job to be created")
+
+	container := modellerJob.Spec.Template.Spec.Containers[0]
+	require.Equal(t, "python:3.11-slim", container.Image)
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "HF_REPO", Value: "meta-llama/Llama-2-7b-hf"})
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "HF_REPO_TYPE", Value: "model"})
+	require.Contains(t, container.Env, corev1.EnvVar{Name: "HF_TOKEN", ValueFrom: &corev1.EnvVarSource{
+		SecretKeyRef: model.Spec.Source.HuggingFace.TokenSecretRef,
+	}})
+
+	testModelLoad(t, model)
+}
+
+func TestModelDistributedTraining(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+			Resources: &apiv1.Resources{
+				CPU:    2,
+				Memory: 4,
+				Disk:   10,
+				Nodes:  3,
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model that is trained across multiple nodes")
+
+	var svc corev1.Service
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-modeller"}, &svc)
+		assert.NoError(t, err, "getting the modeller service")
+	}, timeout, interval, "waiting for the modeller service to be created")
+	require.Equal(t, corev1.ClusterIPNone, svc.Spec.ClusterIP)
+
+	var job batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-modeller"}, &job)
+		assert.NoError(t, err, "getting the modeller job")
+	}, timeout, interval, "waiting for the modeller job to be created")
+	require.Equal(t, batchv1.IndexedCompletion, *job.Spec.CompletionMode)
+	require.Equal(t, int32(3), *job.Spec.Completions)
+	require.Equal(t, int32(3), *job.Spec.Parallelism)
+	require.Equal(t, svc.Name, job.Spec.Template.Spec.Subdomain)
+
+	env := map[string]corev1.EnvVar{}
+	for _, e := range job.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	require.Equal(t, "3", env["WORLD_SIZE"].Value)
+	require.Equal(t, model.GetName()+"-modeller-0."+svc.Name, env["MASTER_ADDR"].Value)
+	require.NotNil(t, env["RANK"].ValueFrom)
+
+	fakeJobComplete(t, &job)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName()}, model)
+		assert.NoError(t, err, "getting model")
+		assert.True(t, model.Status.Ready)
+	}, timeout, interval, "waiting for the model to be ready")
+}
+
+func TestModelPublish(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+			Publish: &apiv1.ModelPublish{
+				Reference:  "registry.test.internal/models/" + name + ":v1",
+				SecretName: "registry-credentials",
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model that is published to a registry")
+
+	var modellerJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-modeller"}, &modellerJob)
+		assert.NoError(t, err, "getting the modeller job")
+	}, timeout, interval, "waiting for the modeller job to be created")
+	fakeJobComplete(t, &modellerJob)
+
+	var publishJob batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-publisher"}, &publishJob)
+		assert.NoError(t, err, "getting the publisher job")
+	}, timeout, interval, "waiting for the publisher job to be created")
+	require.Equal(t, "publish", publishJob.Spec.Template.Labels["role"])
+	require.Equal(t, model.Spec.Publish.Reference, publishJob.Spec.Template.Spec.Containers[0].Env[0].Value)
+	require.Contains(t, publishJob.Spec.Template.Spec.Containers[0].Command[2], "--registry-config")
+
+	require.NoError(t, k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName()}, model))
+	require.False(t, model.Status.Ready, "model should not be ready before it is published")
+
+	fakeJobComplete(t, &publishJob)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName()}, model)
+		assert.NoError(t, err, "getting model")
+		assert.True(t, model.Status.Ready)
+		assert.True(t, meta.IsStatusConditionTrue(model.Status.Conditions, apiv1.ConditionPublished))
+		if assert.NotNil(t, model.Status.Publish) {
+			assert.Equal(t, model.Spec.Publish.Reference, model.Status.Publish.Reference)
+		}
+	}, timeout, interval, "waiting for the model to be published")
+}
+
+func TestModelResume(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image:  ptr.To("some-test-image"),
+			Resume: true,
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model that resumes from checkpoints")
+
+	var job batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-modeller"}, &job)
+		assert.NoError(t, err, "getting the modeller job")
+	}, timeout, interval, "waiting for the modeller job to be created")
+
+	require.NotNil(t, job.Spec.PodFailurePolicy)
+	require.Equal(t, corev1.DisruptionTarget, job.Spec.PodFailurePolicy.Rules[0].OnPodConditions[0].Type)
+
+	container := job.Spec.Template.Spec.Containers[0]
+	var checkpointsDir string
+	for _, e := range container.Env {
+		if e.Name == "CHECKPOINTS_DIR" {
+			checkpointsDir = e.Value
+		}
+	}
+	require.Equal(t, "/content/checkpoints", checkpointsDir)
+
+	var mountPaths []string
+	for _, m := range container.VolumeMounts {
+		mountPaths = append(mountPaths, m.MountPath)
+	}
+	require.Contains(t, mountPaths, "/content/checkpoints")
+
+	fakeJobComplete(t, &job)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName()}, model)
+		assert.NoError(t, err, "getting model")
+		assert.True(t, model.Status.Ready)
+		if assert.NotNil(t, model.Status.Checkpoints) {
+			assert.Contains(t, model.Status.Checkpoints.URL, "/checkpoints")
+		}
+	}, timeout, interval, "waiting for the model to be ready")
+}
+
+func TestModelRevisions(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model")
+
+	t.Cleanup(debugObject(t, model))
+
+	testModelLoad(t, model)
+	require.Len(t, model.Status.Revisions, 1)
+	first := model.Status.Revisions[0]
+	require.Equal(t, int64(1), first.Revision)
+	require.True(t, strings.HasSuffix(first.URL, "/revisions/1"), first.URL)
+	require.Equal(t, first.URL, model.Status.Artifacts.URL)
+	require.Equal(t, "some-test-image", first.Image)
+
+	// Re-train with a new image.
+	model.Spec.Image = ptr.To("some-other-test-image")
+	require.NoError(t, k8sClient.Update(ctx, model), "updating the model image")
+	model.Status.Ready = false
+	require.NoError(t, k8sClient.Status().Update(ctx, model), "marking the model as not ready")
+
+	var job batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.GetNamespace(), Name: model.GetName() + "-modeller"}, &job)
+		if assert.NoError(t, err, "getting the modeller job") {
+			assert.Equal(t, "some-other-test-image", job.Spec.Template.Spec.Containers[0].Image)
+		}
+	}, timeout, interval, "waiting for the modeller job to be recreated")
+
+	var subPaths []string
+	for _, m := range job.Spec.Template.Spec.Containers[0].VolumeMounts {
+		subPaths = append(subPaths, m.SubPath)
+	}
+	require.Contains(t, strings.Join(subPaths, ","), "/revisions/2/artifacts", "the job should write the new revision")
+
+	// Until the new revision completes the previous one is served.
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(model), model))
+	require.Equal(t, first.URL, model.Status.Artifacts.URL)
+
+	fakeJobComplete(t, &job)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(model), model)
+		assert.NoError(t, err, "getting model")
+		assert.True(t, model.Status.Ready)
+		assert.Len(t, model.Status.Revisions, 2)
+	}, timeout, interval, "waiting for the second revision")
+	require.Equal(t, first, model.Status.Revisions[0], "previous revisions should not change")
+	require.True(t, strings.HasSuffix(model.Status.Artifacts.URL, "/revisions/2"), model.Status.Artifacts.URL)
+	require.Equal(t, "some-other-test-image", model.Status.Revisions[1].Image)
+}
+
 func testModelLoad(t *testing.T, model *apiv1.Model) {
 	// Test that a container loader Job gets created by the controller.
 	var loaderJob batchv1.Job
@@ -63,6 +357,9 @@ func testModelLoad(t *testing.T, model *apiv1.Model) {
 		assert.True(t, model.Status.Ready)
 	}, timeout, interval, "waiting for the model to be ready")
 	require.Contains(t, model.Status.Artifacts.URL, "gs://test-artifact-bucket")
+	require.Equal(t, int64(fakeArtifactsObjectCount), model.Status.Artifacts.ObjectCount)
+	require.Equal(t, int64(fakeArtifactsSizeBytes), model.Status.Artifacts.SizeBytes)
+	require.Equal(t, loaderJob.Name, model.Status.JobName)
 }
 
 func TestModelTrainerFromGit(t *testing.T) {
@@ -129,6 +426,161 @@ func TestModelTrainerFromGit(t *testing.T) {
 	testModelTrain(t, trainedModel)
 }
 
+func TestModelCrossNamespaceBaseModel(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	shared := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shared-models"}}
+	require.NoError(t, k8sClient.Create(ctx, shared), "create a namespace for shared models")
+
+	baseModel := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-base-mdl",
+			Namespace: shared.Name,
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, baseModel), "create a shared base model")
+	t.Cleanup(debugObject(t, baseModel))
+
+	trainedModel := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-trained-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+			Model: &apiv1.ObjectRef{
+				Name:      baseModel.Name,
+				Namespace: shared.Name,
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, trainedModel), "create a model that references a base model in another namespace")
+	t.Cleanup(debugObject(t, trainedModel))
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(trainedModel), trainedModel)
+		assert.NoError(t, err, "getting the trained model")
+		if c := meta.FindStatusCondition(trainedModel.Status.Conditions, apiv1.ConditionComplete); assert.NotNil(t, c) {
+			assert.Equal(t, apiv1.ReasonReferenceNotAllowed, c.Reason)
+		}
+	}, timeout, interval, "waiting for the trained model to report the reference that is not allowed")
+
+	// Allow the default namespace to reference the base Model.
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(baseModel), baseModel))
+	baseModel.Annotations = map[string]string{apiv1.AllowedNamespacesAnnotation: "other, default"}
+	require.NoError(t, k8sClient.Update(ctx, baseModel), "allow references from the default namespace")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(trainedModel), trainedModel)
+		assert.NoError(t, err, "getting the trained model")
+		if c := meta.FindStatusCondition(trainedModel.Status.Conditions, apiv1.ConditionComplete); assert.NotNil(t, c) {
+			assert.Equal(t, apiv1.ReasonBaseModelNotReady, c.Reason)
+		}
+	}, timeout, interval, "waiting for the trained model to wait on the base model")
+
+	// The trained Model is requeued once the base Model in the other
+	// namespace becomes ready.
+	testModelLoad(t, baseModel)
+	testModelLoad(t, trainedModel)
+}
+
+func TestModelMultipleDatasets(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	var datasets []*apiv1.Dataset
+	for _, suffix := range []string{"a", "b"} {
+		dataset := &apiv1.Dataset{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-ds-" + suffix,
+				Namespace: "default",
+			},
+			Spec: apiv1.DatasetSpec{
+				Image:  ptr.To("some-image"),
+				Format: "jsonl",
+			},
+		}
+		require.NoError(t, k8sClient.Create(ctx, dataset), "create a dataset to be referenced by the model")
+		datasets = append(datasets, dataset)
+	}
+	testDatasetLoad(t, datasets[0])
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+			Datasets: []apiv1.ObjectRef{
+				{Name: datasets[0].Name},
+				{Name: datasets[1].Name},
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model that is trained on multiple datasets")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: model.Name}, model)
+		assert.NoError(t, err, "getting the model")
+		c := meta.FindStatusCondition(model.Status.Conditions, apiv1.ConditionComplete)
+		if assert.NotNil(t, c) {
+			assert.Equal(t, apiv1.ReasonDatasetNotReady, c.Reason)
+			assert.Contains(t, c.Message, datasets[1].Name)
+			assert.NotContains(t, c.Message, datasets[0].Name)
+		}
+	}, timeout, interval, "waiting for the model to report the dataset that is not ready")
+
+	testDatasetLoad(t, datasets[1])
+
+	var job batchv1.Job
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: model.Namespace, Name: model.Name + "-modeller"}, &job)
+		assert.NoError(t, err, "getting the modeller job")
+	}, timeout, interval, "waiting for the modeller job to be created")
+
+	mountPaths := map[string]bool{}
+	for _, m := range job.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mountPaths[m.MountPath] = true
+	}
+	require.True(t, mountPaths["/content/data/"+datasets[0].Name])
+	require.True(t, mountPaths["/content/data/"+datasets[1].Name])
+	require.Contains(t, job.Spec.Template.Spec.Containers[0].Env, corev1.EnvVar{
+		Name:  "DATASET_FORMAT_" + strings.ToUpper(strings.ReplaceAll(datasets[0].Name, "-", "_")),
+		Value: "jsonl",
+	})
+
+	invalid := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-invalid-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image:    ptr.To("some-test-image"),
+			Dataset:  &apiv1.ObjectRef{Name: datasets[0].Name},
+			Datasets: []apiv1.ObjectRef{{Name: datasets[1].Name}},
+		},
+	}
+	require.Error(t, k8sClient.Create(ctx, invalid), "dataset and datasets should be mutually exclusive")
+
+	duplicate := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-duplicate-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-test-image"),
+			Datasets: []apiv1.ObjectRef{
+				{Name: datasets[0].Name},
+				{Name: datasets[0].Name, Namespace: "other"},
+			},
+		},
+	}
+	require.Error(t, k8sClient.Create(ctx, duplicate), "datasets should have unique names")
+}
+
 func testModelTrain(t *testing.T, model *apiv1.Model) {
 	// Test that a model trainer ServiceAccount gets created by the controller.
 	var sa corev1.ServiceAccount
@@ -156,4 +608,5 @@ func testModelTrain(t *testing.T, model *apiv1.Model) {
 		assert.True(t, model.Status.Ready)
 	}, timeout, interval, "waiting for the model to be ready")
 	require.Contains(t, model.Status.Artifacts.URL, "gs://test-artifact-bucket")
+	require.Equal(t, job.Name, model.Status.JobName)
 }
@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+const (
+	defaultPublishImage = "ghcr.io/oras-project/oras:v1.1.0"
+
+	// modelArtifactType is the OCI artifact type that Models are pushed as.
+	modelArtifactType = "application/vnd.substratus.model.v1"
+
+	publishRegistryConfigDir = "/registry"
+)
+
+// reconcilePublish pushes the Model artifacts to the registry configured in
+// Spec.Publish once the modeller Job has completed. The Published condition
+// and the publish status are set but not persisted, unless the publisher Job
+// can not be constructed.
+func (r *ModelReconciler) reconcilePublish(ctx context.Context, model *apiv1.Model) (result, error) {
+	job, err := r.publishJob(model)
+	if err != nil {
+		return reconcileFailed(ctx, r.Client, model, apiv1.ConditionPublished, fmt.Errorf("constructing publisher Job: %w", err))
+	}
+
+	jobResult, err := reconcileJob(ctx, r.Client, job, "Model")
+	if err != nil {
+		return result{}, err
+	}
+
+	if jobResult.failure {
+		observeJobFailed(model.Status.Conditions, apiv1.ConditionPublished, "Model", "publish")
+		meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
+			Type:               apiv1.ConditionPublished,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonJobFailed,
+			ObservedGeneration: model.Generation,
+		})
+		return jobResult, nil
+	}
+	if !jobResult.success {
+		meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
+			Type:               apiv1.ConditionPublished,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonJobNotComplete,
+			ObservedGeneration: model.Generation,
+			Message:            fmt.Sprintf("Waiting for publisher Job to push %s", model.Spec.Publish.Reference),
+		})
+		return jobResult, nil
+	}
+
+	pods, err := jobPods(ctx, r.Client, job)
+	if err != nil {
+		return result{}, err
+	}
+	model.Status.Publish = &apiv1.PublishStatus{
+		Reference: model.Spec.Publish.Reference,
+		Digest:    publishedDigest(pods),
+	}
+	meta.SetStatusCondition(model.GetConditions(), metav1.Condition{
+		Type:               apiv1.ConditionPublished,
+		Status:             metav1.ConditionTrue,
+		Reason:             apiv1.ReasonJobComplete,
+		ObservedGeneration: model.Generation,
+	})
+
+	return result{success: true}, nil
+}
+
+// publishJob returns a Job that pushes the Model artifacts as an OCI artifact.
+func (r *ModelReconciler) publishJob(model *apiv1.Model) (*batchv1.Job, error) {
+	publish := model.Spec.Publish
+	if publish.Reference == "" {
+		return nil, terminal(fmt.Errorf("publish reference is required"))
+	}
+
+	image := defaultPublishImage
+	if publish.Image != nil {
+		image = *publish.Image
+	}
+
+	push := `oras push`
+	if publish.SecretName != "" {
+		push += ` --registry-config ` + publishRegistryConfigDir + `/config.json`
+	}
+	push += ` --artifact-type ` + modelArtifactType + ` "$REFERENCE" .`
+	// The digest is reported through the termination message so that it
+	// can be recorded in the Model status.
+	script := `set -eo pipefail
+` + push + ` | tee /tmp/push.log
+grep '^Digest:' /tmp/push.log | cut -d' ' -f2 > /dev/termination-log
+`
+
+	const containerName = "publish"
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      publisherJobName(model),
+			Namespace: model.Namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: ptr.To(int32(2)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						"kubectl.kubernetes.io/default-container": containerName,
+					},
+					Labels: map[string]string{
+						"model": model.Name,
+						"role":  "publish",
+					},
+				},
+				Spec: corev1.PodSpec{
+					SecurityContext: &corev1.PodSecurityContext{
+						FSGroup: ptr.To(int64(3003)),
+					},
+					ServiceAccountName: modellerServiceAccountName,
+					Containers: []corev1.Container{
+						{
+							Name:       containerName,
+							Image:      image,
+							Command:    []string{"sh", "-c", script},
+							WorkingDir: "/content/artifacts",
+							Env: []corev1.EnvVar{
+								{Name: "REFERENCE", Value: publish.Reference},
+							},
+						},
+					},
+					RestartPolicy: "Never",
+				},
+			},
+		},
+	}
+
+	if publish.SecretName != "" {
+		job.Spec.Template.Spec.Volumes = append(job.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: "registry",
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: publish.SecretName,
+					Items: []corev1.KeyToPath{
+						{Key: corev1.DockerConfigJsonKey, Path: "config.json"},
+					},
+				},
+			},
+		})
+		job.Spec.Template.Spec.Containers[0].VolumeMounts = append(job.Spec.Template.Spec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "registry",
+			MountPath: publishRegistryConfigDir,
+			ReadOnly:  true,
+		})
+	}
+
+	if err := r.Cloud.MountBucket(&job.Spec.Template.ObjectMeta, &job.Spec.Template.Spec, model, cloud.MountBucketConfig{
+		Name: "artifacts",
+		Mounts: []cloud.BucketMount{
+			{BucketSubdir: "artifacts", ContentSubdir: "artifacts"},
+		},
+		Container:    containerName,
+		ReadOnly:     true,
+		MountOptions: model.Spec.MountOptions,
+	}); err != nil {
+		return nil, fmt.Errorf("mounting model: %w", err)
+	}
+
+	if err := controllerutil.SetControllerReference(model, job, r.Scheme); err != nil {
+		return nil, fmt.Errorf("setting owner reference: %w", err)
+	}
+
+	return job, nil
+}
+
+func publisherJobName(model *apiv1.Model) string {
+	return model.Name + "-publisher"
+}
+
+// publishedDigest returns the digest that a succeeded publisher Pod reported
+// in its termination message.
+func publishedDigest(pods []corev1.Pod) string {
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		for _, cs := range pod.Status.ContainerStatuses {
+			if cs.State.Terminated == nil {
+				continue
+			}
+			if d := strings.TrimSpace(cs.State.Terminated.Message); strings.HasPrefix(d, "sha256:") {
+				return d
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+// modelRevisionsDir is the directory (relative to the Model bucket path)
+// that revisions of the Model artifacts are written to.
+const modelRevisionsDir = "revisions"
+
+// modelRevisionURL returns the bucket prefix of a revision of the Model.
+func modelRevisionURL(c cloud.Cloud, model *apiv1.Model, revision int64) *cloud.BucketURL {
+	u := c.ObjectArtifactURL(model)
+	u.Path = filepath.Join(u.Path, modelRevisionsDir, strconv.FormatInt(revision, 10))
+	return u
+}
+
+// modellerRevision returns the revision that the modeller Job writes and the
+// Job. A new revision is started unless the existing Job wrote the latest
+// revision with the current spec (i.e. while the Model is being published).
+func (r *ModelReconciler) modellerRevision(ctx context.Context, model, baseModel *apiv1.Model, datasets []*apiv1.Dataset) (int64, *batchv1.Job, error) {
+	latest := model.LatestRevision()
+	if latest == nil {
+		job, err := r.modellerJob(ctx, model, baseModel, datasets, 1)
+		return 1, job, err
+	}
+
+	next, err := r.modellerJob(ctx, model, baseModel, datasets, latest.Revision+1)
+	if err != nil {
+		return 0, nil, err
+	}
+	var existing batchv1.Job
+	if err := r.Get(ctx, client.ObjectKeyFromObject(next), &existing); err != nil {
+		if apierrors.IsNotFound(err) {
+			return latest.Revision + 1, next, nil
+		}
+		return 0, nil, fmt.Errorf("getting modeller Job: %w", err)
+	}
+
+	current, err := r.modellerJob(ctx, model, baseModel, datasets, latest.Revision)
+	if err != nil {
+		return 0, nil, err
+	}
+	hash, err := jobSpecHash(current)
+	if err != nil {
+		return 0, nil, fmt.Errorf("hashing Job spec: %w", err)
+	}
+	if existing.Annotations[specHashAnnotation] == hash {
+		return latest.Revision, current, nil
+	}
+	return latest.Revision + 1, next, nil
+}
+
+// recordModelRevision adds the revision written by the completed modeller
+// Job to the Model status and points the artifacts status at it. Recorded
+// revisions are not changed.
+func (r *ModelReconciler) recordModelRevision(ctx context.Context, model *apiv1.Model, revision int64, job *batchv1.Job) error {
+	if latest := model.LatestRevision(); latest != nil && latest.Revision >= revision {
+		return nil
+	}
+
+	u := modelRevisionURL(r.Cloud, model, revision)
+	resp, err := r.SCI.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{
+		BucketName: u.Bucket,
+		Prefix:     filepath.Join(u.Path, "artifacts"),
+	})
+	if err != nil {
+		return fmt.Errorf("calling the sci service to GetPrefixChecksum: %w", err)
+	}
+
+	completionTime := job.Status.CompletionTime
+	if completionTime == nil {
+		completionTime = ptr.To(metav1.Now())
+	}
+	model.Status.Revisions = append(model.Status.Revisions, apiv1.ModelRevision{
+		Revision:       revision,
+		URL:            u.String(),
+		Checksum:       resp.Checksum,
+		ObjectCount:    resp.ObjectCount,
+		SizeBytes:      resp.TotalBytes,
+		Image:          model.GetImage(),
+		CompletionTime: completionTime,
+	})
+
+	model.Status.Artifacts.URL = u.String()
+	model.Status.Artifacts.Checksum = resp.Checksum
+	model.Status.Artifacts.ObjectCount = resp.ObjectCount
+	model.Status.Artifacts.SizeBytes = resp.TotalBytes
+
+	return nil
+}
+
+// servedModelRevision returns the revision of the Model that the Server
+// serves: the pinned revision or the latest one. The revision is nil for
+// Models that were trained before revisions and not found when the pinned
+// revision does not exist.
+func servedModelRevision(server *apiv1.Server, model *apiv1.Model) (*apiv1.ModelRevision, bool) {
+	ref := server.Spec.Model
+	if !ref.Pinned() {
+		return model.LatestRevision(), true
+	}
+	for i := len(model.Status.Revisions) - 1; i >= 0; i-- {
+		rev := &model.Status.Revisions[i]
+		if (ref.Revision != 0 && rev.Revision == ref.Revision) ||
+			(ref.Checksum != "" && rev.Checksum == ref.Checksum) {
+			return rev, true
+		}
+	}
+	return nil, false
+}
+
+// describeModelPin describes the pinned revision for messages.
+func describeModelPin(ref apiv1.ServerModelRef) string {
+	var pins []string
+	if ref.Revision != 0 {
+		pins = append(pins, strconv.FormatInt(ref.Revision, 10))
+	}
+	if ref.Checksum != "" {
+		pins = append(pins, "with checksum "+ref.Checksum)
+	}
+	return strings.Join(pins, " ")
+}
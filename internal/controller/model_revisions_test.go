@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+func Test_modellerJobRevision(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+	r := &ModelReconciler{
+		Scheme: scheme,
+		Cloud: &cloud.GCP{Common: cloud.Common{
+			ArtifactBucketURL: &cloud.BucketURL{Scheme: "gs", Bucket: "artifacts"},
+		}},
+	}
+	model := &apiv1.Model{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Model"},
+		ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default"},
+		Spec:       apiv1.ModelSpec{Image: ptr.To("trainer"), Resume: true},
+	}
+	base := modelRevisionURL(r.Cloud, model, 2)
+	model.Status.Revisions = []apiv1.ModelRevision{{Revision: 1}, {Revision: 2, URL: base.String()}}
+	model.Status.Artifacts.URL = base.String()
+	require.Equal(t, int64(2), model.LatestRevision().Revision)
+
+	job, err := r.modellerJob(context.Background(), model, nil, nil, 3)
+	require.NoError(t, err)
+
+	revision := modelRevisionURL(r.Cloud, model, 3)
+	subPaths := map[string]string{}
+	for _, m := range job.Spec.Template.Spec.Containers[0].VolumeMounts {
+		subPaths[m.MountPath] = m.SubPath
+	}
+	require.Equal(t, revision.Path+"/artifacts", subPaths["/content/artifacts"])
+	require.Equal(t, revision.Path+"/"+modelCheckpointsDir, subPaths["/content/"+modelCheckpointsDir])
+	require.Equal(t, base.String(), model.Status.Artifacts.URL, "the status should not be changed")
+}
+
+func Test_servedModelRevision(t *testing.T) {
+	model := &apiv1.Model{Status: apiv1.ModelStatus{Revisions: []apiv1.ModelRevision{
+		{Revision: 1, Checksum: "a"},
+		{Revision: 2, Checksum: "b"},
+		{Revision: 3, Checksum: "a"},
+	}}}
+	server := func(ref apiv1.ServerModelRef) *apiv1.Server {
+		return &apiv1.Server{Spec: apiv1.ServerSpec{Model: ref}}
+	}
+
+	cases := []struct {
+		name     string
+		ref      apiv1.ServerModelRef
+		expected int64
+		found    bool
+	}{
+		{"latest", apiv1.ServerModelRef{}, 3, true},
+		{"revision", apiv1.ServerModelRef{Revision: 2}, 2, true},
+		{"latest with checksum", apiv1.ServerModelRef{Checksum: "a"}, 3, true},
+		{"missing revision", apiv1.ServerModelRef{Revision: 4}, 0, false},
+		{"missing checksum", apiv1.ServerModelRef{Checksum: "c"}, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rev, found := servedModelRevision(server(c.ref), model)
+			require.Equal(t, c.found, found)
+			if c.found {
+				require.Equal(t, c.expected, rev.Revision)
+			} else {
+				require.Nil(t, rev)
+			}
+		})
+	}
+
+	rev, found := servedModelRevision(server(apiv1.ServerModelRef{}), &apiv1.Model{})
+	require.True(t, found, "models trained before revisions are served")
+	require.Nil(t, rev)
+}
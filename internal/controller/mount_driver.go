@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+// mountDriverCheckInterval is how often objects that are waiting for the
+// mount driver to be installed are requeued.
+const mountDriverCheckInterval = time.Minute
+
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=csidrivers,verbs=get;list;watch
+
+// reconcileMountDriver guards against creating Pods that mount buckets when
+// the CSI driver of the cloud is not installed in the cluster. On GKE the
+// gcsfuse sidecar is not injected without the driver and loaders would write
+// to an empty volume instead of the bucket, reporting success without any
+// artifacts. The given condition type is set to MountDriverNotInstalled until
+// the driver is installed.
+func reconcileMountDriver(ctx context.Context, c client.Client, cld cloud.Cloud, obj statusObject, conditionType string) (result, error) {
+	driver := cld.MountDriver()
+	if driver == "" {
+		return result{success: true}, nil
+	}
+
+	var csiDriver storagev1.CSIDriver
+	err := c.Get(ctx, client.ObjectKey{Name: driver}, &csiDriver)
+	if err == nil {
+		return result{success: true}, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return result{}, fmt.Errorf("getting csi driver: %w", err)
+	}
+
+	msg := fmt.Sprintf("The %s CSI driver is not installed in the cluster, buckets can not be mounted.", driver)
+	if driver == cloud.GCSFuseCSIDriverName {
+		msg += " Enable the Cloud Storage FUSE CSI driver of the GKE cluster: gcloud container clusters update CLUSTER_NAME --update-addons GcsFuseCsiDriver=ENABLED"
+	}
+	obj.SetStatusReady(false)
+	meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonMountDriverNotInstalled,
+		ObservedGeneration: obj.GetGeneration(),
+		Message:            msg,
+	})
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return result{}, fmt.Errorf("updating status: %w", err)
+	}
+	// The driver can be enabled without touching the object.
+	return result{Result: ctrl.Result{RequeueAfter: requeueAfter(mountDriverCheckInterval)}}, nil
+}
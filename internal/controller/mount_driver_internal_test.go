@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+func Test_reconcileMountDriver(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	dataset := &apiv1.Dataset{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Dataset"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "squad", Generation: 2},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(dataset).WithStatusSubresource(dataset).Build()
+	ctx := context.Background()
+
+	// Buckets are not mounted with a CSI driver on kind.
+	res, err := reconcileMountDriver(ctx, c, &cloud.Kind{}, dataset, apiv1.ConditionComplete)
+	require.NoError(t, err)
+	require.True(t, res.success)
+
+	gcp := &cloud.GCP{}
+	res, err = reconcileMountDriver(ctx, c, gcp, dataset, apiv1.ConditionComplete)
+	require.NoError(t, err)
+	require.False(t, res.success)
+	require.NotZero(t, res.RequeueAfter, "the driver can be installed without an event for the Dataset")
+
+	var updated apiv1.Dataset
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(dataset), &updated))
+	require.False(t, updated.Status.Ready)
+	cond := meta.FindStatusCondition(updated.Status.Conditions, apiv1.ConditionComplete)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, apiv1.ReasonMountDriverNotInstalled, cond.Reason)
+	require.Equal(t, int64(2), cond.ObservedGeneration)
+	require.Contains(t, cond.Message, "GcsFuseCsiDriver=ENABLED")
+
+	require.NoError(t, c.Create(ctx, &storagev1.CSIDriver{ObjectMeta: metav1.ObjectMeta{Name: gcp.MountDriver()}}))
+	res, err = reconcileMountDriver(ctx, c, gcp, &updated, apiv1.ConditionComplete)
+	require.NoError(t, err)
+	require.True(t, res.success)
+}
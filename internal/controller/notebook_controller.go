@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -33,9 +34,22 @@ type NotebookReconciler struct {
 	SCI   sci.ControllerClient
 
 	*ParamsReconciler
+
+	// ResourcePressure is optional, when set the ResourcePressure condition
+	// is reported for the Notebook Pod.
+	ResourcePressure *ResourcePressureMonitor
+
+	// DefaultResources is optional, when set it fills in resources that
+	// are omitted.
+	DefaultResources *resources.Defaults
+
+	MaxConcurrentReconciles int
 }
 
 func (r *NotebookReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Reconcile Notebook", "Notebook", req)
+	defer span.End()
+
 	log := log.FromContext(ctx)
 
 	log.Info("Reconciling Notebook")
@@ -46,6 +60,10 @@ func (r *NotebookReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if result, err := reconcileCloudConfig(ctx, r.Client, r.Cloud, &notebook, apiv1.ConditionServing); !result.success {
+		return result.Result, err
+	}
+
 	if notebook.GetImage() == "" {
 		// Image must be building.
 		return ctrl.Result{}, nil
@@ -55,11 +73,8 @@ func (r *NotebookReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		return result.Result, err
 	}
 
-	if result, err := r.reconcileNotebook(ctx, &notebook); !result.success {
-		return result.Result, err
-	}
-
-	return ctrl.Result{}, nil
+	result, err := r.reconcileNotebook(ctx, &notebook)
+	return result.Result, err
 }
 
 //+kubebuilder:rbac:groups=substratus.ai,resources=notebooks,verbs=get;list;watch;create;update;patch;delete
@@ -77,6 +92,7 @@ func (r *NotebookReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Pod{}).
 		Watches(&apiv1.Model{}, handler.EnqueueRequestsFromMapFunc(handler.MapFunc(r.findNotebooksForModel))).
 		Watches(&apiv1.Dataset{}, handler.EnqueueRequestsFromMapFunc(handler.MapFunc(r.findNotebooksForDataset))).
+		WithOptions(ctrlcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -190,7 +206,27 @@ func (r *NotebookReconciler) reconcileNotebook(ctx context.Context, notebook *ap
 			return result{}, fmt.Errorf("getting model: %w", err)
 		}
 
-		if !model.Status.Ready {
+		if rev := notebook.Spec.ModelRevision; rev != 0 {
+			revision := model.Revision(rev)
+			if revision == nil {
+				notebook.Status.Ready = false
+				meta.SetStatusCondition(&notebook.Status.Conditions, metav1.Condition{
+					Type:               apiv1.ConditionServing,
+					Status:             metav1.ConditionFalse,
+					Reason:             apiv1.ReasonModelRevisionNotFound,
+					ObservedGeneration: notebook.Generation,
+					Message:            fmt.Sprintf("Model %q has no revision %d", model.Name, rev),
+				})
+				if err := r.Status().Update(ctx, notebook); err != nil {
+					return result{}, fmt.Errorf("failed to update notebook status: %w", err)
+				}
+
+				// Allow for watch to requeue.
+				return result{}, nil
+			}
+			// A pinned revision is mounted while the Model is re-trained.
+			model.Status.Artifacts.URL = revision.URL
+		} else if !model.Status.Ready {
 			log.Info("Model not ready", "model", model.Name)
 
 			notebook.Status.Ready = false
@@ -259,9 +295,13 @@ func (r *NotebookReconciler) reconcileNotebook(ctx context.Context, notebook *ap
 	//	return result{}, fmt.Errorf("failed to apply pvc: %w", err)
 	//}
 
+	if result, err := reconcileMountDriver(ctx, r.Client, r.Cloud, notebook, apiv1.ConditionServing); !result.success {
+		return result, err
+	}
+
 	pod, err := r.notebookPod(notebook, model, dataset)
 	if err != nil {
-		return result{}, fmt.Errorf("failed to construct pod: %w", err)
+		return reconcileFailed(ctx, r.Client, notebook, apiv1.ConditionServing, fmt.Errorf("failed to construct pod: %w", err))
 	}
 	if err := r.Patch(ctx, pod, client.Apply, client.FieldOwner("notebook-controller"), client.ForceOwnership); err != nil {
 		// If attempt to change an immutable field will result in a Invalid
@@ -300,13 +340,24 @@ func (r *NotebookReconciler) reconcileNotebook(ctx context.Context, notebook *ap
 			Status:             metav1.ConditionFalse,
 			Reason:             apiv1.ReasonPodNotReady,
 			ObservedGeneration: notebook.Generation,
+			Message:            podNotReadyMessage(pod),
 		})
 	}
+	// Pods are watched, no need to requeue.
+	setUnschedulableCondition(&notebook.Status.Conditions, notebook.Generation, []corev1.Pod{*pod})
+
+	var res result
+	if r.ResourcePressure != nil && notebook.Status.Ready {
+		r.ResourcePressure.SetCondition(ctx, &notebook.Status.Conditions, notebook.Generation, []corev1.Pod{*pod})
+		res.RequeueAfter = r.ResourcePressure.Interval
+	}
+
 	if err := r.Status().Update(ctx, notebook); err != nil {
 		return result{}, fmt.Errorf("updating notebook status: %w", err)
 	}
 
-	return result{success: true}, nil
+	res.success = true
+	return res, nil
 }
 
 func nbPodName(nb *apiv1.Notebook) string {
@@ -330,8 +381,14 @@ func (r *NotebookReconciler) notebookPod(notebook *apiv1.Notebook, model *apiv1.
 
 	env, err := resolveEnv(notebook.Spec.Env)
 	if err != nil {
-		return nil, fmt.Errorf("resolving env: %w", err)
+		return nil, terminal(fmt.Errorf("resolving env: %w", err))
+	}
+	params, err := paramsEnv(notebook.GetParams())
+	if err != nil {
+		return nil, terminal(fmt.Errorf("resolving params env: %w", err))
 	}
+	// Explicitly set env takes precedence over params.
+	env = append(params, env...)
 	env = append(env, corev1.EnvVar{Name: "NOTEBOOK_TOKEN", Value: "default"})
 
 	pod := &corev1.Pod{
@@ -357,6 +414,7 @@ func (r *NotebookReconciler) notebookPod(notebook *apiv1.Notebook, model *apiv1.
 			//	FSGroup:    int64Ptr(100),
 			//},
 			ServiceAccountName: notebookServiceAccountName,
+			PriorityClassName:  notebook.Spec.PriorityClassName,
 			Containers: []corev1.Container{
 				{
 					Name:    containerName,
@@ -411,8 +469,9 @@ func (r *NotebookReconciler) notebookPod(notebook *apiv1.Notebook, model *apiv1.
 			Mounts: []cloud.BucketMount{
 				{BucketSubdir: "artifacts", ContentSubdir: "data"},
 			},
-			Container: containerName,
-			ReadOnly:  true,
+			Container:    containerName,
+			ReadOnly:     true,
+			MountOptions: dataset.Spec.MountOptions,
 		}); err != nil {
 			return nil, fmt.Errorf("mounting dataset: %w", err)
 		}
@@ -424,8 +483,9 @@ func (r *NotebookReconciler) notebookPod(notebook *apiv1.Notebook, model *apiv1.
 			Mounts: []cloud.BucketMount{
 				{BucketSubdir: "artifacts", ContentSubdir: "model"},
 			},
-			Container: containerName,
-			ReadOnly:  true,
+			Container:    containerName,
+			ReadOnly:     true,
+			MountOptions: model.Spec.MountOptions,
 		}); err != nil {
 			return nil, fmt.Errorf("mounting model: %w", err)
 		}
@@ -446,8 +506,8 @@ func (r *NotebookReconciler) notebookPod(notebook *apiv1.Notebook, model *apiv1.
 	}
 
 	if err := resources.Apply(&pod.ObjectMeta, &pod.Spec, containerName,
-		r.Cloud.Name(), notebook.Spec.Resources); err != nil {
-		return nil, fmt.Errorf("applying resources: %w", err)
+		r.Cloud.Name(), r.DefaultResources.Resolve(notebook.Spec.Resources)); err != nil {
+		return nil, terminal(fmt.Errorf("applying resources: %w", err))
 	}
 
 	return pod, nil
@@ -7,10 +7,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -49,9 +49,9 @@ func TestNotebookFromGitWithModelOnly(t *testing.T) {
 			Model: &apiv1.ObjectRef{
 				Name: model.Name,
 			},
-			Params: map[string]intstr.IntOrString{
-				"s": intstr.FromString("something-notebook"),
-				"x": intstr.FromInt(789),
+			Params: map[string]apiextensionsv1.JSON{
+				"s": {Raw: []byte(`"something-notebook"`)},
+				"x": {Raw: []byte(`789`)},
 			},
 		},
 	}
@@ -70,6 +70,27 @@ func TestNotebookFromGitWithModelOnly(t *testing.T) {
 	require.Equal(t, "notebook", pod.Spec.Containers[0].Name)
 	require.Contains(t, strings.Join(pod.Spec.Containers[0].Command, " "), "notebook.sh")
 
+	pinned := &apiv1.Notebook{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pinned-nb",
+			Namespace: "default",
+		},
+		Spec: apiv1.NotebookSpec{
+			Image:         ptr.To("some-image"),
+			Model:         &apiv1.ObjectRef{Name: model.Name},
+			ModelRevision: 99,
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, pinned), "creating a notebook that pins a model revision")
+	t.Cleanup(debugObject(t, pinned))
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(pinned), pinned)
+		assert.NoError(t, err, "getting the pinned notebook")
+		if c := meta.FindStatusCondition(pinned.Status.Conditions, apiv1.ConditionServing); assert.NotNil(t, c) {
+			assert.Equal(t, apiv1.ReasonModelRevisionNotFound, c.Reason)
+		}
+	}, timeout, interval, "waiting for the notebook to report the missing model revision")
+
 	fakePodReady(t, &pod)
 	t.Cleanup(debugObject(t, &pod))
 
@@ -79,4 +100,19 @@ func TestNotebookFromGitWithModelOnly(t *testing.T) {
 		assert.True(t, meta.IsStatusConditionTrue(notebook.Status.Conditions, apiv1.ConditionServing))
 		assert.True(t, notebook.Status.Ready)
 	}, timeout, interval, "waiting for the notebook to be ready")
+
+	// Test that Ready is unset when the container stops being ready.
+	require.NoError(t, k8sClient.Get(ctx, client.ObjectKeyFromObject(&pod), &pod))
+	fakePodNotReady(t, &pod, "containers with unready status: [notebook]")
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(notebook), notebook)
+		assert.NoError(t, err, "getting the notebook")
+		assert.False(t, notebook.Status.Ready)
+		cond := meta.FindStatusCondition(notebook.Status.Conditions, apiv1.ConditionServing)
+		if assert.NotNil(t, cond) {
+			assert.Equal(t, apiv1.ReasonPodNotReady, cond.Reason)
+			assert.Contains(t, cond.Message, "containers with unready status: [notebook]")
+		}
+	}, timeout, interval, "waiting for the notebook to not be ready")
 }
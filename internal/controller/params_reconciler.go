@@ -1,15 +1,17 @@
 package controller
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -17,7 +19,7 @@ import (
 
 type ParameterizedObject interface {
 	client.Object
-	GetParams() map[string]intstr.IntOrString
+	GetParams() map[string]apiextensionsv1.JSON
 }
 
 type ParamsReconciler struct {
@@ -102,3 +104,49 @@ func mountParamsConfigMap(podSpec *corev1.PodSpec, obj ParameterizedObject, cont
 
 	return fmt.Errorf("container not found: %s", container)
 }
+
+// paramsEnv converts params to PARAM_<KEY> environment variables.
+// Strings are passed through unquoted, numbers and booleans keep their
+// JSON representation (e.g. 3e-05, true) and objects/lists are passed as
+// compact JSON.
+func paramsEnv(params map[string]apiextensionsv1.JSON) ([]corev1.EnvVar, error) {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	envs := make([]corev1.EnvVar, 0, len(keys))
+	for _, k := range keys {
+		val, err := paramEnvValue(params[k].Raw)
+		if err != nil {
+			return nil, fmt.Errorf("param %q: %w", k, err)
+		}
+		envs = append(envs, corev1.EnvVar{Name: "PARAM_" + strings.ToUpper(k), Value: val})
+	}
+	return envs, nil
+}
+
+func paramEnvValue(raw []byte) (string, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", fmt.Errorf("invalid json: %w", err)
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, float64:
+		// Use the original text to avoid reformatting numbers
+		// (i.e. 3e-5 vs 0.00003 or 1000000 vs 1e+06).
+		return strings.TrimSpace(string(raw)), nil
+	default:
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return "", fmt.Errorf("compacting json: %w", err)
+		}
+		return buf.String(), nil
+	}
+}
@@ -0,0 +1,34 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func Test_paramsEnv(t *testing.T) {
+	envs, err := paramsEnv(map[string]apiextensionsv1.JSON{
+		"name":          {Raw: []byte(`"facebook/opt-125m"`)},
+		"epochs":        {Raw: []byte(`3`)},
+		"learning_rate": {Raw: []byte(`3e-5`)},
+		"big":           {Raw: []byte(`1000000`)},
+		"fp16":          {Raw: []byte(`true`)},
+		"none":          {Raw: []byte(`null`)},
+		"optimizer":     {Raw: []byte(`{ "name": "adamw", "betas": [0.9, 0.999] }`)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []corev1.EnvVar{
+		{Name: "PARAM_BIG", Value: "1000000"},
+		{Name: "PARAM_EPOCHS", Value: "3"},
+		{Name: "PARAM_FP16", Value: "true"},
+		{Name: "PARAM_LEARNING_RATE", Value: "3e-5"},
+		{Name: "PARAM_NAME", Value: "facebook/opt-125m"},
+		{Name: "PARAM_NONE", Value: ""},
+		{Name: "PARAM_OPTIMIZER", Value: `{"name":"adamw","betas":[0.9,0.999]}`},
+	}, envs)
+
+	_, err = paramsEnv(map[string]apiextensionsv1.JSON{"bad": {Raw: []byte(`{`)}})
+	require.Error(t, err)
+}
@@ -0,0 +1,384 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/expfmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// PodUsage is the current resource usage of a Pod, summed over its containers.
+type PodUsage struct {
+	CPUNanoCores          uint64
+	MemoryBytes           uint64
+	EphemeralStorageBytes uint64
+
+	// GPUs is the number of GPUs that GPU usage was read for (0 if unknown).
+	GPUs int
+	// GPUUtilization is the average utilization of the GPUs as a percentage.
+	GPUUtilization      float64
+	GPUMemoryUsedBytes  uint64
+	GPUMemoryTotalBytes uint64
+}
+
+// PodMetricsReader reads the current resource usage of a Pod.
+type PodMetricsReader interface {
+	PodUsage(ctx context.Context, pod *corev1.Pod) (PodUsage, error)
+}
+
+// KubeletSummaryReader reads Pod usage from the kubelet summary API
+// (proxied through the Kubernetes API server).
+type KubeletSummaryReader struct {
+	Client kubernetes.Interface
+}
+
+//+kubebuilder:rbac:groups="",resources=nodes/proxy,verbs=get
+
+func (r *KubeletSummaryReader) PodUsage(ctx context.Context, pod *corev1.Pod) (PodUsage, error) {
+	if pod.Spec.NodeName == "" {
+		return PodUsage{}, fmt.Errorf("pod is not scheduled")
+	}
+
+	raw, err := r.Client.CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", pod.Spec.NodeName, "proxy", "stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return PodUsage{}, fmt.Errorf("getting kubelet summary: %w", err)
+	}
+
+	// Only the fields that are used from the kubelet stats/summary API.
+	var summary struct {
+		Pods []struct {
+			PodRef struct {
+				UID string `json:"uid"`
+			} `json:"podRef"`
+			CPU *struct {
+				UsageNanoCores *uint64 `json:"usageNanoCores"`
+			} `json:"cpu"`
+			Memory *struct {
+				WorkingSetBytes *uint64 `json:"workingSetBytes"`
+			} `json:"memory"`
+			EphemeralStorage *struct {
+				UsedBytes *uint64 `json:"usedBytes"`
+			} `json:"ephemeral-storage"`
+		} `json:"pods"`
+	}
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return PodUsage{}, fmt.Errorf("decoding kubelet summary: %w", err)
+	}
+
+	for _, p := range summary.Pods {
+		if p.PodRef.UID != string(pod.UID) {
+			continue
+		}
+		var usage PodUsage
+		if p.CPU != nil && p.CPU.UsageNanoCores != nil {
+			usage.CPUNanoCores = *p.CPU.UsageNanoCores
+		}
+		if p.Memory != nil && p.Memory.WorkingSetBytes != nil {
+			usage.MemoryBytes = *p.Memory.WorkingSetBytes
+		}
+		if p.EphemeralStorage != nil && p.EphemeralStorage.UsedBytes != nil {
+			usage.EphemeralStorageBytes = *p.EphemeralStorage.UsedBytes
+		}
+		return usage, nil
+	}
+
+	return PodUsage{}, fmt.Errorf("pod not found in kubelet summary")
+}
+
+// DCGMExporterReader adds the GPU usage, read from the NVIDIA DCGM exporter
+// running on the Pod's node, to the usage read by Reader. The exporter must
+// run with Kubernetes Pod attribution enabled (the default) for usage to be
+// mapped to Pods.
+type DCGMExporterReader struct {
+	Reader PodMetricsReader
+	Client kubernetes.Interface
+
+	// Namespace and LabelSelector select the DCGM exporter Pods.
+	Namespace     string
+	LabelSelector string
+	// Port is the port that the exporter serves metrics on.
+	Port int
+}
+
+//+kubebuilder:rbac:groups="",resources=pods/proxy,verbs=get
+
+// PodUsage returns the usage read by Reader. GPU usage is only added for Pods
+// that request GPUs, errors reading it are logged so that CPU and memory
+// usage is still reported.
+func (r *DCGMExporterReader) PodUsage(ctx context.Context, pod *corev1.Pod) (PodUsage, error) {
+	usage, err := r.Reader.PodUsage(ctx, pod)
+	if err != nil || !podRequestsGPUs(pod) {
+		return usage, err
+	}
+
+	if err := r.addGPUUsage(ctx, pod, &usage); err != nil {
+		log.FromContext(ctx).Error(err, "unable to read pod gpu usage", "pod", pod.Name)
+	}
+	return usage, nil
+}
+
+func (r *DCGMExporterReader) addGPUUsage(ctx context.Context, pod *corev1.Pod, usage *PodUsage) error {
+	exporters, err := r.Client.CoreV1().Pods(r.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: r.LabelSelector,
+		FieldSelector: "spec.nodeName=" + pod.Spec.NodeName,
+	})
+	if err != nil {
+		return fmt.Errorf("listing dcgm exporters: %w", err)
+	}
+	if len(exporters.Items) == 0 {
+		return fmt.Errorf("no dcgm exporter found on node %q", pod.Spec.NodeName)
+	}
+
+	raw, err := r.Client.CoreV1().Pods(r.Namespace).
+		ProxyGet("http", exporters.Items[0].Name, strconv.Itoa(r.Port), "/metrics", nil).
+		DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("getting dcgm exporter metrics: %w", err)
+	}
+
+	return parseDCGMMetrics(raw, pod, usage)
+}
+
+// parseDCGMMetrics adds the usage of the GPUs attributed to the Pod in the
+// given DCGM exporter metrics (Prometheus text format).
+func parseDCGMMetrics(raw []byte, pod *corev1.Pod, usage *PodUsage) error {
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("parsing dcgm exporter metrics: %w", err)
+	}
+
+	// Sums over the GPUs of the Pod, framebuffer metrics are in MiB.
+	sum := func(name string) (total float64, n int) {
+		family, ok := families[name]
+		if !ok {
+			return 0, 0
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["namespace"] != pod.Namespace || labels["pod"] != pod.Name {
+				continue
+			}
+			total += m.GetGauge().GetValue()
+			n++
+		}
+		return total, n
+	}
+
+	used, gpus := sum("DCGM_FI_DEV_FB_USED")
+	if gpus == 0 {
+		return fmt.Errorf("no gpu usage found for pod")
+	}
+	free, _ := sum("DCGM_FI_DEV_FB_FREE")
+	util, utilGPUs := sum("DCGM_FI_DEV_GPU_UTIL")
+
+	const mib = 1024 * 1024
+	usage.GPUs = gpus
+	usage.GPUMemoryUsedBytes = uint64(used * mib)
+	usage.GPUMemoryTotalBytes = uint64((used + free) * mib)
+	if utilGPUs > 0 {
+		usage.GPUUtilization = util / float64(utilGPUs)
+	}
+	return nil
+}
+
+// ResourcePressureMonitor samples the usage of Pods and reports whether
+// usage has been above a threshold (fraction of limits) for a sustained
+// window of time. Samples are only taken when reconciling, so the
+// reconcilers requeue every Interval while the monitor is enabled.
+type ResourcePressureMonitor struct {
+	Reader PodMetricsReader
+
+	// Threshold is the fraction of the limit (or request if no limit is set)
+	// above which a resource is considered under pressure.
+	Threshold float64
+	// Window is the duration that usage must stay above Threshold.
+	Window time.Duration
+	// Interval is how often to sample usage.
+	Interval time.Duration
+
+	mtx     sync.Mutex
+	samples map[types.UID][]usageSample
+
+	// now can be overridden in tests.
+	now func() time.Time
+}
+
+type usageSample struct {
+	time time.Time
+	// fractions of the limits (negative if unknown)
+	cpu, memory float64
+	// fraction of the GPU memory (negative if unknown)
+	gpuMemory float64
+}
+
+func (m *ResourcePressureMonitor) timeNow() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+	return time.Now()
+}
+
+// record stores a usage sample for the given Pod and returns the samples
+// that are relevant to the current window.
+func (m *ResourcePressureMonitor) record(pod *corev1.Pod, usage PodUsage) []usageSample {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	if m.samples == nil {
+		m.samples = map[types.UID][]usageSample{}
+	}
+
+	now := m.timeNow()
+	cpuLimit, memLimit := podLimits(pod)
+	s := usageSample{time: now, cpu: -1, memory: -1, gpuMemory: -1}
+	if cpuLimit > 0 {
+		s.cpu = float64(usage.CPUNanoCores) / float64(cpuLimit*1000*1000)
+	}
+	if memLimit > 0 {
+		s.memory = float64(usage.MemoryBytes) / float64(memLimit)
+	}
+	if usage.GPUMemoryTotalBytes > 0 {
+		s.gpuMemory = float64(usage.GPUMemoryUsedBytes) / float64(usage.GPUMemoryTotalBytes)
+	}
+
+	samples := append(m.samples[pod.UID], s)
+	// Keep a single sample from before the window to know whether the
+	// window has been fully observed.
+	start := now.Add(-m.Window)
+	for len(samples) > 1 && !samples[1].time.After(start) {
+		samples = samples[1:]
+	}
+	m.samples[pod.UID] = samples
+
+	// Forget about Pods that have not been sampled in a while.
+	for uid, ss := range m.samples {
+		if ss[len(ss)-1].time.Before(now.Add(-2 * m.Window)) {
+			delete(m.samples, uid)
+		}
+	}
+
+	return samples
+}
+
+// pressure returns the names of resources that have been above the threshold
+// for the whole window.
+func (m *ResourcePressureMonitor) pressure(samples []usageSample) []string {
+	if len(samples) == 0 || samples[0].time.After(m.timeNow().Add(-m.Window)) {
+		// Not enough history.
+		return nil
+	}
+
+	cpu, memory, gpuMemory := true, true, true
+	for _, s := range samples {
+		cpu = cpu && s.cpu >= m.Threshold
+		memory = memory && s.memory >= m.Threshold
+		gpuMemory = gpuMemory && s.gpuMemory >= m.Threshold
+	}
+
+	var names []string
+	if cpu {
+		names = append(names, "cpu")
+	}
+	if memory {
+		names = append(names, "memory")
+	}
+	if gpuMemory {
+		names = append(names, "gpu memory")
+	}
+	return names
+}
+
+// SetCondition samples the given Pods and sets the ResourcePressure condition.
+// Metric read errors are logged and do not fail the reconcile.
+func (m *ResourcePressureMonitor) SetCondition(ctx context.Context, conditions *[]metav1.Condition, generation int64, pods []corev1.Pod) {
+	log := log.FromContext(ctx)
+
+	pressured := map[string][]string{}
+	for i := range pods {
+		pod := &pods[i]
+		usage, err := m.Reader.PodUsage(ctx, pod)
+		if err != nil {
+			log.Error(err, "unable to read pod usage", "pod", pod.Name)
+			continue
+		}
+		if names := m.pressure(m.record(pod, usage)); len(names) > 0 {
+			pressured[pod.Name] = names
+		}
+	}
+
+	if len(pressured) == 0 {
+		meta.SetStatusCondition(conditions, metav1.Condition{
+			Type:               apiv1.ConditionResourcePressure,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonResourceUsageNormal,
+			ObservedGeneration: generation,
+		})
+		return
+	}
+
+	var msgs []string
+	for i := range pods {
+		if names, ok := pressured[pods[i].Name]; ok {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", pods[i].Name, strings.Join(names, ", ")))
+		}
+	}
+	meta.SetStatusCondition(conditions, metav1.Condition{
+		Type:               apiv1.ConditionResourcePressure,
+		Status:             metav1.ConditionTrue,
+		Reason:             apiv1.ReasonResourceUsageHigh,
+		ObservedGeneration: generation,
+		Message: fmt.Sprintf("Usage above %.0f%% of limits for %v (%s), consider increasing resources",
+			m.Threshold*100, m.Window, strings.Join(msgs, "; ")),
+	})
+}
+
+// podLimits returns the summed CPU (millicores) and memory (bytes) limits of
+// the Pod's containers, falling back to requests when limits are not set.
+func podLimits(pod *corev1.Pod) (cpuMilli, memBytes int64) {
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Limits[corev1.ResourceCPU]; ok {
+			cpuMilli += q.MilliValue()
+		} else if q, ok := c.Resources.Requests[corev1.ResourceCPU]; ok {
+			cpuMilli += q.MilliValue()
+		}
+		if q, ok := c.Resources.Limits[corev1.ResourceMemory]; ok {
+			memBytes += q.Value()
+		} else if q, ok := c.Resources.Requests[corev1.ResourceMemory]; ok {
+			memBytes += q.Value()
+		}
+	}
+	return
+}
+
+// podRequestsGPUs returns true if any of the Pod's containers requests an
+// NVIDIA GPU resource (whole GPUs or MIG slices).
+func podRequestsGPUs(pod *corev1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		for name := range c.Resources.Limits {
+			if strings.HasPrefix(string(name), "nvidia.com/") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+type fakePodMetricsReader struct {
+	usage PodUsage
+}
+
+func (f *fakePodMetricsReader) PodUsage(ctx context.Context, pod *corev1.Pod) (PodUsage, error) {
+	return f.usage, nil
+}
+
+func TestResourcePressureMonitor(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "abc"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("1"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+			}},
+		},
+	}
+
+	now := time.Now()
+	reader := &fakePodMetricsReader{}
+	m := &ResourcePressureMonitor{
+		Reader:    reader,
+		Threshold: 0.9,
+		Window:    10 * time.Minute,
+		Interval:  time.Minute,
+		now:       func() time.Time { return now },
+	}
+
+	var conds []metav1.Condition
+	sample := func(cpuNanoCores, memBytes uint64) *metav1.Condition {
+		reader.usage = PodUsage{CPUNanoCores: cpuNanoCores, MemoryBytes: memBytes}
+		m.SetCondition(context.Background(), &conds, 1, []corev1.Pod{pod})
+		now = now.Add(m.Interval)
+		return meta.FindStatusCondition(conds, apiv1.ConditionResourcePressure)
+	}
+
+	// High memory usage, but not for the whole window yet.
+	for i := 0; i < 10; i++ {
+		c := sample(100*1000*1000, 1000*1024*1024)
+		require.Equal(t, metav1.ConditionFalse, c.Status)
+	}
+
+	// Window has now been fully observed.
+	c := sample(100*1000*1000, 1000*1024*1024)
+	require.Equal(t, metav1.ConditionTrue, c.Status)
+	require.Equal(t, apiv1.ReasonResourceUsageHigh, c.Reason)
+	require.Contains(t, c.Message, "pod: memory")
+
+	// A single low sample resets the pressure.
+	c = sample(100*1000*1000, 100*1024*1024)
+	require.Equal(t, metav1.ConditionFalse, c.Status)
+
+	// CPU falls back to requests when no limit is set.
+	for i := 0; i < 11; i++ {
+		c = sample(950*1000*1000, 100*1024*1024)
+	}
+	require.Equal(t, metav1.ConditionTrue, c.Status)
+	require.Contains(t, c.Message, "pod: cpu")
+}
+
+func TestResourcePressureMonitorGPUMemory(t *testing.T) {
+	pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", UID: "abc"}}
+
+	now := time.Now()
+	reader := &fakePodMetricsReader{}
+	m := &ResourcePressureMonitor{
+		Reader:    reader,
+		Threshold: 0.9,
+		Window:    10 * time.Minute,
+		Interval:  time.Minute,
+		now:       func() time.Time { return now },
+	}
+
+	var c *metav1.Condition
+	var conds []metav1.Condition
+	reader.usage = PodUsage{GPUs: 1, GPUMemoryUsedBytes: 23 << 30, GPUMemoryTotalBytes: 24 << 30}
+	for i := 0; i < 11; i++ {
+		m.SetCondition(context.Background(), &conds, 1, []corev1.Pod{pod})
+		now = now.Add(m.Interval)
+		c = meta.FindStatusCondition(conds, apiv1.ConditionResourcePressure)
+	}
+	require.Equal(t, metav1.ConditionTrue, c.Status)
+	require.Contains(t, c.Message, "pod: gpu memory")
+}
+
+func TestParseDCGMMetrics(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nb-notebook"}}
+	raw := []byte(`# HELP DCGM_FI_DEV_FB_USED Framebuffer memory used (in MiB).
+# TYPE DCGM_FI_DEV_FB_USED gauge
+DCGM_FI_DEV_FB_USED{gpu="0",namespace="default",pod="nb-notebook"} 1024
+DCGM_FI_DEV_FB_USED{gpu="1",namespace="default",pod="nb-notebook"} 3072
+DCGM_FI_DEV_FB_USED{gpu="2",namespace="default",pod="other"} 100
+# HELP DCGM_FI_DEV_FB_FREE Framebuffer memory free (in MiB).
+# TYPE DCGM_FI_DEV_FB_FREE gauge
+DCGM_FI_DEV_FB_FREE{gpu="0",namespace="default",pod="nb-notebook"} 1024
+DCGM_FI_DEV_FB_FREE{gpu="1",namespace="default",pod="nb-notebook"} 1024
+DCGM_FI_DEV_FB_FREE{gpu="2",namespace="default",pod="other"} 100
+# HELP DCGM_FI_DEV_GPU_UTIL GPU utilization (in %).
+# TYPE DCGM_FI_DEV_GPU_UTIL gauge
+DCGM_FI_DEV_GPU_UTIL{gpu="0",namespace="default",pod="nb-notebook"} 40
+DCGM_FI_DEV_GPU_UTIL{gpu="1",namespace="default",pod="nb-notebook"} 80
+DCGM_FI_DEV_GPU_UTIL{gpu="2",namespace="default",pod="other"} 100
+`)
+
+	var usage PodUsage
+	require.NoError(t, parseDCGMMetrics(raw, pod, &usage))
+	require.Equal(t, PodUsage{
+		GPUs:                2,
+		GPUUtilization:      60,
+		GPUMemoryUsedBytes:  4 << 30,
+		GPUMemoryTotalBytes: 6 << 30,
+	}, usage)
+
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "nb-notebook"}}
+	require.Error(t, parseDCGMMetrics(raw, other, &PodUsage{}))
+}
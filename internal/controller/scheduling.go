@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// RequeueInterval is the base interval that unschedulable or crashing Pods
+// are re-checked at (jittered, see requeueAfter). Pods are not watched by
+// all reconcilers so a Pod being scheduled would otherwise go unnoticed
+// until the next change to the Job. It should only be changed before the
+// manager is started.
+var RequeueInterval = 30 * time.Second
+
+// setUnschedulableCondition reports Pods that the scheduler was not able to
+// place. The condition is only flipped back to False once it was set, to
+// avoid adding noise to objects that never had scheduling issues. Returns
+// true if any of the Pods is unschedulable.
+func setUnschedulableCondition(conds *[]metav1.Condition, generation int64, pods []corev1.Pod) bool {
+	for _, pod := range pods {
+		for _, c := range pod.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse && c.Reason == corev1.PodReasonUnschedulable {
+				meta.SetStatusCondition(conds, metav1.Condition{
+					Type:               apiv1.ConditionUnschedulable,
+					Status:             metav1.ConditionTrue,
+					Reason:             apiv1.ReasonPodUnschedulable,
+					ObservedGeneration: generation,
+					Message:            fmt.Sprintf("Pod %s: %s", pod.Name, c.Message),
+				})
+				return true
+			}
+		}
+	}
+
+	if meta.FindStatusCondition(*conds, apiv1.ConditionUnschedulable) != nil {
+		meta.SetStatusCondition(conds, metav1.Condition{
+			Type:               apiv1.ConditionUnschedulable,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonPodsScheduled,
+			ObservedGeneration: generation,
+		})
+	}
+	return false
+}
+
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+
+// jobPods lists the Pods that were created for a Job.
+func jobPods(ctx context.Context, c client.Client, job *batchv1.Job) ([]corev1.Pod, error) {
+	var pods corev1.PodList
+	if err := c.List(ctx, &pods, client.InNamespace(job.Namespace),
+		client.MatchingLabels{"job-name": job.Name}); err != nil {
+		return nil, fmt.Errorf("listing job pods: %w", err)
+	}
+	return pods.Items, nil
+}
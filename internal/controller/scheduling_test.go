@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func Test_setUnschedulableCondition(t *testing.T) {
+	scheduled := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "scheduled"},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+		}},
+	}
+	unschedulable := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pending"},
+		Status: corev1.PodStatus{Conditions: []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Reason:  corev1.PodReasonUnschedulable,
+			Message: "0/3 nodes are available: 3 Insufficient nvidia.com/gpu.",
+		}}},
+	}
+
+	var conds []metav1.Condition
+	require.False(t, setUnschedulableCondition(&conds, 1, []corev1.Pod{scheduled}))
+	require.Empty(t, conds, "condition should not be added when nothing was ever unschedulable")
+
+	require.True(t, setUnschedulableCondition(&conds, 1, []corev1.Pod{scheduled, unschedulable}))
+	c := meta.FindStatusCondition(conds, apiv1.ConditionUnschedulable)
+	require.Equal(t, metav1.ConditionTrue, c.Status)
+	require.Equal(t, "Pod pending: 0/3 nodes are available: 3 Insufficient nvidia.com/gpu.", c.Message)
+
+	require.False(t, setUnschedulableCondition(&conds, 2, []corev1.Pod{scheduled}))
+	c = meta.FindStatusCondition(conds, apiv1.ConditionUnschedulable)
+	require.Equal(t, metav1.ConditionFalse, c.Status)
+	require.Equal(t, apiv1.ReasonPodsScheduled, c.Reason)
+}
@@ -3,19 +3,28 @@ package controller
 import (
 	"context"
 	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlcontroller "sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -36,6 +45,16 @@ type ServerReconciler struct {
 
 	*ParamsReconciler
 
+	// ResourcePressure is optional, when set the ResourcePressure condition
+	// is reported for the Server Pods.
+	ResourcePressure *ResourcePressureMonitor
+
+	// DefaultResources is optional, when set it fills in resources that
+	// are omitted.
+	DefaultResources *resources.Defaults
+
+	MaxConcurrentReconciles int
+
 	// log should be used outside the context of Reconcile()
 	log logr.Logger
 }
@@ -45,9 +64,15 @@ type ServerReconciler struct {
 //+kubebuilder:rbac:groups=substratus.ai,resources=servers/finalizers,verbs=update
 //+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 
 func (r *ServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := startReconcileSpan(ctx, "Reconcile Server", "Server", req)
+	defer span.End()
+
 	log := log.FromContext(ctx)
 
 	log.Info("Reconciling Server")
@@ -58,6 +83,10 @@ func (r *ServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if result, err := reconcileCloudConfig(ctx, r.Client, r.Cloud, &server, apiv1.ConditionServing); !result.success {
+		return result.Result, err
+	}
+
 	if server.GetImage() == "" {
 		// Image must be building.
 		return ctrl.Result{}, nil
@@ -67,11 +96,8 @@ func (r *ServerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return result.Result, err
 	}
 
-	if result, err := r.reconcileServer(ctx, &server); !result.success {
-		return result.Result, err
-	}
-
-	return ctrl.Result{}, nil
+	result, err := r.reconcileServer(ctx, &server)
+	return result.Result, err
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -84,6 +110,9 @@ func (r *ServerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&batchv1.Job{}).
+		Owns(&autoscalingv2.HorizontalPodAutoscaler{}).
+		Owns(&networkingv1.Ingress{}).
+		WithOptions(ctrlcontroller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -112,12 +141,33 @@ func (r *ServerReconciler) findServersForModel(ctx context.Context, obj client.O
 }
 
 func (r *ServerReconciler) serverDeployment(server *apiv1.Server, model *apiv1.Model) (*appsv1.Deployment, error) {
-	replicas := int32(1)
+	// When autoscaling, replicas are left unset so that the HPA
+	// is the only manager of the field.
+	var replicas *int32
+	if server.Spec.Autoscaling == nil {
+		replicas = ptr.To(int32(1))
+		if server.Spec.Replicas != nil {
+			replicas = ptr.To(*server.Spec.Replicas)
+		}
+	}
+
+	var minReadySeconds int32
+	if server.Spec.Strategy != nil {
+		minReadySeconds = server.Spec.Strategy.MinReadySeconds
+	}
 
 	envVars, err := resolveEnv(server.Spec.Env)
 	if err != nil {
-		return nil, fmt.Errorf("resolving env: %w", err)
+		return nil, terminal(fmt.Errorf("resolving env: %w", err))
+	}
+	params, err := paramsEnv(server.GetParams())
+	if err != nil {
+		return nil, terminal(fmt.Errorf("resolving params env: %w", err))
 	}
+	// Explicitly set env takes precedence over params.
+	envVars = append(params, envVars...)
+	port := server.GetPort()
+	envVars = append([]corev1.EnvVar{{Name: "PORT", Value: strconv.Itoa(int(port))}}, envVars...)
 
 	const containerName = "serve"
 	deploy := &appsv1.Deployment{
@@ -130,8 +180,9 @@ func (r *ServerReconciler) serverDeployment(server *apiv1.Server, model *apiv1.M
 			Namespace: server.Namespace,
 		},
 		Spec: appsv1.DeploymentSpec{
-			// TODO: HPA?
-			Replicas: &replicas,
+			Replicas:        replicas,
+			Strategy:        serverDeploymentStrategy(server.Spec.Strategy),
+			MinReadySeconds: minReadySeconds,
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					"server": server.Name,
@@ -146,30 +197,28 @@ func (r *ServerReconciler) serverDeployment(server *apiv1.Server, model *apiv1.M
 				},
 				Spec: corev1.PodSpec{
 					ServiceAccountName: modelServerServiceAccountName,
+					PriorityClassName:  server.Spec.PriorityClassName,
 					Containers: []corev1.Container{
 						{
 							Name:            containerName,
 							Image:           server.GetImage(),
 							ImagePullPolicy: "Always",
 							Command:         server.Spec.Command,
+							Args:            server.Spec.Args,
 							Env:             envVars,
 							Ports: []corev1.ContainerPort{
 								{
 									Name:          modelServerHTTPServePortName,
-									ContainerPort: 8080,
-								},
-							},
-							ReadinessProbe: &corev1.Probe{
-								ProbeHandler: corev1.ProbeHandler{
-									HTTPGet: &corev1.HTTPGetAction{
-										// TBD: Not sure if this should ever be something we configure via
-										// the Server API. For now, we'll just hardcode it and add it to the container
-										// contract.
-										Path: "/",
-										Port: intstr.FromString(modelServerHTTPServePortName),
-									},
+									ContainerPort: port,
 								},
 							},
+							ReadinessProbe: serverProbe(server.Spec.ReadinessProbe, &corev1.Probe{}),
+							StartupProbe: serverProbe(server.Spec.StartupProbe, &corev1.Probe{
+								// Large models can take a long time to load,
+								// allow up to 30 minutes before the kubelet restarts the container.
+								PeriodSeconds:    10,
+								FailureThreshold: 180,
+							}),
 						},
 					},
 				},
@@ -186,8 +235,9 @@ func (r *ServerReconciler) serverDeployment(server *apiv1.Server, model *apiv1.M
 		Mounts: []cloud.BucketMount{
 			{BucketSubdir: "artifacts", ContentSubdir: "model"},
 		},
-		Container: containerName,
-		ReadOnly:  true,
+		Container:    containerName,
+		ReadOnly:     true,
+		MountOptions: model.Spec.MountOptions,
 	}); err != nil {
 		return nil, fmt.Errorf("mounting model: %w", err)
 	}
@@ -197,8 +247,12 @@ func (r *ServerReconciler) serverDeployment(server *apiv1.Server, model *apiv1.M
 	}
 
 	if err := resources.Apply(&deploy.Spec.Template.ObjectMeta, &deploy.Spec.Template.Spec, containerName,
-		r.Cloud.Name(), server.Spec.Resources); err != nil {
-		return nil, fmt.Errorf("applying resources: %w", err)
+		r.Cloud.Name(), r.DefaultResources.Resolve(server.Spec.Resources)); err != nil {
+		return nil, terminal(fmt.Errorf("applying resources: %w", err))
+	}
+
+	if err := addSidecars(&deploy.Spec.Template.Spec, containerName, server.Spec.Sidecars); err != nil {
+		return nil, fmt.Errorf("adding sidecars: %w", err)
 	}
 
 	return deploy, nil
@@ -228,7 +282,44 @@ func (r *ServerReconciler) reconcileServer(ctx context.Context, server *apiv1.Se
 		return result{}, fmt.Errorf("getting model: %w", err)
 	}
 
-	if !model.Status.Ready {
+	revision, found := servedModelRevision(server, &model)
+	if !found {
+		server.Status.Ready = false
+		meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
+			Type:               apiv1.ConditionServing,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonModelRevisionNotFound,
+			ObservedGeneration: server.Generation,
+			Message:            fmt.Sprintf("Model %q has no revision %s", model.Name, describeModelPin(server.Spec.Model)),
+		})
+		if err := r.Status().Update(ctx, server); err != nil {
+			return result{}, fmt.Errorf("failed to update server status: %w", err)
+		}
+
+		// Allow for watch to requeue.
+		return result{}, nil
+	}
+
+	if revision != nil && revision.ObjectCount == 0 {
+		// Pods would report ready without a model to serve.
+		server.Status.Ready = false
+		meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
+			Type:               apiv1.ConditionServing,
+			Status:             metav1.ConditionFalse,
+			Reason:             apiv1.ReasonModelArtifactsEmpty,
+			ObservedGeneration: server.Generation,
+			Message:            fmt.Sprintf("Revision %d of Model %q has no artifacts", revision.Revision, model.Name),
+		})
+		if err := r.Status().Update(ctx, server); err != nil {
+			return result{}, fmt.Errorf("failed to update server status: %w", err)
+		}
+
+		// Allow for watch to requeue.
+		return result{}, nil
+	}
+
+	// A pinned revision keeps being served while the Model is re-trained.
+	if !model.Status.Ready && !server.Spec.Model.Pinned() {
 		log.Info("Model not ready", "model", model.Name)
 
 		server.Status.Ready = false
@@ -264,10 +355,29 @@ func (r *ServerReconciler) reconcileServer(ctx context.Context, server *apiv1.Se
 	if err := r.Patch(ctx, service, client.Apply, client.FieldOwner("server-controller")); err != nil {
 		return result{}, fmt.Errorf("failed to apply service: %w", err)
 	}
+	server.Status.URL, server.Status.ExternalURL = serviceURLs(service)
 
-	deploy, err := r.serverDeployment(server, &model)
+	ingress, err := r.reconcileIngress(ctx, server, service)
 	if err != nil {
-		return result{}, fmt.Errorf("failed to construct deployment: %w", err)
+		return result{}, fmt.Errorf("reconciling ingress: %w", err)
+	}
+	if ingress != nil {
+		server.Status.ExternalURL = ingressURL(server, ingress)
+	}
+
+	if result, err := reconcileMountDriver(ctx, r.Client, r.Cloud, server, apiv1.ConditionServing); !result.success {
+		return result, err
+	}
+
+	served := model.DeepCopy()
+	server.Status.ModelRevision = 0
+	if revision != nil {
+		served.Status.Artifacts.URL = revision.URL
+		server.Status.ModelRevision = revision.Revision
+	}
+	deploy, err := r.serverDeployment(server, served)
+	if err != nil {
+		return reconcileFailed(ctx, r.Client, server, apiv1.ConditionServing, fmt.Errorf("failed to construct deployment: %w", err))
 	}
 	if err := r.Patch(ctx, deploy, client.Apply, client.FieldOwner("server-controller")); err != nil {
 		return result{}, fmt.Errorf("failed to apply deployment: %w", err)
@@ -277,6 +387,18 @@ func (r *ServerReconciler) reconcileServer(ctx context.Context, server *apiv1.Se
 		return result{}, fmt.Errorf("failed to get deployment: %w", err)
 	}
 
+	if err := r.reconcileAutoscaler(ctx, server, deploy); err != nil {
+		return reconcileFailed(ctx, r.Client, server, apiv1.ConditionServing, fmt.Errorf("reconciling autoscaler: %w", err))
+	}
+
+	var pods corev1.PodList
+	if err := r.List(ctx, &pods, client.InNamespace(server.Namespace),
+		client.MatchingLabels(withServerSelector(server, map[string]string{}))); err != nil {
+		return result{}, fmt.Errorf("listing server pods: %w", err)
+	}
+
+	// Re-evaluated on every reconcile so that Ready is unset again when all
+	// replicas become unavailable.
 	if deploy.Status.ReadyReplicas == 0 {
 		server.Status.Ready = false
 		meta.SetStatusCondition(&server.Status.Conditions, metav1.Condition{
@@ -284,6 +406,7 @@ func (r *ServerReconciler) reconcileServer(ctx context.Context, server *apiv1.Se
 			Status:             metav1.ConditionFalse,
 			Reason:             apiv1.ReasonDeploymentNotReady,
 			ObservedGeneration: server.Generation,
+			Message:            serverNotReadyMessage(deploy, pods.Items),
 		})
 	} else {
 		server.Status.Ready = true
@@ -292,18 +415,117 @@ func (r *ServerReconciler) reconcileServer(ctx context.Context, server *apiv1.Se
 			Status:             metav1.ConditionTrue,
 			Reason:             apiv1.ReasonDeploymentReady,
 			ObservedGeneration: server.Generation,
+			Message:            fmt.Sprintf("%d/%d replicas ready", deploy.Status.ReadyReplicas, deploy.Status.Replicas),
 		})
 	}
 
+	var res result
+	if setUnschedulableCondition(&server.Status.Conditions, server.Generation, pods.Items) {
+		res.RequeueAfter = requeueAfter(RequeueInterval)
+	}
+
+	if r.ResourcePressure != nil && server.Status.Ready {
+		var ready []corev1.Pod
+		for _, pod := range pods.Items {
+			if isPodReady(&pod) {
+				ready = append(ready, pod)
+			}
+		}
+		r.ResourcePressure.SetCondition(ctx, &server.Status.Conditions, server.Generation, ready)
+		res.RequeueAfter = r.ResourcePressure.Interval
+	}
+
 	if err := r.Status().Update(ctx, server); err != nil {
 		return result{}, fmt.Errorf("failed to update model status: %w", err)
 	}
 
-	return result{success: true}, nil
+	res.success = true
+	return res, nil
 }
 
 const modelServerHTTPServePortName = "http-serve"
 
+// serverNotReadyMessage points at the first Pod that is not ready.
+func serverNotReadyMessage(deploy *appsv1.Deployment, pods []corev1.Pod) string {
+	msg := fmt.Sprintf("0/%d replicas ready", deploy.Status.Replicas)
+	for i := range pods {
+		if !isPodReady(&pods[i]) {
+			return msg + ": " + podNotReadyMessage(&pods[i])
+		}
+	}
+	return msg
+}
+
+// serverDeploymentStrategy returns a RollingUpdate strategy. All fields are
+// always set so that applying an unchanged Server is a no-op.
+func serverDeploymentStrategy(s *apiv1.ServerStrategy) appsv1.DeploymentStrategy {
+	maxSurge := intstr.FromInt(1)
+	maxUnavailable := intstr.FromInt(0)
+	if s != nil {
+		if s.MaxSurge != nil {
+			maxSurge = *s.MaxSurge
+		}
+		if s.MaxUnavailable != nil {
+			maxUnavailable = *s.MaxUnavailable
+		}
+	}
+
+	return appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxSurge:       &maxSurge,
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// serverProbe returns a HTTP GET probe against the server container (or a
+// file check), applying any overrides from the Server API on top of the
+// given defaults.
+func serverProbe(override *apiv1.ServerProbe, defaults *corev1.Probe) *corev1.Probe {
+	probe := defaults
+	probe.ProbeHandler = corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: "/",
+			Port: intstr.FromString(modelServerHTTPServePortName),
+		},
+	}
+
+	if override == nil {
+		return probe
+	}
+
+	if override.File != "" {
+		file := override.File
+		if !path.IsAbs(file) {
+			file = path.Join("/content/model", file)
+		}
+		probe.ProbeHandler = corev1.ProbeHandler{
+			Exec: &corev1.ExecAction{Command: []string{"test", "-e", file}},
+		}
+	}
+	if override.Path != "" && probe.HTTPGet != nil {
+		probe.HTTPGet.Path = override.Path
+	}
+	if override.Port != nil && probe.HTTPGet != nil {
+		probe.HTTPGet.Port = intstr.FromInt(int(*override.Port))
+	}
+	if override.InitialDelaySeconds != 0 {
+		probe.InitialDelaySeconds = override.InitialDelaySeconds
+	}
+	if override.TimeoutSeconds != 0 {
+		probe.TimeoutSeconds = override.TimeoutSeconds
+	}
+	if override.PeriodSeconds != 0 {
+		probe.PeriodSeconds = override.PeriodSeconds
+	}
+	if override.FailureThreshold != 0 {
+		probe.FailureThreshold = override.FailureThreshold
+	}
+
+	return probe
+}
+
 func (r *ServerReconciler) serverService(server *apiv1.Server, model *apiv1.Model) (*corev1.Service, error) {
 	s := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{
@@ -315,18 +537,24 @@ func (r *ServerReconciler) serverService(server *apiv1.Server, model *apiv1.Mode
 			Namespace: server.Namespace,
 		},
 		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
 			Selector: withServerSelector(server, map[string]string{}),
 			Ports: []corev1.ServicePort{
 				{
 					Name:       "http",
 					Protocol:   corev1.ProtocolTCP,
-					Port:       8080,
+					Port:       server.GetPort(),
 					TargetPort: intstr.FromString(modelServerHTTPServePortName),
 				},
 			},
 		},
 	}
 
+	if server.GetExposeType() == apiv1.ServerExposeLoadBalancer {
+		s.Spec.Type = corev1.ServiceTypeLoadBalancer
+		s.Annotations = server.Spec.Expose.Annotations
+	}
+
 	if err := ctrl.SetControllerReference(server, s, r.Scheme); err != nil {
 		return nil, fmt.Errorf("failed to set controller reference: %w", err)
 	}
@@ -334,6 +562,251 @@ func (r *ServerReconciler) serverService(server *apiv1.Server, model *apiv1.Mode
 	return s, nil
 }
 
+// reconcileIngress applies the Ingress of a Server that is exposed through an
+// Ingress and deletes it otherwise. The returned Ingress is nil when the
+// Server is not exposed through an Ingress.
+func (r *ServerReconciler) reconcileIngress(ctx context.Context, server *apiv1.Server, service *corev1.Service) (*networkingv1.Ingress, error) {
+	if server.GetExposeType() != apiv1.ServerExposeIngress {
+		key := client.ObjectKeyFromObject(service)
+		if err := r.deleteControlled(ctx, server, key, &networkingv1.Ingress{}); err != nil {
+			return nil, fmt.Errorf("deleting ingress: %w", err)
+		}
+		return nil, nil
+	}
+
+	ing, err := r.serverIngress(server, service)
+	if err != nil {
+		return nil, fmt.Errorf("constructing ingress: %w", err)
+	}
+	if err := r.Patch(ctx, ing, client.Apply, client.FieldOwner("server-controller")); err != nil {
+		return nil, fmt.Errorf("applying ingress: %w", err)
+	}
+	return ing, nil
+}
+
+func (r *ServerReconciler) serverIngress(server *apiv1.Server, service *corev1.Service) (*networkingv1.Ingress, error) {
+	expose := server.Spec.Expose
+	path := expose.Path
+	if path == "" {
+		path = "/"
+	}
+
+	ing := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "networking.k8s.io/v1",
+			Kind:       "Ingress",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        service.Name,
+			Namespace:   service.Namespace,
+			Annotations: expose.Annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: expose.IngressClassName,
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: expose.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     path,
+									PathType: ptr.To(networkingv1.PathTypePrefix),
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: service.Name,
+											Port: networkingv1.ServiceBackendPort{Name: "http"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if expose.TLSSecretName != "" {
+		tls := networkingv1.IngressTLS{SecretName: expose.TLSSecretName}
+		if expose.Host != "" {
+			tls.Hosts = []string{expose.Host}
+		}
+		ing.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+
+	if err := ctrl.SetControllerReference(server, ing, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	return ing, nil
+}
+
+// ingressURL returns the external URL of a Server that is exposed through the
+// given Ingress. The Ingress host is preferred over the load balancer address.
+func ingressURL(server *apiv1.Server, ing *networkingv1.Ingress) string {
+	expose := server.Spec.Expose
+	host := expose.Host
+	if host == "" {
+		for _, lb := range ing.Status.LoadBalancer.Ingress {
+			if host = lb.Hostname; host == "" {
+				host = lb.IP
+			}
+			if host != "" {
+				break
+			}
+		}
+	}
+	if host == "" {
+		return ""
+	}
+
+	scheme := "http"
+	if expose.TLSSecretName != "" {
+		scheme = "https"
+	}
+	path := strings.TrimSuffix(expose.Path, "/")
+	return scheme + "://" + host + path
+}
+
+// serviceURLs returns the cluster-internal URL of the Service and the
+// external URL once a load balancer was provisioned for it.
+func serviceURLs(svc *corev1.Service) (internal, external string) {
+	if len(svc.Spec.Ports) == 0 {
+		return "", ""
+	}
+	port := strconv.Itoa(int(svc.Spec.Ports[0].Port))
+	internal = "http://" + net.JoinHostPort(fmt.Sprintf("%s.%s.svc.cluster.local", svc.Name, svc.Namespace), port)
+	if svc.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		for _, ing := range svc.Status.LoadBalancer.Ingress {
+			host := ing.Hostname
+			if host == "" {
+				host = ing.IP
+			}
+			if host != "" {
+				external = "http://" + net.JoinHostPort(host, port)
+				break
+			}
+		}
+	}
+	return internal, external
+}
+
+const (
+	serverConcurrencyMetricName    = "substratus_server_concurrent_requests"
+	serverGPUUtilizationMetricName = "DCGM_FI_DEV_GPU_UTIL"
+)
+
+func (r *ServerReconciler) reconcileAutoscaler(ctx context.Context, server *apiv1.Server, deploy *appsv1.Deployment) error {
+	if server.Spec.Autoscaling == nil {
+		key := client.ObjectKeyFromObject(deploy)
+		if err := r.deleteControlled(ctx, server, key, &autoscalingv2.HorizontalPodAutoscaler{}); err != nil {
+			return fmt.Errorf("deleting hpa: %w", err)
+		}
+		return nil
+	}
+
+	hpa, err := r.serverHPA(server, deploy)
+	if err != nil {
+		return fmt.Errorf("constructing hpa: %w", err)
+	}
+	if err := r.Patch(ctx, hpa, client.Apply, client.FieldOwner("server-controller")); err != nil {
+		return fmt.Errorf("applying hpa: %w", err)
+	}
+
+	return nil
+}
+
+// deleteControlled deletes the object with the given key if it exists and is
+// controlled by the Server. The object is read from the cache so that
+// reconciling a Server without the object does not call the API server.
+func (r *ServerReconciler) deleteControlled(ctx context.Context, server *apiv1.Server, key client.ObjectKey, obj client.Object) error {
+	if err := r.Get(ctx, key, obj); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(obj, server) {
+		return nil
+	}
+	if err := r.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+func (r *ServerReconciler) serverHPA(server *apiv1.Server, deploy *appsv1.Deployment) (*autoscalingv2.HorizontalPodAutoscaler, error) {
+	as := server.Spec.Autoscaling
+
+	minReplicas := as.MinReplicas
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	if as.MaxReplicas < minReplicas {
+		return nil, terminal(fmt.Errorf("maxReplicas (%v) must not be less than minReplicas (%v)", as.MaxReplicas, minReplicas))
+	}
+
+	var metrics []autoscalingv2.MetricSpec
+	if as.TargetConcurrency != nil {
+		metrics = append(metrics, podsMetricSpec(serverConcurrencyMetricName, *as.TargetConcurrency))
+	}
+	if as.TargetGPUUtilization != nil {
+		metrics = append(metrics, podsMetricSpec(serverGPUUtilizationMetricName, *as.TargetGPUUtilization))
+	}
+	if as.TargetCPUUtilization != nil {
+		metrics = append(metrics, autoscalingv2.MetricSpec{
+			Type: autoscalingv2.ResourceMetricSourceType,
+			Resource: &autoscalingv2.ResourceMetricSource{
+				Name: corev1.ResourceCPU,
+				Target: autoscalingv2.MetricTarget{
+					Type:               autoscalingv2.UtilizationMetricType,
+					AverageUtilization: as.TargetCPUUtilization,
+				},
+			},
+		})
+	}
+	if len(metrics) == 0 {
+		return nil, terminal(fmt.Errorf("at least one autoscaling target must be set"))
+	}
+
+	hpa := &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "autoscaling/v2",
+			Kind:       "HorizontalPodAutoscaler",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploy.Name,
+			Namespace: deploy.Namespace,
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       deploy.Name,
+			},
+			MinReplicas: &minReplicas,
+			MaxReplicas: as.MaxReplicas,
+			Metrics:     metrics,
+		},
+	}
+
+	if err := ctrl.SetControllerReference(server, hpa, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set controller reference: %w", err)
+	}
+
+	return hpa, nil
+}
+
+func podsMetricSpec(name string, target int32) autoscalingv2.MetricSpec {
+	return autoscalingv2.MetricSpec{
+		Type: autoscalingv2.PodsMetricSourceType,
+		Pods: &autoscalingv2.PodsMetricSource{
+			Metric: autoscalingv2.MetricIdentifier{Name: name},
+			Target: autoscalingv2.MetricTarget{
+				Type:         autoscalingv2.AverageValueMetricType,
+				AverageValue: resource.NewQuantity(int64(target), resource.DecimalSI),
+			},
+		},
+	}
+}
+
 func withServerSelector(server *apiv1.Server, labels map[string]string) map[string]string {
 	labels["role"] = "run"
 	labels["server"] = server.Name
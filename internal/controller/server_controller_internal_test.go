@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+func Test_reconcileAutoscalerInvalid(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	cases := map[string]struct {
+		autoscaling *apiv1.ServerAutoscaling
+		message     string
+	}{
+		"max below min": {
+			autoscaling: &apiv1.ServerAutoscaling{MinReplicas: 3, MaxReplicas: 2, TargetConcurrency: ptr.To(int32(4))},
+			message:     "constructing hpa: maxReplicas (2) must not be less than minReplicas (3)",
+		},
+		"no targets": {
+			autoscaling: &apiv1.ServerAutoscaling{MinReplicas: 1, MaxReplicas: 2},
+			message:     "constructing hpa: at least one autoscaling target must be set",
+		},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			server := &apiv1.Server{
+				TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Server"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "falcon-7b", Generation: 2},
+				Spec:       apiv1.ServerSpec{Autoscaling: c.autoscaling},
+			}
+			r := &ServerReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(server).WithStatusSubresource(server).Build(),
+				Scheme: scheme,
+			}
+			deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "falcon-7b-server"}}
+
+			err := r.reconcileAutoscaler(context.Background(), server, deploy)
+			require.True(t, isTerminal(err), "an invalid spec is not retried")
+
+			res, err := reconcileFailed(context.Background(), r.Client, server, apiv1.ConditionServing, err)
+			require.NoError(t, err)
+			require.True(t, res.failure)
+			cond := meta.FindStatusCondition(server.Status.Conditions, apiv1.ConditionServing)
+			require.NotNil(t, cond)
+			require.Equal(t, apiv1.ReasonFailed, cond.Reason)
+			require.Equal(t, c.message, cond.Message)
+		})
+	}
+}
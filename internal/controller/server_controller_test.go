@@ -8,10 +8,14 @@ import (
 	"github.com/stretchr/testify/require"
 	apiv1 "github.com/substratusai/substratus/api/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestServerFromGit(t *testing.T) {
@@ -37,14 +41,16 @@ func TestServerFromGit(t *testing.T) {
 			Namespace: "default",
 		},
 		Spec: apiv1.ServerSpec{
-			Command: []string{"serve.sh"},
+			Command:           []string{"serve.sh"},
+			Args:              []string{"--log-level", "debug"},
+			PriorityClassName: "inference",
 			Build: &apiv1.Build{
 				Git: &apiv1.BuildGit{
 					URL: "https://github.com/substratusai/some-server",
 				},
 			},
-			Model: apiv1.ObjectRef{
-				Name: model.Name,
+			Model: apiv1.ServerModelRef{
+				ObjectRef: apiv1.ObjectRef{Name: model.Name},
 			},
 		},
 	}
@@ -60,6 +66,7 @@ func TestServerFromGit(t *testing.T) {
 		assert.NoError(t, err, "getting the server service")
 	}, timeout, interval, "waiting for the server service to be created")
 	require.Equal(t, "http-serve", service.Spec.Ports[0].TargetPort.String())
+	require.Equal(t, int32(apiv1.DefaultServerPort), service.Spec.Ports[0].Port)
 
 	// Test that a model server Deployment gets created by the controller.
 	var deploy appsv1.Deployment
@@ -69,4 +76,186 @@ func TestServerFromGit(t *testing.T) {
 	}, timeout, interval, "waiting for the server deployment to be created")
 	require.Equal(t, "serve", deploy.Spec.Template.Spec.Containers[0].Name)
 	require.Contains(t, strings.Join(deploy.Spec.Template.Spec.Containers[0].Command, " "), "serve.sh")
+	require.Equal(t, []string{"--log-level", "debug"}, deploy.Spec.Template.Spec.Containers[0].Args)
+	require.Equal(t, "inference", deploy.Spec.Template.Spec.PriorityClassName)
+	require.NotNil(t, deploy.Spec.Template.Spec.Containers[0].StartupProbe)
+	require.Equal(t, int32(180), deploy.Spec.Template.Spec.Containers[0].StartupProbe.FailureThreshold)
+	require.Equal(t, int32(apiv1.DefaultServerPort), deploy.Spec.Template.Spec.Containers[0].Ports[0].ContainerPort)
+	require.Equal(t, int32(1), *deploy.Spec.Replicas)
+	require.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, deploy.Spec.Strategy.Type)
+	require.Equal(t, 1, deploy.Spec.Strategy.RollingUpdate.MaxSurge.IntValue())
+	require.Equal(t, 0, deploy.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue())
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(modelServer), modelServer)
+		assert.NoError(t, err, "getting the server")
+		assert.Equal(t, "http://"+service.Name+".default.svc.cluster.local:8080", modelServer.Status.URL)
+	}, timeout, interval, "waiting for the server url")
+	require.Empty(t, modelServer.Status.ExternalURL)
+}
+
+func TestServerModelRevision(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-image"),
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model to be referenced by the server")
+	t.Cleanup(debugObject(t, model))
+
+	testModelLoad(t, model)
+
+	server := &apiv1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-svr",
+			Namespace: "default",
+		},
+		Spec: apiv1.ServerSpec{
+			Image:        ptr.To("some-image"),
+			Model:        apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: model.Name}, Revision: 1},
+			StartupProbe: &apiv1.ServerProbe{File: "config.json"},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, server), "creating a server that pins a model revision")
+	t.Cleanup(debugObject(t, server))
+
+	var deploy appsv1.Deployment
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: server.Namespace, Name: server.Name + "-server"}, &deploy)
+		assert.NoError(t, err, "getting the server deployment")
+	}, timeout, interval, "waiting for the server deployment to be created")
+	var subPaths []string
+	for _, m := range deploy.Spec.Template.Spec.Containers[0].VolumeMounts {
+		subPaths = append(subPaths, m.SubPath)
+	}
+	require.Contains(t, strings.Join(subPaths, ","), "/revisions/1/artifacts")
+	startup := deploy.Spec.Template.Spec.Containers[0].StartupProbe
+	require.NotNil(t, startup.Exec, "the startup probe should wait for the model file")
+	require.Equal(t, []string{"test", "-e", "/content/model/config.json"}, startup.Exec.Command)
+	require.Equal(t, "/", deploy.Spec.Template.Spec.Containers[0].ReadinessProbe.HTTPGet.Path)
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(server), server)
+		assert.NoError(t, err, "getting the server")
+		assert.Equal(t, int64(1), server.Status.ModelRevision)
+	}, timeout, interval, "waiting for the served revision")
+
+	server.Spec.Model.Revision = 2
+	require.NoError(t, k8sClient.Update(ctx, server), "pinning a missing revision")
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(server), server)
+		assert.NoError(t, err, "getting the server")
+		cond := meta.FindStatusCondition(server.Status.Conditions, apiv1.ConditionServing)
+		if assert.NotNil(t, cond) {
+			assert.Equal(t, apiv1.ReasonModelRevisionNotFound, cond.Reason)
+		}
+	}, timeout, interval, "waiting for the missing revision to be reported")
+}
+
+func TestServerIngress(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-image"),
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model to be referenced by the server")
+	t.Cleanup(debugObject(t, model))
+
+	testModelLoad(t, model)
+
+	server := &apiv1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-srv",
+			Namespace: "default",
+		},
+		Spec: apiv1.ServerSpec{
+			Image: ptr.To("some-image"),
+			Model: apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: model.Name}},
+			Expose: &apiv1.ServerExpose{
+				Type:          apiv1.ServerExposeIngress,
+				Host:          "falcon.example.com",
+				TLSSecretName: "falcon-tls",
+				Annotations:   map[string]string{"cert-manager.io/cluster-issuer": "letsencrypt"},
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, server), "creating a server")
+	t.Cleanup(debugObject(t, server))
+
+	var ingress networkingv1.Ingress
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: server.Namespace, Name: server.Name + "-server"}, &ingress)
+		assert.NoError(t, err, "getting the server ingress")
+	}, timeout, interval, "waiting for the server ingress to be created")
+	require.Equal(t, "falcon.example.com", ingress.Spec.Rules[0].Host)
+	require.Equal(t, server.Name+"-server", ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+	require.Equal(t, "falcon-tls", ingress.Spec.TLS[0].SecretName)
+	require.Equal(t, "letsencrypt", ingress.Annotations["cert-manager.io/cluster-issuer"])
+
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, client.ObjectKeyFromObject(server), server)
+		assert.NoError(t, err, "getting the server")
+		assert.Equal(t, "https://falcon.example.com", server.Status.ExternalURL)
+	}, timeout, interval, "waiting for the server external url")
+}
+
+func TestServerAutoscaling(t *testing.T) {
+	name := strings.ToLower(t.Name())
+
+	model := &apiv1.Model{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-mdl",
+			Namespace: "default",
+		},
+		Spec: apiv1.ModelSpec{
+			Image: ptr.To("some-image"),
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, model), "create a model to be referenced by the server")
+	t.Cleanup(debugObject(t, model))
+
+	testModelLoad(t, model)
+
+	server := &apiv1.Server{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-svr",
+			Namespace: "default",
+		},
+		Spec: apiv1.ServerSpec{
+			Image: ptr.To("some-image"),
+			Model: apiv1.ServerModelRef{
+				ObjectRef: apiv1.ObjectRef{Name: model.Name},
+			},
+			Autoscaling: &apiv1.ServerAutoscaling{
+				MinReplicas:          2,
+				MaxReplicas:          5,
+				TargetGPUUtilization: ptr.To(int32(70)),
+			},
+		},
+	}
+	require.NoError(t, k8sClient.Create(ctx, server), "creating a server")
+	t.Cleanup(debugObject(t, server))
+
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	require.EventuallyWithT(t, func(t *assert.CollectT) {
+		err := k8sClient.Get(ctx, types.NamespacedName{Namespace: server.Namespace, Name: server.Name + "-server"}, &hpa)
+		assert.NoError(t, err, "getting the server hpa")
+	}, timeout, interval, "waiting for the server hpa to be created")
+	require.Equal(t, server.Name+"-server", hpa.Spec.ScaleTargetRef.Name)
+	require.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	require.Equal(t, int32(5), hpa.Spec.MaxReplicas)
+	require.Len(t, hpa.Spec.Metrics, 1)
+	require.Equal(t, "DCGM_FI_DEV_GPU_UTIL", hpa.Spec.Metrics[0].Pods.Metric.Name)
 }
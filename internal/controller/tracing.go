@@ -0,0 +1,31 @@
+package controller
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// tracer records spans with the global tracer provider, which does not
+// record anything unless tracing is configured on the controller manager.
+var tracer = otel.Tracer("github.com/substratusai/substratus/internal/controller")
+
+// startReconcileSpan starts the span that covers a single reconcile. SCI
+// requests and Job creation during the reconcile are recorded as children.
+func startReconcileSpan(ctx context.Context, name, kind string, req ctrl.Request) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("substratus.kind", kind),
+		attribute.String("substratus.namespace", req.Namespace),
+		attribute.String("substratus.name", req.Name),
+	))
+}
+
+// recordSpanError marks the span as failed with the error.
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
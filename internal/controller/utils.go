@@ -2,16 +2,38 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
 )
 
+// specHashAnnotation records the hash of the desired Job spec so that
+// stale Jobs can be detected (Job specs are mostly immutable).
+const specHashAnnotation = "substratus.ai/spec-hash"
+
 // result allows for propogating controller reconcile information up the call stack.
 // In particular, it allows the called to determine if it should return or not.
 type result struct {
@@ -20,20 +42,81 @@ type result struct {
 	failure bool
 }
 
-func reconcileJob(ctx context.Context, c client.Client, job *batchv1.Job) (result, error) {
-	if err := c.Create(ctx, job); client.IgnoreAlreadyExists(err) != nil {
-		return result{}, fmt.Errorf("creating Job: %w", err)
+// requeueJitterFactor is the maximum fraction that requeue intervals are
+// extended by.
+const requeueJitterFactor = 0.5
+
+// requeueAfter returns the base interval extended by a random jitter so that
+// objects which started waiting at the same time (i.e. many Datasets applied
+// at once) do not requeue in lockstep. Use it for all polling-style waits.
+func requeueAfter(base time.Duration) time.Duration {
+	return wait.Jitter(base, requeueJitterFactor)
+}
+
+// reconcileJob creates the Job if it does not exist yet and recreates it
+// when the desired spec changed. Concurrent Reconciles never process the same
+// object at the same time (controller-runtime guarantees this per key), and
+// Jobs have deterministic names, so a Job can not be created twice for the
+// same object even with MaxConcurrentReconciles > 1.
+func reconcileJob(ctx context.Context, c client.Client, job *batchv1.Job, kind string) (result, error) {
+	ctx, span := tracer.Start(ctx, "reconcileJob", trace.WithAttributes(
+		attribute.String("substratus.job", job.Name),
+		attribute.String("substratus.role", job.Spec.Template.Labels["role"]),
+	))
+	defer span.End()
+
+	hash, err := jobSpecHash(job)
+	if err != nil {
+		return result{}, fmt.Errorf("hashing Job spec: %w", err)
+	}
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	job.Annotations[specHashAnnotation] = hash
+
+	if err := c.Create(ctx, job); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			recordSpanError(span, err)
+			return result{}, fmt.Errorf("creating Job: %w", err)
+		}
+	} else {
+		span.AddEvent("Job created")
+		jobsCreatedTotal.WithLabelValues(kind, job.Spec.Template.Labels["role"]).Inc()
 	}
 
 	if err := c.Get(ctx, client.ObjectKeyFromObject(job), job); err != nil {
 		return result{}, fmt.Errorf("geting Job: %w", err)
 	}
 
+	if job.DeletionTimestamp != nil {
+		// Wait for a stale Job to be removed before recreating it.
+		return result{Result: ctrl.Result{RequeueAfter: requeueAfter(time.Second)}}, nil
+	}
+
+	// Jobs created before the annotation was introduced are left alone.
+	if existing, ok := job.Annotations[specHashAnnotation]; ok && existing != hash {
+		log.FromContext(ctx).Info("Deleting stale Job", "job", job.Name)
+		if err := c.Delete(ctx, job, &client.DeleteOptions{
+			PropagationPolicy: ptr.To(metav1.DeletePropagationBackground),
+		}); client.IgnoreNotFound(err) != nil {
+			return result{}, fmt.Errorf("deleting stale Job: %w", err)
+		}
+		return result{Result: ctrl.Result{RequeueAfter: requeueAfter(time.Second)}}, nil
+	}
+
 	complete, failed := jobResult(job)
 
 	return result{success: complete, failure: failed}, nil
 }
 
+func jobSpecHash(job *batchv1.Job) (string, error) {
+	data, err := json.Marshal(job.Spec)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))[:16], nil
+}
+
 func jobResult(job *batchv1.Job) (complete bool, failed bool) {
 	for _, c := range job.Status.Conditions {
 		if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
@@ -48,6 +131,161 @@ func jobResult(job *batchv1.Job) (complete bool, failed bool) {
 	return
 }
 
+// statusObject is an object that reports its state through conditions.
+type statusObject interface {
+	client.Object
+	GetConditions() *[]metav1.Condition
+	SetStatusReady(bool)
+}
+
+// reconcileCloudConfig guards against reconciling with an incomplete cloud
+// configuration (which would otherwise lead to panics deeper in the
+// reconcile). The given condition type is set to ConfigError when the
+// configuration is invalid.
+func reconcileCloudConfig(ctx context.Context, c client.Client, cld cloud.Cloud, obj statusObject, conditionType string) (result, error) {
+	err := cloud.Validate(cld)
+	if err == nil {
+		return result{success: true}, nil
+	}
+
+	log.FromContext(ctx).Error(err, "invalid cloud configuration")
+	obj.SetStatusReady(false)
+	meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonConfigError,
+		ObservedGeneration: obj.GetGeneration(),
+		Message:            fmt.Sprintf("The controller is misconfigured: %v", err),
+	})
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return result{}, fmt.Errorf("updating status: %w", err)
+	}
+	// No use in retrying until the controller is restarted with a valid
+	// configuration...
+	return result{}, nil
+}
+
+// terminalError is an error that retrying will not resolve, typically caused
+// by an invalid spec. The object is failed until the spec is updated.
+type terminalError struct {
+	err error
+}
+
+func (e *terminalError) Error() string { return e.err.Error() }
+func (e *terminalError) Unwrap() error { return e.err }
+
+// terminal marks err as terminal.
+func terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &terminalError{err: err}
+}
+
+// isTerminal reports whether err (or any error it wraps) is terminal.
+func isTerminal(err error) bool {
+	var t *terminalError
+	return errors.As(err, &t)
+}
+
+// reconcileFailed handles an error that prevented an object from being
+// reconciled. Terminal errors are reported through the given condition type
+// with the Failed reason and are not retried, an update of the spec will
+// trigger a new reconcile. All other errors are returned so that the request
+// is retried with backoff.
+func reconcileFailed(ctx context.Context, c client.Client, obj statusObject, conditionType string, err error) (result, error) {
+	if !isTerminal(err) {
+		return result{}, err
+	}
+
+	log.FromContext(ctx).Error(err, "terminal reconcile error")
+	obj.SetStatusReady(false)
+	meta.SetStatusCondition(obj.GetConditions(), metav1.Condition{
+		Type:               conditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonFailed,
+		ObservedGeneration: obj.GetGeneration(),
+		Message:            err.Error(),
+	})
+	if err := c.Status().Update(ctx, obj); err != nil {
+		return result{}, fmt.Errorf("updating status: %w", err)
+	}
+	return result{failure: true}, nil
+}
+
+// refKey returns the key of a referenced object, ref.Namespace defaults to
+// the namespace of the referencing object.
+func refKey(namespace string, ref apiv1.ObjectRef) types.NamespacedName {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	return types.NamespacedName{Namespace: namespace, Name: ref.Name}
+}
+
+// referenceAllowed reports whether obj can be referenced from the given
+// namespace: either its own namespace or one listed in its
+// AllowedNamespacesAnnotation.
+func referenceAllowed(obj client.Object, namespace string) bool {
+	if obj.GetNamespace() == namespace {
+		return true
+	}
+	for _, ns := range strings.Split(obj.GetAnnotations()[apiv1.AllowedNamespacesAnnotation], ",") {
+		if ns = strings.TrimSpace(ns); ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// jobDeadlineExceededReason is the reason of the Failed condition that the
+// Job controller sets once ActiveDeadlineSeconds is exceeded.
+const jobDeadlineExceededReason = "DeadlineExceeded"
+
+// activeDeadlineSeconds converts a Spec.Timeout to a Job's
+// ActiveDeadlineSeconds (nil when no timeout is set). Fractions of a second
+// are rounded up as the Job deadline must be at least 1s.
+func activeDeadlineSeconds(timeout *metav1.Duration) *int64 {
+	if timeout == nil || timeout.Duration <= 0 {
+		return nil
+	}
+	return ptr.To(int64(math.Ceil(timeout.Duration.Seconds())))
+}
+
+// jobFailedCondition returns the Complete condition for a failed Job,
+// reporting TimedOut when the Job was stopped for exceeding its deadline.
+func jobFailedCondition(job *batchv1.Job, generation int64) metav1.Condition {
+	cond := metav1.Condition{
+		Type:               apiv1.ConditionComplete,
+		Status:             metav1.ConditionFalse,
+		Reason:             apiv1.ReasonJobFailed,
+		ObservedGeneration: generation,
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue && c.Reason == jobDeadlineExceededReason {
+			cond.Reason = apiv1.ReasonTimedOut
+			if job.Spec.ActiveDeadlineSeconds != nil {
+				cond.Message = fmt.Sprintf("Job %s exceeded the timeout of %s", job.Name, time.Duration(*job.Spec.ActiveDeadlineSeconds)*time.Second)
+			}
+		}
+	}
+	return cond
+}
+
+// podNotReadyMessage describes why a Pod is not ready, using the message of
+// the Pod's Ready condition when available
+// (i.e. "containers with unready status: [notebook]").
+func podNotReadyMessage(pod *corev1.Pod) string {
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady && c.Status != corev1.ConditionTrue && c.Message != "" {
+			return fmt.Sprintf("Pod %s is not ready: %s", pod.Name, c.Message)
+		}
+	}
+	if pod.Status.Phase == "" {
+		return fmt.Sprintf("Waiting for Pod %s to start", pod.Name)
+	}
+	return fmt.Sprintf("Pod %s is not ready (phase %s)", pod.Name, pod.Status.Phase)
+}
+
 func isPodReady(pod *corev1.Pod) bool {
 	if pod.Status.Phase != corev1.PodRunning {
 		return false
@@ -89,5 +327,7 @@ func resolveEnv(env map[string]string) ([]corev1.EnvVar, error) {
 			envs = append(envs, corev1.EnvVar{Name: key, Value: value})
 		}
 	}
+	// Keep the order stable so that the resulting Pod specs are comparable.
+	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
 	return envs, nil
 }
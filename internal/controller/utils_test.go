@@ -1,11 +1,25 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
 )
 
 func Test_resolveEnv(t *testing.T) {
@@ -63,3 +77,102 @@ func Test_resolveEnv(t *testing.T) {
 		require.Truef(t, reflect.DeepEqual(actual, tc.expected), "resolveEnv(%v): expected %v, actual %v", tc.input, tc.expected, actual)
 	}
 }
+
+func Test_jobFailedCondition(t *testing.T) {
+	failed := func(reason string) *batchv1.Job {
+		return &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "my-job"},
+			Spec: batchv1.JobSpec{
+				ActiveDeadlineSeconds: activeDeadlineSeconds(&metav1.Duration{Duration: 2 * time.Hour}),
+			},
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{
+				Type:   batchv1.JobFailed,
+				Status: corev1.ConditionTrue,
+				Reason: reason,
+			}}},
+		}
+	}
+
+	cond := jobFailedCondition(failed("BackoffLimitExceeded"), 3)
+	require.Equal(t, apiv1.ReasonJobFailed, cond.Reason)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, int64(3), cond.ObservedGeneration)
+
+	cond = jobFailedCondition(failed("DeadlineExceeded"), 3)
+	require.Equal(t, apiv1.ReasonTimedOut, cond.Reason)
+	require.Equal(t, "Job my-job exceeded the timeout of 2h0m0s", cond.Message)
+
+	require.Nil(t, activeDeadlineSeconds(nil))
+	require.Equal(t, ptr.To(int64(90)), activeDeadlineSeconds(&metav1.Duration{Duration: 90 * time.Second}))
+	require.Equal(t, ptr.To(int64(1)), activeDeadlineSeconds(&metav1.Duration{Duration: 500 * time.Millisecond}))
+	require.Equal(t, ptr.To(int64(91)), activeDeadlineSeconds(&metav1.Duration{Duration: 90*time.Second + time.Millisecond}))
+}
+
+func Test_requeueAfter(t *testing.T) {
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		d := requeueAfter(10 * time.Second)
+		require.GreaterOrEqual(t, d, 10*time.Second)
+		require.Less(t, d, 15*time.Second)
+		seen[d] = true
+	}
+	require.Greater(t, len(seen), 1, "requeues should be jittered")
+}
+
+func Test_referenceAllowed(t *testing.T) {
+	obj := func(annotation string) *apiv1.Dataset {
+		ds := &apiv1.Dataset{ObjectMeta: metav1.ObjectMeta{Name: "squad", Namespace: "shared"}}
+		if annotation != "" {
+			ds.Annotations = map[string]string{apiv1.AllowedNamespacesAnnotation: annotation}
+		}
+		return ds
+	}
+
+	require.True(t, referenceAllowed(obj(""), "shared"), "same namespace")
+	require.False(t, referenceAllowed(obj(""), "team-a"), "no annotation")
+	require.True(t, referenceAllowed(obj("team-a, team-b"), "team-b"))
+	require.False(t, referenceAllowed(obj("team-a,team-b"), "team-c"))
+	require.True(t, referenceAllowed(obj("*"), "team-c"))
+}
+
+func Test_isTerminal(t *testing.T) {
+	require.Nil(t, terminal(nil))
+
+	err := terminal(errors.New("invalid spec"))
+	require.True(t, isTerminal(err))
+	require.True(t, isTerminal(fmt.Errorf("constructing Job: %w", err)))
+	require.False(t, isTerminal(errors.New("connection refused")))
+
+	// Non-terminal errors are returned to be retried.
+	transient := errors.New("connection refused")
+	res, err := reconcileFailed(context.Background(), nil, &apiv1.Model{}, apiv1.ConditionComplete, transient)
+	require.Equal(t, transient, err)
+	require.False(t, res.success)
+	require.False(t, res.failure)
+}
+
+func Test_reconcileFailedTerminal(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, apiv1.AddToScheme(scheme))
+
+	model := &apiv1.Model{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "falcon-7b", Generation: 2}}
+	model.Status.Ready = true
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(model).WithStatusSubresource(model).Build()
+
+	ctx := context.Background()
+	res, err := reconcileFailed(ctx, c, model, apiv1.ConditionComplete, terminal(errors.New("invalid spec")))
+	require.NoError(t, err, "terminal errors should not be retried")
+	require.True(t, res.failure)
+	require.False(t, res.success)
+	require.Equal(t, ctrl.Result{}, res.Result, "terminal errors should not be requeued")
+
+	var updated apiv1.Model
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(model), &updated))
+	require.False(t, updated.Status.Ready)
+	cond := meta.FindStatusCondition(updated.Status.Conditions, apiv1.ConditionComplete)
+	require.NotNil(t, cond)
+	require.Equal(t, metav1.ConditionFalse, cond.Status)
+	require.Equal(t, apiv1.ReasonFailed, cond.Reason)
+	require.Equal(t, "invalid spec", cond.Message)
+	require.Equal(t, int64(2), cond.ObservedGeneration)
+}
@@ -0,0 +1,101 @@
+package metricstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultQueryRange = 24 * time.Hour
+
+// NewHandler returns a HTTP handler for querying the Store.
+//
+//	GET ?kind=Notebook&namespace=default&name=my-nb&metric=gpu&start=<RFC3339>&end=<RFC3339>&step=5m
+//
+// The start and end default to the last 24 hours and step defaults to 1m.
+//
+// The handler does no authorization of its own, it is meant to be served on
+// the metrics endpoint so that it is only reachable through kube-rbac-proxy.
+func NewHandler(store Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q, err := parseQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		samples, err := store.Query(r.Context(), q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if samples == nil {
+			samples = []Sample{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(struct {
+			Samples []Sample `json:"samples"`
+		}{Samples: samples}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+func parseQuery(r *http.Request) (Query, error) {
+	v := r.URL.Query()
+
+	q := Query{
+		Object: Object{
+			Kind:      v.Get("kind"),
+			Namespace: v.Get("namespace"),
+			Name:      v.Get("name"),
+		},
+		Metric: Metric(v.Get("metric")),
+		End:    time.Now(),
+		Step:   time.Minute,
+	}
+	if q.Kind == "" || q.Namespace == "" || q.Name == "" {
+		return q, fmt.Errorf("kind, namespace and name are required")
+	}
+	if err := q.Metric.Validate(); err != nil {
+		return q, err
+	}
+
+	if end := v.Get("end"); end != "" {
+		t, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return q, fmt.Errorf("parsing end: %w", err)
+		}
+		q.End = t
+	}
+	q.Start = q.End.Add(-defaultQueryRange)
+	if start := v.Get("start"); start != "" {
+		t, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return q, fmt.Errorf("parsing start: %w", err)
+		}
+		q.Start = t
+	}
+	if !q.Start.Before(q.End) {
+		return q, fmt.Errorf("start must be before end")
+	}
+	if step := v.Get("step"); step != "" {
+		d, err := time.ParseDuration(step)
+		if err != nil {
+			return q, fmt.Errorf("parsing step: %w", err)
+		}
+		if d <= 0 {
+			return q, fmt.Errorf("step must be positive")
+		}
+		q.Step = d
+	}
+
+	return q, nil
+}
@@ -0,0 +1,105 @@
+package metricstore
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a local, in-memory Store that keeps a rolling window of
+// samples. History is lost on restart.
+type MemoryStore struct {
+	// Retention is how long samples are kept for.
+	Retention time.Duration
+
+	mtx    sync.RWMutex
+	series map[seriesKey][]Sample
+}
+
+type seriesKey struct {
+	Object
+	Metric Metric
+}
+
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{
+		Retention: retention,
+		series:    map[seriesKey][]Sample{},
+	}
+}
+
+func (s *MemoryStore) Append(_ context.Context, obj Object, metric Metric, sample Sample) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	key := seriesKey{Object: obj, Metric: metric}
+	samples := append(s.series[key], sample)
+
+	cutoff := sample.Time.Add(-s.Retention)
+	i := 0
+	for i < len(samples) && samples[i].Time.Before(cutoff) {
+		i++
+	}
+	s.series[key] = samples[i:]
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, obj Object) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for key := range s.series {
+		if key.Object == obj {
+			delete(s.series, key)
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Query(_ context.Context, q Query) ([]Sample, error) {
+	if err := q.Metric.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	var inRange []Sample
+	for _, sample := range s.series[seriesKey{Object: q.Object, Metric: q.Metric}] {
+		if sample.Time.Before(q.Start) || sample.Time.After(q.End) {
+			continue
+		}
+		inRange = append(inRange, sample)
+	}
+
+	if q.Step <= 0 {
+		return inRange, nil
+	}
+
+	// Average the samples within each step.
+	var (
+		result []Sample
+		bucket time.Time
+		sum    float64
+		count  int
+	)
+	flush := func() {
+		if count > 0 {
+			result = append(result, Sample{Time: bucket, Value: sum / float64(count)})
+		}
+	}
+	for _, sample := range inRange {
+		b := q.Start.Add(sample.Time.Sub(q.Start).Truncate(q.Step))
+		if !b.Equal(bucket) {
+			flush()
+			bucket, sum, count = b, 0, 0
+		}
+		sum += sample.Value
+		count++
+	}
+	flush()
+
+	return result, nil
+}
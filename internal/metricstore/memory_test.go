@@ -0,0 +1,120 @@
+package metricstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/substratusai/substratus/internal/metricstore"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := metricstore.NewMemoryStore(time.Hour)
+	obj := metricstore.Object{Kind: "Notebook", Namespace: "default", Name: "nb"}
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 90; i++ {
+		require.NoError(t, store.Append(ctx, obj, metricstore.MetricCPU, metricstore.Sample{
+			Time:  start.Add(time.Duration(i) * time.Minute),
+			Value: float64(i),
+		}))
+	}
+
+	t.Run("retention", func(t *testing.T) {
+		samples, err := store.Query(ctx, metricstore.Query{
+			Object: obj,
+			Metric: metricstore.MetricCPU,
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Len(t, samples, 61)
+		require.Equal(t, float64(29), samples[0].Value)
+	})
+
+	t.Run("step", func(t *testing.T) {
+		samples, err := store.Query(ctx, metricstore.Query{
+			Object: obj,
+			Metric: metricstore.MetricCPU,
+			Start:  start.Add(60 * time.Minute),
+			End:    start.Add(80 * time.Minute),
+			Step:   10 * time.Minute,
+		})
+		require.NoError(t, err)
+		require.Equal(t, []metricstore.Sample{
+			{Time: start.Add(60 * time.Minute), Value: 64.5},
+			{Time: start.Add(70 * time.Minute), Value: 74.5},
+			{Time: start.Add(80 * time.Minute), Value: 80},
+		}, samples)
+	})
+
+	t.Run("other metric", func(t *testing.T) {
+		samples, err := store.Query(ctx, metricstore.Query{
+			Object: obj,
+			Metric: metricstore.MetricMemory,
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Empty(t, samples)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		other := metricstore.Object{Kind: "Notebook", Namespace: "default", Name: "other"}
+		require.NoError(t, store.Append(ctx, other, metricstore.MetricCPU, metricstore.Sample{Time: start.Add(89 * time.Minute), Value: 1}))
+
+		require.NoError(t, store.Delete(ctx, obj))
+		for _, metric := range []metricstore.Metric{metricstore.MetricCPU, metricstore.MetricMemory} {
+			samples, err := store.Query(ctx, metricstore.Query{
+				Object: obj,
+				Metric: metric,
+				Start:  start,
+				End:    start.Add(2 * time.Hour),
+			})
+			require.NoError(t, err)
+			require.Empty(t, samples)
+		}
+
+		samples, err := store.Query(ctx, metricstore.Query{
+			Object: other,
+			Metric: metricstore.MetricCPU,
+			Start:  start,
+			End:    start.Add(2 * time.Hour),
+		})
+		require.NoError(t, err)
+		require.Len(t, samples, 1)
+	})
+}
+
+func TestHandler(t *testing.T) {
+	store := metricstore.NewMemoryStore(time.Hour)
+	obj := metricstore.Object{Kind: "Server", Namespace: "default", Name: "svr"}
+	now := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, store.Append(context.Background(), obj, metricstore.MetricMemory, metricstore.Sample{Time: now, Value: 123}))
+
+	srv := httptest.NewServer(metricstore.NewHandler(store))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?kind=Server&namespace=default&name=svr&metric=memory&step=1s")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var body struct {
+		Samples []metricstore.Sample `json:"samples"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	require.Len(t, body.Samples, 1)
+	require.Equal(t, float64(123), body.Samples[0].Value)
+
+	resp, err = http.Get(srv.URL + "?kind=Server&namespace=default&name=svr&metric=bogus")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
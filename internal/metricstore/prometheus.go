@@ -0,0 +1,88 @@
+package metricstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// PrometheusStore reads history from a Prometheus server that scrapes the
+// kubelet (cAdvisor) and DCGM exporter metrics. Prometheus is responsible
+// for collection and retention so appended samples are ignored.
+type PrometheusStore struct {
+	API promv1.API
+}
+
+func NewPrometheusStore(address string) (*PrometheusStore, error) {
+	c, err := api.NewClient(api.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("creating prometheus client: %w", err)
+	}
+	return &PrometheusStore{API: promv1.NewAPI(c)}, nil
+}
+
+func (s *PrometheusStore) Append(context.Context, Object, Metric, Sample) error {
+	return nil
+}
+
+func (s *PrometheusStore) Delete(context.Context, Object) error {
+	return nil
+}
+
+func (s *PrometheusStore) Query(ctx context.Context, q Query) ([]Sample, error) {
+	expr, err := promQL(q.Object, q.Metric)
+	if err != nil {
+		return nil, err
+	}
+
+	val, _, err := s.API.QueryRange(ctx, expr, promv1.Range{
+		Start: q.Start,
+		End:   q.End,
+		Step:  q.Step,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("querying prometheus: %w", err)
+	}
+
+	matrix, ok := val.(model.Matrix)
+	if !ok {
+		return nil, fmt.Errorf("unexpected prometheus result type: %s", val.Type())
+	}
+	if len(matrix) == 0 {
+		return nil, nil
+	}
+
+	samples := make([]Sample, 0, len(matrix[0].Values))
+	for _, v := range matrix[0].Values {
+		samples = append(samples, Sample{Time: v.Timestamp.Time(), Value: float64(v.Value)})
+	}
+	return samples, nil
+}
+
+func promQL(obj Object, metric Metric) (string, error) {
+	var podPattern string
+	switch obj.Kind {
+	case "Notebook":
+		podPattern = obj.Name + "-notebook"
+	case "Server":
+		podPattern = obj.Name + "-server-.+"
+	default:
+		return "", fmt.Errorf("unsupported kind: %q", obj.Kind)
+	}
+	selector := fmt.Sprintf(`namespace=%q,pod=~%q`, obj.Namespace, podPattern)
+
+	switch metric {
+	case MetricCPU:
+		return fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{%s,container!=""}[5m]))`, selector), nil
+	case MetricMemory:
+		return fmt.Sprintf(`sum(container_memory_working_set_bytes{%s,container!=""})`, selector), nil
+	case MetricDisk:
+		return fmt.Sprintf(`sum(container_fs_usage_bytes{%s,container!=""})`, selector), nil
+	case MetricGPU:
+		return fmt.Sprintf(`avg(DCGM_FI_DEV_GPU_UTIL{%s})`, selector), nil
+	}
+	return "", metric.Validate()
+}
@@ -0,0 +1,66 @@
+// Package metricstore keeps a history of the resource usage of Substratus
+// objects (Notebooks and Servers) so that usage can be rendered over
+// long running (multi-day) sessions.
+package metricstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Metric string
+
+const (
+	// MetricCPU is CPU usage in cores.
+	MetricCPU Metric = "cpu"
+	// MetricMemory is memory (working set) usage in bytes.
+	MetricMemory Metric = "memory"
+	// MetricDisk is ephemeral storage usage in bytes.
+	MetricDisk Metric = "disk"
+	// MetricGPU is GPU utilization as a percentage.
+	MetricGPU Metric = "gpu"
+)
+
+func (m Metric) Validate() error {
+	switch m {
+	case MetricCPU, MetricMemory, MetricDisk, MetricGPU:
+		return nil
+	}
+	return fmt.Errorf("unsupported metric: %q", m)
+}
+
+// Object identifies the Substratus object that the metrics belong to.
+type Object struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// Sample is a single data point.
+type Sample struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Query selects the samples of a metric for an object within a time range.
+// When Step is set, samples are aggregated into Step sized buckets.
+type Query struct {
+	Object
+	Metric Metric
+	Start  time.Time
+	End    time.Time
+	Step   time.Duration
+}
+
+// Store is implemented by the metric history backends.
+type Store interface {
+	// Append records a sample. Backends that collect data by other means
+	// (e.g. Prometheus scraping exporters) may ignore appended samples.
+	Append(ctx context.Context, obj Object, metric Metric, s Sample) error
+	// Query returns samples ordered by time.
+	Query(ctx context.Context, q Query) ([]Sample, error)
+	// Delete removes the history of an object that no longer exists.
+	// Backends that do not own their data may ignore it.
+	Delete(ctx context.Context, obj Object) error
+}
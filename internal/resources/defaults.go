@@ -0,0 +1,88 @@
+package resources
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+)
+
+// DefaultsKey is the ConfigMap key that holds the defaults used when no GPU
+// type specific defaults apply.
+const DefaultsKey = "default"
+
+// Defaults are cluster-wide default resources that are used in place of
+// omitted CPU, Memory and Disk values.
+type Defaults struct {
+	// Default is used when no GPUs are requested (and when Resources are
+	// omitted altogether).
+	Default *apiv1.Resources
+	// GPUs holds the defaults per requested GPU type.
+	GPUs map[apiv1.GPUType]apiv1.Resources
+}
+
+// ParseDefaults parses the data of a defaults ConfigMap. Each key is either
+// "default" or a GPU type and each value is a YAML encoded Resources object
+// (i.e. "cpu: 8\nmemory: 32\ndisk: 100").
+func ParseDefaults(cloudName string, data map[string]string) (*Defaults, error) {
+	d := &Defaults{GPUs: map[apiv1.GPUType]apiv1.Resources{}}
+	for key, value := range data {
+		var res apiv1.Resources
+		if err := yaml.UnmarshalStrict([]byte(value), &res); err != nil {
+			return nil, fmt.Errorf("parsing %q: %w", key, err)
+		}
+		if res.GPU != nil {
+			return nil, fmt.Errorf("parsing %q: gpu can not be defaulted", key)
+		}
+		if key == DefaultsKey {
+			d.Default = &res
+			continue
+		}
+		gpuType := apiv1.GPUType(key)
+		if err := ValidateGPUType(cloudName, gpuType); err != nil {
+			return nil, fmt.Errorf("parsing %q: %w", key, err)
+		}
+		d.GPUs[gpuType] = res
+	}
+	return d, nil
+}
+
+// Resolve returns a copy of res with omitted CPU, Memory and Disk values set
+// from the defaults for the requested GPU type, falling back to the general
+// defaults. Nil resources resolve to the general defaults. A nil *Defaults
+// resolves to res as-is.
+func (d *Defaults) Resolve(res *apiv1.Resources) *apiv1.Resources {
+	if d == nil {
+		return res
+	}
+	if res == nil {
+		if d.Default == nil {
+			return nil
+		}
+		return d.Default.DeepCopy()
+	}
+
+	res = res.DeepCopy()
+	if res.GPU != nil {
+		if def, ok := d.GPUs[res.GPU.Type]; ok {
+			fillUnset(res, &def)
+		}
+	}
+	if d.Default != nil {
+		fillUnset(res, d.Default)
+	}
+	return res
+}
+
+func fillUnset(res, def *apiv1.Resources) {
+	if res.CPU == 0 {
+		res.CPU = def.CPU
+	}
+	if res.Memory == 0 {
+		res.Memory = def.Memory
+	}
+	if res.Disk == 0 {
+		res.Disk = def.Disk
+	}
+}
@@ -0,0 +1,84 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+func Test_ParseDefaults(t *testing.T) {
+	d, err := ParseDefaults(cloud.GCPName, map[string]string{
+		"default":     "cpu: 4\nmemory: 8\ndisk: 50",
+		"nvidia-l4":   "cpu: 8\nmemory: 32",
+		"nvidia-a100": "cpu: 12\nmemory: 85\ndisk: 200",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &apiv1.Resources{CPU: 4, Memory: 8, Disk: 50}, d.Default)
+	require.Equal(t, apiv1.Resources{CPU: 8, Memory: 32}, d.GPUs[apiv1.GPUTypeNvidiaL4])
+	require.Len(t, d.GPUs, 2)
+
+	_, err = ParseDefaults(cloud.GCPName, map[string]string{"nvidia-h100": "cpu: 8"})
+	require.ErrorContains(t, err, "not supported")
+
+	_, err = ParseDefaults(cloud.GCPName, map[string]string{"default": "cores: 8"})
+	require.ErrorContains(t, err, `parsing "default"`)
+
+	_, err = ParseDefaults(cloud.GCPName, map[string]string{"default": "gpu: {type: nvidia-l4, count: 1}"})
+	require.ErrorContains(t, err, "gpu can not be defaulted")
+}
+
+func Test_DefaultsResolve(t *testing.T) {
+	d := &Defaults{
+		Default: &apiv1.Resources{CPU: 4, Memory: 8, Disk: 50},
+		GPUs: map[apiv1.GPUType]apiv1.Resources{
+			apiv1.GPUTypeNvidiaL4: {CPU: 8, Memory: 32},
+		},
+	}
+	l4 := &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaL4, Count: 1}
+	t4 := &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaT4, Count: 1}
+
+	cases := []struct {
+		name     string
+		defaults *Defaults
+		input    *apiv1.Resources
+		expected *apiv1.Resources
+	}{
+		{
+			name:     "nil defaults",
+			defaults: nil,
+			input:    &apiv1.Resources{GPU: l4},
+			expected: &apiv1.Resources{GPU: l4},
+		},
+		{
+			name:     "nil resources",
+			defaults: d,
+			input:    nil,
+			expected: &apiv1.Resources{CPU: 4, Memory: 8, Disk: 50},
+		},
+		{
+			name:     "gpu type defaults",
+			defaults: d,
+			input:    &apiv1.Resources{GPU: l4},
+			expected: &apiv1.Resources{CPU: 8, Memory: 32, Disk: 50, GPU: l4},
+		},
+		{
+			name:     "gpu type without defaults",
+			defaults: d,
+			input:    &apiv1.Resources{GPU: t4},
+			expected: &apiv1.Resources{CPU: 4, Memory: 8, Disk: 50, GPU: t4},
+		},
+		{
+			name:     "explicit values are kept",
+			defaults: d,
+			input:    &apiv1.Resources{CPU: 1, GPU: l4},
+			expected: &apiv1.Resources{CPU: 1, Memory: 32, Disk: 50, GPU: l4},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, c.defaults.Resolve(c.input))
+		})
+	}
+}
@@ -1,6 +1,9 @@
 package resources
 
 import (
+	"fmt"
+	"sort"
+
 	apiv1 "github.com/substratusai/substratus/api/v1"
 	"github.com/substratusai/substratus/internal/cloud"
 	corev1 "k8s.io/api/core/v1"
@@ -8,7 +11,15 @@ import (
 
 type GPUInfo struct {
 	ResourceName corev1.ResourceName
+	// NodeSelector holds all labels that nodes with the GPU carry (i.e. the
+	// accelerator and the machine family that it is attached to). All of
+	// them are merged into the Pod's node selector.
 	NodeSelector map[string]string
+	// Memory per GPU in Gigabytes, 0 if unknown.
+	Memory int64
+	// MIGProfiles are the Multi-Instance GPU partition sizes (i.e. "1g.5gb")
+	// that the GPU can be partitioned into, empty when MIG is not supported.
+	MIGProfiles []string
 }
 
 func GetGPUInfo(cloudName string, gpuType apiv1.GPUType) (*GPUInfo, bool) {
@@ -22,6 +33,36 @@ func GetGPUInfo(cloudName string, gpuType apiv1.GPUType) (*GPUInfo, bool) {
 	return gpuInfo, ok
 }
 
+// SupportedGPUs returns the GPU types that can be requested on the given
+// cloud, sorted by name.
+func SupportedGPUs(cloudName string) []apiv1.GPUType {
+	var gpus []apiv1.GPUType
+	if cloudName == cloud.KindName {
+		gpus = append(gpus, apiv1.GPUTypes...)
+	} else {
+		for t := range cloudGPUs[cloudName] {
+			gpus = append(gpus, t)
+		}
+	}
+	sort.Slice(gpus, func(i, j int) bool { return gpus[i] < gpus[j] })
+	return gpus
+}
+
+// ValidateGPUType returns an error listing the supported GPU types if the
+// given type can not be requested on the cloud.
+func ValidateGPUType(cloudName string, gpuType apiv1.GPUType) error {
+	supported := SupportedGPUs(cloudName)
+	for _, t := range supported {
+		if t == gpuType {
+			return nil
+		}
+	}
+	if len(supported) == 0 {
+		return fmt.Errorf("GPUs are not supported on cloud %q", cloudName)
+	}
+	return fmt.Errorf("GPU type %q is not supported on cloud %q, supported types: %v", gpuType, cloudName, supported)
+}
+
 var cloudGPUs = map[string]map[apiv1.GPUType]*GPUInfo{
 	cloud.GCPName: {
 		// https://cloud.google.com/compute/docs/gpus#nvidia_t4_gpus
@@ -29,20 +70,29 @@ var cloudGPUs = map[string]map[apiv1.GPUType]*GPUInfo{
 			ResourceName: corev1.ResourceName("nvidia.com/gpu"),
 			NodeSelector: map[string]string{
 				"cloud.google.com/gke-accelerator": "nvidia-tesla-t4",
+				"cloud.google.com/machine-family":  "n1",
 			},
+			Memory: 16,
 		},
 		// https://cloud.google.com/compute/docs/gpus#l4-gpus
 		apiv1.GPUTypeNvidiaL4: {
 			ResourceName: corev1.ResourceName("nvidia.com/gpu"),
 			NodeSelector: map[string]string{
 				"cloud.google.com/gke-accelerator": "nvidia-l4",
+				"cloud.google.com/machine-family":  "g2",
 			},
+			Memory: 24,
 		},
 		apiv1.GPUTypeNvidiaA100: {
 			ResourceName: corev1.ResourceName("nvidia.com/gpu"),
 			NodeSelector: map[string]string{
 				"cloud.google.com/gke-accelerator": "nvidia-tesla-a100",
+				"cloud.google.com/machine-family":  "a2",
 			},
+			// The 40GB variant (nvidia-a100-80gb is a separate accelerator).
+			Memory: 40,
+			// https://cloud.google.com/kubernetes-engine/docs/how-to/gpus-multi#partition-sizes
+			MIGProfiles: []string{"1g.5gb", "2g.10gb", "3g.20gb", "7g.40gb"},
 		},
 	},
 }
@@ -8,23 +8,16 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
 )
 
 func Apply(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, containerName string, cloudName string, res *apiv1.Resources) error {
 	// TODO: Auto-determine resources if nil.
 	if res == nil {
-		// TODO(nstogner): Cloud-specific conditional should go away...
-		// Most likely this stuff will all go into a ConfigMap that contains cloud-specific
-		// information.
-		if cloudName == "kind" {
-			res = &apiv1.Resources{}
-		} else {
-			res = &apiv1.Resources{
-				CPU:    2,
-				Memory: 4,
-				Disk:   100,
-			}
-		}
+		res = defaultResources(cloudName)
+	} else {
+		res = res.DeepCopy()
+		fillUnset(res, &unsetResources)
 	}
 
 	resources := corev1.ResourceRequirements{
@@ -32,16 +25,65 @@ func Apply(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, containerNam
 		Limits:   corev1.ResourceList{},
 	}
 
+	var gpuInfo *GPUInfo
+	if res.GPU != nil {
+		if err := ValidateGPUType(cloudName, res.GPU.Type); err != nil {
+			return err
+		}
+		gpuInfo, _ = GetGPUInfo(cloudName, res.GPU.Type)
+
+		if sharing := res.GPU.Sharing; sharing != nil {
+			if err := ValidateGPUSharing(cloudName, res.GPU); err != nil {
+				return err
+			}
+			resourceName, nodeSelector, memory := sharedGPU(cloudName, gpuInfo, sharing)
+			shared := &GPUInfo{
+				ResourceName: resourceName,
+				NodeSelector: map[string]string{},
+				Memory:       memory,
+			}
+			for k, v := range gpuInfo.NodeSelector {
+				shared.NodeSelector[k] = v
+			}
+			for k, v := range nodeSelector {
+				shared.NodeSelector[k] = v
+			}
+			gpuInfo = shared
+		}
+	}
+
+	memory, err := memoryRequest(res, gpuInfo)
+	if err != nil {
+		return err
+	}
+
 	resources.Requests[corev1.ResourceCPU] = *resource.NewQuantity(res.CPU, resource.DecimalSI)
-	resources.Requests[corev1.ResourceMemory] = *resource.NewQuantity(res.Memory*gigabyte, resource.BinarySI)
+	resources.Requests[corev1.ResourceMemory] = *resource.NewQuantity(memory, resource.BinarySI)
 	resources.Requests[corev1.ResourceEphemeralStorage] = *resource.NewQuantity(res.Disk*gigabyte, resource.BinarySI)
 
-	if res.GPU != nil {
-		gpuInfo, ok := GetGPUInfo(cloudName, res.GPU.Type)
-		if !ok {
-			return fmt.Errorf("GPU %s is not supported on cloud %s", res.GPU.Type, cloudName)
+	if res.Limits != nil {
+		if res.Limits.CPU != 0 {
+			if res.Limits.CPU < res.CPU {
+				return fmt.Errorf("CPU limit %d is less than the requested CPU %d", res.Limits.CPU, res.CPU)
+			}
+			resources.Limits[corev1.ResourceCPU] = *resource.NewQuantity(res.Limits.CPU, resource.DecimalSI)
+		}
+		if res.Limits.Memory != 0 {
+			if res.Limits.Memory*gigabyte < memory {
+				return fmt.Errorf("memory limit %dGB is less than the requested memory %dGB", res.Limits.Memory, res.Memory)
+			}
+			resources.Limits[corev1.ResourceMemory] = *resource.NewQuantity(res.Limits.Memory*gigabyte, resource.BinarySI)
 		}
+		if res.Limits.Disk != 0 {
+			if res.Limits.Disk < res.Disk {
+				return fmt.Errorf("disk limit %dGB is less than the requested disk %dGB", res.Limits.Disk, res.Disk)
+			}
+			resources.Limits[corev1.ResourceEphemeralStorage] = *resource.NewQuantity(res.Limits.Disk*gigabyte, resource.BinarySI)
+		}
+	}
 
+	if res.GPU != nil {
+		// Kubernetes requires GPU requests to equal limits.
 		resources.Requests[gpuInfo.ResourceName] = *resource.NewQuantity(res.GPU.Count, resource.DecimalSI)
 		resources.Limits[gpuInfo.ResourceName] = *resource.NewQuantity(res.GPU.Count, resource.DecimalSI)
 
@@ -64,6 +106,15 @@ func Apply(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, containerNam
 		}
 	}
 
+	if res.NodePool != "" {
+		if key, ok := nodePoolLabels[cloudName]; ok {
+			if podSpec.NodeSelector == nil {
+				podSpec.NodeSelector = map[string]string{}
+			}
+			podSpec.NodeSelector[key] = res.NodePool
+		}
+	}
+
 	if !setContainerResources(containerName, podSpec, resources) {
 		return fmt.Errorf("container %s not found in pod", containerName)
 	}
@@ -71,6 +122,90 @@ func Apply(podMetadata *metav1.ObjectMeta, podSpec *corev1.PodSpec, containerNam
 	return nil
 }
 
+// nodePoolLabels are the node labels that hold the name of the node pool
+// per cloud. Clouds without node pools (kind) are not listed.
+var nodePoolLabels = map[string]string{
+	cloud.GCPName: "cloud.google.com/gke-nodepool",
+}
+
+// memoryRequest returns the memory request in bytes. When GPUs are requested
+// the memory is raised to at least the total GPU memory, as loading a model
+// onto the GPUs typically requires it to fit in host memory first. An
+// explicit memory limit below that floor takes precedence.
+func memoryRequest(res *apiv1.Resources, gpuInfo *GPUInfo) (int64, error) {
+	memory := res.Memory * gigabyte
+	if gpuInfo == nil || gpuInfo.Memory == 0 || res.GPU.Count == 0 {
+		return memory, nil
+	}
+
+	floor, err := roundUpGB(res.GPU.Count * gpuInfo.Memory * gigabyte)
+	if err != nil {
+		return 0, fmt.Errorf("GPU memory: %w", err)
+	}
+	if res.Limits != nil && res.Limits.Memory != 0 && res.Limits.Memory*gigabyte < floor {
+		floor = res.Limits.Memory * gigabyte
+	}
+	if memory < floor {
+		memory = floor
+	}
+	return memory, nil
+}
+
+// unsetResources are used for CPU, Memory and Disk values that are left
+// unset when other resources (i.e. GPUs) are specified and no Defaults
+// apply.
+var unsetResources = apiv1.Resources{
+	CPU:    2,
+	Memory: 10,
+	Disk:   10,
+}
+
+func defaultResources(cloudName string) *apiv1.Resources {
+	// TODO(nstogner): Cloud-specific conditional should go away...
+	// Most likely this stuff will all go into a ConfigMap that contains cloud-specific
+	// information.
+	if cloudName == "kind" {
+		return &apiv1.Resources{}
+	}
+	return &apiv1.Resources{
+		CPU:    2,
+		Memory: 4,
+		Disk:   100,
+	}
+}
+
+// LoaderResources returns the resources for a Dataset's data-loader container.
+// Memory and disk limits that are not explicitly set default to the requested
+// amounts, like for Guaranteed QoS: the loader never uses more than the node
+// set aside for it, so a download that outgrows its requests fails at a known
+// size (OOMKilled or evicted for its ephemeral storage) instead of starving
+// the other Pods on the node. CPU is left unlimited to avoid throttling
+// downloads, which keeps the Pod Burstable. Omitted values are resolved the
+// same way as in Apply before the limits are derived from them.
+func LoaderResources(cloudName string, res *apiv1.Resources) *apiv1.Resources {
+	if res == nil {
+		res = defaultResources(cloudName)
+	} else {
+		res = res.DeepCopy()
+		fillUnset(res, &unsetResources)
+	}
+	if cloudName == "kind" {
+		return res
+	}
+
+	if res.Limits == nil {
+		res.Limits = &apiv1.ResourceLimits{}
+	}
+	if res.Limits.Memory == 0 {
+		res.Limits.Memory = res.Memory
+	}
+	if res.Limits.Disk == 0 {
+		res.Limits.Disk = res.Disk
+	}
+
+	return res
+}
+
 func ContainerBuilderResources(cloudName string) corev1.ResourceRequirements {
 	// TODO(nstogner): Cloud-specific conditional should go away...
 	// Most likely this stuff will all go into a ConfigMap that contains cloud-specific
@@ -32,6 +32,11 @@ func Test_Apply(t *testing.T) {
 			Resources: nil,
 			Expected:  &apiv1.Resources{CPU: 2, Memory: 4, Disk: 100},
 		},
+		{
+			Name:      "partially set",
+			Resources: &apiv1.Resources{Disk: 300},
+			Expected:  &apiv1.Resources{CPU: 2, Memory: 10, Disk: 300},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -46,3 +51,195 @@ func Test_Apply(t *testing.T) {
 			resource.NewQuantity(testCase.Expected.Disk*gigabyte, resource.BinarySI))
 	}
 }
+
+func Test_ApplyLimits(t *testing.T) {
+	objectMeta := &metav1.ObjectMeta{Name: "test", Namespace: "test"}
+
+	testCases := []struct {
+		Name           string
+		Resources      *apiv1.Resources
+		ExpectedLimits corev1.ResourceList
+		ExpectError    bool
+	}{
+		{
+			Name:           "no limits",
+			Resources:      &apiv1.Resources{CPU: 2, Memory: 4},
+			ExpectedLimits: corev1.ResourceList{},
+		},
+		{
+			Name:      "limits above requests",
+			Resources: &apiv1.Resources{CPU: 2, Memory: 4, Limits: &apiv1.ResourceLimits{CPU: 4, Memory: 8}},
+			ExpectedLimits: corev1.ResourceList{
+				corev1.ResourceCPU:    *resource.NewQuantity(4, resource.DecimalSI),
+				corev1.ResourceMemory: *resource.NewQuantity(8*gigabyte, resource.BinarySI),
+			},
+		},
+		{
+			Name:      "memory limit only",
+			Resources: &apiv1.Resources{CPU: 2, Memory: 4, Limits: &apiv1.ResourceLimits{Memory: 4}},
+			ExpectedLimits: corev1.ResourceList{
+				corev1.ResourceMemory: *resource.NewQuantity(4*gigabyte, resource.BinarySI),
+			},
+		},
+		{
+			Name:      "disk limit",
+			Resources: &apiv1.Resources{CPU: 2, Memory: 4, Disk: 100, Limits: &apiv1.ResourceLimits{Disk: 200}},
+			ExpectedLimits: corev1.ResourceList{
+				corev1.ResourceEphemeralStorage: *resource.NewQuantity(200*gigabyte, resource.BinarySI),
+			},
+		},
+		{
+			Name:        "disk limit below request",
+			Resources:   &apiv1.Resources{CPU: 2, Memory: 4, Disk: 100, Limits: &apiv1.ResourceLimits{Disk: 50}},
+			ExpectError: true,
+		},
+		{
+			Name:        "cpu limit below request",
+			Resources:   &apiv1.Resources{CPU: 4, Memory: 4, Limits: &apiv1.ResourceLimits{CPU: 2}},
+			ExpectError: true,
+		},
+		{
+			Name:        "memory limit below request",
+			Resources:   &apiv1.Resources{CPU: 2, Memory: 8, Limits: &apiv1.ResourceLimits{Memory: 4}},
+			ExpectError: true,
+		},
+		{
+			Name:      "gpu limit equals request",
+			Resources: &apiv1.Resources{CPU: 2, Memory: 4, GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaL4, Count: 2}},
+			ExpectedLimits: corev1.ResourceList{
+				"nvidia.com/gpu": *resource.NewQuantity(2, resource.DecimalSI),
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "test"},
+			}}
+			err := Apply(objectMeta, podSpec, "test", cloud.GCPName, testCase.Resources)
+			if testCase.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.ExpectedLimits, podSpec.Containers[0].Resources.Limits)
+			for name, limit := range podSpec.Containers[0].Resources.Limits {
+				if name == "nvidia.com/gpu" {
+					require.Equal(t, limit, podSpec.Containers[0].Resources.Requests[name])
+				}
+			}
+		})
+	}
+}
+
+func Test_LoaderResources(t *testing.T) {
+	require.Equal(t,
+		&apiv1.Resources{CPU: 2, Memory: 4, Disk: 100, Limits: &apiv1.ResourceLimits{Memory: 4, Disk: 100}},
+		LoaderResources(cloud.GCPName, nil))
+
+	res := &apiv1.Resources{CPU: 4, Memory: 16, Disk: 200, Limits: &apiv1.ResourceLimits{Memory: 32}}
+	require.Equal(t,
+		&apiv1.Resources{CPU: 4, Memory: 16, Disk: 200, Limits: &apiv1.ResourceLimits{Memory: 32, Disk: 200}},
+		LoaderResources(cloud.GCPName, res))
+	require.Equal(t, &apiv1.ResourceLimits{Memory: 32}, res.Limits, "input should not be modified")
+
+	// Partially set resources get limits for the resolved values.
+	require.Equal(t,
+		&apiv1.Resources{CPU: 4, Memory: 10, Disk: 10, Limits: &apiv1.ResourceLimits{Memory: 10, Disk: 10}},
+		LoaderResources(cloud.GCPName, &apiv1.Resources{CPU: 4}))
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "loader"}}}
+	require.NoError(t, Apply(&metav1.ObjectMeta{}, podSpec, "loader", cloud.GCPName, LoaderResources(cloud.GCPName, &apiv1.Resources{CPU: 4})))
+	limits := podSpec.Containers[0].Resources.Limits
+	require.Equal(t, int64(10*gigabyte), limits.Memory().Value())
+	require.Equal(t, int64(10*gigabyte), limits.StorageEphemeral().Value())
+
+	require.Equal(t, &apiv1.Resources{}, LoaderResources("kind", nil))
+}
+
+func Test_ValidateGPUType(t *testing.T) {
+	require.Equal(t, []apiv1.GPUType{apiv1.GPUTypeNvidiaA100, apiv1.GPUTypeNvidiaL4, apiv1.GPUTypeNvidiaT4}, SupportedGPUs(cloud.GCPName))
+	require.Empty(t, SupportedGPUs("unknown"))
+
+	require.NoError(t, ValidateGPUType(cloud.GCPName, apiv1.GPUTypeNvidiaL4))
+	require.NoError(t, ValidateGPUType(cloud.KindName, apiv1.GPUTypeNvidiaT4))
+
+	err := ValidateGPUType(cloud.GCPName, apiv1.GPUType("nvidia-h100"))
+	require.ErrorContains(t, err, "nvidia-l4")
+
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+	err = Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.GCPName, &apiv1.Resources{
+		GPU: &apiv1.GPUResources{Type: apiv1.GPUType("nvidia-h100"), Count: 1},
+	})
+	require.ErrorContains(t, err, "not supported")
+}
+
+func Test_ApplyGPUMemoryFloor(t *testing.T) {
+	testCases := []struct {
+		Name           string
+		Resources      *apiv1.Resources
+		ExpectedMemory int64
+	}{
+		{
+			Name:           "raised to gpu memory",
+			Resources:      &apiv1.Resources{CPU: 2, Memory: 10, GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaL4, Count: 2}},
+			ExpectedMemory: 48,
+		},
+		{
+			Name:           "requested memory above gpu memory",
+			Resources:      &apiv1.Resources{CPU: 2, Memory: 64, GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaT4, Count: 1}},
+			ExpectedMemory: 64,
+		},
+		{
+			Name: "capped by memory limit",
+			Resources: &apiv1.Resources{CPU: 2, Memory: 10, GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 1},
+				Limits: &apiv1.ResourceLimits{Memory: 20}},
+			ExpectedMemory: 20,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+			err := Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.GCPName, testCase.Resources)
+			require.NoError(t, err)
+			require.Equal(t, resource.NewQuantity(testCase.ExpectedMemory*gigabyte, resource.BinarySI),
+				podSpec.Containers[0].Resources.Requests.Memory())
+		})
+	}
+
+	// No GPU memory information on kind.
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+	require.NoError(t, Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.KindName,
+		&apiv1.Resources{Memory: 1, GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaT4, Count: 1}}))
+	require.Equal(t, resource.NewQuantity(gigabyte, resource.BinarySI), podSpec.Containers[0].Resources.Requests.Memory())
+}
+
+func Test_ApplyNodeSelector(t *testing.T) {
+	a100 := &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 1}
+
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+	require.NoError(t, Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.GCPName, &apiv1.Resources{GPU: a100}))
+	require.Equal(t, map[string]string{
+		"cloud.google.com/gke-accelerator": "nvidia-tesla-a100",
+		"cloud.google.com/machine-family":  "a2",
+	}, podSpec.NodeSelector)
+
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+	require.NoError(t, Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.GCPName, &apiv1.Resources{GPU: a100, NodePool: "a2-highgpu"}))
+	require.Equal(t, map[string]string{
+		"cloud.google.com/gke-accelerator": "nvidia-tesla-a100",
+		"cloud.google.com/machine-family":  "a2",
+		"cloud.google.com/gke-nodepool":    "a2-highgpu",
+	}, podSpec.NodeSelector)
+
+	// Without GPUs.
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+	require.NoError(t, Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.GCPName, &apiv1.Resources{NodePool: "cpu-pool"}))
+	require.Equal(t, map[string]string{"cloud.google.com/gke-nodepool": "cpu-pool"}, podSpec.NodeSelector)
+
+	// Node pools are ignored on kind.
+	podSpec = &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+	require.NoError(t, Apply(&metav1.ObjectMeta{}, podSpec, "test", cloud.KindName, &apiv1.Resources{NodePool: "cpu-pool"}))
+	require.Empty(t, podSpec.NodeSelector)
+}
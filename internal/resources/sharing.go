@@ -0,0 +1,100 @@
+package resources
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+)
+
+const defaultMaxSharedClients = 2
+
+// ValidateGPUSharing returns an error if the GPU can not be shared as
+// requested on the cloud.
+func ValidateGPUSharing(cloudName string, gpu *apiv1.GPUResources) error {
+	s := gpu.Sharing
+	if s == nil {
+		return nil
+	}
+	switch s.Strategy {
+	case apiv1.GPUSharingTimeSharing:
+		if gpu.Count != 1 {
+			return fmt.Errorf("time-shared GPUs can only be requested one at a time, got count %d", gpu.Count)
+		}
+		if s.Profile != "" {
+			return fmt.Errorf("profile is only supported by the %s strategy", apiv1.GPUSharingMIG)
+		}
+	case apiv1.GPUSharingMIG:
+		if _, err := migProfileMemory(s.Profile); err != nil {
+			return err
+		}
+		if cloudName == cloud.KindName {
+			return nil
+		}
+		gpuInfo, ok := GetGPUInfo(cloudName, gpu.Type)
+		if !ok {
+			return fmt.Errorf("GPU type %q is not supported on cloud %q", gpu.Type, cloudName)
+		}
+		for _, p := range gpuInfo.MIGProfiles {
+			if p == s.Profile {
+				return nil
+			}
+		}
+		if len(gpuInfo.MIGProfiles) == 0 {
+			return fmt.Errorf("GPU type %q does not support MIG on cloud %q", gpu.Type, cloudName)
+		}
+		return fmt.Errorf("MIG profile %q is not supported by GPU type %q, supported profiles: %v", s.Profile, gpu.Type, gpuInfo.MIGProfiles)
+	default:
+		return fmt.Errorf("unsupported GPU sharing strategy %q", s.Strategy)
+	}
+	return nil
+}
+
+// sharedGPU returns the resource name, additional node selectors and the
+// memory (in Gigabytes, 0 if unknown) of a share of the GPU.
+func sharedGPU(cloudName string, gpuInfo *GPUInfo, s *apiv1.GPUSharing) (corev1.ResourceName, map[string]string, int64) {
+	switch s.Strategy {
+	case apiv1.GPUSharingMIG:
+		memory, _ := migProfileMemory(s.Profile)
+		if cloudName == cloud.KindName {
+			// The NVIDIA device plugin advertises each partition size as a
+			// separate resource (mixed MIG strategy).
+			return corev1.ResourceName("nvidia.com/mig-" + s.Profile), nil, memory
+		}
+		// GKE advertises partitions as whole GPUs on nodes that are
+		// partitioned into the selected size.
+		return gpuInfo.ResourceName, map[string]string{
+			"cloud.google.com/gke-gpu-partition-size": s.Profile,
+		}, memory
+	default:
+		if cloudName == cloud.KindName {
+			return gpuInfo.ResourceName, nil, gpuInfo.Memory
+		}
+		maxClients := s.MaxSharedClients
+		if maxClients == 0 {
+			maxClients = defaultMaxSharedClients
+		}
+		return gpuInfo.ResourceName, map[string]string{
+			"cloud.google.com/gke-gpu-sharing-strategy":       "time-sharing",
+			"cloud.google.com/gke-max-shared-clients-per-gpu": strconv.Itoa(int(maxClients)),
+		}, gpuInfo.Memory
+	}
+}
+
+// migProfileMemory returns the memory in Gigabytes of a MIG profile
+// (i.e. 5 for "1g.5gb").
+func migProfileMemory(profile string) (int64, error) {
+	compute, memory, ok := strings.Cut(profile, ".")
+	if !ok || !strings.HasSuffix(compute, "g") || !strings.HasSuffix(memory, "gb") {
+		return 0, fmt.Errorf("invalid MIG profile %q, expected <compute>g.<memory>gb (i.e. 1g.5gb)", profile)
+	}
+	gb, err := strconv.ParseInt(strings.TrimSuffix(memory, "gb"), 10, 64)
+	if err != nil || gb <= 0 {
+		return 0, fmt.Errorf("invalid MIG profile %q, expected <compute>g.<memory>gb (i.e. 1g.5gb)", profile)
+	}
+	return gb, nil
+}
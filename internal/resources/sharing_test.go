@@ -0,0 +1,101 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_ApplyGPUSharing(t *testing.T) {
+	testCases := []struct {
+		Name                 string
+		Cloud                string
+		GPU                  *apiv1.GPUResources
+		ExpectedResourceName corev1.ResourceName
+		ExpectedNodeSelector map[string]string
+		ExpectedMemory       int64
+		ExpectedError        string
+	}{
+		{
+			Name:                 "gke mig",
+			Cloud:                cloud.GCPName,
+			GPU:                  &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 1, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "1g.5gb"}},
+			ExpectedResourceName: "nvidia.com/gpu",
+			ExpectedNodeSelector: map[string]string{
+				"cloud.google.com/gke-accelerator":        "nvidia-tesla-a100",
+				"cloud.google.com/machine-family":         "a2",
+				"cloud.google.com/gke-gpu-partition-size": "1g.5gb",
+			},
+			ExpectedMemory: 5,
+		},
+		{
+			Name:                 "gke time-sharing",
+			Cloud:                cloud.GCPName,
+			GPU:                  &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaL4, Count: 1, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingTimeSharing, MaxSharedClients: 4}},
+			ExpectedResourceName: "nvidia.com/gpu",
+			ExpectedNodeSelector: map[string]string{
+				"cloud.google.com/gke-accelerator":                "nvidia-l4",
+				"cloud.google.com/machine-family":                 "g2",
+				"cloud.google.com/gke-gpu-sharing-strategy":       "time-sharing",
+				"cloud.google.com/gke-max-shared-clients-per-gpu": "4",
+			},
+			ExpectedMemory: 24,
+		},
+		{
+			Name:                 "kind mig",
+			Cloud:                cloud.KindName,
+			GPU:                  &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 2, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "3g.20gb"}},
+			ExpectedResourceName: "nvidia.com/mig-3g.20gb",
+			ExpectedNodeSelector: map[string]string{},
+			ExpectedMemory:       40,
+		},
+		{
+			Name:          "mig not supported by gpu type",
+			Cloud:         cloud.GCPName,
+			GPU:           &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaT4, Count: 1, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "1g.5gb"}},
+			ExpectedError: "does not support MIG",
+		},
+		{
+			Name:          "unknown mig profile",
+			Cloud:         cloud.GCPName,
+			GPU:           &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 1, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "4g.20gb"}},
+			ExpectedError: "supported profiles",
+		},
+		{
+			Name:          "invalid mig profile",
+			Cloud:         cloud.KindName,
+			GPU:           &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 1, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "small"}},
+			ExpectedError: "invalid MIG profile",
+		},
+		{
+			Name:          "multiple time-shared gpus",
+			Cloud:         cloud.GCPName,
+			GPU:           &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaL4, Count: 2, Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingTimeSharing}},
+			ExpectedError: "one at a time",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "test"}}}
+			err := Apply(&metav1.ObjectMeta{}, podSpec, "test", testCase.Cloud, &apiv1.Resources{CPU: 1, Memory: 1, GPU: testCase.GPU})
+			if testCase.ExpectedError != "" {
+				require.ErrorContains(t, err, testCase.ExpectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			requests := podSpec.Containers[0].Resources.Requests
+			require.Equal(t, resource.NewQuantity(testCase.GPU.Count, resource.DecimalSI), ptrTo(requests[testCase.ExpectedResourceName]))
+			require.Equal(t, testCase.ExpectedNodeSelector, podSpec.NodeSelector)
+			require.Equal(t, resource.NewQuantity(testCase.ExpectedMemory*gigabyte, resource.BinarySI), requests.Memory())
+		})
+	}
+}
+
+func ptrTo(q resource.Quantity) *resource.Quantity { return &q }
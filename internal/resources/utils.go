@@ -1,6 +1,9 @@
 package resources
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 const (
 	thousand = 1000
@@ -10,6 +13,16 @@ const (
 	gigabyte = int64(1024 * 1024 * 1024)
 )
 
-func roundUpGB(bytes int64) int64 {
-	return int64(math.Ceil(float64(bytes)/float64(gigabyte))) * gigabyte
+// roundUpGB rounds the number of bytes up to a whole number of gigabytes
+// (returned in bytes). Sizes are never rounded below 1 gigabyte, as a
+// zero-size request produces an invalid Pod spec.
+func roundUpGB(bytes int64) (int64, error) {
+	if bytes < 0 {
+		return 0, fmt.Errorf("negative size: %d bytes", bytes)
+	}
+	gbs := int64(math.Ceil(float64(bytes) / float64(gigabyte)))
+	if gbs < 1 {
+		gbs = 1
+	}
+	return gbs * gigabyte, nil
 }
@@ -0,0 +1,34 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_roundUpGB(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Bytes       int64
+		Expected    int64
+		ExpectError bool
+	}{
+		{Name: "zero", Bytes: 0, Expected: gigabyte},
+		{Name: "one byte", Bytes: 1, Expected: gigabyte},
+		{Name: "exact", Bytes: 2 * gigabyte, Expected: 2 * gigabyte},
+		{Name: "round up", Bytes: 2*gigabyte + 1, Expected: 3 * gigabyte},
+		{Name: "negative", Bytes: -1, ExpectError: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			actual, err := roundUpGB(testCase.Bytes)
+			if testCase.ExpectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, testCase.Expected, actual)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package aws
+
+import (
+	awsSdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Config configures the S3 client. The defaults talk to AWS, setting an
+// Endpoint allows for S3-compatible stores such as MinIO or Ceph RGW (which
+// typically also need ForcePathStyle).
+type S3Config struct {
+	// Endpoint overrides the S3 endpoint, i.e. "https://minio.example.com:9000".
+	Endpoint string `env:"S3_ENDPOINT"`
+	// Region overrides the region of the session.
+	Region string `env:"S3_REGION"`
+	// ForcePathStyle uses "<endpoint>/<bucket>/<key>" URLs instead of
+	// "<bucket>.<endpoint>/<key>".
+	ForcePathStyle bool `env:"S3_FORCE_PATH_STYLE"`
+	// DisableSSL uses http instead of https when the Endpoint has no scheme.
+	DisableSSL bool `env:"S3_DISABLE_SSL"`
+}
+
+// NewS3Client returns an S3 client for the given configuration.
+func NewS3Client(sess *session.Session, cfg S3Config) *s3.S3 {
+	c := awsSdk.NewConfig().
+		WithS3ForcePathStyle(cfg.ForcePathStyle).
+		WithDisableSSL(cfg.DisableSSL)
+	if cfg.Endpoint != "" {
+		c = c.WithEndpoint(cfg.Endpoint)
+	}
+	if cfg.Region != "" {
+		c = c.WithRegion(cfg.Region)
+	}
+	return s3.New(sess, c)
+}
@@ -0,0 +1,232 @@
+package aws_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	awsSdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/substratusai/substratus/internal/sci"
+	sciAws "github.com/substratusai/substratus/internal/sci/aws"
+)
+
+func TestS3CompatibleEndpoint(t *testing.T) {
+	// Minimal S3-compatible API (path-style) like the one served by MinIO.
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/bucket/prefix/a.txt":
+			w.Header().Set("ETag", `"0cc175b9c0f1b6a831c399e269772661"`)
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket" && r.URL.Query().Get("list-type") == "2":
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Name>bucket</Name><Prefix>prefix/</Prefix><KeyCount>2</KeyCount><IsTruncated>false</IsTruncated>
+  <Contents><Key>prefix/a.txt</Key><ETag>"0cc175b9c0f1b6a831c399e269772661"</ETag><Size>1</Size></Contents>
+  <Contents><Key>prefix/b.txt</Key><ETag>"92eb5ffee6ae2fec3ad71c777531578f"</ETag><Size>1</Size></Contents>
+</ListBucketResult>`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	sess, err := session.NewSession(awsSdk.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("minio", "minio123", "")).
+		WithRegion("us-east-1"))
+	require.NoError(t, err)
+
+	server := &sciAws.Server{Clients: sciAws.Clients{
+		S3Client: sciAws.NewS3Client(sess, sciAws.S3Config{
+			Endpoint:       srv.URL,
+			ForcePathStyle: true,
+		}),
+	}}
+
+	md5Resp, err := server.GetObjectMd5(context.Background(), &sci.GetObjectMd5Request{
+		BucketName: "bucket",
+		ObjectName: "prefix/a.txt",
+	})
+	require.NoError(t, err)
+	require.Contains(t, md5Resp.Md5Checksum, "0cc175b9c0f1b6a831c399e269772661")
+
+	checksumResp, err := server.GetPrefixChecksum(context.Background(), &sci.GetPrefixChecksumRequest{
+		BucketName: "bucket",
+		Prefix:     "prefix",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), checksumResp.ObjectCount)
+	require.Equal(t, sci.CombineChecksums(map[string]string{
+		"a.txt": "0cc175b9c0f1b6a831c399e269772661",
+		"b.txt": "92eb5ffee6ae2fec3ad71c777531578f",
+	}), checksumResp.Checksum)
+
+	require.Equal(t, []string{"HEAD /bucket/prefix/a.txt", "GET /bucket"}, paths, "requests should use path-style addressing")
+}
+
+func TestCreateBucket(t *testing.T) {
+	existing := map[string]bool{"owned": true}
+	var locations []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, ok := r.URL.Query()["tagging"]; ok {
+			return
+		}
+		name := r.URL.Path[1:]
+		if existing[name] {
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>BucketAlreadyOwnedByYou</Code><Message>Your previous request to create the named bucket succeeded and you already own it.</Message><BucketName>%s</BucketName></Error>`, name)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		locations = append(locations, string(body))
+		existing[name] = true
+	}))
+	defer srv.Close()
+
+	sess, err := session.NewSession(awsSdk.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("minio", "minio123", "")).
+		WithRegion("us-east-1"))
+	require.NoError(t, err)
+
+	server := &sciAws.Server{Clients: sciAws.Clients{
+		S3Client: sciAws.NewS3Client(sess, sciAws.S3Config{
+			Endpoint:       srv.URL,
+			ForcePathStyle: true,
+		}),
+	}}
+
+	resp, err := server.CreateBucket(context.Background(), &sci.CreateBucketRequest{
+		BucketName: "new",
+		Location:   "eu-west-1",
+		Labels:     map[string]string{"managed-by": "substratus"},
+	})
+	require.NoError(t, err)
+	require.True(t, resp.Created)
+	require.Len(t, locations, 1)
+	require.Contains(t, locations[0], "<LocationConstraint>eu-west-1</LocationConstraint>")
+
+	resp, err = server.CreateBucket(context.Background(), &sci.CreateBucketRequest{BucketName: "owned"})
+	require.NoError(t, err)
+	require.False(t, resp.Created, "creating an owned bucket should be a no-op")
+}
+
+func TestCreateBucketEncryptionFailure(t *testing.T) {
+	var encryptionCode string
+	existing := map[string]bool{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Path[1:]
+		switch {
+		case r.Method == http.MethodPut && r.URL.Query().Has("encryption"):
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>%s</Code><Message>denied</Message></Error>`, encryptionCode)
+		case r.Method == http.MethodPut:
+			existing[name] = true
+		case r.Method == http.MethodDelete:
+			delete(existing, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	sess, err := session.NewSession(awsSdk.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("minio", "minio123", "")).
+		WithRegion("us-east-1"))
+	require.NoError(t, err)
+	server := &sciAws.Server{Clients: sciAws.Clients{
+		S3Client: sciAws.NewS3Client(sess, sciAws.S3Config{
+			Endpoint:       srv.URL,
+			ForcePathStyle: true,
+		}),
+	}}
+
+	for code, expected := range map[string]codes.Code{
+		"KMS.NotFoundException": codes.FailedPrecondition,
+		"AccessDenied":          codes.PermissionDenied,
+	} {
+		encryptionCode = code
+		_, err := server.CreateBucket(context.Background(), &sci.CreateBucketRequest{
+			BucketName: "new",
+			KmsKeyName: "arn:aws:kms:us-east-1:123456789012:key/abc",
+		})
+		require.Equal(t, expected, status.Code(err), code)
+		require.Empty(t, existing, "the bucket should be deleted again (%s)", code)
+	}
+}
+
+func TestGetBucketLocationEncryption(t *testing.T) {
+	var encryption string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/xml")
+		if _, ok := r.URL.Query()["location"]; ok {
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">eu-west-1</LocationConstraint>`)
+			return
+		}
+		switch encryption {
+		case "AccessDenied":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`)
+		case "":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?>
+<Error><Code>ServerSideEncryptionConfigurationNotFoundError</Code><Message>The server side encryption configuration was not found</Message></Error>`)
+		default:
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<ServerSideEncryptionConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>aws:kms</SSEAlgorithm><KMSMasterKeyID>%s</KMSMasterKeyID></ApplyServerSideEncryptionByDefault></Rule>
+</ServerSideEncryptionConfiguration>`, encryption)
+		}
+	}))
+	defer srv.Close()
+
+	sess, err := session.NewSession(awsSdk.NewConfig().
+		WithCredentials(credentials.NewStaticCredentials("minio", "minio123", "")).
+		WithRegion("us-east-1"))
+	require.NoError(t, err)
+
+	server := &sciAws.Server{Clients: sciAws.Clients{
+		S3Client: sciAws.NewS3Client(sess, sciAws.S3Config{
+			Endpoint:       srv.URL,
+			ForcePathStyle: true,
+		}),
+	}}
+
+	const key = "arn:aws:kms:eu-west-1:123456789012:key/abc"
+	cases := []struct {
+		encryption string
+		key        string
+		unknown    bool
+	}{
+		{encryption: key, key: key},
+		{encryption: ""},
+		{encryption: "AccessDenied", unknown: true},
+	}
+	for _, c := range cases {
+		encryption = c.encryption
+		resp, err := server.GetBucketLocation(context.Background(), &sci.GetBucketLocationRequest{BucketName: "bucket"})
+		require.NoError(t, err, c.encryption)
+		require.True(t, resp.Exists)
+		require.Equal(t, "eu-west-1", resp.Location)
+		require.Equal(t, c.key, resp.KmsKeyName, c.encryption)
+		require.Equal(t, c.unknown, resp.KmsKeyUnknown, c.encryption)
+	}
+}
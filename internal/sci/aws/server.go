@@ -6,19 +6,25 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	awsSdk "github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/eks"
 	"github.com/aws/aws-sdk-go/service/iam"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/substratusai/substratus/internal/sci"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
@@ -57,10 +63,276 @@ func (s *Server) GetObjectMd5(ctx context.Context, req *sci.GetObjectMd5Request)
 	}, nil
 }
 
+// GetPrefixChecksum combines the md5 checksums (ETags) of all objects under a prefix.
+func (s *Server) GetPrefixChecksum(ctx context.Context, req *sci.GetPrefixChecksumRequest) (*sci.GetPrefixChecksumResponse, error) {
+	prefix := strings.TrimSuffix(req.GetPrefix(), "/") + "/"
+
+	md5s := map[string]string{}
+	var size int64
+	if err := s.Clients.S3Client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: awsSdk.String(req.GetBucketName()),
+		Prefix: awsSdk.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			// NOTE: Multi-part uploads have an ETag that is not a plain MD5,
+			// it still changes when the content changes.
+			md5s[strings.TrimPrefix(awsSdk.StringValue(obj.Key), prefix)] = strings.Trim(awsSdk.StringValue(obj.ETag), `"`)
+			size += awsSdk.Int64Value(obj.Size)
+		}
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("listing objects: %w", err)
+	}
+
+	return &sci.GetPrefixChecksumResponse{
+		Checksum:    sci.CombineChecksums(md5s),
+		ObjectCount: int64(len(md5s)),
+		TotalBytes:  size,
+	}, nil
+}
+
+// ListObjects lists all objects under a prefix (in lexicographic order).
+// The checksums are ETags, see GetPrefixChecksum.
+func (s *Server) ListObjects(ctx context.Context, req *sci.ListObjectsRequest) (*sci.ListObjectsResponse, error) {
+	prefix := strings.TrimSuffix(req.GetPrefix(), "/") + "/"
+
+	resp := &sci.ListObjectsResponse{}
+	if err := s.Clients.S3Client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: awsSdk.String(req.GetBucketName()),
+		Prefix: awsSdk.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			resp.Objects = append(resp.Objects, &sci.ObjectInfo{
+				Name:        strings.TrimPrefix(awsSdk.StringValue(obj.Key), prefix),
+				Size:        awsSdk.Int64Value(obj.Size),
+				Md5Checksum: strings.Trim(awsSdk.StringValue(obj.ETag), `"`),
+			})
+		}
+		return true
+	}); err != nil {
+		return nil, fmt.Errorf("listing objects: %w", err)
+	}
+	return resp, nil
+}
+
+// DeletePrefix deletes the objects under the prefix in batches (one
+// DeleteObjects request each), sending an update after every batch.
+func (s *Server) DeletePrefix(req *sci.DeletePrefixRequest, stream sci.Controller_DeletePrefixServer) error {
+	ctx := stream.Context()
+
+	prefix, err := sci.DeletePrefixPath(req)
+	if err != nil {
+		return err
+	}
+
+	var keys []string
+	if err := s.Clients.S3Client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket: awsSdk.String(req.GetBucketName()),
+		Prefix: awsSdk.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			keys = append(keys, awsSdk.StringValue(obj.Key))
+		}
+		return true
+	}); err != nil {
+		return fmt.Errorf("listing objects: %w", err)
+	}
+
+	progress := &sci.DeletePrefixProgress{TotalObjects: int64(len(keys))}
+	if err := stream.Send(progress); err != nil {
+		return err
+	}
+	for len(keys) > 0 {
+		n := sci.DeletePrefixBatchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+		batch := keys[:n]
+		keys = keys[n:]
+
+		var objs []*s3.ObjectIdentifier
+		for _, k := range batch {
+			objs = append(objs, &s3.ObjectIdentifier{Key: awsSdk.String(k)})
+		}
+		out, err := s.Clients.S3Client.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket: awsSdk.String(req.GetBucketName()),
+			Delete: &s3.Delete{Objects: objs, Quiet: awsSdk.Bool(true)},
+		})
+		if err != nil {
+			return fmt.Errorf("deleting objects: %w", err)
+		}
+		if len(out.Errors) > 0 {
+			e := out.Errors[0]
+			return fmt.Errorf("deleting object %q: %s: %s", awsSdk.StringValue(e.Key), awsSdk.StringValue(e.Code), awsSdk.StringValue(e.Message))
+		}
+
+		progress.DeletedObjects += int64(n)
+		progress.Done = len(keys) == 0
+		if err := stream.Send(progress); err != nil {
+			return err
+		}
+	}
+	if progress.TotalObjects == 0 {
+		progress.Done = true
+		return stream.Send(progress)
+	}
+	return nil
+}
+
+func (s *Server) GetBucketLocation(ctx context.Context, req *sci.GetBucketLocationRequest) (*sci.GetBucketLocationResponse, error) {
+	out, err := s.Clients.S3Client.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{
+		Bucket: awsSdk.String(req.GetBucketName()),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == s3.ErrCodeNoSuchBucket {
+			return &sci.GetBucketLocationResponse{Exists: false}, nil
+		}
+		return nil, fmt.Errorf("getting bucket location: %w", err)
+	}
+
+	location := awsSdk.StringValue(out.LocationConstraint)
+	if location == "" {
+		// Buckets in us-east-1 have no location constraint.
+		location = "us-east-1"
+	}
+
+	kmsKey, known, err := s.bucketKMSKey(ctx, req.GetBucketName())
+	if err != nil {
+		return nil, err
+	}
+
+	return &sci.GetBucketLocationResponse{Exists: true, Location: location, KmsKeyName: kmsKey, KmsKeyUnknown: !known}, nil
+}
+
+// bucketKMSKey returns the KMS key of the SSE-KMS default encryption of the
+// bucket, empty for S3 managed keys. The key is not known if the SCI is not
+// allowed to get the bucket encryption.
+func (s *Server) bucketKMSKey(ctx context.Context, bucket string) (string, bool, error) {
+	out, err := s.Clients.S3Client.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{
+		Bucket: awsSdk.String(bucket),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) {
+			switch aerr.Code() {
+			case "ServerSideEncryptionConfigurationNotFoundError":
+				// S3-compatible stores might not configure default encryption.
+				return "", true, nil
+			case "AccessDenied":
+				// Older SCI roles lack s3:GetEncryptionConfiguration.
+				return "", false, nil
+			}
+		}
+		return "", false, fmt.Errorf("getting bucket encryption: %w", err)
+	}
+	if out.ServerSideEncryptionConfiguration == nil {
+		return "", true, nil
+	}
+	for _, rule := range out.ServerSideEncryptionConfiguration.Rules {
+		if def := rule.ApplyServerSideEncryptionByDefault; def != nil && awsSdk.StringValue(def.SSEAlgorithm) == s3.ServerSideEncryptionAwsKms {
+			return awsSdk.StringValue(def.KMSMasterKeyID), true, nil
+		}
+	}
+	return "", true, nil
+}
+
+// CreateBucket creates a bucket in the given region, S3 creates buckets
+// without a location constraint in us-east-1 (regardless of the region of
+// the session, which is the region requests are sent to so creating a
+// bucket elsewhere without a location fails). When a KMS key is requested,
+// SSE-KMS is configured as the default encryption so that every PutObject
+// (including uploads through signed URLs) is encrypted with it. A new bucket is deleted again if the encryption can
+// not be configured. It succeeds without changes if the bucket is already
+// owned by the account.
+func (s *Server) CreateBucket(ctx context.Context, req *sci.CreateBucketRequest) (*sci.CreateBucketResponse, error) {
+	input := &s3.CreateBucketInput{
+		Bucket: awsSdk.String(req.GetBucketName()),
+	}
+	// us-east-1 is the only region that must not be set as a constraint.
+	if loc := req.GetLocation(); loc != "" && loc != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{
+			LocationConstraint: awsSdk.String(loc),
+		}
+	}
+
+	created := true
+	if _, err := s.Clients.S3Client.CreateBucketWithContext(ctx, input); err != nil {
+		var aerr awserr.Error
+		if !errors.As(err, &aerr) || aerr.Code() != s3.ErrCodeBucketAlreadyOwnedByYou {
+			return nil, fmt.Errorf("creating bucket: %w", err)
+		}
+		created = false
+	}
+
+	if key := req.GetKmsKeyName(); created && key != "" {
+		if _, err := s.Clients.S3Client.PutBucketEncryptionWithContext(ctx, &s3.PutBucketEncryptionInput{
+			Bucket: awsSdk.String(req.GetBucketName()),
+			ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+				Rules: []*s3.ServerSideEncryptionRule{{
+					ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+						SSEAlgorithm:   awsSdk.String(s3.ServerSideEncryptionAwsKms),
+						KMSMasterKeyID: awsSdk.String(key),
+					},
+					// Reduces the number of requests to KMS.
+					BucketKeyEnabled: awsSdk.Bool(true),
+				}},
+			},
+		}); err != nil {
+			// Otherwise the bucket would be found without the key later on.
+			if _, delErr := s.Clients.S3Client.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{
+				Bucket: awsSdk.String(req.GetBucketName()),
+			}); delErr != nil {
+				err = fmt.Errorf("%w (deleting the bucket again: %v)", err, delErr)
+			}
+			var aerr awserr.Error
+			switch {
+			case errors.As(err, &aerr) && strings.HasPrefix(aerr.Code(), "KMS."):
+				return nil, status.Errorf(codes.FailedPrecondition, "kms key %q is not accessible: %v", key, err)
+			case errors.As(err, &aerr) && aerr.Code() == "AccessDenied":
+				return nil, status.Errorf(codes.PermissionDenied, "configuring bucket encryption (requires s3:PutEncryptionConfiguration): %v", err)
+			}
+			return nil, fmt.Errorf("configuring bucket encryption: %w", err)
+		}
+	}
+
+	if created && len(req.GetLabels()) > 0 {
+		var tags []*s3.Tag
+		for k, v := range req.GetLabels() {
+			tags = append(tags, &s3.Tag{Key: awsSdk.String(k), Value: awsSdk.String(v)})
+		}
+		if _, err := s.Clients.S3Client.PutBucketTaggingWithContext(ctx, &s3.PutBucketTaggingInput{
+			Bucket:  awsSdk.String(req.GetBucketName()),
+			Tagging: &s3.Tagging{TagSet: tags},
+		}); err != nil {
+			return nil, fmt.Errorf("tagging bucket: %w", err)
+		}
+	}
+
+	return &sci.CreateBucketResponse{Created: created}, nil
+}
+
 func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRequest) (*sci.CreateSignedURLResponse, error) {
 	bucketName, objectName, checksum := req.GetBucketName(),
 		req.GetObjectName(),
 		req.GetMd5Checksum()
+	expiration := time.Duration(req.GetExpirationSeconds()) * time.Second
+
+	switch req.GetMethod() {
+	case "", http.MethodPut:
+	case http.MethodGet:
+		getReq, _ := s.Clients.S3Client.GetObjectRequest(&s3.GetObjectInput{
+			Bucket: awsSdk.String(bucketName),
+			Key:    awsSdk.String(objectName),
+		})
+		url, err := getReq.Presign(expiration)
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign request: %w", err)
+		}
+		return &sci.CreateSignedURLResponse{Url: url}, nil
+	default:
+		return nil, fmt.Errorf("unsupported method: %q", req.GetMethod())
+	}
 
 	// Convert hex MD5 to base64
 	data, err := hex.DecodeString(checksum)
@@ -76,7 +348,6 @@ func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRe
 		ContentMD5:  awsSdk.String(base64md5),
 	}
 
-	expiration := time.Duration(req.GetExpirationSeconds()) * time.Second
 	putReq, _ := s.Clients.S3Client.PutObjectRequest(reqInput)
 	url, err := putReq.Presign(expiration)
 	if err != nil {
@@ -86,41 +357,18 @@ func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRe
 }
 
 func (s *Server) BindIdentity(ctx context.Context, req *sci.BindIdentityRequest) (*sci.BindIdentityResponse, error) {
-	// Fetch the current trust policy
-	getRoleInput := &iam.GetRoleInput{
-		RoleName: awsSdk.String(req.Principal),
-	}
-	getRoleOutput, err := s.Clients.IAMClient.GetRole(getRoleInput)
+	trustPolicy, err := s.getTrustPolicy(req.Principal)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get the role: %v", err)
-	}
-
-	// URL decode the trust policy before decoding
-	decodedPolicy, err := url.QueryUnescape(*getRoleOutput.Role.AssumeRolePolicyDocument)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode trust policy: %v", err)
-	}
-
-	// Decode the current trust policy
-	var existingTrustPolicy map[string]interface{}
-	if err := json.Unmarshal([]byte(decodedPolicy), &existingTrustPolicy); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal trust policy: %v", err)
+		return nil, err
 	}
 
 	subValue := fmt.Sprintf("system:serviceaccount:%s:%s", req.KubernetesNamespace, req.KubernetesServiceAccount)
 
-	// Check if the OIDC provider's trust relationship already exists
-	statements := existingTrustPolicy["Statement"].([]interface{})
-	alreadyExists := false
+	// Check if the OIDC provider's trust relationship already exists for this service account
+	statements, _ := trustPolicy["Statement"].([]interface{})
 	for _, stmt := range statements {
-		stmtMap := stmt.(map[string]interface{})
-		if principal, ok := stmtMap["Principal"].(map[string]interface{}); ok {
-			if federated, ok := principal["Federated"].(string); ok && federated == s.OIDCProviderARN {
-				condition := stmtMap["Condition"].(map[string]interface{})["StringEquals"].(map[string]interface{})
-				condition[fmt.Sprintf("%s:sub", s.OIDCProviderURL)] = subValue
-				alreadyExists = true
-				break
-			}
+		if s.isIdentityStatement(stmt, subValue) {
+			return &sci.BindIdentityResponse{}, nil
 		}
 	}
 
@@ -137,28 +385,110 @@ func (s *Server) BindIdentity(ctx context.Context, req *sci.BindIdentityRequest)
 			},
 		},
 	}
-	if !alreadyExists {
-		// Append the new trust relationship to the existing policy
-		existingTrustPolicy["Statement"] = append(statements, newTrustRelationship)
+	// Append the new trust relationship to the existing policy
+	trustPolicy["Statement"] = append(statements, newTrustRelationship)
+
+	if err := s.updateTrustPolicy(req.Principal, trustPolicy); err != nil {
+		return nil, err
 	}
 
-	updatedTrustPolicy, err := json.Marshal(existingTrustPolicy)
+	return &sci.BindIdentityResponse{}, nil
+}
+
+func (s *Server) UnbindIdentity(ctx context.Context, req *sci.UnbindIdentityRequest) (*sci.UnbindIdentityResponse, error) {
+	trustPolicy, err := s.getTrustPolicy(req.Principal)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal updated trust policy: %v", err)
+		return nil, err
 	}
 
-	// Apply the updated policy
-	input := &iam.UpdateAssumeRolePolicyInput{
-		PolicyDocument: awsSdk.String(string(updatedTrustPolicy)),
-		RoleName:       awsSdk.String(req.Principal),
+	subValue := fmt.Sprintf("system:serviceaccount:%s:%s", req.KubernetesNamespace, req.KubernetesServiceAccount)
+
+	statements, _ := trustPolicy["Statement"].([]interface{})
+	remaining := make([]interface{}, 0, len(statements))
+	for _, stmt := range statements {
+		if !s.isIdentityStatement(stmt, subValue) {
+			remaining = append(remaining, stmt)
+		}
+	}
+	if len(remaining) == len(statements) {
+		// Nothing to remove.
+		return &sci.UnbindIdentityResponse{}, nil
+	}
+	trustPolicy["Statement"] = remaining
+
+	if err := s.updateTrustPolicy(req.Principal, trustPolicy); err != nil {
+		return nil, err
 	}
 
-	_, err = s.Clients.IAMClient.UpdateAssumeRolePolicy(input)
+	return &sci.UnbindIdentityResponse{}, nil
+}
+
+// getTrustPolicy fetches and decodes the trust policy of the given role.
+func (s *Server) getTrustPolicy(roleName string) (map[string]interface{}, error) {
+	getRoleInput := &iam.GetRoleInput{
+		RoleName: awsSdk.String(roleName),
+	}
+	getRoleOutput, err := s.Clients.IAMClient.GetRole(getRoleInput)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update trust policy: %v", err)
+		return nil, fmt.Errorf("failed to get the role: %v", err)
 	}
 
-	return &sci.BindIdentityResponse{}, nil
+	// URL decode the trust policy before decoding
+	decodedPolicy, err := url.QueryUnescape(*getRoleOutput.Role.AssumeRolePolicyDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode trust policy: %v", err)
+	}
+
+	var trustPolicy map[string]interface{}
+	if err := json.Unmarshal([]byte(decodedPolicy), &trustPolicy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal trust policy: %v", err)
+	}
+
+	return trustPolicy, nil
+}
+
+// updateTrustPolicy replaces the trust policy of the given role.
+func (s *Server) updateTrustPolicy(roleName string, trustPolicy map[string]interface{}) error {
+	updatedTrustPolicy, err := json.Marshal(trustPolicy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated trust policy: %v", err)
+	}
+
+	input := &iam.UpdateAssumeRolePolicyInput{
+		PolicyDocument: awsSdk.String(string(updatedTrustPolicy)),
+		RoleName:       awsSdk.String(roleName),
+	}
+	if _, err := s.Clients.IAMClient.UpdateAssumeRolePolicy(input); err != nil {
+		return fmt.Errorf("failed to update trust policy: %v", err)
+	}
+
+	return nil
+}
+
+// isIdentityStatement reports whether the trust policy statement allows the
+// given service account subject to assume the role via the cluster's OIDC provider.
+func (s *Server) isIdentityStatement(stmt interface{}, subValue string) bool {
+	stmtMap, ok := stmt.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	principal, ok := stmtMap["Principal"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	if federated, ok := principal["Federated"].(string); !ok || federated != s.OIDCProviderARN {
+		return false
+	}
+	condition, ok := stmtMap["Condition"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	stringEquals, ok := condition["StringEquals"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	sub, ok := stringEquals[fmt.Sprintf("%s:sub", s.OIDCProviderURL)].(string)
+	return ok && sub == subValue
 }
 
 func GetAccountID(stsSvc *sts.STS) (string, error) {
@@ -224,6 +224,127 @@ func TestBindIdentity(t *testing.T) {
 	assert.NotNil(t, resp)
 }
 
+func TestBindIdentityTwice(t *testing.T) {
+	server, roleName := setupBindIdentityTest(t)
+
+	req := &sci.BindIdentityRequest{
+		Principal:                roleName,
+		KubernetesNamespace:      "test-namespace",
+		KubernetesServiceAccount: "test-serviceaccount",
+	}
+	_, err := server.BindIdentity(context.TODO(), req)
+	assert.NoError(t, err)
+	_, err = server.BindIdentity(context.TODO(), req)
+	assert.NoError(t, err)
+
+	policy := getTrustPolicy(t, server, roleName)
+	assert.Equal(t, 1, strings.Count(policy, "system:serviceaccount:test-namespace:test-serviceaccount"))
+	assert.Equal(t, 1, strings.Count(policy, "sts:AssumeRoleWithWebIdentity"))
+}
+
+func TestUnbindIdentity(t *testing.T) {
+	server, roleName := setupBindIdentityTest(t)
+
+	for _, sa := range []string{"test-serviceaccount", "other-serviceaccount"} {
+		_, err := server.BindIdentity(context.TODO(), &sci.BindIdentityRequest{
+			Principal:                roleName,
+			KubernetesNamespace:      "test-namespace",
+			KubernetesServiceAccount: sa,
+		})
+		assert.NoError(t, err)
+	}
+
+	resp, err := server.UnbindIdentity(context.TODO(), &sci.UnbindIdentityRequest{
+		Principal:                roleName,
+		KubernetesNamespace:      "test-namespace",
+		KubernetesServiceAccount: "test-serviceaccount",
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+
+	policy := getTrustPolicy(t, server, roleName)
+	assert.NotContains(t, policy, "system:serviceaccount:test-namespace:test-serviceaccount")
+	assert.Contains(t, policy, "system:serviceaccount:test-namespace:other-serviceaccount")
+	assert.Contains(t, policy, "lambda.amazonaws.com")
+
+	// Unbinding an identity that is not bound is a no-op.
+	_, err = server.UnbindIdentity(context.TODO(), &sci.UnbindIdentityRequest{
+		Principal:                roleName,
+		KubernetesNamespace:      "test-namespace",
+		KubernetesServiceAccount: "test-serviceaccount",
+	})
+	assert.NoError(t, err)
+}
+
+// setupBindIdentityTest creates a temporary IAM role that is deleted when the test finishes.
+func setupBindIdentityTest(t *testing.T) (*sciAws.Server, string) {
+	t.Helper()
+	if !AwsCredentialsPresent() {
+		t.Skip("AWS credentials not found")
+	}
+	if os.Getenv("AWS_ACCOUNT_ID") == "" {
+		t.Skipf("Skipping %s because AWS_ACCOUNT_ID is not set", t.Name())
+	}
+	if os.Getenv("CLUSTER_NAME") == "" {
+		t.Skipf("Skipping %s because CLUSTER_NAME is not set", t.Name())
+	}
+
+	sess, err := session.NewSession()
+	assert.NoError(t, err)
+
+	oidcProviderURL := "oidc.eks.us-west-2.amazonaws.com/id/C2A3CBF5FF8C55D72C8843756CD44444"
+	server := &sciAws.Server{
+		Clients: sciAws.Clients{
+			IAMClient: iam.New(sess),
+		},
+		OIDCProviderURL: oidcProviderURL,
+		OIDCProviderARN: "arn:aws:iam::243019462222:oidc-provider/" + oidcProviderURL,
+	}
+
+	roleName := "test-role" + randomString(8, charset)
+	rolePolicy := `{
+		"Version": "2012-10-17",
+		"Statement": [
+		  {
+			"Effect": "Allow",
+			"Principal": {
+			  "Service": "lambda.amazonaws.com"
+			},
+			"Action": "sts:AssumeRole"
+		  }
+		]
+	  }`
+	_, err = server.Clients.IAMClient.CreateRole(&iam.CreateRoleInput{
+		RoleName:                 &roleName,
+		AssumeRolePolicyDocument: awsSdk.String(rolePolicy),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create IAM role: %v", err)
+	}
+	t.Cleanup(func() {
+		if _, err := server.Clients.IAMClient.DeleteRole(&iam.DeleteRoleInput{RoleName: &roleName}); err != nil {
+			t.Logf("Failed to delete IAM role: %v", err)
+		}
+	})
+
+	return server, roleName
+}
+
+func getTrustPolicy(t *testing.T, server *sciAws.Server, roleName string) string {
+	t.Helper()
+	getRoleOutput, err := server.Clients.IAMClient.GetRole(&iam.GetRoleInput{
+		RoleName: awsSdk.String(roleName),
+	})
+	if err != nil {
+		t.Fatalf("Failed to get the role: %v", err)
+	}
+	decodedPolicy, err := url.QueryUnescape(*getRoleOutput.Role.AssumeRolePolicyDocument)
+	if err != nil {
+		t.Fatalf("Error decoding policy document: %v", err)
+	}
+	return decodedPolicy
+}
+
 func TestCreateSignedURL(t *testing.T) {
 	if !AwsCredentialsPresent() {
 		t.Skip("AWS credentials not found")
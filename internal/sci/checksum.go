@@ -0,0 +1,24 @@
+package sci
+
+import (
+	"crypto/md5"
+	"fmt"
+	"sort"
+)
+
+// CombineChecksums returns the checksum reported by GetPrefixChecksum: the md5
+// of the (relative object name, md5) pairs sorted by name. Including the names
+// means that renaming an object changes the checksum.
+func CombineChecksums(md5s map[string]string) string {
+	names := make([]string, 0, len(md5s))
+	for name := range md5s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := md5.New()
+	for _, name := range names {
+		fmt.Fprintf(h, "%s %s\n", name, md5s[name])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -2,11 +2,19 @@ package sci
 
 import (
 	context "context"
+	"sync/atomic"
 
 	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 )
 
-type FakeSCIControllerClient struct{}
+type FakeSCIControllerClient struct {
+	// PrefixObjectCount is the object count returned for every prefix.
+	PrefixObjectCount atomic.Int64
+	// PrefixTotalBytes is the total size returned for every prefix.
+	PrefixTotalBytes atomic.Int64
+}
 
 func (c *FakeSCIControllerClient) CreateSignedURL(ctx context.Context, in *CreateSignedURLRequest, opts ...grpc.CallOption) (*CreateSignedURLResponse, error) {
 	return &CreateSignedURLResponse{}, nil
@@ -19,3 +27,27 @@ func (c *FakeSCIControllerClient) GetObjectMd5(ctx context.Context, in *GetObjec
 func (c *FakeSCIControllerClient) BindIdentity(ctx context.Context, in *BindIdentityRequest, opts ...grpc.CallOption) (*BindIdentityResponse, error) {
 	return &BindIdentityResponse{}, nil
 }
+
+func (c *FakeSCIControllerClient) UnbindIdentity(ctx context.Context, in *UnbindIdentityRequest, opts ...grpc.CallOption) (*UnbindIdentityResponse, error) {
+	return &UnbindIdentityResponse{}, nil
+}
+
+func (c *FakeSCIControllerClient) GetPrefixChecksum(ctx context.Context, in *GetPrefixChecksumRequest, opts ...grpc.CallOption) (*GetPrefixChecksumResponse, error) {
+	return &GetPrefixChecksumResponse{ObjectCount: c.PrefixObjectCount.Load(), TotalBytes: c.PrefixTotalBytes.Load()}, nil
+}
+
+func (c *FakeSCIControllerClient) GetBucketLocation(ctx context.Context, in *GetBucketLocationRequest, opts ...grpc.CallOption) (*GetBucketLocationResponse, error) {
+	return &GetBucketLocationResponse{Exists: true}, nil
+}
+
+func (c *FakeSCIControllerClient) CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*CreateBucketResponse, error) {
+	return &CreateBucketResponse{}, nil
+}
+
+func (c *FakeSCIControllerClient) DeletePrefix(ctx context.Context, in *DeletePrefixRequest, opts ...grpc.CallOption) (Controller_DeletePrefixClient, error) {
+	return nil, status.Error(codes.Unimplemented, "DeletePrefix is not faked")
+}
+
+func (c *FakeSCIControllerClient) ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error) {
+	return &ListObjectsResponse{}, nil
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/compute/metadata"
@@ -17,8 +19,15 @@ import (
 	"github.com/sethvargo/go-envconfig"
 	"github.com/substratusai/substratus/internal/sci"
 	"golang.org/x/oauth2/google"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iam/v1"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	// Can be changed to slices once we go to 1.21
+	"golang.org/x/exp/slices"
 )
 
 // server implements the sci.ControllerServer interface.
@@ -27,6 +36,15 @@ type Server struct {
 	Clients
 	SaEmail   string
 	ProjectID string `env:"PROJECT_ID"`
+
+	// CredentialsFile is an optional path to a service account JSON key
+	// (i.e. from a mounted Secret). When set, the key is used instead of the
+	// ambient identity (metadata server / workload identity), also on GCE.
+	CredentialsFile string `env:"CREDENTIALS_FILE"`
+
+	// StorageTimeout bounds each RPC's calls to GCS (and signing), on top of
+	// any deadline set by the caller. A value of 0 disables the timeout.
+	StorageTimeout time.Duration `env:"STORAGE_TIMEOUT,default=30s"`
 }
 
 type Clients struct {
@@ -51,34 +69,36 @@ func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRe
 	log := log.FromContext(ctx)
 	log.Info("creating signed URL", "bucket", req.BucketName, "object", req.ObjectName)
 
+	ctx, cancel := s.storageContext(ctx)
+	defer cancel()
+
 	bucketName, objectName, checksum := req.GetBucketName(),
 		req.GetObjectName(),
 		req.GetMd5Checksum()
+
+	method := req.GetMethod()
+	switch method {
+	case "":
+		method = http.MethodPut
+	case http.MethodPut, http.MethodGet:
+	default:
+		return nil, fmt.Errorf("unsupported method: %q", method)
+	}
+
 	bucket := s.Clients.Storage.Bucket(bucketName)
 	obj := bucket.Object(objectName)
 	if _, err := obj.Attrs(ctx); err != nil && err != storage.ErrObjectNotExist {
 		// An error occurred that was NOT ErrObjectNotExist.
 		// This is an unexpected error and we should return it.
 		log.Error(err, "error checking if object exists", "object", objectName)
-		return nil, err
-	}
-
-	data, err := hex.DecodeString(checksum)
-	if err != nil {
-		log.Error(err, "error decoding MD5 checksum", "checksum", checksum)
-		return nil, fmt.Errorf("failed to decode MD5 checksum: %w", err)
+		return nil, storageError(err)
 	}
-	base64md5 := base64.StdEncoding.EncodeToString(data)
 
 	opts := &storage.SignedURLOptions{
-		Scheme: storage.SigningSchemeV4,
-		Method: http.MethodPut,
-		Headers: []string{
-			"Content-Type:application/octet-stream",
-		},
+		Scheme:         storage.SigningSchemeV4,
+		Method:         method,
 		Expires:        time.Now().Add(time.Duration(req.GetExpirationSeconds()) * time.Second),
 		GoogleAccessID: s.SaEmail,
-		MD5:            base64md5,
 		SignBytes: func(b []byte) ([]byte, error) {
 			req := &credentialspb.SignBlobRequest{
 				Payload: b,
@@ -93,34 +113,266 @@ func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRe
 		},
 	}
 
+	if method == http.MethodPut {
+		data, err := hex.DecodeString(checksum)
+		if err != nil {
+			log.Error(err, "error decoding MD5 checksum", "checksum", checksum)
+			return nil, fmt.Errorf("failed to decode MD5 checksum: %w", err)
+		}
+		opts.MD5 = base64.StdEncoding.EncodeToString(data)
+		opts.Headers = []string{
+			"Content-Type:application/octet-stream",
+		}
+	}
+
 	// Create a signed URL
 	url, err := storage.SignedURL(bucketName, objectName, opts)
 	if err != nil {
 		log.Error(err, "error creating signed url")
-		return nil, fmt.Errorf("error creating signed url: %w", err)
+		return nil, storageError(fmt.Errorf("error creating signed url: %w", err))
 	}
 
 	return &sci.CreateSignedURLResponse{Url: url}, nil
 }
 
 func (s *Server) GetObjectMd5(ctx context.Context, req *sci.GetObjectMd5Request) (*sci.GetObjectMd5Response, error) {
+	ctx, cancel := s.storageContext(ctx)
+	defer cancel()
+
 	bucketName, objectName := req.GetBucketName(), req.GetObjectName()
 	bucket := s.Clients.Storage.Bucket(bucketName)
 	obj := bucket.Object(objectName)
 	attrs, err := obj.Attrs(ctx)
 	if err != nil {
-		return nil, err
+		return nil, storageError(err)
 	}
 	md5str := hex.EncodeToString(attrs.MD5)
 	return &sci.GetObjectMd5Response{Md5Checksum: md5str}, nil
 }
 
+// GetPrefixChecksum combines the md5 checksums of all objects under a prefix.
+func (s *Server) GetPrefixChecksum(ctx context.Context, req *sci.GetPrefixChecksumRequest) (*sci.GetPrefixChecksumResponse, error) {
+	ctx, cancel := s.storageContext(ctx)
+	defer cancel()
+
+	prefix := strings.TrimSuffix(req.GetPrefix(), "/") + "/"
+	it := s.Clients.Storage.Bucket(req.GetBucketName()).Objects(ctx, &storage.Query{Prefix: prefix})
+
+	md5s := map[string]string{}
+	var size int64
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, storageError(fmt.Errorf("listing objects: %w", err))
+		}
+		md5s[strings.TrimPrefix(attrs.Name, prefix)] = hex.EncodeToString(attrs.MD5)
+		size += attrs.Size
+	}
+
+	return &sci.GetPrefixChecksumResponse{
+		Checksum:    sci.CombineChecksums(md5s),
+		ObjectCount: int64(len(md5s)),
+		TotalBytes:  size,
+	}, nil
+}
+
+// ListObjects lists all objects under a prefix (in lexicographic order).
+func (s *Server) ListObjects(ctx context.Context, req *sci.ListObjectsRequest) (*sci.ListObjectsResponse, error) {
+	ctx, cancel := s.storageContext(ctx)
+	defer cancel()
+
+	prefix := strings.TrimSuffix(req.GetPrefix(), "/") + "/"
+	it := s.Clients.Storage.Bucket(req.GetBucketName()).Objects(ctx, &storage.Query{Prefix: prefix})
+
+	resp := &sci.ListObjectsResponse{}
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, storageError(fmt.Errorf("listing objects: %w", err))
+		}
+		resp.Objects = append(resp.Objects, &sci.ObjectInfo{
+			Name:        strings.TrimPrefix(attrs.Name, prefix),
+			Size:        attrs.Size,
+			Md5Checksum: hex.EncodeToString(attrs.MD5),
+		})
+	}
+	return resp, nil
+}
+
+func (s *Server) GetBucketLocation(ctx context.Context, req *sci.GetBucketLocationRequest) (*sci.GetBucketLocationResponse, error) {
+	ctx, cancel := s.storageContext(ctx)
+	defer cancel()
+
+	attrs, err := s.Clients.Storage.Bucket(req.GetBucketName()).Attrs(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrBucketNotExist) {
+			return &sci.GetBucketLocationResponse{Exists: false}, nil
+		}
+		return nil, storageError(fmt.Errorf("getting bucket attrs: %w", err))
+	}
+
+	resp := &sci.GetBucketLocationResponse{
+		Exists: true,
+		// GCS reports locations in uppercase (i.e. "US-CENTRAL1").
+		Location: strings.ToLower(attrs.Location),
+	}
+	if attrs.Encryption != nil {
+		resp.KmsKeyName = attrs.Encryption.DefaultKMSKeyName
+	}
+	return resp, nil
+}
+
+// CreateBucket creates a bucket with uniform bucket-level access, using the
+// requested KMS key (CMEK) for default encryption. It succeeds without
+// changes if the bucket already exists and is accessible.
+func (s *Server) CreateBucket(ctx context.Context, req *sci.CreateBucketRequest) (*sci.CreateBucketResponse, error) {
+	log := log.FromContext(ctx)
+
+	ctx, cancel := s.storageContext(ctx)
+	defer cancel()
+
+	bucket := s.Clients.Storage.Bucket(req.GetBucketName())
+	attrs := &storage.BucketAttrs{
+		Location: req.GetLocation(),
+		Labels:   req.GetLabels(),
+		UniformBucketLevelAccess: storage.UniformBucketLevelAccess{
+			Enabled: true,
+		},
+	}
+	if key := req.GetKmsKeyName(); key != "" {
+		attrs.Encryption = &storage.BucketEncryption{DefaultKMSKeyName: key}
+	}
+	err := bucket.Create(ctx, s.ProjectID, attrs)
+	if err != nil {
+		var gerr *googleapi.Error
+		if errors.As(err, &gerr) && req.GetKmsKeyName() != "" && isKMSError(gerr) {
+			// The Cloud Storage service agent needs the Encrypter/Decrypter
+			// role on the key.
+			return nil, status.Errorf(codes.FailedPrecondition, "kms key %q is not accessible: %v", req.GetKmsKeyName(), gerr.Message)
+		}
+		if errors.As(err, &gerr) && gerr.Code == http.StatusConflict {
+			// Bucket names are global, make sure that the existing bucket is ours.
+			if _, err := bucket.Attrs(ctx); err != nil {
+				return nil, status.Errorf(codes.AlreadyExists, "bucket %q exists but is not accessible: %v", req.GetBucketName(), err)
+			}
+			return &sci.CreateBucketResponse{Created: false}, nil
+		}
+		return nil, storageError(fmt.Errorf("creating bucket: %w", err))
+	}
+
+	log.Info("Created bucket", "bucket", req.GetBucketName(), "location", req.GetLocation(), "kmsKey", req.GetKmsKeyName())
+	return &sci.CreateBucketResponse{Created: true}, nil
+}
+
+// isKMSError reports whether the bucket could not be created because of the
+// KMS key (missing permissions, disabled or nonexistent key).
+func isKMSError(err *googleapi.Error) bool {
+	if err.Code != http.StatusForbidden && err.Code != http.StatusBadRequest && err.Code != http.StatusNotFound {
+		return false
+	}
+	return strings.Contains(strings.ToLower(err.Message), "kms")
+}
+
+// DeletePrefix deletes the objects under the prefix one by one, sending an
+// update after every batch. The storage timeout applies to each call, not to
+// the whole (possibly long running) deletion.
+func (s *Server) DeletePrefix(req *sci.DeletePrefixRequest, stream sci.Controller_DeletePrefixServer) error {
+	log := log.FromContext(stream.Context())
+
+	prefix, err := sci.DeletePrefixPath(req)
+	if err != nil {
+		return err
+	}
+	bucket := s.Clients.Storage.Bucket(req.GetBucketName())
+
+	names, err := func() ([]string, error) {
+		ctx, cancel := s.storageContext(stream.Context())
+		defer cancel()
+
+		query := &storage.Query{Prefix: prefix}
+		if err := query.SetAttrSelection([]string{"Name"}); err != nil {
+			return nil, err
+		}
+		var names []string
+		it := bucket.Objects(ctx, query)
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				return names, nil
+			}
+			if err != nil {
+				return nil, storageError(fmt.Errorf("listing objects: %w", err))
+			}
+			names = append(names, attrs.Name)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	progress := &sci.DeletePrefixProgress{TotalObjects: int64(len(names))}
+	if err := stream.Send(progress); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := func() error {
+			ctx, cancel := s.storageContext(stream.Context())
+			defer cancel()
+			err := bucket.Object(name).Delete(ctx)
+			if errors.Is(err, storage.ErrObjectNotExist) {
+				// Deleted concurrently.
+				return nil
+			}
+			return err
+		}(); err != nil {
+			return storageError(fmt.Errorf("deleting object %q: %w", name, err))
+		}
+		progress.DeletedObjects++
+		if progress.DeletedObjects%sci.DeletePrefixBatchSize == 0 && progress.DeletedObjects < progress.TotalObjects {
+			if err := stream.Send(progress); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Info("Deleted prefix", "bucket", req.GetBucketName(), "prefix", prefix, "objects", progress.DeletedObjects)
+	progress.Done = true
+	return stream.Send(progress)
+}
+
+// storageContext derives the context that storage calls of a single RPC
+// are made with.
+func (s *Server) storageContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.StorageTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.StorageTimeout)
+}
+
+// storageError maps timeouts to a DeadlineExceeded status so that clients
+// can tell them apart from other failures.
+func storageError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+	return err
+}
+
+const workloadIdentityUserRole = "roles/iam.workloadIdentityUser"
+
 func (s *Server) BindIdentity(ctx context.Context, req *sci.BindIdentityRequest) (*sci.BindIdentityResponse, error) {
 	log := log.FromContext(ctx)
 	log.Info("Binding K8s Service Account to GCP Service Account",
 		"k8s_service_account", req.KubernetesServiceAccount, "namespace", req.KubernetesNamespace,
 		"gcp_service_account", req.Principal)
 	resource := fmt.Sprintf("projects/%v/serviceAccounts/%v", s.ProjectID, req.Principal)
+	member := s.workloadIdentityMember(req.KubernetesNamespace, req.KubernetesServiceAccount)
 
 	// There is no add iam policy binding API so have to get existing policy to
 	// modify locally, then fully overwrite existing policy using set iam policy
@@ -128,11 +380,26 @@ func (s *Server) BindIdentity(ctx context.Context, req *sci.BindIdentityRequest)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get policy of Service Account: %w", err)
 	}
-	policy.Bindings = append(policy.Bindings, &iam.Binding{
-		Members: []string{fmt.Sprintf("serviceAccount:%s.svc.id.goog[%s/%s]",
-			s.ProjectID, req.KubernetesNamespace, req.KubernetesServiceAccount)},
-		Role: "roles/iam.workloadIdentityUser",
-	})
+
+	var roleBinding *iam.Binding
+	for _, binding := range policy.Bindings {
+		if binding.Role != workloadIdentityUserRole || binding.Condition != nil {
+			continue
+		}
+		if slices.Contains(binding.Members, member) {
+			log.Info("K8s Service Account is already bound to GCP Service Account")
+			return &sci.BindIdentityResponse{}, nil
+		}
+		roleBinding = binding
+	}
+	if roleBinding != nil {
+		roleBinding.Members = append(roleBinding.Members, member)
+	} else {
+		policy.Bindings = append(policy.Bindings, &iam.Binding{
+			Members: []string{member},
+			Role:    workloadIdentityUserRole,
+		})
+	}
 
 	rb := &iam.SetIamPolicyRequest{Policy: policy}
 	_, err = s.Clients.IAM.Projects.ServiceAccounts.SetIamPolicy(resource, rb).Context(ctx).Do()
@@ -143,9 +410,60 @@ func (s *Server) BindIdentity(ctx context.Context, req *sci.BindIdentityRequest)
 	return &sci.BindIdentityResponse{}, nil
 }
 
+func (s *Server) UnbindIdentity(ctx context.Context, req *sci.UnbindIdentityRequest) (*sci.UnbindIdentityResponse, error) {
+	log := log.FromContext(ctx)
+	log.Info("Unbinding K8s Service Account from GCP Service Account",
+		"k8s_service_account", req.KubernetesServiceAccount, "namespace", req.KubernetesNamespace,
+		"gcp_service_account", req.Principal)
+	resource := fmt.Sprintf("projects/%v/serviceAccounts/%v", s.ProjectID, req.Principal)
+	member := s.workloadIdentityMember(req.KubernetesNamespace, req.KubernetesServiceAccount)
+
+	policy, err := s.Clients.IAM.Projects.ServiceAccounts.GetIamPolicy(resource).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy of Service Account: %w", err)
+	}
+
+	removed := false
+	bindings := make([]*iam.Binding, 0, len(policy.Bindings))
+	for _, binding := range policy.Bindings {
+		if binding.Role == workloadIdentityUserRole {
+			if index := slices.Index(binding.Members, member); index != -1 {
+				binding.Members = slices.Delete(binding.Members, index, index+1)
+				removed = true
+			}
+			// Bindings without members are rejected by the IAM API.
+			if len(binding.Members) == 0 {
+				continue
+			}
+		}
+		bindings = append(bindings, binding)
+	}
+	if !removed {
+		return &sci.UnbindIdentityResponse{}, nil
+	}
+	policy.Bindings = bindings
+
+	rb := &iam.SetIamPolicyRequest{Policy: policy}
+	_, err = s.Clients.IAM.Projects.ServiceAccounts.SetIamPolicy(resource, rb).Context(ctx).Do()
+	if err != nil {
+		return nil, fmt.Errorf("error setting IAM policy: %w", err)
+	}
+
+	return &sci.UnbindIdentityResponse{}, nil
+}
+
+// workloadIdentityMember returns the IAM member used by GKE Workload Identity
+// for the given K8s Service Account.
+func (s *Server) workloadIdentityMember(namespace, serviceAccount string) string {
+	return fmt.Sprintf("serviceAccount:%s.svc.id.goog[%s/%s]", s.ProjectID, namespace, serviceAccount)
+}
+
 // GetServiceAccountEmail returns the email address of the service account
 // it relies on either a local metadata service or a key file.
 func (s *Server) AutoConfigure(m *metadata.Client) error {
+	if s.CredentialsFile != "" {
+		return s.configureFromKeyFile(s.CredentialsFile)
+	}
 	if metadata.OnGCE() {
 		email, err := m.Email("default")
 		if err != nil {
@@ -166,24 +484,30 @@ func (s *Server) AutoConfigure(m *metadata.Client) error {
 		if keyFile == "" {
 			return fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS environment variable not set")
 		}
+		return s.configureFromKeyFile(keyFile)
+	}
+	return nil
+}
 
-		key, err := os.ReadFile(keyFile)
-		if err != nil {
-			return err
-		}
+// configureFromKeyFile sets the service account email and project ID from
+// a service account JSON key.
+func (s *Server) configureFromKeyFile(keyFile string) error {
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
 
-		cfg, err := google.JWTConfigFromJSON(key)
-		if err != nil {
-			return err
-		}
-		s.SaEmail = cfg.Email
+	cfg, err := google.JWTConfigFromJSON(key)
+	if err != nil {
+		return err
+	}
+	s.SaEmail = cfg.Email
 
-		cred, err := google.CredentialsFromJSON(context.Background(), key)
-		if err != nil {
-			return err
-		}
-		s.ProjectID = cred.ProjectID
+	cred, err := google.CredentialsFromJSON(context.Background(), key)
+	if err != nil {
+		return err
 	}
+	s.ProjectID = cred.ProjectID
 	return nil
 }
 
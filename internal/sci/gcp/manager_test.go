@@ -2,16 +2,24 @@ package gcp_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/compute/metadata"
+	"cloud.google.com/go/storage"
 	"github.com/stretchr/testify/require"
 	"github.com/substratusai/substratus/internal/sci"
 	"github.com/substratusai/substratus/internal/sci/gcp"
 	"google.golang.org/api/iam/v1"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	// Can be changed to slices once we go to 1.21
 	"golang.org/x/exp/slices"
@@ -73,10 +81,30 @@ func TestServer(t *testing.T) {
 		}
 	}
 	require.Equal(t, bindingWasSet, true)
-	policy.Bindings = removeBindingMember(t, policy.Bindings, expectedMember)
-	rb = &iam.SetIamPolicyRequest{Policy: policy}
-	_, err = server.Clients.IAM.Projects.ServiceAccounts.SetIamPolicy(resourceID, rb).Context(ctx).Do()
-	require.NoErrorf(t, err, "error removing IAM policy on SA: %v", resourceID)
+
+	// Binding the same identity again should not add a duplicate member.
+	_, err = server.BindIdentity(ctx, &sci.BindIdentityRequest{
+		Principal:                server.SaEmail,
+		KubernetesServiceAccount: "integration-test",
+		KubernetesNamespace:      "integration-test",
+	})
+	require.NoErrorf(t, err, "error calling BindIdentity a second time: %v", resourceID)
+
+	policy, err = server.Clients.IAM.Projects.ServiceAccounts.GetIamPolicy(resourceID).Context(ctx).Do()
+	require.NoErrorf(t, err, "error calling GetIAMPolicy on SA: %v", resourceID)
+	require.Equal(t, 1, countBindingMember(policy.Bindings, expectedMember))
+
+	_, err = server.UnbindIdentity(ctx, &sci.UnbindIdentityRequest{
+		Principal:                server.SaEmail,
+		KubernetesServiceAccount: "integration-test",
+		KubernetesNamespace:      "integration-test",
+	})
+	require.NoErrorf(t, err, "error calling UnbindIdentity: %v", resourceID)
+
+	policy, err = server.Clients.IAM.Projects.ServiceAccounts.GetIamPolicy(resourceID).Context(ctx).Do()
+	require.NoErrorf(t, err, "error calling GetIAMPolicy on SA: %v", resourceID)
+	logIAMPolicyBindings(t, policy.Bindings, "policy bindings after UnbindIdentity")
+	require.Equal(t, 0, countBindingMember(policy.Bindings, expectedMember))
 }
 
 func logIAMPolicyBindings(t *testing.T, bindings []*iam.Binding, message string) {
@@ -95,3 +123,147 @@ func removeBindingMember(t *testing.T, bindings []*iam.Binding, member string) [
 	}
 	return bindings
 }
+
+func countBindingMember(bindings []*iam.Binding, member string) int {
+	var n int
+	for _, binding := range bindings {
+		for _, m := range binding.Members {
+			if m == member {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func TestServerStorageTimeout(t *testing.T) {
+	// A storage backend that never responds.
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	ctx := context.Background()
+	storageClient, err := storage.NewClient(ctx,
+		option.WithEndpoint(slow.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	server := &gcp.Server{
+		Clients:        gcp.Clients{Storage: storageClient},
+		StorageTimeout: 100 * time.Millisecond,
+	}
+
+	start := time.Now()
+	_, err = server.GetObjectMd5(ctx, &sci.GetObjectMd5Request{
+		BucketName: "bucket",
+		ObjectName: "object",
+	})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err), "unexpected error: %v", err)
+
+	_, err = server.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{
+		BucketName: "bucket",
+		Prefix:     "prefix",
+	})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err), "unexpected error: %v", err)
+
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestServerGetBucketLocation(t *testing.T) {
+	gcs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/storage/v1/b/existing":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name": "existing", "location": "US-CENTRAL1", "encryption": {"defaultKmsKeyName": "projects/p/locations/us-central1/keyRings/r/cryptoKeys/k"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404, "message": "The specified bucket does not exist."}}`)
+		}
+	}))
+	defer gcs.Close()
+
+	ctx := context.Background()
+	storageClient, err := storage.NewClient(ctx,
+		option.WithEndpoint(gcs.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	server := &gcp.Server{Clients: gcp.Clients{Storage: storageClient}}
+
+	resp, err := server.GetBucketLocation(ctx, &sci.GetBucketLocationRequest{BucketName: "existing"})
+	require.NoError(t, err)
+	require.True(t, resp.Exists)
+	require.Equal(t, "us-central1", resp.Location)
+	require.Equal(t, "projects/p/locations/us-central1/keyRings/r/cryptoKeys/k", resp.KmsKeyName)
+
+	resp, err = server.GetBucketLocation(ctx, &sci.GetBucketLocationRequest{BucketName: "missing"})
+	require.NoError(t, err)
+	require.False(t, resp.Exists)
+}
+
+func TestServerCreateBucketKMSKey(t *testing.T) {
+	const key = "projects/p/locations/us-central1/keyRings/r/cryptoKeys/k"
+	var requested string
+	gcs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Name       string `json:"name"`
+			Encryption struct {
+				DefaultKmsKeyName string `json:"defaultKmsKeyName"`
+			} `json:"encryption"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requested = body.Encryption.DefaultKmsKeyName
+
+		w.Header().Set("Content-Type", "application/json")
+		if body.Name == "no-access" {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error": {"code": 403, "message": "The Cloud Storage service account does not have permission to use Cloud KMS key."}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"name": %q}`, body.Name)
+	}))
+	defer gcs.Close()
+
+	ctx := context.Background()
+	storageClient, err := storage.NewClient(ctx,
+		option.WithEndpoint(gcs.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+	)
+	require.NoError(t, err)
+
+	server := &gcp.Server{ProjectID: "p", Clients: gcp.Clients{Storage: storageClient}}
+
+	resp, err := server.CreateBucket(ctx, &sci.CreateBucketRequest{BucketName: "encrypted", KmsKeyName: key})
+	require.NoError(t, err)
+	require.True(t, resp.Created)
+	require.Equal(t, key, requested)
+
+	_, err = server.CreateBucket(ctx, &sci.CreateBucketRequest{BucketName: "no-access", KmsKeyName: key})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err), "unexpected error: %v", err)
+}
+
+func TestAutoConfigureCredentialsFile(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(keyFile, []byte(`{
+  "type": "service_account",
+  "project_id": "test-project",
+  "private_key_id": "abc",
+  "private_key": "",
+  "client_email": "sci@test-project.iam.gserviceaccount.com",
+  "token_uri": "https://oauth2.googleapis.com/token"
+}`), 0600))
+
+	server := &gcp.Server{CredentialsFile: keyFile}
+	require.NoError(t, server.AutoConfigure(metadata.NewClient(&http.Client{})))
+	require.Equal(t, "sci@test-project.iam.gserviceaccount.com", server.SaEmail)
+	require.Equal(t, "test-project", server.ProjectID)
+
+	server = &gcp.Server{CredentialsFile: filepath.Join(t.TempDir(), "missing.json")}
+	require.Error(t, server.AutoConfigure(metadata.NewClient(&http.Client{})))
+}
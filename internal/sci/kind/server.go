@@ -2,23 +2,35 @@ package kind
 
 import (
 	"context"
+	"crypto/md5"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
 	sci "github.com/substratusai/substratus/internal/sci"
 )
 
 var _ sci.ControllerServer = &Server{}
 
+// DefaultBucketDir is the host path that the bucket is mounted at.
+const DefaultBucketDir = "/bucket"
+
 type Server struct {
 	SignedURLAddress string
+	// BucketDir is the directory that objects are stored in (defaults to
+	// DefaultBucketDir). Object paths outside of it are rejected.
+	BucketDir string
 
 	sci.UnimplementedControllerServer
 }
@@ -27,6 +39,13 @@ type Server struct {
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Signed URL Server: %v", r.URL.Path)
 
+	path, err := s.bucketPath(r.URL.Path)
+	if err != nil {
+		log.Print(err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodPut:
 		// Expect "Content-Type: application/octet-stream" in a PUT request and save the body to a file.
@@ -50,34 +69,71 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 		md5 := hex.EncodeToString(md5Raw)
 
-		if err := s.saveUpload(r.Body, r.URL.Path, md5); err != nil {
+		if err := s.saveUpload(r.Body, path, md5); err != nil {
 			log.Printf("failed to save upload: %v", err)
+			if errors.Is(err, errBadDigest) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
+	case http.MethodGet:
+		http.ServeFile(w, r, path)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
 	}
 
 }
 
-func (s *Server) saveUpload(r io.Reader, urlPath, md5 string) error {
+func (s *Server) bucketRoot() string {
+	if s.BucketDir == "" {
+		return DefaultBucketDir
+	}
+	return filepath.Clean(s.BucketDir)
+}
+
+// bucketPath cleans an object path (i.e. "/bucket/<guid>/...") and returns
+// an error if it is not within the bucket directory.
+func (s *Server) bucketPath(p string) (string, error) {
+	root := s.bucketRoot()
+	clean := filepath.Clean(p)
+	if !filepath.IsAbs(clean) || (clean != root && !strings.HasPrefix(clean, root+string(filepath.Separator))) {
+		return "", status.Errorf(codes.InvalidArgument, "path %q is outside of the bucket directory %s", p, root)
+	}
+	return clean, nil
+}
+
+// errBadDigest is returned when the uploaded content does not match the
+// Content-MD5 header (like cloud buckets reject such uploads).
+var errBadDigest = errors.New("content does not match the content-md5")
+
+// saveUpload writes the uploaded file, removing it again if its checksum
+// does not match the expected md5. Checksums are computed when they are read
+// (see fileMd5), so only the object itself is stored.
+func (s *Server) saveUpload(r io.Reader, urlPath, expectedMd5 string) error {
 	// urlPath should look like: "/bucket/<guid>/..."
-	dir := filepath.Dir(urlPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(urlPath), 0755); err != nil {
 		return fmt.Errorf("mkdir (all): %v", err)
 	}
-	if err := os.WriteFile(filepath.Join(dir, "md5.txt"), []byte(md5), 0644); err != nil {
-		return fmt.Errorf("write md5 file: %v", err)
-	}
 
 	f, err := os.Create(urlPath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	if _, err := io.Copy(f, r); err != nil {
+	h := md5.New()
+	if _, err := io.Copy(io.MultiWriter(f, h), r); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
 		return err
 	}
-	return f.Close()
+	if sum := hex.EncodeToString(h.Sum(nil)); sum != expectedMd5 {
+		os.Remove(urlPath)
+		return fmt.Errorf("%w: got %s, expected %s", errBadDigest, sum, expectedMd5)
+	}
+	return nil
 }
 
 func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRequest) (*sci.CreateSignedURLResponse, error) {
@@ -91,20 +147,188 @@ func (s *Server) CreateSignedURL(ctx context.Context, req *sci.CreateSignedURLRe
 func (s *Server) GetObjectMd5(ctx context.Context, req *sci.GetObjectMd5Request) (*sci.GetObjectMd5Response, error) {
 	log.Printf("GetObjectMd5: %v", req.ObjectName)
 
-	path := filepath.Join(filepath.Dir(req.ObjectName), "md5.txt")
-	contents, err := os.ReadFile(path)
+	object, err := s.bucketPath(req.ObjectName)
 	if err != nil {
-		return nil, fmt.Errorf("read md5 file: %v", err)
+		return nil, err
 	}
-
-	md5 := strings.TrimSpace(string(contents))
-	log.Printf("GetObjectMd5: found file %q with md5: %v", path, md5)
+	md5, err := fileMd5(object)
+	if err != nil {
+		return nil, fmt.Errorf("computing md5: %v", err)
+	}
+	log.Printf("GetObjectMd5: found file %q with md5: %v", object, md5)
 
 	return &sci.GetObjectMd5Response{
 		Md5Checksum: md5,
 	}, nil
 }
 
+func (s *Server) GetPrefixChecksum(ctx context.Context, req *sci.GetPrefixChecksumRequest) (*sci.GetPrefixChecksumResponse, error) {
+	log.Printf("GetPrefixChecksum: %v", req.Prefix)
+
+	prefix, err := s.bucketPath(req.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	md5s := map[string]string{}
+	var size int64
+	err = filepath.WalkDir(prefix, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		sum, err := fileMd5(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(prefix, path)
+		if err != nil {
+			return err
+		}
+		md5s[filepath.ToSlash(rel)] = sum
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("walking prefix: %w", err)
+	}
+
+	return &sci.GetPrefixChecksumResponse{
+		Checksum:    sci.CombineChecksums(md5s),
+		ObjectCount: int64(len(md5s)),
+		TotalBytes:  size,
+	}, nil
+}
+
+// ListObjects lists the files under the prefix, which is a directory on the
+// node (the bucket is ignored).
+func (s *Server) ListObjects(ctx context.Context, req *sci.ListObjectsRequest) (*sci.ListObjectsResponse, error) {
+	log.Printf("ListObjects: %v", req.Prefix)
+
+	prefix, err := s.bucketPath(req.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &sci.ListObjectsResponse{}
+	err = filepath.WalkDir(prefix, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		sum, err := fileMd5(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(prefix, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		resp.Objects = append(resp.Objects, &sci.ObjectInfo{
+			Name:        filepath.ToSlash(rel),
+			Size:        info.Size(),
+			Md5Checksum: sum,
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("walking prefix: %w", err)
+	}
+	return resp, nil
+}
+
+// DeletePrefix removes the files under the prefix, which is a directory on
+// the node (the bucket is ignored) below the bucket directory.
+func (s *Server) DeletePrefix(req *sci.DeletePrefixRequest, stream sci.Controller_DeletePrefixServer) error {
+	log.Printf("DeletePrefix: %v", req.Prefix)
+
+	if _, err := sci.DeletePrefixPath(req); err != nil {
+		return err
+	}
+	dir, err := s.bucketPath(req.Prefix)
+	if err != nil {
+		return err
+	}
+	if dir == s.bucketRoot() {
+		return status.Error(codes.InvalidArgument, "prefix must be below the bucket directory")
+	}
+
+	var files []string
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("walking prefix: %w", err)
+	}
+
+	progress := &sci.DeletePrefixProgress{TotalObjects: int64(len(files))}
+	if err := stream.Send(progress); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("removing file: %w", err)
+		}
+		progress.DeletedObjects++
+		if progress.DeletedObjects%sci.DeletePrefixBatchSize == 0 && progress.DeletedObjects < progress.TotalObjects {
+			if err := stream.Send(progress); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("removing prefix: %w", err)
+	}
+
+	progress.Done = true
+	return stream.Send(progress)
+}
+
+func fileMd5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 func (s *Server) BindIdentity(ctx context.Context, in *sci.BindIdentityRequest) (*sci.BindIdentityResponse, error) {
 	return &sci.BindIdentityResponse{}, nil
 }
+
+func (s *Server) UnbindIdentity(ctx context.Context, in *sci.UnbindIdentityRequest) (*sci.UnbindIdentityResponse, error) {
+	return &sci.UnbindIdentityResponse{}, nil
+}
+
+func (s *Server) GetBucketLocation(ctx context.Context, in *sci.GetBucketLocationRequest) (*sci.GetBucketLocationResponse, error) {
+	// The "bucket" is a host path that is always present on the node.
+	return &sci.GetBucketLocationResponse{Exists: true}, nil
+}
+
+func (s *Server) CreateBucket(ctx context.Context, in *sci.CreateBucketRequest) (*sci.CreateBucketResponse, error) {
+	return &sci.CreateBucketResponse{Created: false}, nil
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -13,17 +14,21 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/go-logr/logr"
 	"github.com/stretchr/testify/require"
 	sci "github.com/substratusai/substratus/internal/sci"
 	scikind "github.com/substratusai/substratus/internal/sci/kind"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
 )
 
 func TestServer(t *testing.T) {
 	bucketDir := os.TempDir()
 
-	s := &scikind.Server{}
+	s := &scikind.Server{BucketDir: bucketDir}
 
 	signedURLServer := httptest.NewServer(s)
 	defer signedURLServer.Close()
@@ -79,12 +84,29 @@ func TestServer(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 200, resp.StatusCode)
 
-		require.FileExists(t, filepath.Join(bucketDir, "abc/uploads/md5.txt"))
+		require.NoFileExists(t, filepath.Join(bucketDir, "abc/uploads/md5.txt"), "checksums are computed on read")
 		contents, err := os.ReadFile(filepath.Join(bucketDir, "abc/uploads/latest.tar.gz"))
 		require.NoError(t, err)
 		require.Equal(t, "hello", string(contents))
 	}
 
+	{
+		t.Log("Uploading a file that does not match its md5")
+		req, err := http.NewRequest(
+			http.MethodPut,
+			fmt.Sprintf("%v%v", signedURLServer.URL, filepath.Join(bucketDir, "/abc/uploads/corrupt.tar.gz")),
+			bytes.NewReader([]byte("hellp")),
+		)
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.NoFileExists(t, filepath.Join(bucketDir, "abc/uploads/corrupt.tar.gz"))
+	}
+
 	{
 		t.Log("Getting md5")
 		resp, err := c.GetObjectMd5(ctx, &sci.GetObjectMd5Request{
@@ -94,4 +116,140 @@ func TestServer(t *testing.T) {
 		require.Equal(t, "5d41402abc4b2a76b9719d911017c592", resp.Md5Checksum)
 	}
 
+	{
+		t.Log("Downloading file")
+		resp, err := c.CreateSignedURL(ctx, &sci.CreateSignedURLRequest{
+			ObjectName: filepath.Join(bucketDir, "abc/uploads/latest.tar.gz"),
+			Method:     http.MethodGet,
+		})
+		require.NoError(t, err)
+
+		dl, err := http.Get(resp.Url)
+		require.NoError(t, err)
+		defer dl.Body.Close()
+		require.Equal(t, 200, dl.StatusCode)
+		contents, err := io.ReadAll(dl.Body)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(contents))
+	}
+
+	{
+		t.Log("Getting prefix checksum")
+		resp, err := c.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{
+			Prefix: filepath.Join(bucketDir, "abc"),
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), resp.ObjectCount)
+		require.Equal(t, int64(len("hello")), resp.TotalBytes)
+		require.Equal(t, sci.CombineChecksums(map[string]string{
+			"uploads/latest.tar.gz": "5d41402abc4b2a76b9719d911017c592",
+		}), resp.Checksum)
+	}
+
+	{
+		t.Log("Listing objects")
+		resp, err := c.ListObjects(ctx, &sci.ListObjectsRequest{
+			Prefix: filepath.Join(bucketDir, "abc"),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.Objects, 1)
+		require.Equal(t, "uploads/latest.tar.gz", resp.Objects[0].Name)
+		require.Equal(t, int64(len("hello")), resp.Objects[0].Size)
+		require.Equal(t, "5d41402abc4b2a76b9719d911017c592", resp.Objects[0].Md5Checksum)
+	}
+
+	{
+		t.Log("Escaping the bucket directory")
+		outside := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644))
+		// Unlike os.TempDir, stays outside of a bucket at "/tmp".
+		s.BucketDir = filepath.Join(bucketDir, "abc")
+
+		escaping := filepath.Join(s.BucketDir, "..", filepath.Base(filepath.Dir(outside)), filepath.Base(outside), "secret.txt")
+		for _, method := range []string{http.MethodGet, http.MethodPut} {
+			req, err := http.NewRequest(method, signedURLServer.URL+"/", bytes.NewReader([]byte("overwritten")))
+			require.NoError(t, err)
+			// Bypass the cleaning of the URL by the client.
+			req.URL.Path = escaping
+			req.Header.Set("Content-Type", "application/octet-stream")
+			req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString([]byte("0123456789abcdef")))
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusForbidden, resp.StatusCode, method)
+		}
+		contents, err := os.ReadFile(filepath.Join(outside, "secret.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "secret", string(contents))
+
+		_, err = c.GetObjectMd5(ctx, &sci.GetObjectMd5Request{ObjectName: escaping})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = c.ListObjects(ctx, &sci.ListObjectsRequest{Prefix: outside})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = c.GetPrefixChecksum(ctx, &sci.GetPrefixChecksumRequest{Prefix: "relative/path"})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	dir := t.TempDir()
+	prefix := filepath.Join(dir, "data")
+	for i := 0; i < 250; i++ {
+		sub := filepath.Join(prefix, fmt.Sprintf("shard-%d", i%3))
+		require.NoError(t, os.MkdirAll(sub, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(sub, fmt.Sprintf("%d.txt", i)), []byte("x"), 0644))
+	}
+	// Sibling with a common name prefix.
+	sibling := filepath.Join(dir, "data-2", "keep.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(sibling), 0755))
+	require.NoError(t, os.WriteFile(sibling, []byte("x"), 0644))
+
+	lis := bufconn.Listen(1024 * 1024)
+	gs := grpc.NewServer(grpc.ChainStreamInterceptor(
+		sci.LoggingStreamServerInterceptor(logr.Discard()),
+		sci.RecoveryStreamServerInterceptor(),
+	))
+	sci.RegisterControllerServer(gs, &scikind.Server{BucketDir: dir})
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := sci.NewControllerClient(conn)
+
+	stream, err := client.DeletePrefix(context.Background(), &sci.DeletePrefixRequest{Prefix: prefix})
+	require.NoError(t, err)
+	var deleted []int64
+	var last *sci.DeletePrefixProgress
+	for {
+		p, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.EqualValues(t, 250, p.TotalObjects)
+		deleted = append(deleted, p.DeletedObjects)
+		last = p
+	}
+	require.Equal(t, []int64{0, 100, 200, 250}, deleted)
+	require.True(t, last.Done)
+
+	_, err = os.Stat(prefix)
+	require.True(t, os.IsNotExist(err))
+	require.FileExists(t, sibling)
+
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "keep.txt"), []byte("x"), 0644))
+	for _, p := range []string{"/", dir, outside, filepath.Join(dir, "..", filepath.Base(outside)), "/etc"} {
+		stream, err = client.DeletePrefix(context.Background(), &sci.DeletePrefixRequest{Prefix: p})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		require.Equal(t, codes.InvalidArgument, status.Code(err), p)
+	}
+	require.FileExists(t, sibling)
+	require.FileExists(t, filepath.Join(outside, "keep.txt"))
 }
@@ -0,0 +1,92 @@
+package sci
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RequestIDKey is the metadata key that carries the ID of a request. If the
+// client does not set it, the server generates one.
+const RequestIDKey = "x-request-id"
+
+// LoggingUnaryServerInterceptor returns a server interceptor that logs every
+// RPC with its method, duration, status code and request ID. The logger
+// (with the request ID) is stored in the context so that handlers can log
+// using log.FromContext(ctx).
+func LoggingUnaryServerInterceptor(logger logr.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		requestID := incomingRequestID(ctx)
+		// Best effort, allows clients to correlate errors with server logs.
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+		l := logger.WithValues("method", info.FullMethod, "requestID", requestID)
+		ctx = log.IntoContext(ctx, l)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		code := status.Code(err)
+
+		kv := []interface{}{"code", code.String(), "duration", time.Since(start).String()}
+		if err != nil {
+			l.Error(err, "Handled request", kv...)
+		} else {
+			l.Info("Handled request", kv...)
+		}
+
+		return resp, err
+	}
+}
+
+// LoggingStreamServerInterceptor is the streaming counterpart of
+// LoggingUnaryServerInterceptor, the duration covers the whole stream.
+func LoggingStreamServerInterceptor(logger logr.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := ss.Context()
+		requestID := incomingRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, requestID))
+
+		l := logger.WithValues("method", info.FullMethod, "requestID", requestID)
+
+		start := time.Now()
+		err := handler(srv, &contextServerStream{ServerStream: ss, ctx: log.IntoContext(ctx, l)})
+		code := status.Code(err)
+
+		kv := []interface{}{"code", code.String(), "duration", time.Since(start).String()}
+		if err != nil {
+			l.Error(err, "Handled stream", kv...)
+		} else {
+			l.Info("Handled stream", kv...)
+		}
+
+		return err
+	}
+}
+
+// contextServerStream overrides the context of a stream.
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context { return s.ctx }
+
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDKey); len(ids) > 0 && ids[0] != "" {
+			return ids[0]
+		}
+	}
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,48 @@
+package sci_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+func TestLoggingUnaryServerInterceptor(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+
+	interceptor := sci.LoggingUnaryServerInterceptor(logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/sci.v1.Controller/GetObjectMd5"}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(sci.RequestIDKey, "abc123"))
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		log.FromContext(ctx).Info("looking up object")
+		return nil, status.Error(codes.NotFound, "object not found")
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"requestID"="abc123"`, "handlers should log with the request logger")
+	require.Contains(t, lines[1], `"requestID"="abc123"`)
+	require.Contains(t, lines[1], `"method"="/sci.v1.Controller/GetObjectMd5"`)
+	require.Contains(t, lines[1], `"code"="NotFound"`)
+
+	// A request ID is generated when the client does not send one.
+	_, err = interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	require.Regexp(t, `"requestID"="[0-9a-f]{16}"`, lines[2])
+	require.Contains(t, lines[2], `"code"="OK"`)
+}
@@ -0,0 +1,28 @@
+package sci
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsUnaryClientInterceptor returns a client interceptor that records the
+// latency of SCI requests (by method and response code) in the given registry.
+func MetricsUnaryClientInterceptor(reg prometheus.Registerer) grpc.UnaryClientInterceptor {
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "substratus_sci_request_duration_seconds",
+		Help:    "Latency of requests to the Substratus Cloud Interface.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+	reg.MustRegister(latency)
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		latency.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
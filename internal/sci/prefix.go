@@ -0,0 +1,23 @@
+package sci
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DeletePrefixBatchSize is the number of objects that DeletePrefix
+// implementations delete between progress updates.
+const DeletePrefixBatchSize = 100
+
+// DeletePrefixPath returns the prefix of a DeletePrefix request with a
+// trailing "/" so that sibling prefixes (i.e. "data/abc" for "data/a") are
+// not deleted. An empty prefix is rejected as it would empty the bucket.
+func DeletePrefixPath(req *DeletePrefixRequest) (string, error) {
+	prefix := strings.Trim(req.GetPrefix(), "/")
+	if prefix == "" {
+		return "", status.Error(codes.InvalidArgument, "prefix is required")
+	}
+	return prefix + "/", nil
+}
@@ -0,0 +1,59 @@
+package sci
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// NewRateLimiter returns a token bucket limiter that allows qps requests per
+// second with bursts of up to burst requests. It does not limit anything
+// when qps is not positive.
+func NewRateLimiter(qps float64, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.NewLimiter(rate.Limit(qps), burst)
+}
+
+// RateLimitUnaryClientInterceptor returns a client interceptor that waits
+// for the limiter before every request. Sharing one limiter across all
+// reconciles keeps a burst of objects from exhausting the cloud API quotas
+// behind the SCI. Requests fail with the status of their context if it is
+// done (or would be) before a token is available.
+func RateLimitUnaryClientInterceptor(limiter *rate.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := wait(ctx, limiter); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// RateLimitStreamClientInterceptor is the streaming counterpart of
+// RateLimitUnaryClientInterceptor, a stream takes a single token.
+func RateLimitStreamClientInterceptor(limiter *rate.Limiter) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if err := wait(ctx, limiter); err != nil {
+			return nil, err
+		}
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+func wait(ctx context.Context, limiter *rate.Limiter) error {
+	if err := limiter.Wait(ctx); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		// The deadline is before the next token.
+		return status.Errorf(codes.DeadlineExceeded, "waiting for the sci rate limiter: %v", err)
+	}
+	return nil
+}
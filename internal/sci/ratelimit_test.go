@@ -0,0 +1,73 @@
+package sci_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+func TestRateLimitUnaryClientInterceptor(t *testing.T) {
+	interceptor := sci.RateLimitUnaryClientInterceptor(sci.NewRateLimiter(20, 2))
+
+	var mu sync.Mutex
+	var calls []time.Time
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, time.Now())
+		return nil
+	}
+
+	// A burst of 6 concurrent requests: 2 pass immediately, the remaining
+	// 4 are spread out at 20 QPS (50ms apart).
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, interceptor(context.Background(), "/sci.v1.Controller/GetObjectMd5", nil, nil, nil, invoker))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, calls, 6)
+	require.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+	var immediate int
+	for _, c := range calls {
+		if c.Sub(start) < 25*time.Millisecond {
+			immediate++
+		}
+	}
+	require.Equal(t, 2, immediate, "only the burst should pass without waiting")
+}
+
+func TestRateLimitUnaryClientInterceptorContext(t *testing.T) {
+	interceptor := sci.RateLimitUnaryClientInterceptor(sci.NewRateLimiter(1, 1))
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	require.NoError(t, interceptor(context.Background(), "m", nil, nil, nil, invoker))
+
+	// The next token is a second away.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := interceptor(ctx, "m", nil, nil, nil, invoker)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err), "unexpected error: %v", err)
+}
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	limiter := sci.NewRateLimiter(0, 0)
+	for i := 0; i < 1000; i++ {
+		require.True(t, limiter.Allow())
+	}
+}
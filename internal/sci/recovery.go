@@ -0,0 +1,46 @@
+package sci
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// RecoveryUnaryServerInterceptor returns a server interceptor that converts a
+// panic in a handler into a codes.Internal error so that a single bad request
+// does not take down the server. The stack trace is logged using the logger
+// from the context (see LoggingUnaryServerInterceptor).
+func RecoveryUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.FromContext(ctx).Error(fmt.Errorf("panic: %v", r), "Recovered from panic in handler",
+					"method", info.FullMethod, "stack", string(debug.Stack()))
+				resp, err = nil, status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamServerInterceptor is the streaming counterpart of
+// RecoveryUnaryServerInterceptor.
+func RecoveryStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.FromContext(ss.Context()).Error(fmt.Errorf("panic: %v", r), "Recovered from panic in handler",
+					"method", info.FullMethod, "stack", string(debug.Stack()))
+				err = status.Errorf(codes.Internal, "internal error handling %s", info.FullMethod)
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
@@ -0,0 +1,50 @@
+package sci_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+type panickingServer struct {
+	sci.UnimplementedControllerServer
+}
+
+func (s *panickingServer) GetObjectMd5(ctx context.Context, req *sci.GetObjectMd5Request) (*sci.GetObjectMd5Response, error) {
+	var resp *sci.GetObjectMd5Response
+	// nil pointer dereference
+	resp.Md5Checksum = req.ObjectName
+	return resp, nil
+}
+
+func TestRecoveryUnaryServerInterceptor(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	gs := sci.NewGRPCServer(&panickingServer{}, logr.Discard(), false)
+	go gs.Serve(lis)
+	defer gs.Stop()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := sci.NewControllerClient(conn)
+
+	_, err = client.GetObjectMd5(context.Background(), &sci.GetObjectMd5Request{BucketName: "b", ObjectName: "o"})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	// The server is still serving after the panic.
+	_, err = client.GetObjectMd5(context.Background(), &sci.GetObjectMd5Request{BucketName: "b", ObjectName: "o"})
+	require.Equal(t, codes.Internal, status.Code(err))
+}
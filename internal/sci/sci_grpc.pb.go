@@ -20,7 +20,15 @@ const _ = grpc.SupportPackageIsVersion7
 type ControllerClient interface {
 	CreateSignedURL(ctx context.Context, in *CreateSignedURLRequest, opts ...grpc.CallOption) (*CreateSignedURLResponse, error)
 	GetObjectMd5(ctx context.Context, in *GetObjectMd5Request, opts ...grpc.CallOption) (*GetObjectMd5Response, error)
+	GetPrefixChecksum(ctx context.Context, in *GetPrefixChecksumRequest, opts ...grpc.CallOption) (*GetPrefixChecksumResponse, error)
+	ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error)
 	BindIdentity(ctx context.Context, in *BindIdentityRequest, opts ...grpc.CallOption) (*BindIdentityResponse, error)
+	UnbindIdentity(ctx context.Context, in *UnbindIdentityRequest, opts ...grpc.CallOption) (*UnbindIdentityResponse, error)
+	GetBucketLocation(ctx context.Context, in *GetBucketLocationRequest, opts ...grpc.CallOption) (*GetBucketLocationResponse, error)
+	CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*CreateBucketResponse, error)
+	// DeletePrefix deletes all objects under a prefix, streaming progress
+	// updates until all objects are deleted.
+	DeletePrefix(ctx context.Context, in *DeletePrefixRequest, opts ...grpc.CallOption) (Controller_DeletePrefixClient, error)
 }
 
 type controllerClient struct {
@@ -49,6 +57,24 @@ func (c *controllerClient) GetObjectMd5(ctx context.Context, in *GetObjectMd5Req
 	return out, nil
 }
 
+func (c *controllerClient) GetPrefixChecksum(ctx context.Context, in *GetPrefixChecksumRequest, opts ...grpc.CallOption) (*GetPrefixChecksumResponse, error) {
+	out := new(GetPrefixChecksumResponse)
+	err := c.cc.Invoke(ctx, "/sci.v1.Controller/GetPrefixChecksum", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) ListObjects(ctx context.Context, in *ListObjectsRequest, opts ...grpc.CallOption) (*ListObjectsResponse, error) {
+	out := new(ListObjectsResponse)
+	err := c.cc.Invoke(ctx, "/sci.v1.Controller/ListObjects", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *controllerClient) BindIdentity(ctx context.Context, in *BindIdentityRequest, opts ...grpc.CallOption) (*BindIdentityResponse, error) {
 	out := new(BindIdentityResponse)
 	err := c.cc.Invoke(ctx, "/sci.v1.Controller/BindIdentity", in, out, opts...)
@@ -58,13 +84,80 @@ func (c *controllerClient) BindIdentity(ctx context.Context, in *BindIdentityReq
 	return out, nil
 }
 
+func (c *controllerClient) UnbindIdentity(ctx context.Context, in *UnbindIdentityRequest, opts ...grpc.CallOption) (*UnbindIdentityResponse, error) {
+	out := new(UnbindIdentityResponse)
+	err := c.cc.Invoke(ctx, "/sci.v1.Controller/UnbindIdentity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) GetBucketLocation(ctx context.Context, in *GetBucketLocationRequest, opts ...grpc.CallOption) (*GetBucketLocationResponse, error) {
+	out := new(GetBucketLocationResponse)
+	err := c.cc.Invoke(ctx, "/sci.v1.Controller/GetBucketLocation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) CreateBucket(ctx context.Context, in *CreateBucketRequest, opts ...grpc.CallOption) (*CreateBucketResponse, error) {
+	out := new(CreateBucketResponse)
+	err := c.cc.Invoke(ctx, "/sci.v1.Controller/CreateBucket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controllerClient) DeletePrefix(ctx context.Context, in *DeletePrefixRequest, opts ...grpc.CallOption) (Controller_DeletePrefixClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Controller_ServiceDesc.Streams[0], "/sci.v1.Controller/DeletePrefix", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controllerDeletePrefixClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Controller_DeletePrefixClient interface {
+	Recv() (*DeletePrefixProgress, error)
+	grpc.ClientStream
+}
+
+type controllerDeletePrefixClient struct {
+	grpc.ClientStream
+}
+
+func (x *controllerDeletePrefixClient) Recv() (*DeletePrefixProgress, error) {
+	m := new(DeletePrefixProgress)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ControllerServer is the server API for Controller service.
 // All implementations must embed UnimplementedControllerServer
 // for forward compatibility
 type ControllerServer interface {
 	CreateSignedURL(context.Context, *CreateSignedURLRequest) (*CreateSignedURLResponse, error)
 	GetObjectMd5(context.Context, *GetObjectMd5Request) (*GetObjectMd5Response, error)
+	GetPrefixChecksum(context.Context, *GetPrefixChecksumRequest) (*GetPrefixChecksumResponse, error)
+	ListObjects(context.Context, *ListObjectsRequest) (*ListObjectsResponse, error)
 	BindIdentity(context.Context, *BindIdentityRequest) (*BindIdentityResponse, error)
+	UnbindIdentity(context.Context, *UnbindIdentityRequest) (*UnbindIdentityResponse, error)
+	GetBucketLocation(context.Context, *GetBucketLocationRequest) (*GetBucketLocationResponse, error)
+	CreateBucket(context.Context, *CreateBucketRequest) (*CreateBucketResponse, error)
+	// DeletePrefix deletes all objects under a prefix, streaming progress
+	// updates until all objects are deleted.
+	DeletePrefix(*DeletePrefixRequest, Controller_DeletePrefixServer) error
 	mustEmbedUnimplementedControllerServer()
 }
 
@@ -78,9 +171,27 @@ func (UnimplementedControllerServer) CreateSignedURL(context.Context, *CreateSig
 func (UnimplementedControllerServer) GetObjectMd5(context.Context, *GetObjectMd5Request) (*GetObjectMd5Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetObjectMd5 not implemented")
 }
+func (UnimplementedControllerServer) GetPrefixChecksum(context.Context, *GetPrefixChecksumRequest) (*GetPrefixChecksumResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrefixChecksum not implemented")
+}
+func (UnimplementedControllerServer) ListObjects(context.Context, *ListObjectsRequest) (*ListObjectsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListObjects not implemented")
+}
 func (UnimplementedControllerServer) BindIdentity(context.Context, *BindIdentityRequest) (*BindIdentityResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BindIdentity not implemented")
 }
+func (UnimplementedControllerServer) UnbindIdentity(context.Context, *UnbindIdentityRequest) (*UnbindIdentityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnbindIdentity not implemented")
+}
+func (UnimplementedControllerServer) GetBucketLocation(context.Context, *GetBucketLocationRequest) (*GetBucketLocationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBucketLocation not implemented")
+}
+func (UnimplementedControllerServer) CreateBucket(context.Context, *CreateBucketRequest) (*CreateBucketResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBucket not implemented")
+}
+func (UnimplementedControllerServer) DeletePrefix(*DeletePrefixRequest, Controller_DeletePrefixServer) error {
+	return status.Errorf(codes.Unimplemented, "method DeletePrefix not implemented")
+}
 func (UnimplementedControllerServer) mustEmbedUnimplementedControllerServer() {}
 
 // UnsafeControllerServer may be embedded to opt out of forward compatibility for this service.
@@ -130,6 +241,42 @@ func _Controller_GetObjectMd5_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Controller_GetPrefixChecksum_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPrefixChecksumRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).GetPrefixChecksum(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sci.v1.Controller/GetPrefixChecksum",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).GetPrefixChecksum(ctx, req.(*GetPrefixChecksumRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_ListObjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListObjectsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).ListObjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sci.v1.Controller/ListObjects",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).ListObjects(ctx, req.(*ListObjectsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Controller_BindIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BindIdentityRequest)
 	if err := dec(in); err != nil {
@@ -148,6 +295,81 @@ func _Controller_BindIdentity_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Controller_UnbindIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnbindIdentityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).UnbindIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sci.v1.Controller/UnbindIdentity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).UnbindIdentity(ctx, req.(*UnbindIdentityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_GetBucketLocation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBucketLocationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).GetBucketLocation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sci.v1.Controller/GetBucketLocation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).GetBucketLocation(ctx, req.(*GetBucketLocationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_CreateBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControllerServer).CreateBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/sci.v1.Controller/CreateBucket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControllerServer).CreateBucket(ctx, req.(*CreateBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Controller_DeletePrefix_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DeletePrefixRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ControllerServer).DeletePrefix(m, &controllerDeletePrefixServer{stream})
+}
+
+type Controller_DeletePrefixServer interface {
+	Send(*DeletePrefixProgress) error
+	grpc.ServerStream
+}
+
+type controllerDeletePrefixServer struct {
+	grpc.ServerStream
+}
+
+func (x *controllerDeletePrefixServer) Send(m *DeletePrefixProgress) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Controller_ServiceDesc is the grpc.ServiceDesc for Controller service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -163,11 +385,37 @@ var Controller_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetObjectMd5",
 			Handler:    _Controller_GetObjectMd5_Handler,
 		},
+		{
+			MethodName: "GetPrefixChecksum",
+			Handler:    _Controller_GetPrefixChecksum_Handler,
+		},
+		{
+			MethodName: "ListObjects",
+			Handler:    _Controller_ListObjects_Handler,
+		},
 		{
 			MethodName: "BindIdentity",
 			Handler:    _Controller_BindIdentity_Handler,
 		},
+		{
+			MethodName: "UnbindIdentity",
+			Handler:    _Controller_UnbindIdentity_Handler,
+		},
+		{
+			MethodName: "GetBucketLocation",
+			Handler:    _Controller_GetBucketLocation_Handler,
+		},
+		{
+			MethodName: "CreateBucket",
+			Handler:    _Controller_CreateBucket_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "DeletePrefix",
+			Handler:       _Controller_DeletePrefix_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "sci.proto",
 }
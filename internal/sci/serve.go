@@ -0,0 +1,41 @@
+package sci
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Serve serves gRPC requests on the listener until the context is done. It
+// then stops accepting requests and waits up to timeout for in-flight RPCs
+// to complete before closing all connections, so that rolling restarts (the
+// SIGTERM context of the SCI servers) do not interrupt bucket operations.
+func Serve(ctx context.Context, gs *grpc.Server, lis net.Listener, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- gs.Serve(lis)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		gs.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		// Cancels the remaining RPCs.
+		gs.Stop()
+		<-stopped
+	}
+
+	return <-errCh
+}
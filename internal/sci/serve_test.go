@@ -0,0 +1,89 @@
+package sci_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+type blockingServer struct {
+	sci.UnimplementedControllerServer
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingServer) GetObjectMd5(ctx context.Context, req *sci.GetObjectMd5Request) (*sci.GetObjectMd5Response, error) {
+	close(s.started)
+	select {
+	case <-s.release:
+		return &sci.GetObjectMd5Response{Md5Checksum: "abc"}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestServe(t *testing.T) {
+	cases := []struct {
+		name        string
+		release     bool
+		expectError bool
+	}{
+		{name: "in-flight request completes", release: true},
+		{name: "timeout cancels request", release: false, expectError: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			lis, err := net.Listen("tcp", "localhost:0")
+			require.NoError(t, err)
+
+			srv := &blockingServer{started: make(chan struct{}), release: make(chan struct{})}
+			gs := grpc.NewServer()
+			sci.RegisterControllerServer(gs, srv)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			served := make(chan error, 1)
+			go func() {
+				served <- sci.Serve(ctx, gs, lis, 200*time.Millisecond)
+			}()
+
+			conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			defer conn.Close()
+
+			rpcErr := make(chan error, 1)
+			go func() {
+				_, err := sci.NewControllerClient(conn).GetObjectMd5(context.Background(), &sci.GetObjectMd5Request{})
+				rpcErr <- err
+			}()
+
+			<-srv.started
+			// Simulate SIGTERM.
+			cancel()
+			if c.release {
+				time.Sleep(50 * time.Millisecond)
+				close(srv.release)
+			}
+
+			select {
+			case err := <-served:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("server did not stop")
+			}
+			if c.expectError {
+				require.Error(t, <-rpcErr)
+			} else {
+				require.NoError(t, <-rpcErr)
+			}
+		})
+	}
+}
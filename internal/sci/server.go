@@ -0,0 +1,36 @@
+package sci
+
+import (
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	hv1 "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewGRPCServer returns a gRPC server that serves srv and the health service
+// (and the reflection service when enableReflection is set). Requests are
+// traced, logged with logger and recovered from panics in that order: the
+// recovery interceptor is the innermost so that a panic is logged (and
+// reported) as an Internal error for the request.
+func NewGRPCServer(srv ControllerServer, logger logr.Logger, enableReflection bool) *grpc.Server {
+	gs := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		TracingUnaryServerInterceptor(),
+		LoggingUnaryServerInterceptor(logger),
+		RecoveryUnaryServerInterceptor(),
+	), grpc.ChainStreamInterceptor(
+		TracingStreamServerInterceptor(),
+		LoggingStreamServerInterceptor(logger),
+		RecoveryStreamServerInterceptor(),
+	))
+	RegisterControllerServer(gs, srv)
+	if enableReflection {
+		reflection.Register(gs)
+	}
+
+	hs := health.NewServer()
+	hs.SetServingStatus("", hv1.HealthCheckResponse_SERVING)
+	hv1.RegisterHealthServer(gs, hs)
+
+	return gs
+}
@@ -0,0 +1,32 @@
+package sci
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
+	"google.golang.org/grpc"
+)
+
+// TracingUnaryServerInterceptor returns a server interceptor that records a
+// span for every RPC, continuing the trace propagated by the client. Health
+// checks are not traced as they are polled by the kubelet.
+func TracingUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return otelgrpc.UnaryServerInterceptor(otelgrpc.WithInterceptorFilter(filters.Not(filters.HealthCheck())))
+}
+
+// TracingStreamServerInterceptor is the streaming counterpart of
+// TracingUnaryServerInterceptor.
+func TracingStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return otelgrpc.StreamServerInterceptor(otelgrpc.WithInterceptorFilter(filters.Not(filters.HealthCheck())))
+}
+
+// TracingUnaryClientInterceptor returns a client interceptor that records a
+// span for every SCI request and propagates the trace context to the server.
+func TracingUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return otelgrpc.UnaryClientInterceptor()
+}
+
+// TracingStreamClientInterceptor is the streaming counterpart of
+// TracingUnaryClientInterceptor.
+func TracingStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return otelgrpc.StreamClientInterceptor()
+}
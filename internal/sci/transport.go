@@ -0,0 +1,45 @@
+package sci
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// ConfigureDefaultTransport prepares http.DefaultTransport, which the cloud
+// SDK clients build their transports from, for restricted networks:
+// proxies are taken from HTTPS_PROXY/HTTP_PROXY/NO_PROXY and, if caBundle
+// is set, the PEM encoded certificates in that file are trusted in addition
+// to the system roots (i.e. for TLS intercepting proxies).
+func ConfigureDefaultTransport(caBundle string) error {
+	t, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("unexpected default transport type: %T", http.DefaultTransport)
+	}
+	t.Proxy = http.ProxyFromEnvironment
+
+	if caBundle == "" {
+		return nil
+	}
+
+	pem, err := os.ReadFile(caBundle)
+	if err != nil {
+		return fmt.Errorf("reading CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("no certificates found in CA bundle: %s", caBundle)
+	}
+
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.RootCAs = pool
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package sci_test
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/substratusai/substratus/internal/sci"
+)
+
+func TestConfigureDefaultTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	original := http.DefaultTransport
+	http.DefaultTransport = original.(*http.Transport).Clone()
+	defer func() { http.DefaultTransport = original }()
+	client := &http.Client{Transport: http.DefaultTransport}
+
+	_, err := client.Get(srv.URL)
+	require.Error(t, err, "the test server certificate should not be trusted by default")
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0644))
+	require.NoError(t, sci.ConfigureDefaultTransport(bundle))
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	require.Error(t, sci.ConfigureDefaultTransport(empty))
+}
@@ -0,0 +1,27 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestSetup(t *testing.T) {
+	ctx := context.Background()
+
+	shutdown, err := Setup(ctx, "test", "", false)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(ctx))
+	_, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.False(t, ok, "tracing should be disabled without an endpoint")
+
+	// The exporter connects lazily, no collector is needed.
+	shutdown, err = Setup(ctx, "test", "localhost:4317", true)
+	require.NoError(t, err)
+	_, ok = otel.GetTracerProvider().(*sdktrace.TracerProvider)
+	require.True(t, ok)
+	require.NoError(t, shutdown(ctx))
+}
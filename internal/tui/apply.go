@@ -2,6 +2,7 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
@@ -25,6 +26,9 @@ type applyObject struct {
 	status  status
 	error   error
 	spinner spinner.Model
+
+	// ready is only used when waiting for readiness.
+	ready status
 }
 
 type ApplyModel struct {
@@ -35,6 +39,12 @@ type ApplyModel struct {
 	Namespace     Namespace
 	Filename      string
 	NoOpenBrowser bool
+	// Wait for applied objects that report readiness to become Ready.
+	// The Ctx deadline (if any) bounds the wait.
+	Wait bool
+	// ForceConflicts takes ownership of fields that are managed by others
+	// (i.e. the controller) instead of failing with a conflict.
+	ForceConflicts bool
 
 	// Clients
 	Client client.Interface
@@ -76,7 +86,16 @@ func (m ApplyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, applyCmd(m.Ctx, res, &applyInput{
 			Object: o.DeepCopyObject().(client.Object),
 			index:  idx,
-		}))
+		}, m.ForceConflicts))
+	}
+	waitReady := func(o client.Object, idx int) {
+		res, err := m.Client.Resource(o)
+		if err != nil {
+			m.finalError = fmt.Errorf("resource client: %w", err)
+			return
+		}
+
+		cmds = append(cmds, applyWaitReadyCmd(m.Ctx, res, o.DeepCopyObject().(client.Object), idx))
 	}
 	switch msg := msg.(type) {
 	case manifestsFoundMsg:
@@ -110,18 +129,36 @@ func (m ApplyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		ao := m.objects[msg.index]
 		ao.status = completed
 		ao.error = msg.err
+		if m.Wait && ao.error == nil && isReadyable(ao.object) {
+			ao.ready = inProgress
+			waitReady(ao.object, msg.index)
+		}
 		m.objects[msg.index] = ao
 
 		if msg.index == len(m.objects)-1 {
 			m.applying = completed
-			return m, tea.Quit
+			if !m.waitingReady() {
+				return m, tea.Quit
+			}
+		} else {
+			apply(m.objects[msg.index+1].object, msg.index+1)
 		}
-		apply(m.objects[msg.index+1].object, msg.index+1)
 		return m, tea.Batch(cmds...)
 
+	case appliedReadyMsg:
+		ao := m.objects[msg.index]
+		ao.ready = completed
+		ao.error = msg.err
+		m.objects[msg.index] = ao
+
+		if m.applying == completed && !m.waitingReady() {
+			return m, tea.Quit
+		}
+
 	case tea.KeyMsg:
 		log.Println("Received key msg:", msg.String())
 		if msg.String() == "q" {
+			m.quitting = true
 			return m, tea.Quit
 		}
 
@@ -137,6 +174,58 @@ func (m ApplyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m ApplyModel) waitingReady() bool {
+	for _, o := range m.objects {
+		if o.ready == inProgress {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns the first error that occurred while applying (or waiting
+// for) objects. When waiting, quitting before all objects were Ready is
+// also an error.
+func (m ApplyModel) Err() error {
+	if m.finalError != nil {
+		return m.finalError
+	}
+	for _, o := range m.objects {
+		if o.error != nil {
+			gvk := o.object.GetObjectKind().GroupVersionKind()
+			return fmt.Errorf("%v %v: %w", gvk.Kind, o.object.GetName(), o.error)
+		}
+	}
+	if m.quitting && (m.applying != completed || m.waitingReady()) {
+		return fmt.Errorf("quit before all objects were applied and ready")
+	}
+	return nil
+}
+
+func isReadyable(o client.Object) bool {
+	_, ok := o.(interface{ GetStatusReady() bool })
+	return ok
+}
+
+type appliedReadyMsg struct {
+	index int
+	err   error
+}
+
+func applyWaitReadyCmd(ctx context.Context, res *client.Resource, obj client.Object, idx int) tea.Cmd {
+	return func() tea.Msg {
+		if err := res.WaitReady(ctx, obj, func(client.Object) {}); err != nil {
+			// The poll error does not wrap the context error, check the
+			// context for the --timeout.
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("not ready before timeout")
+			}
+			return appliedReadyMsg{index: idx, err: err}
+		}
+		return appliedReadyMsg{index: idx}
+	}
+}
+
 // View returns a string based on data in the model. That string which will be
 // rendered to the terminal.
 func (m ApplyModel) View() (v string) {
@@ -145,13 +234,13 @@ func (m ApplyModel) View() (v string) {
 	}()
 
 	if m.finalError != nil {
-		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalMargins()-10).Render("Error: "+m.finalError.Error()) + "\n"
+		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalMargins()-10).Render(errorPrefix(m.finalError)+m.finalError.Error()) + "\n"
 		return
 	}
 
 	for _, o := range m.objects {
 		var indicator string
-		if o.status != completed {
+		if o.status != completed || o.ready == inProgress {
 			indicator = o.spinner.View()
 		} else {
 			if o.error != nil {
@@ -170,6 +259,9 @@ func (m ApplyModel) View() (v string) {
 	if m.applying == inProgress {
 		v += "\nApplying...\n"
 		v += helpStyle("Press \"q\" to quit")
+	} else if m.waitingReady() {
+		v += "\nWaiting for objects to be ready...\n"
+		v += helpStyle("Press \"q\" to quit")
 	}
 
 	return v
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,7 +14,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
 	"github.com/substratusai/substratus/internal/cli/utils"
@@ -67,15 +68,19 @@ type (
 	fileTarredMsg      string
 )
 
-func prepareTarballCmd(ctx context.Context, dir string) tea.Cmd {
+func prepareTarballCmd(ctx context.Context, dir string, opts client.TarballOptions) tea.Cmd {
 	return func() tea.Msg {
 		log.Println("Preparing tarball")
-		tarball, err := client.PrepareImageTarball(ctx, dir, func(file string) {
+		tarball, err := client.PrepareImageTarball(ctx, dir, opts, func(file string) {
 			log.Println("tarred", file)
 			P.Send(fileTarredMsg(file))
 		})
 		if err != nil {
 			log.Println("Error", err)
+			var tooLarge *client.TarballTooLargeError
+			if errors.As(err, &tooLarge) {
+				return err
+			}
 			return fmt.Errorf("preparing tarball: %w", err)
 		}
 		return tarballCompleteMsg(tarball)
@@ -86,25 +91,51 @@ type (
 	tarballUploadedMsg struct {
 		client.Object
 	}
-	uploadTarballProgressMsg float64
+	uploadTarballProgressMsg struct {
+		uploaded, total int64
+	}
 )
 
 func uploadTarballCmd(ctx context.Context, res *client.Resource, obj client.Object, tarball *client.Tarball) tea.Cmd {
 	return func() tea.Msg {
 		log.Println("Uploading tarball")
-		err := res.Upload(ctx, obj, tarball, func(percentage float64) {
-			log.Printf("Upload percentage: %v", percentage)
-			P.Send(uploadTarballProgressMsg(percentage))
+		err := res.Upload(ctx, obj, tarball, func(uploaded, total int64) {
+			log.Printf("Uploaded: %v/%v bytes", uploaded, total)
+			P.Send(uploadTarballProgressMsg{uploaded: uploaded, total: total})
 		})
 		if err != nil {
 			log.Println("Upload failed", err)
-			return fmt.Errorf("uploading: %w", err)
+			return err
 		}
 		log.Println("Upload completed")
 		return tarballUploadedMsg{Object: obj}
 	}
 }
 
+// errorPrefix returns the label for the error shown to the user, making
+// upload failures distinguishable from failures to apply objects.
+func errorPrefix(err error) string {
+	var uErr *client.UploadError
+	if errors.As(err, &uErr) {
+		return "Upload error: "
+	}
+	return "Error: "
+}
+
+// FormatBytes returns a human readable representation of a byte count.
+func FormatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
+
 func specifyUpload(obj client.Object, tarball *client.Tarball) error {
 	if err := client.ClearImage(obj); err != nil {
 		return fmt.Errorf("clearing image in spec: %w", err)
@@ -119,13 +150,13 @@ type appliedWithUploadMsg struct {
 	client.Object
 }
 
-func applyWithUploadCmd(ctx context.Context, res *client.Resource, obj client.Object, tarball *client.Tarball) tea.Cmd {
+func applyWithUploadCmd(ctx context.Context, res *client.Resource, obj client.Object, tarball *client.Tarball, force bool) tea.Cmd {
 	return func() tea.Msg {
 		if err := specifyUpload(obj, tarball); err != nil {
 			return fmt.Errorf("specifying upload: %w", err)
 		}
-		if err := res.Apply(obj, true); err != nil {
-			return fmt.Errorf("applying: %w", err)
+		if err := res.Apply(obj, force); err != nil {
+			return fmt.Errorf("applying: %w", conflictHint(err))
 		}
 		return appliedWithUploadMsg{Object: obj}
 	}
@@ -142,15 +173,24 @@ type applyInput struct {
 	index int
 }
 
-func applyCmd(ctx context.Context, res *client.Resource, in *applyInput) tea.Cmd {
+func applyCmd(ctx context.Context, res *client.Resource, in *applyInput, force bool) tea.Cmd {
 	return func() tea.Msg {
-		if err := res.Apply(in.Object, true); err != nil {
-			return appliedMsg{index: in.index, err: err}
+		if err := res.Apply(in.Object, force); err != nil {
+			return appliedMsg{index: in.index, err: conflictHint(err)}
 		}
 		return appliedMsg{Object: in.Object, index: in.index}
 	}
 }
 
+// conflictHint points to --force-conflicts when applying failed because of
+// fields that are owned by another field manager.
+func conflictHint(err error) error {
+	if apierrors.IsConflict(err) {
+		return fmt.Errorf("%w (rerun with --force-conflicts to take ownership of the fields)", err)
+	}
+	return err
+}
+
 type createdWithUploadMsg struct {
 	client.Object
 }
@@ -268,11 +308,15 @@ type suspendedMsg struct {
 	error error
 }
 
-func suspendCmd(ctx context.Context, res *client.Resource, obj client.Object) tea.Cmd {
+// suspendCmd applies the Notebook (as it was applied) with suspend set, so
+// that the fields stay owned by the same field manager and resuming the
+// Notebook with another apply does not conflict.
+func suspendCmd(ctx context.Context, res *client.Resource, nb *apiv1.Notebook) tea.Cmd {
 	return func() tea.Msg {
 		log.Println("Suspending")
-		_, err := res.Patch(obj.GetNamespace(), obj.GetName(), types.MergePatchType, []byte(`{"spec": {"suspend": true} }`), &metav1.PatchOptions{})
-		if err != nil {
+		nb = nb.DeepCopy()
+		nb.Spec.Suspend = ptr.To(true)
+		if err := res.Apply(nb, false); err != nil {
 			log.Printf("Error suspending: %v", err)
 			return suspendedMsg{error: err}
 		}
@@ -6,11 +6,13 @@ import (
 	"log"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 	"k8s.io/apimachinery/pkg/watch"
 
 	apiv1 "github.com/substratusai/substratus/api/v1"
@@ -25,6 +27,12 @@ type GetModel struct {
 	Scope     string
 	Namespace string
 
+	// Filters
+	LabelSelector string
+	FieldSelector string
+	// Ready only lists objects with a matching readiness when set.
+	Ready *bool
+
 	// Clients
 	Client client.Interface
 
@@ -33,6 +41,10 @@ type GetModel struct {
 
 	objects map[string]map[string]listedObject
 
+	// Paging
+	height int
+	offset int
+
 	Style lipgloss.Style
 }
 
@@ -57,16 +69,34 @@ func (m *GetModel) New() GetModel {
 }
 
 func (m GetModel) Init() tea.Cmd {
-	return watchCmd(m.Ctx, m.Client, m.Namespace, m.Scope)
+	return watchCmd(m.Ctx, m.Client, m.Namespace, m.Scope, metav1.ListOptions{
+		LabelSelector: m.LabelSelector,
+		FieldSelector: m.FieldSelector,
+	})
 }
 
 func (m GetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		log.Println("Received key msg:", msg.String())
-		if msg.String() == "q" {
+		page := m.pageSize()
+		switch msg.String() {
+		case "q":
 			return m, tea.Quit
+		case "up", "k":
+			m.offset--
+		case "down", "j":
+			m.offset++
+		case "pgup", "b":
+			m.offset -= page
+		case "pgdown", "f", " ":
+			m.offset += page
+		case "home", "g":
+			m.offset = 0
+		case "end", "G":
+			m.offset = len(m.rows())
 		}
+		m.offset = clampOffset(m.offset, len(m.rows()), page)
 
 	case watchMsg:
 		var cmd tea.Cmd
@@ -106,6 +136,8 @@ func (m GetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tea.WindowSizeMsg:
 		m.Style.Width(msg.Width)
+		m.height = msg.Height
+		m.offset = clampOffset(m.offset, len(m.rows()), m.pageSize())
 
 	case error:
 		m.finalError = msg
@@ -115,43 +147,70 @@ func (m GetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// View returns a string based on data in the model. That string which will be
-// rendered to the terminal.
-func (m GetModel) View() (v string) {
-	defer func() {
-		v = m.Style.Render(v)
-	}()
+// getRow is a single line of the listing, either a resource header or an object.
+type getRow struct {
+	text string
+	// index of the object (starting from 1), 0 for headers and spacing.
+	index int
+}
 
-	if m.finalError != nil {
-		v += errorStyle.Render("Error: "+m.finalError.Error()) + "\n"
-		v += helpStyle("Press \"q\" to quit")
-		return v
+// getViewReservedLines is the number of lines used by padding, the
+// "Total" line, the paging footer and the help text.
+const getViewReservedLines = 7
+
+// pageSize returns the number of rows that fit in the window, or 0 if the
+// window size is not known (in which case all rows are shown).
+func (m GetModel) pageSize() int {
+	if m.height == 0 {
+		return 0
 	}
+	if n := m.height - getViewReservedLines; n > 0 {
+		return n
+	}
+	return 1
+}
 
-	scopeResource, scopeName := splitScope(m.Scope)
+// clampOffset keeps the first visible row within bounds.
+func clampOffset(offset, rows, page int) int {
+	if page <= 0 || rows <= page {
+		return 0
+	}
+	if last := rows - page; offset > last {
+		return last
+	}
+	if offset < 0 {
+		return 0
+	}
+	return offset
+}
 
-	var total int
+func (m GetModel) rows() []getRow {
+	scopeResource, _ := splitScope(m.Scope)
+
+	var rows []getRow
+	var index int
 	for _, resource := range []string{
 		"notebooks",
 		"datasets",
 		"models",
 		"servers",
 	} {
-		if len(m.objects[resource]) == 0 {
+		var names []string
+		for name, o := range m.objects[resource] {
+			if m.Ready != nil && o.GetStatusReady() != *m.Ready {
+				continue
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
 			continue
 		}
+		sort.Strings(names)
 
 		if scopeResource == "" {
-			v += resource + "/" + "\n"
+			rows = append(rows, getRow{text: resource + "/"})
 		}
 
-		var names []string
-		for name := range m.objects[resource] {
-			names = append(names, name)
-			total++
-		}
-		sort.Strings(names)
-
 		for _, name := range names {
 			o := m.objects[resource][name]
 
@@ -161,14 +220,77 @@ func (m GetModel) View() (v string) {
 			} else {
 				indicator = o.spinner.View()
 			}
-			v += "" + indicator + " " + name + "\n"
+			details := duration.HumanDuration(time.Since(o.GetCreationTimestamp().Time))
+			if a, ok := o.object.(interface{ GetStatusArtifacts() apiv1.ArtifactsStatus }); ok {
+				if loc := artifactsLocation(a.GetStatusArtifacts()); loc != "" {
+					details += " " + loc
+				}
+			}
+			if srv, ok := o.object.(*apiv1.Server); ok {
+				if u := srv.Status.ExternalURL; u != "" {
+					details += " " + u
+				} else if u := srv.Status.URL; u != "" {
+					details += " " + u
+				}
+			}
+			index++
+			rows = append(rows, getRow{
+				text:  "" + indicator + " " + name + " " + helpStyle(details),
+				index: index,
+			})
+		}
+		rows = append(rows, getRow{})
+	}
+
+	return rows
+}
+
+// View returns a string based on data in the model. That string which will be
+// rendered to the terminal.
+func (m GetModel) View() (v string) {
+	defer func() {
+		v = m.Style.Render(v)
+	}()
+
+	if m.finalError != nil {
+		v += errorStyle.Render("Error: "+m.finalError.Error()) + "\n"
+		v += helpStyle("Press \"q\" to quit")
+		return v
+	}
+
+	_, scopeName := splitScope(m.Scope)
+
+	rows := m.rows()
+	var total int
+	for _, r := range rows {
+		if r.index > 0 {
+			total++
+		}
+	}
+
+	visible := rows
+	page := m.pageSize()
+	if page > 0 && len(rows) > page {
+		offset := clampOffset(m.offset, len(rows), page)
+		visible = rows[offset : offset+page]
+	}
+	var first, last int
+	for _, r := range visible {
+		v += r.text + "\n"
+		if r.index > 0 {
+			if first == 0 {
+				first = r.index
+			}
+			last = r.index
 		}
-		v += "\n"
 	}
 
 	if scopeName == "" {
 		v += fmt.Sprintf("\nTotal: %v\n", total)
 	}
+	if len(visible) < len(rows) {
+		v += helpStyle(fmt.Sprintf("Showing %v-%v of %v (up/down, pgup/pgdown to scroll)", first, last, total)) + "\n"
+	}
 
 	v += helpStyle("Press \"q\" to quit")
 
@@ -180,13 +302,28 @@ type watchMsg struct {
 	resource string
 }
 
+// artifactsLocation returns where artifacts are stored (i.e. "gcp/us-central1").
+func artifactsLocation(a apiv1.ArtifactsStatus) string {
+	if a.Cloud == "" || a.Region == "" {
+		return a.Cloud
+	}
+	return a.Cloud + "/" + a.Region
+}
+
 type object interface {
 	client.Object
 	GetConditions() *[]metav1.Condition
 	GetStatusReady() bool
 }
 
-func watchCmd(ctx context.Context, c client.Interface, namespace, scope string) tea.Cmd {
+var (
+	_ object = &apiv1.Notebook{}
+	_ object = &apiv1.Dataset{}
+	_ object = &apiv1.Model{}
+	_ object = &apiv1.Server{}
+)
+
+func watchCmd(ctx context.Context, c client.Interface, namespace, scope string, opts metav1.ListOptions) tea.Cmd {
 	pluralName := func(s string) string {
 		return strings.ToLower(s) + "s"
 	}
@@ -208,7 +345,8 @@ func watchCmd(ctx context.Context, c client.Interface, namespace, scope string)
 			kind := obj.GetObjectKind().GroupVersionKind().Kind
 			log.Printf("Starting watch: %v", kind)
 
-			w, err := res.Watch(ctx, namespace, obj, &metav1.ListOptions{})
+			opts := opts
+			w, err := res.Watch(ctx, namespace, obj, &opts)
 			if err != nil {
 				return fmt.Errorf("watch: %w", err)
 			}
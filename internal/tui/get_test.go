@@ -0,0 +1,30 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_clampOffset(t *testing.T) {
+	cases := []struct {
+		name               string
+		offset, rows, page int
+		expected           int
+	}{
+		{name: "fits on a page", offset: 3, rows: 5, page: 10, expected: 0},
+		{name: "exactly one page", offset: 1, rows: 10, page: 10, expected: 0},
+		{name: "no page size", offset: 3, rows: 50, page: 0, expected: 0},
+		{name: "negative page size", offset: 3, rows: 50, page: -1, expected: 0},
+		{name: "within range", offset: 7, rows: 50, page: 10, expected: 7},
+		{name: "last page", offset: 40, rows: 50, page: 10, expected: 40},
+		{name: "past the last page", offset: 45, rows: 50, page: 10, expected: 40},
+		{name: "rows removed", offset: 40, rows: 12, page: 10, expected: 2},
+		{name: "negative offset", offset: -10, rows: 50, page: 10, expected: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.expected, clampOffset(c.offset, c.rows, c.page))
+		})
+	}
+}
@@ -129,14 +129,14 @@ func groupObjectsByKind(objs []client.Object) map[string][]client.Object {
 
 func findManifests(path string, substratusOnly bool) tea.Cmd {
 	return func() tea.Msg {
-		manifests, err := resolveManifests(path, substratusOnly)
+		manifests, err := ReadManifests(path)
 		if err != nil {
 			return fmt.Errorf("resolving manifests: %w", err)
 		}
 
 		var all []client.Object
 		for _, manifest := range manifests {
-			objs, err := manifestToObjects(manifest, substratusOnly)
+			objs, err := manifestToObjects(manifest.Data, substratusOnly)
 			if err != nil {
 				return fmt.Errorf("manifest to objects: %w", err)
 			}
@@ -153,7 +153,16 @@ func findManifests(path string, substratusOnly bool) tea.Cmd {
 	}
 }
 
-func resolveManifests(path string, substratusOnly bool) ([][]byte, error) {
+// Manifest is the content of a manifest file (or URL) that can hold
+// multiple YAML documents.
+type Manifest struct {
+	Path string
+	Data []byte
+}
+
+// ReadManifests reads the manifest file or URL at the path, or the *.yaml
+// files if the path is a directory.
+func ReadManifests(path string) ([]Manifest, error) {
 	typ, err := determinePathType(path)
 	if err != nil {
 		return nil, fmt.Errorf("determining path type: %w", err)
@@ -176,14 +185,14 @@ func resolveManifests(path string, substratusOnly bool) ([][]byte, error) {
 		if err != nil {
 			return nil, fmt.Errorf("http: reading: %w", err)
 		}
-		return [][]byte{manifest}, nil
+		return []Manifest{{Path: path, Data: manifest}}, nil
 
 	case pathFile:
 		manifest, err := os.ReadFile(path)
 		if err != nil {
 			return nil, fmt.Errorf("reading file: %w", err)
 		}
-		return [][]byte{manifest}, nil
+		return []Manifest{{Path: path, Data: manifest}}, nil
 	case pathDir:
 		glob := filepath.Join(path, "*.yaml")
 		matches, err := filepath.Glob(glob)
@@ -191,13 +200,13 @@ func resolveManifests(path string, substratusOnly bool) ([][]byte, error) {
 			return nil, err
 		}
 
-		var all [][]byte
+		var all []Manifest
 		for _, p := range matches {
 			manifest, err := os.ReadFile(p)
 			if err != nil {
 				return nil, fmt.Errorf("reading file: %w", err)
 			}
-			all = append(all, manifest)
+			all = append(all, Manifest{Path: p, Data: manifest})
 		}
 		return all, nil
 
@@ -231,14 +240,21 @@ func determinePathType(path string) (pathType, error) {
 	return pathFile, nil
 }
 
-func manifestToObjects(manifest []byte, substratusOnly bool) ([]client.Object, error) {
-	var m []client.Object
-	split := bytes.Split(manifest, []byte("---\n"))
-	for _, doc := range split {
+// SplitManifest returns the non-empty YAML documents of a manifest.
+func SplitManifest(manifest []byte) [][]byte {
+	var docs [][]byte
+	for _, doc := range bytes.Split(manifest, []byte("---\n")) {
 		if strings.TrimSpace(string(doc)) == "" {
 			continue
 		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
 
+func manifestToObjects(manifest []byte, substratusOnly bool) ([]client.Object, error) {
+	var m []client.Object
+	for _, doc := range SplitManifest(manifest) {
 		obj, err := client.Decode(doc)
 		if err != nil {
 			return nil, fmt.Errorf("decoding: %w", err)
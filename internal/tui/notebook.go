@@ -25,6 +25,14 @@ type NotebookModel struct {
 	Filename      string
 	Namespace     Namespace
 	NoOpenBrowser bool
+	// LocalPort is the local port that Jupyter is forwarded to (defaults to 8888).
+	LocalPort int
+	// NoSync disables syncing files between the notebook and the local directory.
+	NoSync  bool
+	Tarball client.TarballOptions
+	// ForceConflicts takes ownership of fields of the Notebook that are
+	// managed by others instead of failing with a conflict.
+	ForceConflicts bool
 
 	// Clients
 	Client client.Interface
@@ -33,6 +41,9 @@ type NotebookModel struct {
 	// Current notebook
 	notebook *apiv1.Notebook
 	resource *client.Resource
+	// applied is the Notebook as it was applied, it is applied again to
+	// suspend it.
+	applied *apiv1.Notebook
 
 	// Proceses
 	manifests manifestsModel
@@ -70,10 +81,12 @@ func (m *NotebookModel) New() NotebookModel {
 		Kinds:          []string{"Notebook", "Model", "Dataset"},
 	}).New()
 	m.upload = (&uploadModel{
-		Ctx:    m.Ctx,
-		Client: m.Client,
-		Path:   m.Path,
-		Mode:   uploadModeApply,
+		Ctx:            m.Ctx,
+		Client:         m.Client,
+		Path:           m.Path,
+		Tarball:        m.Tarball,
+		ForceConflicts: m.ForceConflicts,
+		Mode:           uploadModeApply,
 	}).New()
 	m.readiness = (&readinessModel{
 		Ctx:    m.Ctx,
@@ -85,6 +98,10 @@ func (m *NotebookModel) New() NotebookModel {
 		K8s:    m.K8s,
 	}).New()
 
+	if m.LocalPort == 0 {
+		m.LocalPort = 8888
+	}
+
 	m.Style = appStyle
 
 	return *m
@@ -160,7 +177,12 @@ func (m NotebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// case "l":
 			//	cmds = append(cmds, m.cleanupAndQuitCmd)
 			case "s":
-				cmds = append(cmds, suspendCmd(context.Background(), m.resource, m.notebook))
+				if m.applied == nil {
+					// Nothing to suspend yet.
+					cmds = append(cmds, m.cleanupAndQuitCmd)
+					break
+				}
+				cmds = append(cmds, suspendCmd(context.Background(), m.resource, m.applied))
 			case "d":
 				cmds = append(cmds, deleteCmd(context.Background(), m.resource, m.notebook))
 			}
@@ -186,6 +208,9 @@ func (m NotebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		cmds = append(cmds, m.cleanupAndQuitCmd)
 
+	case appliedWithUploadMsg:
+		m.applied = msg.Object.(*apiv1.Notebook).DeepCopy()
+
 	case tarballUploadedMsg:
 		m.notebook = msg.Object.(*apiv1.Notebook)
 
@@ -200,10 +225,13 @@ func (m NotebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case objectReadyMsg:
 		m.notebook = msg.Object.(*apiv1.Notebook)
-		m.syncingFiles = inProgress
+		if !m.NoSync {
+			m.syncingFiles = inProgress
+			cmds = append(cmds, notebookSyncFilesCmd(m.Ctx, m.Client, m.notebook.DeepCopy(), m.Path))
+		}
+		m.portForwarding = inProgress
 		cmds = append(cmds,
-			notebookSyncFilesCmd(m.Ctx, m.Client, m.notebook.DeepCopy(), m.Path),
-			portForwardCmd(m.Ctx, m.Client, client.PodForNotebook(m.notebook), client.ForwardedPorts{Local: 8888, Pod: 8888}),
+			portForwardCmd(m.Ctx, m.Client, client.PodForNotebook(m.notebook), client.ForwardedPorts{Local: m.LocalPort, Pod: 8888}),
 		)
 
 	case notebookFileSyncMsg:
@@ -219,7 +247,7 @@ func (m NotebookModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case portForwardReadyMsg:
-		cmds = append(cmds, notebookOpenInBrowser(m.notebook.DeepCopy()))
+		cmds = append(cmds, notebookOpenInBrowser(m.notebook.DeepCopy(), m.LocalPort, !m.NoOpenBrowser))
 
 	case localURLMsg:
 		m.localURL = string(msg)
@@ -248,7 +276,7 @@ func (m NotebookModel) View() (v string) {
 	}()
 
 	if m.finalError != nil {
-		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalMargins()-10).Render("Error: "+m.finalError.Error()) + "\n"
+		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalMargins()-10).Render(errorPrefix(m.finalError)+m.finalError.Error()) + "\n"
 		v += helpStyle("Press \"s\" to suspend, \"d\" to delete")
 		// v += helpStyle("Press \"l\" to leave be, \"s\" to suspend, \"d\" to delete")
 		return v
@@ -305,7 +333,7 @@ type notebookFileSyncMsg struct {
 
 func notebookSyncFilesCmd(ctx context.Context, c client.Interface, nb *apiv1.Notebook, dir string) tea.Cmd {
 	return func() tea.Msg {
-		if err := c.SyncFilesFromNotebook(ctx, nb, dir, LogFile, func(file string, complete bool, syncErr error) {
+		if err := c.SyncFilesWithNotebook(ctx, nb, dir, LogFile, func(file string, complete bool, syncErr error) {
 			P.Send(notebookFileSyncMsg{
 				file:     file,
 				complete: complete,
@@ -320,12 +348,14 @@ func notebookSyncFilesCmd(ctx context.Context, c client.Interface, nb *apiv1.Not
 	}
 }
 
-func notebookOpenInBrowser(nb *apiv1.Notebook) tea.Cmd {
+func notebookOpenInBrowser(nb *apiv1.Notebook, localPort int, open bool) tea.Cmd {
 	return func() tea.Msg {
 		// TODO(nstogner): Grab token from Notebook status.
-		url := "http://localhost:8888?token=default"
-		log.Printf("Opening browser to %s\n", url)
-		browser.OpenURL(url)
+		url := fmt.Sprintf("http://localhost:%d?token=default", localPort)
+		if open {
+			log.Printf("Opening browser to %s\n", url)
+			browser.OpenURL(url)
+		}
 		return localURLMsg(url)
 	}
 }
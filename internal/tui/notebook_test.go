@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/utils/ptr"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/client"
+)
+
+func TestSuspendAndResumeNotebook(t *testing.T) {
+	// The server tracks the owner of spec.suspend like server-side apply
+	// does: a field manager is identified by its name and operation.
+	var (
+		owner   string
+		applied []apiv1.Notebook
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		w.Header().Set("Content-Type", "application/json")
+
+		manager := r.URL.Query().Get("fieldManager") + "/" + r.Header.Get("Content-Type")
+		if owner != "" && owner != manager && r.URL.Query().Get("force") != "true" {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(apierrors.NewApplyConflict(nil, "conflict with "+owner).Status())
+			return
+		}
+		owner = manager
+
+		body, _ := io.ReadAll(r.Body)
+		var nb apiv1.Notebook
+		require.NoError(t, json.Unmarshal(body, &nb))
+		applied = append(applied, nb)
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(apiv1.GroupVersion.WithKind("Notebook"), meta.RESTScopeNamespace)
+	c := &client.Client{Config: &rest.Config{Host: srv.URL}, RESTMapper: mapper}
+
+	nb := &apiv1.Notebook{
+		TypeMeta:   metav1.TypeMeta{APIVersion: apiv1.GroupVersion.String(), Kind: "Notebook"},
+		ObjectMeta: metav1.ObjectMeta{Name: "falcon-7b", Namespace: "default"},
+		Spec: apiv1.NotebookSpec{
+			Image:   ptr.To("substratusai/base"),
+			Suspend: ptr.To(false),
+		},
+	}
+	res, err := c.Resource(nb)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	msg := applyCmd(ctx, res, &applyInput{Object: nb.DeepCopy()}, false)().(appliedMsg)
+	require.NoError(t, msg.err)
+
+	require.Equal(t, suspendedMsg{}, suspendCmd(ctx, res, nb)())
+	require.False(t, *nb.Spec.Suspend, "the applied Notebook should not be changed")
+
+	msg = applyCmd(ctx, res, &applyInput{Object: nb.DeepCopy()}, false)().(appliedMsg)
+	require.NoError(t, msg.err, "resuming should not conflict")
+
+	require.Len(t, applied, 3)
+	for i, suspend := range []bool{false, true, false} {
+		require.Equal(t, suspend, *applied[i].Spec.Suspend)
+		require.Equal(t, "substratusai/base", *applied[i].Spec.Image, "all fields should be applied")
+	}
+}
@@ -26,6 +26,7 @@ type RunModel struct {
 	Namespace Namespace
 	Increment bool
 	Replace   bool
+	Tarball   client.TarballOptions
 
 	// Focal object
 	object   client.Object
@@ -53,6 +54,7 @@ func (m *RunModel) New() RunModel {
 		Ctx:       m.Ctx,
 		Client:    m.Client,
 		Path:      m.Path,
+		Tarball:   m.Tarball,
 		Increment: m.Increment,
 		Replace:   m.Replace,
 		Mode:      uploadModeCreate,
@@ -164,7 +166,7 @@ func (m RunModel) View() (v string) {
 	}()
 
 	if m.finalError != nil {
-		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalPadding()).Render("Error: "+m.finalError.Error()) + "\n"
+		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalPadding()).Render(errorPrefix(m.finalError)+m.finalError.Error()) + "\n"
 		v += helpStyle("Press \"q\" to quit")
 		return v
 	}
@@ -25,6 +25,9 @@ type ServeModel struct {
 	Path          string
 	Filename      string
 	NoOpenBrowser bool
+	// ForceConflicts takes ownership of fields of the Server that are
+	// managed by others instead of failing with a conflict.
+	ForceConflicts bool
 
 	// Clients
 	Client client.Interface
@@ -111,7 +114,7 @@ func (m ServeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.resource = res
 
 		m.applying = inProgress
-		cmds = append(cmds, applyCmd(m.Ctx, m.resource, &applyInput{Object: m.server.DeepCopy()}))
+		cmds = append(cmds, applyCmd(m.Ctx, m.resource, &applyInput{Object: m.server.DeepCopy()}, m.ForceConflicts))
 	}
 
 	switch msg := msg.(type) {
@@ -204,7 +207,7 @@ func (m ServeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			cmds = append(cmds,
 				portForwardCmd(m.Ctx, m.Client,
 					types.NamespacedName{Namespace: m.readyPod.Namespace, Name: m.readyPod.Name},
-					client.ForwardedPorts{Local: 8000, Pod: 8080},
+					client.ForwardedPorts{Local: 8000, Pod: int(m.server.GetPort())},
 				),
 			)
 		}
@@ -238,7 +241,7 @@ func (m ServeModel) View() (v string) {
 	}()
 
 	if m.finalError != nil {
-		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalMargins()-10).Render("Error: "+m.finalError.Error()) + "\n"
+		v += errorStyle.Width(m.Style.GetWidth()-m.Style.GetHorizontalMargins()-10).Render(errorPrefix(m.finalError)+m.finalError.Error()) + "\n"
 		// v += helpStyle("Press \"s\" to suspend, \"d\" to delete")
 		v += helpStyle("Press \"l\" to leave be, \"d\" to delete")
 		return
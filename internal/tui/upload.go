@@ -18,7 +18,8 @@ type uploadModel struct {
 	Ctx context.Context
 
 	// Config
-	Path string
+	Path    string
+	Tarball client.TarballOptions
 
 	// Clients
 	Client   client.Interface
@@ -26,9 +27,11 @@ type uploadModel struct {
 
 	Increment bool
 	Replace   bool
-	Mode      uploadMode
-	applying  status
-	creating  status
+	// ForceConflicts takes ownership of conflicting fields when applying.
+	ForceConflicts bool
+	Mode           uploadMode
+	applying       status
+	creating       status
 
 	// Original Object (could be a Dataset, Model, or Server)
 	Object client.Object
@@ -38,9 +41,14 @@ type uploadModel struct {
 	tarredFileCount int
 	tarball         *client.Tarball
 
+	// Confirming the upload of a tarball above the warn size.
+	confirming status
+
 	// Uploading
 	uploading      status
 	uploadProgress progress.Model
+	uploadedBytes  int64
+	totalBytes     int64
 
 	Style lipgloss.Style
 }
@@ -82,7 +90,7 @@ func (m uploadModel) Active() bool {
 func (m uploadModel) Init() tea.Cmd {
 	return tea.Sequence(
 		func() tea.Msg { return uploadInitMsg{} },
-		prepareTarballCmd(m.Ctx, m.Path),
+		prepareTarballCmd(m.Ctx, m.Path, m.Tarball),
 	)
 }
 
@@ -100,14 +108,25 @@ func (m uploadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tarballCompleteMsg:
 		m.tarring = completed
 		m.tarball = msg
-		if m.Mode == uploadModeApply {
-			m.applying = inProgress
-			return m, applyWithUploadCmd(m.Ctx, m.Resource, m.Object.DeepCopyObject().(client.Object), m.tarball)
-		} else if m.Mode == uploadModeCreate {
-			m.creating = inProgress
-			return m, createWithUploadCmd(m.Ctx, m.Resource, m.Object.DeepCopyObject().(client.Object), m.tarball, m.Increment, m.Replace)
-		} else {
-			panic("unkown upload mode")
+		if m.Tarball.WarnSize > 0 && m.tarball.Size > m.Tarball.WarnSize {
+			m.confirming = inProgress
+			return m, nil
+		}
+		return m.applyOrCreate()
+
+	case tea.KeyMsg:
+		if m.confirming != inProgress {
+			return m, nil
+		}
+		switch msg.String() {
+		case "y", "enter":
+			m.confirming = completed
+			return m.applyOrCreate()
+		case "n", "esc":
+			m.confirming = completed
+			return m, func() tea.Msg {
+				return fmt.Errorf("upload of the %v tarball cancelled", FormatBytes(m.tarball.Size))
+			}
 		}
 
 	case appliedWithUploadMsg:
@@ -123,7 +142,11 @@ func (m uploadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, uploadTarballCmd(m.Ctx, m.Resource, m.Object.DeepCopyObject().(client.Object), m.tarball)
 
 	case uploadTarballProgressMsg:
-		return m, m.uploadProgress.SetPercent(float64(msg))
+		m.uploadedBytes, m.totalBytes = msg.uploaded, msg.total
+		if msg.total == 0 {
+			return m, nil
+		}
+		return m, m.uploadProgress.SetPercent(float64(msg.uploaded) / float64(msg.total))
 
 	case tarballUploadedMsg:
 		m.uploading = completed
@@ -139,6 +162,20 @@ func (m uploadModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// applyOrCreate creates or applies the object with the upload of the tarball,
+// the upload starts once the object is accepted.
+func (m uploadModel) applyOrCreate() (tea.Model, tea.Cmd) {
+	if m.Mode == uploadModeApply {
+		m.applying = inProgress
+		return m, applyWithUploadCmd(m.Ctx, m.Resource, m.Object.DeepCopyObject().(client.Object), m.tarball, m.ForceConflicts)
+	} else if m.Mode == uploadModeCreate {
+		m.creating = inProgress
+		return m, createWithUploadCmd(m.Ctx, m.Resource, m.Object.DeepCopyObject().(client.Object), m.tarball, m.Increment, m.Replace)
+	} else {
+		panic("unkown upload mode")
+	}
+}
+
 // View returns a string based on data in the model. That string which will be
 // rendered to the terminal.
 func (m uploadModel) View() (v string) {
@@ -154,6 +191,11 @@ func (m uploadModel) View() (v string) {
 		v += fmt.Sprintf("File count: %v\n", m.tarredFileCount)
 	}
 
+	if m.confirming == inProgress {
+		v += fmt.Sprintf("Warning: the tarball is %v, exclude files that are not needed to build the image in .substratusignore\n", FormatBytes(m.tarball.Size))
+		v += "Upload anyway? (y/n)\n"
+	}
+
 	if m.applying == inProgress {
 		v += "Applying...\n"
 	}
@@ -164,7 +206,8 @@ func (m uploadModel) View() (v string) {
 
 	if m.uploading == inProgress {
 		v += "Uploading...\n\n"
-		v += m.uploadProgress.View() + "\n\n"
+		v += m.uploadProgress.View() + "\n"
+		v += fmt.Sprintf("%v / %v\n\n", FormatBytes(m.uploadedBytes), FormatBytes(m.totalBytes))
 	}
 
 	return v
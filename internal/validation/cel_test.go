@@ -0,0 +1,343 @@
+package validation_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/apiserver/schema/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	celconfig "k8s.io/apiserver/pkg/apis/cel"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/validation"
+)
+
+// TestValidateCELRules checks that Validate agrees with the CEL rules of the
+// generated CRDs: each fixture is valid apart from (at most) one CEL rule and
+// must be rejected by both or by neither.
+func TestValidateCELRules(t *testing.T) {
+	model := func(mutate func(*apiv1.ModelSpec)) client.Object {
+		m := &apiv1.Model{Spec: apiv1.ModelSpec{Image: ptr.To("img")}}
+		mutate(&m.Spec)
+		return m
+	}
+	dataset := func(mutate func(*apiv1.DatasetSpec)) client.Object {
+		d := &apiv1.Dataset{Spec: apiv1.DatasetSpec{Image: ptr.To("img")}}
+		mutate(&d.Spec)
+		return d
+	}
+	server := func(mutate func(*apiv1.ServerSpec)) client.Object {
+		s := &apiv1.Server{Spec: apiv1.ServerSpec{
+			Image: ptr.To("img"),
+			Model: apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: "falcon-7b"}},
+		}}
+		mutate(&s.Spec)
+		return s
+	}
+	notebook := func(mutate func(*apiv1.NotebookSpec)) client.Object {
+		n := &apiv1.Notebook{Spec: apiv1.NotebookSpec{Image: ptr.To("img")}}
+		mutate(&n.Spec)
+		return n
+	}
+	a100 := func(sharing apiv1.GPUSharing) *apiv1.Resources {
+		return &apiv1.Resources{GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaA100, Count: 1, Sharing: &sharing}}
+	}
+
+	cases := []struct {
+		name    string
+		obj     client.Object
+		invalid bool
+	}{
+		{
+			name: "model",
+			obj:  model(func(s *apiv1.ModelSpec) {}),
+		},
+		{
+			name: "model dataset and datasets",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Dataset = &apiv1.ObjectRef{Name: "squad"}
+				s.Datasets = []apiv1.ObjectRef{{Name: "imdb"}}
+			}),
+			invalid: true,
+		},
+		{
+			name: "model datasets",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Datasets = []apiv1.ObjectRef{{Name: "squad"}, {Name: "imdb"}}
+			}),
+		},
+		{
+			name: "model duplicate datasets",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Datasets = []apiv1.ObjectRef{{Name: "squad"}, {Name: "squad", Namespace: "shared"}}
+			}),
+			invalid: true,
+		},
+		{
+			name: "model source",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Image = nil
+				s.Source = &apiv1.ModelSource{HuggingFace: &apiv1.HuggingFaceSource{Repo: "tiiuae/falcon-7b"}}
+			}),
+		},
+		{
+			name: "model source with image",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Source = &apiv1.ModelSource{HuggingFace: &apiv1.HuggingFaceSource{Repo: "tiiuae/falcon-7b"}}
+			}),
+			invalid: true,
+		},
+		{
+			name: "model build args",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Image = nil
+				s.Build = &apiv1.Build{
+					Git:  &apiv1.BuildGit{URL: "https://github.com/substratusai/model-falcon"},
+					Args: map[string]string{"PYTHON_VERSION": "3.11"},
+				}
+			}),
+		},
+		{
+			name: "model invalid build args",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Image = nil
+				s.Build = &apiv1.Build{
+					Git:  &apiv1.BuildGit{URL: "https://github.com/substratusai/model-falcon"},
+					Args: map[string]string{"python-version": "3.11"},
+				}
+			}),
+			invalid: true,
+		},
+		{
+			name: "model gpu time-sharing with profile",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Resources = a100(apiv1.GPUSharing{Strategy: apiv1.GPUSharingTimeSharing, Profile: "1g.5gb"})
+			}),
+			invalid: true,
+		},
+		{
+			name: "model gpu mig",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Resources = a100(apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "1g.5gb"})
+			}),
+		},
+		{
+			name: "model gpu mig without profile",
+			obj: model(func(s *apiv1.ModelSpec) {
+				s.Resources = a100(apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG})
+			}),
+			invalid: true,
+		},
+		{
+			name: "dataset",
+			obj:  dataset(func(s *apiv1.DatasetSpec) {}),
+		},
+		{
+			name: "dataset source",
+			obj: dataset(func(s *apiv1.DatasetSpec) {
+				s.Image = nil
+				s.Source = &apiv1.DatasetSource{HTTP: "https://example.com/data.csv"}
+			}),
+		},
+		{
+			name: "dataset source with image",
+			obj: dataset(func(s *apiv1.DatasetSpec) {
+				s.Source = &apiv1.DatasetSource{HTTP: "https://example.com/data.csv"}
+			}),
+			invalid: true,
+		},
+		{
+			name: "dataset source with http and huggingFace",
+			obj: dataset(func(s *apiv1.DatasetSpec) {
+				s.Image = nil
+				s.Source = &apiv1.DatasetSource{
+					HTTP:        "https://example.com/data.csv",
+					HuggingFace: &apiv1.HuggingFaceSource{Repo: "squad"},
+				}
+			}),
+			invalid: true,
+		},
+		{
+			name: "dataset source with sample",
+			obj: dataset(func(s *apiv1.DatasetSpec) {
+				s.Image = nil
+				s.Source = &apiv1.DatasetSource{HTTP: "https://example.com/data.csv"}
+				s.Sample = &apiv1.DatasetSample{Count: 10}
+			}),
+			invalid: true,
+		},
+		{
+			name: "dataset sample",
+			obj: dataset(func(s *apiv1.DatasetSpec) {
+				s.Sample = &apiv1.DatasetSample{Fraction: "0.1"}
+			}),
+		},
+		{
+			name: "dataset sample with count and fraction",
+			obj: dataset(func(s *apiv1.DatasetSpec) {
+				s.Sample = &apiv1.DatasetSample{Count: 10, Fraction: "0.1"}
+			}),
+			invalid: true,
+		},
+		{
+			name: "server",
+			obj:  server(func(s *apiv1.ServerSpec) {}),
+		},
+		{
+			name: "server model revision and checksum",
+			obj: server(func(s *apiv1.ServerSpec) {
+				s.Model.Revision = 2
+				s.Model.Checksum = "abc"
+			}),
+			invalid: true,
+		},
+		{
+			name: "server model in other namespace",
+			obj: server(func(s *apiv1.ServerSpec) {
+				s.Model.Namespace = "shared"
+			}),
+			invalid: true,
+		},
+		{
+			name: "server expose ingress",
+			obj: server(func(s *apiv1.ServerSpec) {
+				s.Expose = &apiv1.ServerExpose{Type: apiv1.ServerExposeIngress, Host: "example.com"}
+			}),
+		},
+		{
+			name: "server expose load balancer with host",
+			obj: server(func(s *apiv1.ServerSpec) {
+				s.Expose = &apiv1.ServerExpose{Type: apiv1.ServerExposeLoadBalancer, Host: "example.com"}
+			}),
+			invalid: true,
+		},
+		{
+			name: "notebook",
+			obj: notebook(func(s *apiv1.NotebookSpec) {
+				s.Model = &apiv1.ObjectRef{Name: "falcon-7b"}
+				s.ModelRevision = 2
+				s.Dataset = &apiv1.ObjectRef{Name: "squad"}
+			}),
+		},
+		{
+			name: "notebook model revision without model",
+			obj: notebook(func(s *apiv1.NotebookSpec) {
+				s.ModelRevision = 2
+			}),
+			invalid: true,
+		},
+		{
+			name: "notebook model in other namespace",
+			obj: notebook(func(s *apiv1.NotebookSpec) {
+				s.Model = &apiv1.ObjectRef{Name: "falcon-7b", Namespace: "shared"}
+			}),
+			invalid: true,
+		},
+		{
+			name: "notebook dataset in other namespace",
+			obj: notebook(func(s *apiv1.NotebookSpec) {
+				s.Dataset = &apiv1.ObjectRef{Name: "squad", Namespace: "shared"}
+			}),
+			invalid: true,
+		},
+	}
+
+	schemas := map[string]*structuralschema.Structural{}
+	for _, kind := range []string{"models", "datasets", "servers", "notebooks"} {
+		schemas[kind] = loadCRDSchema(t, kind)
+	}
+
+	// Every CEL rule must be covered by a fixture that breaks it.
+	allRules, brokenRules := sets.New[string](), sets.New[string]()
+	for _, s := range schemas {
+		collectCELRules(s, allRules)
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.obj.SetName("test")
+
+			goErrs := validation.Validate(c.obj, cloud.GCPName)
+			celErrs := validateCEL(t, schemas[crdPlural(t, c.obj)], c.obj)
+			for _, err := range celErrs {
+				brokenRules.Insert(err.Detail)
+			}
+
+			require.Equal(t, c.invalid, len(goErrs) > 0, "go: %v", goErrs)
+			require.Equal(t, c.invalid, len(celErrs) > 0, "cel: %v", celErrs)
+		})
+	}
+
+	require.Empty(t, sets.List(allRules.Difference(brokenRules)), "CEL rules without an invalid fixture")
+}
+
+func crdPlural(t *testing.T, obj client.Object) string {
+	switch obj.(type) {
+	case *apiv1.Model:
+		return "models"
+	case *apiv1.Dataset:
+		return "datasets"
+	case *apiv1.Server:
+		return "servers"
+	case *apiv1.Notebook:
+		return "notebooks"
+	}
+	t.Fatalf("unexpected object type %T", obj)
+	return ""
+}
+
+func loadCRDSchema(t *testing.T, plural string) *structuralschema.Structural {
+	data, err := os.ReadFile(filepath.Join("..", "..", "config", "crd", "bases", "substratus.ai_"+plural+".yaml"))
+	require.NoError(t, err)
+	var crd apiextensionsv1.CustomResourceDefinition
+	require.NoError(t, yaml.Unmarshal(data, &crd))
+
+	for _, v := range crd.Spec.Versions {
+		if v.Name != apiv1.GroupVersion.Version {
+			continue
+		}
+		var props apiextensions.JSONSchemaProps
+		require.NoError(t, apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(v.Schema.OpenAPIV3Schema, &props, nil))
+		s, err := structuralschema.NewStructural(&props)
+		require.NoError(t, err)
+		return s
+	}
+	t.Fatalf("%s CRD has no version %s", plural, apiv1.GroupVersion.Version)
+	return nil
+}
+
+func collectCELRules(s *structuralschema.Structural, rules sets.Set[string]) {
+	if s == nil {
+		return
+	}
+	for _, r := range s.Extensions.XValidations {
+		rules.Insert(r.Message)
+	}
+	for _, p := range s.Properties {
+		p := p
+		collectCELRules(&p, rules)
+	}
+	collectCELRules(s.Items, rules)
+	if s.AdditionalProperties != nil {
+		collectCELRules(s.AdditionalProperties.Structural, rules)
+	}
+}
+
+func validateCEL(t *testing.T, s *structuralschema.Structural, obj client.Object) field.ErrorList {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	errs, _ := cel.NewValidator(s, true, celconfig.PerCallLimit).Validate(context.Background(), nil, s, u, nil, celconfig.RuntimeCELCostBudget)
+	return errs
+}
@@ -0,0 +1,414 @@
+// Package validation checks Substratus objects without a cluster. It mirrors
+// the rules of the CRD schemas (required fields, enums, CEL rules) and adds
+// checks that depend on the cloud (i.e. the supported GPU types).
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/validation"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/resources"
+)
+
+var (
+	md5Pattern          = regexp.MustCompile(`^[a-fA-F0-9]{32}$`)
+	buildArgNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	buildTargetPattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+	// Mirrors the pattern of DatasetSample.Fraction.
+	sampleFractionPattern = regexp.MustCompile(`^0?\.[0-9]*[1-9][0-9]*$`)
+)
+
+// Validate returns all problems found with the object. Objects of kinds
+// other than Model, Dataset, Server and Notebook are not checked.
+func Validate(obj client.Object, cloudName string) field.ErrorList {
+	var errs field.ErrorList
+	switch obj.(type) {
+	case *apiv1.Model, *apiv1.Dataset, *apiv1.Server, *apiv1.Notebook:
+	default:
+		return nil
+	}
+
+	metaPath := field.NewPath("metadata")
+	if obj.GetName() == "" {
+		errs = append(errs, field.Required(metaPath.Child("name"), ""))
+	} else {
+		for _, msg := range validation.NameIsDNSSubdomain(obj.GetName(), false) {
+			errs = append(errs, field.Invalid(metaPath.Child("name"), obj.GetName(), msg))
+		}
+	}
+
+	spec := field.NewPath("spec")
+	switch o := obj.(type) {
+	case *apiv1.Model:
+		errs = append(errs, validateModel(&o.Spec, spec, cloudName)...)
+	case *apiv1.Dataset:
+		errs = append(errs, validateDataset(&o.Spec, spec, cloudName)...)
+	case *apiv1.Server:
+		errs = append(errs, validateServer(&o.Spec, spec, cloudName)...)
+	case *apiv1.Notebook:
+		errs = append(errs, validateBuild(o.Spec.Build, spec)...)
+		errs = append(errs, validateResources(o.Spec.Resources, spec.Child("resources"), cloudName)...)
+		errs = append(errs, validateLocalObjectRef(o.Spec.Model, spec.Child("model"), "Notebook")...)
+		if o.Spec.ModelRevision != 0 && o.Spec.Model == nil {
+			errs = append(errs, field.Required(spec.Child("model"), "required by modelRevision"))
+		}
+		errs = append(errs, validateLocalObjectRef(o.Spec.Dataset, spec.Child("dataset"), "Notebook")...)
+		errs = append(errs, validatePriorityClassName(o.Spec.PriorityClassName, spec.Child("priorityClassName"))...)
+	}
+	return errs
+}
+
+func validateModel(s *apiv1.ModelSpec, path *field.Path, cloudName string) field.ErrorList {
+	var errs field.ErrorList
+	if s.Source != nil {
+		if s.Image != nil || s.Build != nil || s.Model != nil {
+			errs = append(errs, field.Forbidden(path.Child("source"), "source can not be combined with image, build or model"))
+		}
+		if s.Source.HuggingFace == nil {
+			errs = append(errs, field.Required(path.Child("source", "huggingFace"), ""))
+		} else {
+			errs = append(errs, validateHuggingFace(s.Source.HuggingFace, path.Child("source", "huggingFace"))...)
+		}
+	} else {
+		errs = append(errs, validateBuild(s.Build, path)...)
+	}
+	if s.Dataset != nil && len(s.Datasets) > 0 {
+		errs = append(errs, field.Forbidden(path.Child("datasets"), "dataset and datasets are mutually exclusive"))
+	}
+	errs = append(errs, validateObjectRef(s.Model, path.Child("model"))...)
+	errs = append(errs, validateObjectRef(s.Dataset, path.Child("dataset"))...)
+	datasetNames := map[string]bool{}
+	for i := range s.Datasets {
+		errs = append(errs, validateObjectRef(&s.Datasets[i], path.Child("datasets").Index(i))...)
+		if name := s.Datasets[i].Name; name != "" {
+			if datasetNames[name] {
+				errs = append(errs, field.Duplicate(path.Child("datasets").Index(i).Child("name"), name))
+			}
+			datasetNames[name] = true
+		}
+	}
+	if s.Publish != nil && s.Publish.Reference == "" {
+		errs = append(errs, field.Required(path.Child("publish", "reference"), ""))
+	}
+	errs = append(errs, validateTimeout(s.Timeout, path.Child("timeout"))...)
+	errs = append(errs, validateResources(s.Resources, path.Child("resources"), cloudName)...)
+	errs = append(errs, validatePriorityClassName(s.PriorityClassName, path.Child("priorityClassName"))...)
+	errs = append(errs, validateContainers(s.InitContainers, nil, "model", path)...)
+	return errs
+}
+
+func validateDataset(s *apiv1.DatasetSpec, path *field.Path, cloudName string) field.ErrorList {
+	var errs field.ErrorList
+	if s.Source != nil {
+		srcPath := path.Child("source")
+		if s.Image != nil || s.Build != nil {
+			errs = append(errs, field.Forbidden(srcPath, "source can not be combined with image or build"))
+		}
+		if (s.Source.HTTP != "") == (s.Source.HuggingFace != nil) {
+			errs = append(errs, field.Invalid(srcPath, "", "exactly one of http or huggingFace must be set"))
+		}
+		if s.Source.HTTP != "" && !strings.HasPrefix(s.Source.HTTP, "http://") && !strings.HasPrefix(s.Source.HTTP, "https://") {
+			errs = append(errs, field.Invalid(srcPath.Child("http"), s.Source.HTTP, "must be an http or https URL"))
+		}
+		if s.Source.HuggingFace != nil {
+			errs = append(errs, validateHuggingFace(s.Source.HuggingFace, srcPath.Child("huggingFace"))...)
+		}
+		if s.Sample != nil {
+			errs = append(errs, field.Forbidden(path.Child("sample"), "sample is not supported by the built-in loaders of source"))
+		}
+	} else {
+		errs = append(errs, validateBuild(s.Build, path)...)
+	}
+	if sample := s.Sample; sample != nil {
+		samplePath := path.Child("sample")
+		if (sample.Count != 0) == (sample.Fraction != "") {
+			errs = append(errs, field.Invalid(samplePath, "", "exactly one of count or fraction must be set"))
+		}
+		if sample.Count < 0 {
+			errs = append(errs, field.Invalid(samplePath.Child("count"), sample.Count, "must be greater than or equal to 1"))
+		}
+		if sample.Fraction != "" && !sampleFractionPattern.MatchString(sample.Fraction) {
+			errs = append(errs, field.Invalid(samplePath.Child("fraction"), sample.Fraction, "must be a decimal between 0 and 1"))
+		}
+	}
+	if s.Format != "" {
+		errs = append(errs, validateEnum(path.Child("format"), string(s.Format), "jsonl", "json", "csv", "parquet", "text", "images")...)
+	}
+	errs = append(errs, validateTimeout(s.Timeout, path.Child("timeout"))...)
+	errs = append(errs, validateResources(s.Resources, path.Child("resources"), cloudName)...)
+	errs = append(errs, validatePriorityClassName(s.PriorityClassName, path.Child("priorityClassName"))...)
+	errs = append(errs, validateContainers(s.InitContainers, nil, "load", path)...)
+	return errs
+}
+
+func validateServer(s *apiv1.ServerSpec, path *field.Path, cloudName string) field.ErrorList {
+	var errs field.ErrorList
+	errs = append(errs, validateBuild(s.Build, path)...)
+	errs = append(errs, validateLocalObjectRef(&s.Model.ObjectRef, path.Child("model"), "Server")...)
+	if s.Model.Revision < 0 {
+		errs = append(errs, field.Invalid(path.Child("model", "revision"), s.Model.Revision, "must be greater than or equal to 1"))
+	}
+	if s.Model.Revision != 0 && s.Model.Checksum != "" {
+		errs = append(errs, field.Forbidden(path.Child("model"), "revision and checksum are mutually exclusive"))
+	}
+	errs = append(errs, validateServerProbe(s.ReadinessProbe, path.Child("readinessProbe"))...)
+	errs = append(errs, validateServerProbe(s.StartupProbe, path.Child("startupProbe"))...)
+	if s.Replicas != nil && *s.Replicas < 0 {
+		errs = append(errs, field.Invalid(path.Child("replicas"), *s.Replicas, "must be greater than or equal to 0"))
+	}
+	if s.Port != nil && (*s.Port < 1 || *s.Port > 65535) {
+		errs = append(errs, field.Invalid(path.Child("port"), *s.Port, "must be between 1 and 65535"))
+	}
+	if e := s.Expose; e != nil {
+		exposePath := path.Child("expose")
+		if e.Type != "" {
+			errs = append(errs, validateEnum(exposePath.Child("type"), string(e.Type),
+				string(apiv1.ServerExposeClusterIP), string(apiv1.ServerExposeLoadBalancer), string(apiv1.ServerExposeIngress))...)
+		}
+		if e.Type != apiv1.ServerExposeIngress && (e.Host != "" || e.Path != "" || e.TLSSecretName != "" || e.IngressClassName != nil) {
+			errs = append(errs, field.Forbidden(exposePath, "host, path, tlsSecretName and ingressClassName are only supported for the Ingress type"))
+		}
+	}
+	if a := s.Autoscaling; a != nil {
+		asPath := path.Child("autoscaling")
+		if a.MaxReplicas < 1 {
+			errs = append(errs, field.Invalid(asPath.Child("maxReplicas"), a.MaxReplicas, "must be greater than or equal to 1"))
+		}
+		if a.MinReplicas > a.MaxReplicas {
+			errs = append(errs, field.Invalid(asPath.Child("minReplicas"), a.MinReplicas, "must not be greater than maxReplicas"))
+		}
+		for _, t := range []struct {
+			name  string
+			value *int32
+		}{
+			{"targetGPUUtilization", a.TargetGPUUtilization},
+			{"targetCPUUtilization", a.TargetCPUUtilization},
+		} {
+			if t.value != nil && (*t.value < 1 || *t.value > 100) {
+				errs = append(errs, field.Invalid(asPath.Child(t.name), *t.value, "must be between 1 and 100"))
+			}
+		}
+		if a.TargetConcurrency == nil && a.TargetGPUUtilization == nil && a.TargetCPUUtilization == nil {
+			errs = append(errs, field.Required(asPath, "at least one target must be set"))
+		}
+	}
+	errs = append(errs, validateResources(s.Resources, path.Child("resources"), cloudName)...)
+	errs = append(errs, validatePriorityClassName(s.PriorityClassName, path.Child("priorityClassName"))...)
+	errs = append(errs, validateContainers(nil, s.Sidecars, "serve", path)...)
+	return errs
+}
+
+// validateBuild checks the build of an image. An omitted image and build is
+// valid as the CLI specifies an upload when applying (i.e. with sub run).
+func validateBuild(build *apiv1.Build, path *field.Path) field.ErrorList {
+	if build == nil {
+		return nil
+	}
+
+	var errs field.ErrorList
+	buildPath := path.Child("build")
+	if (build.Git != nil) == (build.Upload != nil) {
+		errs = append(errs, field.Invalid(buildPath, "", "exactly one of git or upload must be set"))
+	}
+	if g := build.Git; g != nil {
+		if g.URL == "" {
+			errs = append(errs, field.Required(buildPath.Child("git", "url"), ""))
+		}
+		errs = append(errs, validateObjectRef(g.SecretRef, buildPath.Child("git", "secretRef"))...)
+		if g.Tag != "" && g.Branch != "" {
+			errs = append(errs, field.Forbidden(buildPath.Child("git", "branch"), "tag and branch are mutually exclusive"))
+		}
+	}
+	if u := build.Upload; u != nil && !md5Pattern.MatchString(u.MD5Checksum) {
+		errs = append(errs, field.Invalid(buildPath.Child("upload", "md5Checksum"), u.MD5Checksum, "must be a hex encoded md5 checksum"))
+	}
+	for _, name := range sortedKeys(build.Args) {
+		if !buildArgNamePattern.MatchString(name) {
+			errs = append(errs, field.Invalid(buildPath.Child("args").Key(name), name, "must be a valid build argument name"))
+		}
+	}
+	if build.Target != "" && !buildTargetPattern.MatchString(build.Target) {
+		errs = append(errs, field.Invalid(buildPath.Child("target"), build.Target, "must be a valid Dockerfile stage name"))
+	}
+	if build.BuilderImage != "" && strings.TrimSpace(build.BuilderImage) != build.BuilderImage {
+		errs = append(errs, field.Invalid(buildPath.Child("builderImage"), build.BuilderImage, "must not contain leading or trailing whitespace"))
+	}
+	return errs
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateObjectRef(ref *apiv1.ObjectRef, path *field.Path) field.ErrorList {
+	if ref == nil {
+		return nil
+	}
+	if ref.Name == "" {
+		return field.ErrorList{field.Required(path.Child("name"), "")}
+	}
+	return nil
+}
+
+// validateLocalObjectRef checks a reference that must be in the namespace of
+// the referencing object.
+func validateLocalObjectRef(ref *apiv1.ObjectRef, path *field.Path, kind string) field.ErrorList {
+	errs := validateObjectRef(ref, path)
+	if ref != nil && ref.Namespace != "" {
+		errs = append(errs, field.Forbidden(path.Child("namespace"), fmt.Sprintf("must be in the same namespace as the %s", kind)))
+	}
+	return errs
+}
+
+func validateHuggingFace(hf *apiv1.HuggingFaceSource, path *field.Path) field.ErrorList {
+	if hf.Repo == "" {
+		return field.ErrorList{field.Required(path.Child("repo"), "")}
+	}
+	return nil
+}
+
+func validatePriorityClassName(name string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if name != "" {
+		for _, msg := range validation.NameIsDNSSubdomain(name, false) {
+			errs = append(errs, field.Invalid(path, name, msg))
+		}
+	}
+	return errs
+}
+
+func validateTimeout(timeout *metav1.Duration, path *field.Path) field.ErrorList {
+	if timeout != nil && timeout.Duration <= 0 {
+		return field.ErrorList{field.Invalid(path, timeout.Duration.String(), "must be positive")}
+	}
+	return nil
+}
+
+func validateServerProbe(probe *apiv1.ServerProbe, path *field.Path) field.ErrorList {
+	if probe == nil || probe.File == "" {
+		return nil
+	}
+	if probe.Path != "" || probe.Port != nil {
+		return field.ErrorList{field.Forbidden(path.Child("file"), "can not be combined with path or port")}
+	}
+	return nil
+}
+
+// reservedContainerNames are the names of containers that are added to Pods
+// besides the main container of the object.
+var reservedContainerNames = map[string]bool{
+	"gke-gcsfuse-sidecar": true,
+}
+
+// validateContainers checks that the init and sidecar containers have
+// unique names that do not collide with the main container (named main).
+func validateContainers(initContainers, sidecars []corev1.Container, main string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	names := map[string]bool{main: true}
+	type indexed struct {
+		path *field.Path
+		c    corev1.Container
+	}
+	var all []indexed
+	for i, c := range initContainers {
+		all = append(all, indexed{path.Child("initContainers").Index(i), c})
+	}
+	for i, c := range sidecars {
+		all = append(all, indexed{path.Child("sidecars").Index(i), c})
+	}
+	for _, ic := range all {
+		p, c := ic.path, ic.c
+		switch {
+		case c.Name == "":
+			errs = append(errs, field.Required(p.Child("name"), ""))
+		case names[c.Name] || reservedContainerNames[c.Name]:
+			errs = append(errs, field.Duplicate(p.Child("name"), c.Name))
+		default:
+			for _, msg := range k8svalidation.IsDNS1123Label(c.Name) {
+				errs = append(errs, field.Invalid(p.Child("name"), c.Name, msg))
+			}
+		}
+		names[c.Name] = true
+		if c.Image == "" {
+			errs = append(errs, field.Required(p.Child("image"), ""))
+		}
+	}
+	return errs
+}
+
+func validateEnum(path *field.Path, value string, valid ...string) field.ErrorList {
+	for _, v := range valid {
+		if value == v {
+			return nil
+		}
+	}
+	return field.ErrorList{field.NotSupported(path, value, valid)}
+}
+
+// validateResources checks for sane resource values and that the GPU type
+// can be requested on the cloud.
+func validateResources(res *apiv1.Resources, path *field.Path, cloudName string) field.ErrorList {
+	if res == nil {
+		return nil
+	}
+	var errs field.ErrorList
+	for _, r := range []struct {
+		name  string
+		value int64
+	}{
+		{"cpu", res.CPU},
+		{"memory", res.Memory},
+		{"disk", res.Disk},
+	} {
+		if r.value < 0 {
+			errs = append(errs, field.Invalid(path.Child(r.name), r.value, "must not be negative"))
+		}
+	}
+	if res.NodePool != "" {
+		for _, msg := range k8svalidation.IsDNS1123Label(res.NodePool) {
+			errs = append(errs, field.Invalid(path.Child("nodePool"), res.NodePool, msg))
+		}
+	}
+	if res.Nodes < 0 {
+		errs = append(errs, field.Invalid(path.Child("nodes"), res.Nodes, "must not be negative (omit for a single node)"))
+	}
+	if g := res.GPU; g != nil {
+		if err := resources.ValidateGPUType(cloudName, g.Type); err != nil {
+			errs = append(errs, field.Invalid(path.Child("gpu", "type"), string(g.Type), err.Error()))
+		}
+		if g.Count < 1 {
+			errs = append(errs, field.Invalid(path.Child("gpu", "count"), g.Count, "must be greater than or equal to 1"))
+		}
+		if err := resources.ValidateGPUSharing(cloudName, g); err != nil {
+			errs = append(errs, field.Invalid(path.Child("gpu", "sharing"), g.Sharing.Strategy, err.Error()))
+		}
+	}
+	if l := res.Limits; l != nil {
+		limitsPath := path.Child("limits")
+		if l.CPU != 0 && l.CPU < res.CPU {
+			errs = append(errs, field.Invalid(limitsPath.Child("cpu"), l.CPU, fmt.Sprintf("must not be less than the requested cpu (%d)", res.CPU)))
+		}
+		if l.Memory != 0 && l.Memory < res.Memory {
+			errs = append(errs, field.Invalid(limitsPath.Child("memory"), l.Memory, fmt.Sprintf("must not be less than the requested memory (%d)", res.Memory)))
+		}
+		if l.Disk != 0 && l.Disk < res.Disk {
+			errs = append(errs, field.Invalid(limitsPath.Child("disk"), l.Disk, fmt.Sprintf("must not be less than the requested disk (%d)", res.Disk)))
+		}
+	}
+	return errs
+}
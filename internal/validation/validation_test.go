@@ -0,0 +1,235 @@
+package validation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apiv1 "github.com/substratusai/substratus/api/v1"
+	"github.com/substratusai/substratus/internal/cloud"
+	"github.com/substratusai/substratus/internal/validation"
+)
+
+func TestValidate(t *testing.T) {
+	meta := metav1.ObjectMeta{Name: "test"}
+
+	cases := []struct {
+		name     string
+		obj      client.Object
+		expected []string
+	}{
+		{
+			name: "valid model",
+			obj: &apiv1.Model{ObjectMeta: meta, Spec: apiv1.ModelSpec{
+				Image:     ptr.To("substratusai/model-trainer-huggingface"),
+				Model:     &apiv1.ObjectRef{Name: "falcon-7b"},
+				Dataset:   &apiv1.ObjectRef{Name: "squad"},
+				Resources: &apiv1.Resources{CPU: 4, GPU: &apiv1.GPUResources{Type: apiv1.GPUTypeNvidiaL4, Count: 1}},
+				Timeout:   &metav1.Duration{Duration: time.Hour},
+			}},
+		},
+		{
+			name: "invalid model",
+			obj: &apiv1.Model{ObjectMeta: metav1.ObjectMeta{Name: "Test_Model"}, Spec: apiv1.ModelSpec{
+				Dataset:  &apiv1.ObjectRef{Name: "squad"},
+				Datasets: []apiv1.ObjectRef{{}},
+				Resources: &apiv1.Resources{
+					CPU:    4,
+					GPU:    &apiv1.GPUResources{Type: "nvidia-h100", Count: 0},
+					Limits: &apiv1.ResourceLimits{CPU: 2},
+				},
+			}},
+			expected: []string{
+				"metadata.name",
+				"spec.datasets",
+				"spec.datasets[0].name",
+				"spec.resources.gpu.type",
+				"spec.resources.gpu.count",
+				"spec.resources.limits.cpu",
+			},
+		},
+		{
+			name: "server autoscaling without targets",
+			obj: &apiv1.Server{ObjectMeta: meta, Spec: apiv1.ServerSpec{
+				Model:       apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: "falcon-7b"}},
+				Autoscaling: &apiv1.ServerAutoscaling{MinReplicas: 1, MaxReplicas: 2},
+			}},
+			expected: []string{"spec.autoscaling"},
+		},
+		{
+			name: "model with duplicate datasets",
+			obj: &apiv1.Model{ObjectMeta: meta, Spec: apiv1.ModelSpec{
+				Image: ptr.To("img"),
+				Datasets: []apiv1.ObjectRef{
+					{Name: "squad"},
+					{Name: "squad", Namespace: "shared"},
+				},
+			}},
+			expected: []string{"spec.datasets[1].name"},
+		},
+		{
+			name: "model source combined with image",
+			obj: &apiv1.Model{ObjectMeta: meta, Spec: apiv1.ModelSpec{
+				Image:  ptr.To("img"),
+				Source: &apiv1.ModelSource{HuggingFace: &apiv1.HuggingFaceSource{}},
+			}},
+			expected: []string{"spec.source", "spec.source.huggingFace.repo"},
+		},
+		{
+			name: "dataset source",
+			obj: &apiv1.Dataset{ObjectMeta: meta, Spec: apiv1.DatasetSpec{
+				Format: "xml",
+				Source: &apiv1.DatasetSource{HTTP: "ftp://example.com/data.csv"},
+			}},
+			expected: []string{"spec.source.http", "spec.format"},
+		},
+		{
+			name: "dataset sample",
+			obj: &apiv1.Dataset{ObjectMeta: meta, Spec: apiv1.DatasetSpec{
+				Image:  ptr.To("img"),
+				Sample: &apiv1.DatasetSample{Fraction: "1.5"},
+			}},
+			expected: []string{"spec.sample.fraction"},
+		},
+		{
+			name: "dataset sample with source",
+			obj: &apiv1.Dataset{ObjectMeta: meta, Spec: apiv1.DatasetSpec{
+				Source: &apiv1.DatasetSource{HTTP: "https://example.com/data.csv"},
+				Sample: &apiv1.DatasetSample{Count: 10, Fraction: "0.1"},
+			}},
+			expected: []string{"spec.sample", "spec.sample"},
+		},
+		{
+			name: "dataset build",
+			obj: &apiv1.Dataset{ObjectMeta: meta, Spec: apiv1.DatasetSpec{
+				Build: &apiv1.Build{
+					Git:    &apiv1.BuildGit{Tag: "v1", Branch: "main"},
+					Upload: &apiv1.BuildUpload{MD5Checksum: "abc"},
+				},
+			}},
+			expected: []string{"spec.build", "spec.build.git.url", "spec.build.git.branch", "spec.build.upload.md5Checksum"},
+		},
+		{
+			name: "dataset build options",
+			obj: &apiv1.Dataset{ObjectMeta: meta, Spec: apiv1.DatasetSpec{
+				Build: &apiv1.Build{
+					Git:    &apiv1.BuildGit{URL: "https://github.com/substratusai/dataset-squad"},
+					Args:   map[string]string{"PYTHON_VERSION": "3.11", "1BAD": "x", "bad-name": "y"},
+					Target: "-runtime",
+				},
+			}},
+			expected: []string{"spec.build.args[1BAD]", "spec.build.args[bad-name]", "spec.build.target"},
+		},
+		{
+			name: "server",
+			obj: &apiv1.Server{ObjectMeta: meta, Spec: apiv1.ServerSpec{
+				Port:              ptr.To(int32(0)),
+				PriorityClassName: "High_Priority",
+				Expose:            &apiv1.ServerExpose{Type: apiv1.ServerExposeLoadBalancer, Host: "example.com"},
+				Autoscaling:       &apiv1.ServerAutoscaling{MinReplicas: 3, MaxReplicas: 2, TargetCPUUtilization: ptr.To(int32(120))},
+			}},
+			expected: []string{
+				"spec.model.name",
+				"spec.port",
+				"spec.expose",
+				"spec.autoscaling.minReplicas",
+				"spec.autoscaling.targetCPUUtilization",
+				"spec.priorityClassName",
+			},
+		},
+		{
+			name: "server model pin and probes",
+			obj: &apiv1.Server{ObjectMeta: meta, Spec: apiv1.ServerSpec{
+				Model:          apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: "falcon-7b"}, Revision: 2, Checksum: "abc"},
+				ReadinessProbe: &apiv1.ServerProbe{File: "config.json", Path: "/health"},
+				StartupProbe:   &apiv1.ServerProbe{File: "config.json"},
+			}},
+			expected: []string{"spec.model", "spec.readinessProbe.file"},
+		},
+		{
+			name: "dataset init containers",
+			obj: &apiv1.Dataset{ObjectMeta: meta, Spec: apiv1.DatasetSpec{
+				Image: ptr.To("img"),
+				InitContainers: []corev1.Container{
+					{Name: "proxy", Image: "envoy"},
+					{Name: "load", Image: "busybox"},
+					{Name: "proxy"},
+					{Name: "Exporter", Image: "exporter"},
+				},
+			}},
+			expected: []string{
+				"spec.initContainers[1].name",
+				"spec.initContainers[2].name",
+				"spec.initContainers[2].image",
+				"spec.initContainers[3].name",
+			},
+		},
+		{
+			name: "server sidecars",
+			obj: &apiv1.Server{ObjectMeta: meta, Spec: apiv1.ServerSpec{
+				Image:    ptr.To("img"),
+				Model:    apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: "falcon-7b"}},
+				Sidecars: []corev1.Container{{Name: "serve", Image: "envoy"}},
+			}},
+			expected: []string{"spec.sidecars[0].name"},
+		},
+		{
+			name: "notebook",
+			obj: &apiv1.Notebook{ObjectMeta: meta, Spec: apiv1.NotebookSpec{
+				Resources: &apiv1.Resources{Memory: -1, NodePool: "A100_Pool", Nodes: -1},
+			}},
+			expected: []string{"spec.resources.memory", "spec.resources.nodePool", "spec.resources.nodes"},
+		},
+		{
+			name: "notebook model revision without model",
+			obj: &apiv1.Notebook{ObjectMeta: meta, Spec: apiv1.NotebookSpec{
+				ModelRevision: 2,
+			}},
+			expected: []string{"spec.model"},
+		},
+		{
+			name: "notebook refs in other namespaces",
+			obj: &apiv1.Notebook{ObjectMeta: meta, Spec: apiv1.NotebookSpec{
+				Model:   &apiv1.ObjectRef{Name: "falcon-7b", Namespace: "shared"},
+				Dataset: &apiv1.ObjectRef{Name: "squad", Namespace: "shared"},
+			}},
+			expected: []string{"spec.model.namespace", "spec.dataset.namespace"},
+		},
+		{
+			name: "server model in other namespace",
+			obj: &apiv1.Server{ObjectMeta: meta, Spec: apiv1.ServerSpec{
+				Model: apiv1.ServerModelRef{ObjectRef: apiv1.ObjectRef{Name: "falcon-7b", Namespace: "shared"}},
+			}},
+			expected: []string{"spec.model.namespace"},
+		},
+		{
+			name: "notebook gpu sharing",
+			obj: &apiv1.Notebook{ObjectMeta: meta, Spec: apiv1.NotebookSpec{
+				Resources: &apiv1.Resources{GPU: &apiv1.GPUResources{
+					Type:    apiv1.GPUTypeNvidiaL4,
+					Count:   1,
+					Sharing: &apiv1.GPUSharing{Strategy: apiv1.GPUSharingMIG, Profile: "1g.5gb"},
+				}},
+			}},
+			expected: []string{"spec.resources.gpu.sharing"},
+		},
+		{
+			name: "other kinds are not checked",
+			obj:  &corev1.ConfigMap{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range validation.Validate(c.obj, cloud.GCPName) {
+				fields = append(fields, err.Field)
+			}
+			require.Equal(t, c.expected, fields)
+		})
+	}
+}